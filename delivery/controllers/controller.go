@@ -1,8 +1,17 @@
 package controllers
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"task_managment_api/domain"
+	"time"
 
 	//"time"
 
@@ -17,76 +26,1324 @@ type UserController struct {
 	userUsecase domain.UserUsecase
 }
 
+type CommentController struct {
+	commentUsecase domain.CommentUsecase
+}
+
+type LabelController struct {
+	labelUsecase domain.LabelUsecase
+}
+
+type ProjectController struct {
+	projectUsecase domain.ProjectUsecase
+}
+
+type WebhookController struct {
+	webhookUsecase domain.WebhookUsecase
+}
+
+type CalendarController struct {
+	calendarUsecase domain.CalendarUsecase
+}
+
+type RoleController struct {
+	roleUsecase domain.RoleUsecase
+}
+
+type UserAdminController struct {
+	userAdminUsecase domain.UserAdminUsecase
+}
+
 //task controllers
 
-func NewTaskController(taskUsecase domain.TaskUsecase) *TaskController {
-	return &TaskController{
-		taskUsecase: taskUsecase,
+func NewTaskController(taskUsecase domain.TaskUsecase) *TaskController {
+	return &TaskController{
+		taskUsecase: taskUsecase,
+	}
+}
+
+// getAuthUser reads the caller identity that AuthMiddleware stored in the context.
+func getAuthUser(c *gin.Context) domain.AuthUser {
+	return domain.AuthUser{
+		UserID:   c.GetString("userId"),
+		Username: c.GetString("username"),
+		Role:     c.GetString("role"),
+		SessionID: c.GetString("sessionId"),
+		TokenID:   c.GetString("tokenId"),
+		Permissions: getPermissions(c),
+	}
+}
+
+// getPermissions returns the permissions AuthMiddleware found for the caller's role.
+func getPermissions(c *gin.Context) []domain.Permission {
+	permissions, _ := c.Value("permissions").([]domain.Permission)
+	return permissions
+}
+
+// errorBody builds the JSON error response, naming the offending field for validation errors.
+func errorBody(err domain.CustomError) gin.H {
+	body := gin.H{"message": err.ErrMessage}
+	if err.Field != "" {
+		body["field"] = err.Field
+	}
+	for key, value := range err.Details {
+		body[key] = value
+	}
+	return body
+}
+
+// taskETag formats a task version as a strong entity tag.
+func taskETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch reads the optional If-Match header. No header or "*" means the update is unconditional;
+// a tag that is not one of ours can never match the stored version.
+func parseIfMatch(c *gin.Context) (*int64, domain.CustomError) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, domain.CustomError{}
+	}
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusPreconditionFailed, ErrMessage: "If-Match does not match the current version of the task", Field: "If-Match"}
+	}
+	return &version, domain.CustomError{}
+}
+
+// parseScope reads which occurrences of a recurring task an edit applies to.
+func parseScope(c *gin.Context) (domain.RecurrenceScope, domain.CustomError) {
+	scope, err := domain.ParseRecurrenceScope(c.Query("scope"))
+	if err != nil {
+		return "", domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: err.Error(), Field: "scope"}
+	}
+	return scope, domain.CustomError{}
+}
+
+// parseLimit reads the optional page size; zero means the usecase default.
+func parseLimit(c *gin.Context) (int64, domain.CustomError) {
+	limit := c.Query("limit")
+	if limit == "" {
+		return 0, domain.CustomError{}
+	}
+	parsed, err := strconv.ParseInt(limit, 10, 64)
+	if err != nil || parsed < 1 {
+		return 0, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "limit must be a positive integer", Field: "limit"}
+	}
+	return parsed, domain.CustomError{}
+}
+
+// parseTaskQuery reads the filter, sort and pagination parameters shared by the task listings.
+func parseTaskQuery(c *gin.Context) (domain.TaskQuery, domain.CustomError) {
+	query := domain.TaskQuery{
+		Status:     c.Query("status"),
+		Title:      c.Query("title"),
+		AssigneeID: c.Query("assignee"),
+		SortBy:     c.Query("sort"),
+		SortOrder:  c.Query("order"),
+		Cursor:     c.Query("cursor"),
+		LabelMatch: c.Query("label_match"),
+		ProjectID:  c.Query("project"),
+	}
+	for _, labelID := range strings.Split(c.Query("labels"), ",") {
+		if labelID = strings.TrimSpace(labelID); labelID != "" {
+			query.LabelIDs = append(query.LabelIDs, labelID)
+		}
+	}
+	limit, limitErr := parseLimit(c)
+	if limitErr.ErrCode != 0 {
+		return domain.TaskQuery{}, limitErr
+	}
+	query.Limit = limit
+
+	var err error
+	if query.DueFrom, err = domain.ParseDueDate(c.Query("due_from")); err != nil {
+		return domain.TaskQuery{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "due_from must be an RFC 3339 date-time or a YYYY-MM-DD date", Field: "due_from"}
+	}
+	if query.DueTo, err = domain.ParseDueDate(c.Query("due_to")); err != nil {
+		return domain.TaskQuery{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "due_to must be an RFC 3339 date-time or a YYYY-MM-DD date", Field: "due_to"}
+	}
+	return query, domain.CustomError{}
+}
+
+func (tc *TaskController) GetTasks(c *gin.Context) {
+	query, err := parseTaskQuery(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+
+	page, err := tc.taskUsecase.GetTasks(c, getAuthUser(c), query)
+	if err.ErrCode != 0  {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (tc *TaskController) SearchTasks(c *gin.Context) {
+	query, err := parseTaskQuery(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	query.Search = c.Query("q")
+
+	page, err := tc.taskUsecase.SearchTasks(c, getAuthUser(c), query)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (tc *TaskController) GetOverdueTasks(c *gin.Context) {
+	query, err := parseTaskQuery(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+
+	page, err := tc.taskUsecase.GetOverdueTasks(c, getAuthUser(c), query)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (tc *TaskController) GetUpcomingTasks(c *gin.Context) {
+	query, err := parseTaskQuery(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+
+	within := domain.DefaultUpcomingWindow
+	if value := c.Query("within"); value != "" {
+		parsed, parseErr := time.ParseDuration(value)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "within must be a duration such as 72h", "field": "within"})
+			return
+		}
+		within = parsed
+	}
+
+	page, err := tc.taskUsecase.GetUpcomingTasks(c, getAuthUser(c), within, query)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (tc *TaskController) GetTaskByID(c *gin.Context) {
+	id := c.Param("id")
+	task, err := tc.taskUsecase.GetTaskByID(c, getAuthUser(c), id)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.Header("ETag", taskETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
+func (tc *TaskController) UpdateTaskByID(c *gin.Context) {
+	id := c.Param("id")
+	expectedVersion, err := parseIfMatch(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	scope, err := parseScope(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	var input domain.TaskInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+	task, err := tc.taskUsecase.UpdateTaskByID(c, getAuthUser(c), id, input, expectedVersion, scope)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.Header("ETag", taskETag(task.Version))
+	c.JSON(http.StatusOK, gin.H{"message": "Task updated successfully", "version": task.Version})
+}
+
+// PatchTaskByID accepts a JSON Merge Patch (RFC 7396); an explicit null clears a field.
+func (tc *TaskController) PatchTaskByID(c *gin.Context) {
+	id := c.Param("id")
+	if contentType := c.ContentType(); contentType != "application/merge-patch+json" && contentType != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"message": "PATCH expects application/merge-patch+json"})
+		return
+	}
+	expectedVersion, err := parseIfMatch(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	scope, err := parseScope(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	var patch domain.TaskPatch
+	body, readErr := c.GetRawData()
+	if readErr != nil || json.Unmarshal(body, &patch) != nil || patch == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+	task, err := tc.taskUsecase.PatchTaskByID(c, getAuthUser(c), id, patch, expectedVersion, scope)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.Header("ETag", taskETag(task.Version))
+	c.JSON(http.StatusOK, gin.H{"message": "Task updated successfully", "version": task.Version})
+}
+
+// GetOccurrences previews the due dates of the next occurrences of a recurring task.
+func (tc *TaskController) GetOccurrences(c *gin.Context) {
+	id := c.Param("id")
+	count := 0
+	if value := c.Query("count"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "count must be a positive integer", "field": "count"})
+			return
+		}
+		count = parsed
+	}
+
+	preview, err := tc.taskUsecase.GetOccurrences(c, getAuthUser(c), id, count)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+func (tc *TaskController) TransitionTask(c *gin.Context) {
+	id := c.Param("id")
+	var transition domain.TaskTransitionRequest
+	if err := c.ShouldBindJSON(&transition); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	err := tc.taskUsecase.TransitionTask(c, getAuthUser(c), id, transition.Status)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Task status changed successfully"})
+}
+
+func (tc *TaskController) DeleteTaskByID(c *gin.Context) {
+	id := c.Param("id")
+	err := tc.taskUsecase.DeleteTaskByID(c, getAuthUser(c), id)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Task moved to trash"})
+}
+
+func (tc *TaskController) GetTrash(c *gin.Context) {
+	query, err := parseTaskQuery(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+
+	page, err := tc.taskUsecase.GetTrash(c, getAuthUser(c), query)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (tc *TaskController) RestoreTaskByID(c *gin.Context) {
+	id := c.Param("id")
+	task, err := tc.taskUsecase.RestoreTaskByID(c, getAuthUser(c), id)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.Header("ETag", taskETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
+func (tc *TaskController) PurgeTaskByID(c *gin.Context) {
+	id := c.Param("id")
+	err := tc.taskUsecase.PurgeTaskByID(c, getAuthUser(c), id)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Task permanently deleted"})
+}
+
+func (tc *TaskController) CreateTask(c *gin.Context) {
+	var task domain.TaskInput
+	if err := c.ShouldBindJSON(&task); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+	
+	created, err := tc.taskUsecase.CreateTask(c, getAuthUser(c), task)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	
+	c.JSON(http.StatusCreated, gin.H{"message": "Task created successfully", "id": created.ID})
+}
+
+// ExportTasks streams the caller's tasks, filtered like GET /tasks, as CSV, a JSON array or NDJSON. The
+// response only starts with the first page, so errors found before it are still answered as JSON.
+func (tc *TaskController) ExportTasks(c *gin.Context) {
+	format, parseErr := domain.ParseTransferFormat(c.Query("format"))
+	if parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": parseErr.Error(), "field": "format"})
+		return
+	}
+	query, err := parseTaskQuery(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+
+	export := &taskExport{c: c, format: format}
+	err = tc.taskUsecase.ExportTasks(c, getAuthUser(c), query, export.write)
+	if err.ErrCode != 0 {
+		if !export.started {
+			c.JSON(err.ErrCode, errorBody(err))
+			return
+		}
+		// the status is already sent; leaving the export unfinished tells the client it is incomplete
+		log.Printf("task export stopped after %d tasks: %s", export.count, err.ErrMessage)
+		return
+	}
+	export.finish()
+}
+
+// taskExport writes the pages of an export to the response as they arrive.
+type taskExport struct {
+	c       *gin.Context
+	format  string
+	csv     *csv.Writer
+	started bool
+	count   int
+}
+
+func (e *taskExport) start() {
+	contentType := "application/json"
+	switch e.format {
+	case domain.FormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case domain.FormatNDJSON:
+		contentType = "application/x-ndjson"
+	}
+	e.c.Header("Content-Type", contentType)
+	e.c.Header("Content-Disposition", `attachment; filename="tasks.`+e.format+`"`)
+	e.c.Status(http.StatusOK)
+
+	switch e.format {
+	case domain.FormatCSV:
+		e.csv = csv.NewWriter(e.c.Writer)
+		e.csv.Write(domain.TaskCSVHeader)
+	case domain.FormatJSON:
+		e.c.Writer.WriteString("[")
+	}
+	e.started = true
+}
+
+func (e *taskExport) write(tasks []domain.Task) error {
+	if !e.started {
+		e.start()
+	}
+	for _, task := range tasks {
+		if e.format == domain.FormatCSV {
+			if err := e.csv.Write(domain.TaskCSVRecord(task)); err != nil {
+				return err
+			}
+			e.count++
+			continue
+		}
+		data, err := json.Marshal(task)
+		if err != nil {
+			return err
+		}
+		switch {
+		case e.format == domain.FormatNDJSON:
+			data = append(data, '\n')
+		case e.count > 0:
+			data = append([]byte(",\n"), data...)
+		default:
+			data = append([]byte("\n"), data...)
+		}
+		if _, err := e.c.Writer.Write(data); err != nil {
+			return err
+		}
+		e.count++
+	}
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	e.c.Writer.Flush()
+	return nil
+}
+
+func (e *taskExport) finish() {
+	if !e.started {
+		e.start()
+	}
+	if e.format == domain.FormatJSON {
+		if e.count > 0 {
+			e.c.Writer.WriteString("\n")
+		}
+		e.c.Writer.WriteString("]\n")
+	}
+	if e.csv != nil {
+		e.csv.Flush()
+	}
+	e.c.Writer.Flush()
+}
+
+// ImportTasks creates tasks from an uploaded CSV, JSON or NDJSON file sent as the "file" form field. The
+// format comes from ?format= or else the file's extension. With ?dry_run=true the file is only checked.
+// A file with invalid rows answers 422 with the report and creates nothing.
+func (tc *TaskController) ImportTasks(c *gin.Context) {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, parseErr := strconv.ParseBool(value)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "dry_run must be true or false", "field": "dry_run"})
+			return
+		}
+		dryRun = parsed
+	}
+
+	// leave room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, domain.MaxImportSize+1<<20)
+	file, header, fileErr := c.Request.FormFile("file")
+	if fileErr != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(fileErr, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "The file is larger than 5 MB"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Upload the tasks as the file form field", "field": "file"})
+		return
+	}
+	defer file.Close()
+	data, readErr := io.ReadAll(io.LimitReader(file, domain.MaxImportSize+1))
+	if readErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "The file could not be read", "field": "file"})
+		return
+	}
+	if len(data) > domain.MaxImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "The file is larger than 5 MB"})
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+		if format == "jsonl" {
+			format = domain.FormatNDJSON
+		}
+	}
+	format, parseErr := domain.ParseTransferFormat(format)
+	if parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": parseErr.Error(), "field": "format"})
+		return
+	}
+	rows, err := domain.ParseTaskImport(format, data)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+
+	report, err := tc.taskUsecase.ImportTasks(c, getAuthUser(c), rows, dryRun)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	switch {
+	case len(report.Errors) > 0:
+		c.JSON(http.StatusUnprocessableEntity, report)
+	case dryRun:
+		c.JSON(http.StatusOK, report)
+	default:
+		c.JSON(http.StatusCreated, report)
+	}
+}
+
+// BatchTasks serves POST /tasks:batch. gin reads ":batch" as a path parameter, so the route also matches
+// other paths that start with /tasks and only the literal one is served. A rolled back atomic batch
+// answers 409 with the same per-item results.
+func (tc *TaskController) BatchTasks(c *gin.Context) {
+	if c.Param("batch") != ":batch" {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Not found"})
+		return
+	}
+	var request domain.BatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	response, err := tc.taskUsecase.BatchTasks(c, getAuthUser(c), request)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	if response.RolledBack {
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (tc *TaskController) GetTaskHistory(c *gin.Context) {
+	id := c.Param("id")
+	limit, err := parseLimit(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+
+	page, err := tc.taskUsecase.GetTaskHistory(c, getAuthUser(c), id, c.Query("cursor"), limit)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (tc *TaskController) GetSubtasks(c *gin.Context) {
+	id := c.Param("id")
+	query, err := parseTaskQuery(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+
+	page, err := tc.taskUsecase.GetSubtasks(c, getAuthUser(c), id, query)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (tc *TaskController) AddChecklistItem(c *gin.Context) {
+	id := c.Param("id")
+	var input domain.ChecklistItemCreate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	item, err := tc.taskUsecase.AddChecklistItem(c, getAuthUser(c), id, input.Text)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+func (tc *TaskController) UpdateChecklistItem(c *gin.Context) {
+	id := c.Param("id")
+	var update domain.ChecklistItemUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	item, err := tc.taskUsecase.UpdateChecklistItem(c, getAuthUser(c), id, c.Param("itemId"), update)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func (tc *TaskController) RemoveChecklistItem(c *gin.Context) {
+	id := c.Param("id")
+	err := tc.taskUsecase.RemoveChecklistItem(c, getAuthUser(c), id, c.Param("itemId"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Checklist item removed successfully"})
+}
+
+func (tc *TaskController) GetDependencies(c *gin.Context) {
+	id := c.Param("id")
+	graph, err := tc.taskUsecase.GetDependencies(c, getAuthUser(c), id)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
+
+func (tc *TaskController) AddDependency(c *gin.Context) {
+	id := c.Param("id")
+	var input domain.TaskDependencyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	err := tc.taskUsecase.AddDependency(c, getAuthUser(c), id, input.BlockerID)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Dependency added successfully"})
+}
+
+func (tc *TaskController) RemoveDependency(c *gin.Context) {
+	id := c.Param("id")
+	err := tc.taskUsecase.RemoveDependency(c, getAuthUser(c), id, c.Param("blockerId"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Dependency removed successfully"})
+}
+
+func (tc *TaskController) AssignUsers(c *gin.Context) {
+	id := c.Param("id")
+	var assignees domain.TaskAssignees
+	if err := c.ShouldBindJSON(&assignees); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	err := tc.taskUsecase.AssignUsers(c, getAuthUser(c), id, assignees.UserIDs)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Users assigned successfully"})
+}
+
+func (tc *TaskController) AddLabels(c *gin.Context) {
+	id := c.Param("id")
+	var labels domain.TaskLabels
+	if err := c.ShouldBindJSON(&labels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	err := tc.taskUsecase.AddLabels(c, getAuthUser(c), id, labels.LabelIDs)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Labels added successfully"})
+}
+
+func (tc *TaskController) RemoveLabel(c *gin.Context) {
+	id := c.Param("id")
+	err := tc.taskUsecase.RemoveLabel(c, getAuthUser(c), id, c.Param("labelId"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Label removed successfully"})
+}
+
+func (tc *TaskController) UnassignUser(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.Param("userId")
+	err := tc.taskUsecase.UnassignUser(c, getAuthUser(c), id, userID)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User unassigned successfully"})
+}
+
+//comment controllers
+
+func NewCommentController(commentUsecase domain.CommentUsecase) *CommentController {
+	return &CommentController{
+		commentUsecase: commentUsecase,
+	}
+}
+
+func (cc *CommentController) CreateComment(c *gin.Context) {
+	id := c.Param("id")
+	var input domain.CommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	comment, err := cc.commentUsecase.CreateComment(c, getAuthUser(c), id, input)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (cc *CommentController) GetComments(c *gin.Context) {
+	id := c.Param("id")
+	limit, err := parseLimit(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+
+	page, err := cc.commentUsecase.GetComments(c, getAuthUser(c), id, c.Query("cursor"), limit)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (cc *CommentController) UpdateComment(c *gin.Context) {
+	id := c.Param("id")
+	var update domain.CommentUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	comment, err := cc.commentUsecase.UpdateComment(c, getAuthUser(c), id, c.Param("commentId"), update.Body)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+func (cc *CommentController) DeleteComment(c *gin.Context) {
+	id := c.Param("id")
+	err := cc.commentUsecase.DeleteComment(c, getAuthUser(c), id, c.Param("commentId"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+func (cc *CommentController) GetMentions(c *gin.Context) {
+	limit, err := parseLimit(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+
+	page, err := cc.commentUsecase.GetMentions(c, getAuthUser(c), c.Query("cursor"), limit)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+//label controllers
+
+func NewLabelController(labelUsecase domain.LabelUsecase) *LabelController {
+	return &LabelController{
+		labelUsecase: labelUsecase,
+	}
+}
+
+func (lc *LabelController) GetLabels(c *gin.Context) {
+	labels, err := lc.labelUsecase.GetLabels(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, labels)
+}
+
+func (lc *LabelController) GetLabelByID(c *gin.Context) {
+	label, err := lc.labelUsecase.GetLabelByID(c, c.Param("id"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
 	}
+	c.JSON(http.StatusOK, label)
 }
 
-func (tc *TaskController) GetTasks(c *gin.Context) {
-	tasks, err := tc.taskUsecase.GetTasks(c)
-	if err.ErrCode != 0  {
-		c.JSON(err.ErrCode, gin.H{"message": err.ErrMessage})
+func (lc *LabelController) CreateLabel(c *gin.Context) {
+	var input domain.LabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
 		return
 	}
-	if len(tasks) == 0 {
-		c.JSON(err.ErrCode, gin.H{"message": err.ErrMessage})
+
+	label, err := lc.labelUsecase.CreateLabel(c, input)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
 		return
 	}
-	c.JSON(http.StatusOK, tasks)
+	c.JSON(http.StatusCreated, label)
 }
 
-func (tc *TaskController) GetTaskByID(c *gin.Context) {
-	id := c.Param("id")
-	task, err := tc.taskUsecase.GetTaskByID(c, id)
+func (lc *LabelController) UpdateLabel(c *gin.Context) {
+	var input domain.LabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	label, err := lc.labelUsecase.UpdateLabel(c, c.Param("id"), input)
 	if err.ErrCode != 0 {
-		c.JSON(err.ErrCode, gin.H{"message": err.ErrMessage})
+		c.JSON(err.ErrCode, errorBody(err))
 		return
 	}
-	c.JSON(http.StatusOK, task)
+	c.JSON(http.StatusOK, label)
 }
 
-func (tc *TaskController) UpdateTaskByID(c *gin.Context) {
-	id := c.Param("id")
-	var task domain.Task
-	if err := c.ShouldBindJSON(&task); err != nil {
+func (lc *LabelController) DeleteLabel(c *gin.Context) {
+	err := lc.labelUsecase.DeleteLabel(c, c.Param("id"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Label deleted successfully"})
+}
+
+//webhook controllers
+
+func NewWebhookController(webhookUsecase domain.WebhookUsecase) *WebhookController {
+	return &WebhookController{
+		webhookUsecase: webhookUsecase,
+	}
+}
+
+func (wc *WebhookController) GetWebhooks(c *gin.Context) {
+	webhooks, err := wc.webhookUsecase.GetWebhooks(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (wc *WebhookController) GetWebhookByID(c *gin.Context) {
+	webhook, err := wc.webhookUsecase.GetWebhookByID(c, c.Param("id"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (wc *WebhookController) CreateWebhook(c *gin.Context) {
+	var input domain.WebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
 		return
 	}
-	err := tc.taskUsecase.UpdateTaskByID(c,id, task)
+
+	webhook, err := wc.webhookUsecase.CreateWebhook(c, getAuthUser(c), input)
 	if err.ErrCode != 0 {
-		c.JSON(err.ErrCode, gin.H{"message": err.ErrMessage})
+		c.JSON(err.ErrCode, errorBody(err))
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Task updated successfully"})
+	c.JSON(http.StatusCreated, webhook)
 }
 
-func (tc *TaskController) DeleteTaskByID(c *gin.Context) {
-	id := c.Param("id")
-	err := tc.taskUsecase.DeleteTaskByID(c,id)
+func (wc *WebhookController) UpdateWebhook(c *gin.Context) {
+	var input domain.WebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	webhook, err := wc.webhookUsecase.UpdateWebhook(c, c.Param("id"), input)
 	if err.ErrCode != 0 {
-		c.JSON(err.ErrCode, gin.H{"message": err.ErrMessage})
+		c.JSON(err.ErrCode, errorBody(err))
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
+	c.JSON(http.StatusOK, webhook)
 }
 
-func (tc *TaskController) CreateTask(c *gin.Context) {
-	var task domain.Task
-	if err := c.ShouldBindJSON(&task); err != nil {
+func (wc *WebhookController) DeleteWebhook(c *gin.Context) {
+	err := wc.webhookUsecase.DeleteWebhook(c, c.Param("id"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+func (wc *WebhookController) GetDeliveries(c *gin.Context) {
+	limit, err := parseLimit(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+
+	page, err := wc.webhookUsecase.GetDeliveries(c, c.Param("id"), c.Query("cursor"), limit)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (wc *WebhookController) GetDeliveryByID(c *gin.Context) {
+	delivery, err := wc.webhookUsecase.GetDeliveryByID(c, c.Param("id"), c.Param("deliveryId"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver sends an earlier delivery again and returns the new delivery with the outcome of its first attempt.
+func (wc *WebhookController) Redeliver(c *gin.Context) {
+	delivery, err := wc.webhookUsecase.Redeliver(c, c.Param("id"), c.Param("deliveryId"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusCreated, delivery)
+}
+
+//calendar controllers
+
+func NewCalendarController(calendarUsecase domain.CalendarUsecase) *CalendarController {
+	return &CalendarController{
+		calendarUsecase: calendarUsecase,
+	}
+}
+
+// CreateFeedToken creates a new feed URL for the caller; the URL they had stops working.
+func (cc *CalendarController) CreateFeedToken(c *gin.Context) {
+	token, err := cc.calendarUsecase.CreateFeedToken(c, getAuthUser(c))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	token.URL = calendarFeedURL(c, token.Token)
+	c.JSON(http.StatusCreated, token)
+}
+
+func (cc *CalendarController) GetFeedToken(c *gin.Context) {
+	feed, err := cc.calendarUsecase.GetFeedToken(c, getAuthUser(c))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, feed)
+}
+
+func (cc *CalendarController) RevokeFeedToken(c *gin.Context) {
+	if err := cc.calendarUsecase.RevokeFeedToken(c, getAuthUser(c)); err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed revoked"})
+}
+
+// GetFeed serves the iCalendar feed of a token; ?kind=todo writes tasks as to-dos instead of events.
+func (cc *CalendarController) GetFeed(c *gin.Context) {
+	kind, parseErr := domain.ParseCalendarKind(c.Query("kind"))
+	if parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": parseErr.Error(), "field": "kind"})
+		return
+	}
+	calendar, err := cc.calendarUsecase.GetFeed(c, c.Param("token"), kind)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	// the URL is the credential, so keep the feed out of shared caches
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(calendar))
+}
+
+// calendarFeedURL builds the absolute URL of a feed from the request, honouring X-Forwarded-Proto behind
+// a proxy.
+func calendarFeedURL(c *gin.Context, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + "/calendar/feed/" + token + ".ics"
+}
+
+//project controllers
+
+func NewProjectController(projectUsecase domain.ProjectUsecase) *ProjectController {
+	return &ProjectController{
+		projectUsecase: projectUsecase,
+	}
+}
+
+func (pc *ProjectController) GetProjects(c *gin.Context) {
+	projects, err := pc.projectUsecase.GetProjects(c, getAuthUser(c))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, projects)
+}
+
+func (pc *ProjectController) GetProjectByID(c *gin.Context) {
+	project, err := pc.projectUsecase.GetProjectByID(c, getAuthUser(c), c.Param("id"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+func (pc *ProjectController) CreateProject(c *gin.Context) {
+	var input domain.ProjectInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
 		return
 	}
-	
-	err := tc.taskUsecase.CreateTask(c,task)
+
+	project, err := pc.projectUsecase.CreateProject(c, getAuthUser(c), input)
 	if err.ErrCode != 0 {
-		c.JSON(err.ErrCode, gin.H{"message": err.ErrMessage})
+		c.JSON(err.ErrCode, errorBody(err))
 		return
 	}
-	
-	c.JSON(http.StatusCreated, gin.H{"message": "Task created successfully"})
+	c.JSON(http.StatusCreated, project)
+}
+
+func (pc *ProjectController) UpdateProject(c *gin.Context) {
+	var input domain.ProjectInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	project, err := pc.projectUsecase.UpdateProject(c, getAuthUser(c), c.Param("id"), input)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+func (pc *ProjectController) DeleteProject(c *gin.Context) {
+	err := pc.projectUsecase.DeleteProject(c, getAuthUser(c), c.Param("id"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
+}
+
+func (pc *ProjectController) SetMember(c *gin.Context) {
+	var input domain.ProjectMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	project, err := pc.projectUsecase.SetMember(c, getAuthUser(c), c.Param("id"), c.Param("userId"), input.Role)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+func (pc *ProjectController) RemoveMember(c *gin.Context) {
+	err := pc.projectUsecase.RemoveMember(c, getAuthUser(c), c.Param("id"), c.Param("userId"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+//role controllers
+
+func NewRoleController(roleUsecase domain.RoleUsecase) *RoleController {
+	return &RoleController{
+		roleUsecase: roleUsecase,
+	}
+}
+
+// GetPermissions lists the permissions roles can be given.
+func (rc *RoleController) GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, rc.roleUsecase.GetPermissions())
+}
+
+func (rc *RoleController) GetRoles(c *gin.Context) {
+	roles, err := rc.roleUsecase.GetRoles(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+func (rc *RoleController) GetRoleByName(c *gin.Context) {
+	role, err := rc.roleUsecase.GetRoleByName(c, c.Param("name"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+func (rc *RoleController) CreateRole(c *gin.Context) {
+	var input domain.RoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	role, err := rc.roleUsecase.CreateRole(c, input)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole replaces the description and permissions of a role. Tokens pick up the change within the
+// cache TTL of AuthMiddleware.
+func (rc *RoleController) UpdateRole(c *gin.Context) {
+	var input domain.RoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	role, err := rc.roleUsecase.UpdateRole(c, c.Param("name"), input)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+func (rc *RoleController) DeleteRole(c *gin.Context) {
+	err := rc.roleUsecase.DeleteRole(c, c.Param("name"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+//user admin controllers
+
+func NewUserAdminController(userAdminUsecase domain.UserAdminUsecase) *UserAdminController {
+	return &UserAdminController{
+		userAdminUsecase: userAdminUsecase,
+	}
+}
+
+// GetUsers lists users, filtered by ?q=, ?role= and ?disabled= and paged with ?cursor= and ?limit=.
+func (ac *UserAdminController) GetUsers(c *gin.Context) {
+	limit, err := parseLimit(c)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	query := domain.UserQuery{
+		Search: c.Query("q"),
+		Role:   c.Query("role"),
+		Cursor: c.Query("cursor"),
+		Limit:  limit,
+	}
+	if value := c.Query("disabled"); value != "" {
+		disabled, parseErr := strconv.ParseBool(value)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "disabled must be true or false", "field": "disabled"})
+			return
+		}
+		query.Disabled = &disabled
+	}
+
+	page, err := ac.userAdminUsecase.GetUsers(c, query)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (ac *UserAdminController) GetUserByID(c *gin.Context) {
+	user, err := ac.userAdminUsecase.GetUserByID(c, c.Param("id"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// ChangeRole gives a user another role, which revokes the tokens they hold.
+func (ac *UserAdminController) ChangeRole(c *gin.Context) {
+	var change domain.RoleChange
+	if err := c.ShouldBindJSON(&change); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	user, err := ac.userAdminUsecase.ChangeRole(c, c.Param("id"), change.Role)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (ac *UserAdminController) DisableUser(c *gin.Context) {
+	user, err := ac.userAdminUsecase.DisableUser(c, getAuthUser(c), c.Param("id"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (ac *UserAdminController) EnableUser(c *gin.Context) {
+	user, err := ac.userAdminUsecase.EnableUser(c, c.Param("id"))
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser deletes a user. ?tasks=transfer&to=<user id> hands their tasks to another user and
+// ?tasks=delete moves the tasks they created to the trash.
+func (ac *UserAdminController) DeleteUser(c *gin.Context) {
+	policy, parseErr := domain.ParseUserTaskPolicy(c.Query("tasks"))
+	if parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": parseErr.Error(), "field": "tasks"})
+		return
+	}
+
+	report, err := ac.userAdminUsecase.DeleteUser(c, getAuthUser(c), c.Param("id"), domain.UserDeletion{Tasks: policy, TransferTo: c.Query("to")})
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, report)
 }
 
 //user controllers
@@ -124,13 +1381,45 @@ func (uc *UserController) LoginUser(c *gin.Context) {
 		return
 	}
 
-	token, err := uc.userUsecase.AuthenticateUser(c,user.Username, user.Password)
+	tokens, err := uc.userUsecase.AuthenticateUser(c,user.Username, user.Password)
 	if err.ErrCode != 0 {
 		c.JSON(err.ErrCode, gin.H{"message": err.ErrMessage})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, tokens)
+}
+
+// GetJWKS publishes the keys access tokens can be verified with. Clients may cache them for a few
+// minutes; a rotated key is published before anything is signed with it.
+func (uc *UserController) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, uc.userUsecase.GetJWKS())
+}
+
+// RefreshToken exchanges a refresh token for a new token pair. The refresh token sent is used up.
+func (uc *UserController) RefreshToken(c *gin.Context) {
+	var request domain.RefreshRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid JSON"})
+		return
+	}
+
+	tokens, err := uc.userUsecase.RefreshSession(c, request.RefreshToken)
+	if err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout ends the caller's session, so its refresh tokens stop working.
+func (uc *UserController) Logout(c *gin.Context) {
+	if err := uc.userUsecase.Logout(c, getAuthUser(c)); err.ErrCode != 0 {
+		c.JSON(err.ErrCode, errorBody(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
 }
 
 
@@ -144,9 +1433,9 @@ func (uc *UserController) PromoteUser(c *gin.Context) {
 		return
 	}
 
-	err := uc.userUsecase.PromoteUser(c,user.Username)
+	err := uc.userUsecase.PromoteUser(c,user.Username, user.Role)
 	if err.ErrCode != 0 {
-		c.JSON(err.ErrCode, gin.H{"message": err.ErrMessage})
+		c.JSON(err.ErrCode, errorBody(err))
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "User promoted successfully"})
@@ -1,13 +1,17 @@
 package controllers_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"task_managment_api/delivery/controllers"
 	"task_managment_api/domain"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -20,31 +24,169 @@ type MockTaskUsecase struct {
 	mock.Mock
 }
 
-func (m *MockTaskUsecase) GetTasks(c context.Context) ([]domain.Task, domain.CustomError) {
-	args := m.Called(c)
-	return args.Get(0).([]domain.Task), args.Get(1).(domain.CustomError)
+func (m *MockTaskUsecase) GetTasks(c context.Context, user domain.AuthUser, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	args := m.Called(c, user, query)
+	return args.Get(0).(domain.TaskPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) SearchTasks(c context.Context, user domain.AuthUser, query domain.TaskQuery) (domain.TaskSearchPage, domain.CustomError) {
+	args := m.Called(c, user, query)
+	return args.Get(0).(domain.TaskSearchPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) GetTaskByID(c context.Context, user domain.AuthUser, id string) (domain.Task, domain.CustomError) {
+	args := m.Called(c, user, id)
+	return args.Get(0).(domain.Task), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) GetOverdueTasks(c context.Context, user domain.AuthUser, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	args := m.Called(c, user, query)
+	return args.Get(0).(domain.TaskPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) GetUpcomingTasks(c context.Context, user domain.AuthUser, within time.Duration, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	args := m.Called(c, user, within, query)
+	return args.Get(0).(domain.TaskPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) UpdateTaskByID(c context.Context, user domain.AuthUser, id string, task domain.TaskInput, expectedVersion *int64, scope domain.RecurrenceScope) (domain.Task, domain.CustomError) {
+	args := m.Called(c, user, id, task, expectedVersion, scope)
+	return args.Get(0).(domain.Task), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) PatchTaskByID(c context.Context, user domain.AuthUser, id string, patch domain.TaskPatch, expectedVersion *int64, scope domain.RecurrenceScope) (domain.Task, domain.CustomError) {
+	args := m.Called(c, user, id, patch, expectedVersion, scope)
+	return args.Get(0).(domain.Task), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) TransitionTask(c context.Context, user domain.AuthUser, id string, status string) domain.CustomError {
+	args := m.Called(c, user, id, status)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) DeleteTaskByID(c context.Context, user domain.AuthUser, id string) domain.CustomError {
+	args := m.Called(c, user, id)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) ReleaseUserTasks(c context.Context, user domain.AuthUser, userID string, deletion domain.UserDeletion) (domain.UserDeletionReport, domain.CustomError) {
+	args := m.Called(c, user, userID, deletion)
+	return args.Get(0).(domain.UserDeletionReport), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) GetTrash(c context.Context, user domain.AuthUser, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	args := m.Called(c, user, query)
+	return args.Get(0).(domain.TaskPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) RestoreTaskByID(c context.Context, user domain.AuthUser, id string) (domain.Task, domain.CustomError) {
+	args := m.Called(c, user, id)
+	return args.Get(0).(domain.Task), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) PurgeTaskByID(c context.Context, user domain.AuthUser, id string) domain.CustomError {
+	args := m.Called(c, user, id)
+	return args.Get(0).(domain.CustomError)
 }
 
-func (m *MockTaskUsecase) GetTaskByID(c context.Context, id string) (domain.Task, domain.CustomError) {
-	args := m.Called(c, id)
+func (m *MockTaskUsecase) PurgeExpiredTasks(c context.Context, retention time.Duration) (int64, domain.CustomError) {
+	args := m.Called(c, retention)
+	return args.Get(0).(int64), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) GetSubtasks(c context.Context, user domain.AuthUser, id string, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	args := m.Called(c, user, id, query)
+	return args.Get(0).(domain.TaskPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) AddChecklistItem(c context.Context, user domain.AuthUser, id string, text string) (domain.ChecklistItem, domain.CustomError) {
+	args := m.Called(c, user, id, text)
+	return args.Get(0).(domain.ChecklistItem), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) UpdateChecklistItem(c context.Context, user domain.AuthUser, id string, itemID string, update domain.ChecklistItemUpdate) (domain.ChecklistItem, domain.CustomError) {
+	args := m.Called(c, user, id, itemID, update)
+	return args.Get(0).(domain.ChecklistItem), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) RemoveChecklistItem(c context.Context, user domain.AuthUser, id string, itemID string) domain.CustomError {
+	args := m.Called(c, user, id, itemID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) GetDependencies(c context.Context, user domain.AuthUser, id string) (domain.TaskDependencyGraph, domain.CustomError) {
+	args := m.Called(c, user, id)
+	return args.Get(0).(domain.TaskDependencyGraph), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) AddDependency(c context.Context, user domain.AuthUser, id string, blockerID string) domain.CustomError {
+	args := m.Called(c, user, id, blockerID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) RemoveDependency(c context.Context, user domain.AuthUser, id string, blockerID string) domain.CustomError {
+	args := m.Called(c, user, id, blockerID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) AddLabels(c context.Context, user domain.AuthUser, id string, labelIDs []string) domain.CustomError {
+	args := m.Called(c, user, id, labelIDs)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) RemoveLabel(c context.Context, user domain.AuthUser, id string, labelID string) domain.CustomError {
+	args := m.Called(c, user, id, labelID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) GetTaskHistory(c context.Context, user domain.AuthUser, id string, cursor string, limit int64) (domain.TaskHistoryPage, domain.CustomError) {
+	args := m.Called(c, user, id, cursor, limit)
+	return args.Get(0).(domain.TaskHistoryPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) CreateTask(c context.Context, user domain.AuthUser, task domain.TaskInput) (domain.Task, domain.CustomError) {
+	args := m.Called(c, user, task)
 	return args.Get(0).(domain.Task), args.Get(1).(domain.CustomError)
 }
 
-func (m *MockTaskUsecase) UpdateTaskByID(c context.Context, id string, task domain.Task) domain.CustomError {
-	args := m.Called(c, id, task)
+func (m *MockTaskUsecase) AssignUsers(c context.Context, user domain.AuthUser, id string, userIDs []string) domain.CustomError {
+	args := m.Called(c, user, id, userIDs)
 	return args.Get(0).(domain.CustomError)
 }
 
-func (m *MockTaskUsecase) DeleteTaskByID(c context.Context, id string) domain.CustomError {
-	args := m.Called(c, id)
+func (m *MockTaskUsecase) UnassignUser(c context.Context, user domain.AuthUser, id string, userID string) domain.CustomError {
+	args := m.Called(c, user, id, userID)
 	return args.Get(0).(domain.CustomError)
 }
 
-func (m *MockTaskUsecase) CreateTask(c context.Context, task domain.Task) domain.CustomError {
-	args := m.Called(c, task)
+func (m *MockTaskUsecase) GetOccurrences(c context.Context, user domain.AuthUser, id string, count int) (domain.OccurrencePreview, domain.CustomError) {
+	args := m.Called(c, user, id, count)
+	return args.Get(0).(domain.OccurrencePreview), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) BatchTasks(c context.Context, user domain.AuthUser, request domain.BatchRequest) (domain.BatchResponse, domain.CustomError) {
+	args := m.Called(c, user, request)
+	return args.Get(0).(domain.BatchResponse), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskUsecase) ExportTasks(c context.Context, user domain.AuthUser, query domain.TaskQuery, write func(tasks []domain.Task) error) domain.CustomError {
+	args := m.Called(c, user, query, write)
+	// hand the pages to the controller like the usecase does
+	if pages, ok := args.Get(1).([][]domain.Task); ok {
+		for _, page := range pages {
+			if err := write(page); err != nil {
+				return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: err.Error()}
+			}
+		}
+	}
 	return args.Get(0).(domain.CustomError)
 }
 
+func (m *MockTaskUsecase) ImportTasks(c context.Context, user domain.AuthUser, rows []domain.ImportRow, dryRun bool) (domain.ImportReport, domain.CustomError) {
+	args := m.Called(c, user, rows, dryRun)
+	return args.Get(0).(domain.ImportReport), args.Get(1).(domain.CustomError)
+}
+
 // TaskControllerTestSuite defines a suite of tests for the TaskController
 type TaskControllerTestSuite struct {
 	suite.Suite
@@ -69,254 +211,1769 @@ func (suite *TaskControllerTestSuite) TestGetTasks() {
 		{ID: "2", Title: "Task 2", Description: "Description 2"},
 	}
 
-	suite.mockTaskUsecase.On("GetTasks", mock.Anything).Return(mockTasks, domain.CustomError{})
+	mockPage := domain.TaskPage{Tasks: mockTasks, NextCursor: "next", Total: 5}
+	expectedQuery := domain.TaskQuery{Status: "done", Title: "Task", SortBy: "due_date", SortOrder: "desc", Limit: 2}
+
+	suite.mockTaskUsecase.On("GetTasks", mock.Anything, mock.Anything, expectedQuery).Return(mockPage, domain.CustomError{})
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks?status=done&title=Task&sort=due_date&order=desc&limit=2", nil)
 
 	suite.controller.GetTasks(c)
 
 	suite.Equal(http.StatusOK, w.Code)
 	suite.Contains(w.Body.String(), "Task 1")
+	suite.Contains(w.Body.String(), `"next_cursor":"next"`)
+	suite.Contains(w.Body.String(), `"total":5`)
 }
 
-// TestCreateTask tests the CreateTask method
-func (suite *TaskControllerTestSuite) TestCreateTask() {
-	taskJSON := `{"title": "New Task", "description": "New Description"}`
-
-	suite.mockTaskUsecase.On("CreateTask", mock.Anything, mock.Anything).Return(domain.CustomError{})
+// TestGetTasksByLabels tests reading the label filter of GetTasks
+func (suite *TaskControllerTestSuite) TestGetTasksByLabels() {
+	expectedQuery := domain.TaskQuery{LabelIDs: []string{"label-1", "label-2"}, LabelMatch: "all"}
+	suite.mockTaskUsecase.On("GetTasks", mock.Anything, mock.Anything, expectedQuery).Return(domain.TaskPage{Tasks: []domain.Task{}}, domain.CustomError{})
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks", strings.NewReader(taskJSON))
-	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks?labels=label-1,%20label-2,&label_match=all", nil)
 
-	suite.controller.CreateTask(c)
+	suite.controller.GetTasks(c)
 
-	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	suite.Equal(http.StatusOK, w.Code)
 }
 
-//TestGetTaskById tests the GetTaskByID method
-func (suite *TaskControllerTestSuite) TestGetTaskByID() {
-	mockTask := domain.Task{ID: "1", Title: "Task 1", Description: "Description 1"}
-
-	suite.mockTaskUsecase.On("GetTaskByID", mock.Anything, "1").Return(mockTask, domain.CustomError{})
+// TestSearchTasks tests the SearchTasks method
+func (suite *TaskControllerTestSuite) TestSearchTasks() {
+	expectedQuery := domain.TaskQuery{Search: "deploy api", Status: "todo", Limit: 10}
+	mockPage := domain.TaskSearchPage{
+		Results: []domain.TaskSearchResult{{Task: domain.Task{ID: "1", Title: "Deploy API"}, Score: 5.5, Highlights: domain.TaskHighlights{Title: "<mark>Deploy</mark> <mark>API</mark>"}}},
+		Total:   1,
+	}
+	suite.mockTaskUsecase.On("SearchTasks", mock.Anything, mock.Anything, expectedQuery).Return(mockPage, domain.CustomError{})
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/search?q=deploy+api&status=todo&limit=10", nil)
 
-	suite.controller.GetTaskByID(c)
+	suite.controller.SearchTasks(c)
 
-	suite.Equal( http.StatusOK, w.Code)
-	suite.Contains(w.Body.String(), "Task 1")
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"score":5.5`)
+	suite.Contains(w.Body.String(), `"highlights"`)
 }
 
-// TestUpdateTaskByID tests the UpdateTaskByID method
-func (suite *TaskControllerTestSuite) TestUpdateTaskByID() {
-	taskJSON := `{"title": "Updated Task", "description": "Updated Description"}`
-
-	suite.mockTaskUsecase.On("UpdateTaskByID", mock.Anything, "1", mock.AnythingOfType("domain.Task")).Return(domain.CustomError{})
+// TestAddLabels tests the AddLabels method
+func (suite *TaskControllerTestSuite) TestAddLabels() {
+	suite.mockTaskUsecase.On("AddLabels", mock.Anything, mock.Anything, "1", []string{"label-1"}).Return(domain.CustomError{})
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest(http.MethodPut, "/tasks/1", strings.NewReader(taskJSON))
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks/1/labels", strings.NewReader(`{"label_ids": ["label-1"]}`))
 	c.Request.Header.Set("Content-Type", "application/json")
 	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
 
-	suite.controller.UpdateTaskByID(c)
+	suite.controller.AddLabels(c)
+
 	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), "Labels added successfully")
 }
 
-// TestDeleteTaskByID tests the DeleteTaskByID method
-func (suite *TaskControllerTestSuite) TestDeleteTaskByID() {
-	suite.mockTaskUsecase.On("DeleteTaskByID", mock.Anything, "1").Return(domain.CustomError{})
+// TestGetTasksInvalidDueFrom tests the GetTasks method with a malformed due date filter
+func (suite *TaskControllerTestSuite) TestGetTasksInvalidDueFrom() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks?due_from=yesterday", nil)
+
+	suite.controller.GetTasks(c)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.Contains(w.Body.String(), `"field":"due_from"`)
+}
+
+// TestGetUpcomingTasks tests the GetUpcomingTasks method
+func (suite *TaskControllerTestSuite) TestGetUpcomingTasks() {
+	suite.mockTaskUsecase.On("GetUpcomingTasks", mock.Anything, mock.Anything, 24*time.Hour, mock.Anything).Return(domain.TaskPage{Tasks: []domain.Task{{ID: "1", Title: "Task 1"}}}, domain.CustomError{})
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/upcoming?within=24h", nil)
+
+	suite.controller.GetUpcomingTasks(c)
 
-	suite.controller.DeleteTaskByID(c)
 	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), "Task 1")
 }
 
-// TestCreateTaskInvalidJSON tests the CreateTask method with invalid JSON
-func (suite *TaskControllerTestSuite) TestCreateTaskInvalidJSON() {
-    taskJSON := `{"title": "New Task", "description": }` // Invalid JSON
+// TestGetOverdueTasksFieldError tests that GetOverdueTasks names the offending field of an error
+func (suite *TaskControllerTestSuite) TestGetOverdueTasksFieldError() {
+	suite.mockTaskUsecase.On("GetOverdueTasks", mock.Anything, mock.Anything, mock.Anything).Return(domain.TaskPage{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid cursor", Field: "cursor"})
 
-    w := httptest.NewRecorder()
-    c, _ := gin.CreateTestContext(w)
-    c.Request, _ = http.NewRequest(http.MethodPost, "/tasks", strings.NewReader(taskJSON))
-    c.Request.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/overdue?cursor=nope", nil)
 
-    suite.controller.CreateTask(c)
+	suite.controller.GetOverdueTasks(c)
 
-    suite.Equal(http.StatusBadRequest, w.Code)
-    suite.Contains(w.Body.String(), "Invalid JSON")
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.JSONEq(`{"message": "Invalid cursor", "field": "cursor"}`, w.Body.String())
 }
 
-// TestGetTaskByIDNotFound tests the GetTaskByID method when the task is not found
-func (suite *TaskControllerTestSuite) TestGetTaskByIDNotFound() {
-    suite.mockTaskUsecase.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{}, domain.CustomError{
-        ErrCode: http.StatusNotFound,
-        ErrMessage: "Task not found",
-    })
+// TestGetUpcomingTasksDefaultWindow tests that GetUpcomingTasks falls back to the default window
+func (suite *TaskControllerTestSuite) TestGetUpcomingTasksDefaultWindow() {
+	suite.mockTaskUsecase.On("GetUpcomingTasks", mock.Anything, mock.Anything, domain.DefaultUpcomingWindow, mock.Anything).Return(domain.TaskPage{}, domain.CustomError{})
 
-    w := httptest.NewRecorder()
-    c, _ := gin.CreateTestContext(w)
-    c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/upcoming", nil)
 
-    suite.controller.GetTaskByID(c)
+	suite.controller.GetUpcomingTasks(c)
 
-    suite.Equal(http.StatusNotFound, w.Code)
-    suite.Contains(w.Body.String(), "Task not found")
+	suite.Equal(http.StatusOK, w.Code)
 }
 
-// TestDeleteTaskByIDNotFound tests the DeleteTaskByID method when the task is not found
-func (suite *TaskControllerTestSuite) TestDeleteTaskByIDNotFound() {
-    suite.mockTaskUsecase.On("DeleteTaskByID", mock.Anything, "1").Return(domain.CustomError{
-        ErrCode: http.StatusNotFound,
-        ErrMessage: "Task not found",
-    })
+// TestCreateTaskFieldError tests that validation errors name the offending field
+func (suite *TaskControllerTestSuite) TestCreateTaskFieldError() {
+	suite.mockTaskUsecase.On("CreateTask", mock.Anything, mock.Anything, mock.Anything).Return(domain.Task{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "bad due date", Field: "due_date"})
 
-    w := httptest.NewRecorder()
-    c, _ := gin.CreateTestContext(w)
-    c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{"title": "New Task", "due_date": "soon"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
 
-    suite.controller.DeleteTaskByID(c)
+	suite.controller.CreateTask(c)
 
-    suite.Equal(http.StatusNotFound, w.Code)
-    suite.Contains(w.Body.String(), "Task not found")
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.JSONEq(`{"message": "bad due date", "field": "due_date"}`, w.Body.String())
 }
 
+// TestExportTasksCSV tests that the export streams a CSV file with a header row
+func (suite *TaskControllerTestSuite) TestExportTasksCSV() {
+	pages := [][]domain.Task{{{ID: "1", Title: "Task 1", Status: domain.StatusTodo, AssigneeIDs: []string{"u1", "u2"}, Version: 2}}, {{ID: "2", Title: "=SUM(A1)", Status: domain.StatusDone, Version: 1}}}
+	suite.mockTaskUsecase.On("ExportTasks", mock.Anything, mock.Anything, domain.TaskQuery{Status: "todo"}, mock.Anything).Return(domain.CustomError{}, pages)
 
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/export?format=csv&status=todo", nil)
 
-// Mock for UserUsecase
-type MockUserUsecase struct {
-	mock.Mock
-}
+	suite.controller.ExportTasks(c)
 
-func (m *MockUserUsecase) RegisterUser(c context.Context, user domain.User) domain.CustomError {
-	args := m.Called(c, user)
-	return args.Get(0).(domain.CustomError)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal("text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	suite.Equal(`attachment; filename="tasks.csv"`, w.Header().Get("Content-Disposition"))
+	suite.Equal("id,title,description,status,due_date,project_id,parent_id,assignee_ids,label_ids,recurrence,created_by,version\n"+
+		"1,Task 1,,todo,,,,u1;u2,,,,2\n"+
+		"2,'=SUM(A1),,done,,,,,,,,1\n", w.Body.String())
 }
 
-func (m *MockUserUsecase) AuthenticateUser(c context.Context, username, password string) (string, domain.CustomError) {
-	args := m.Called(c, username, password)
-	return args.String(0), args.Get(1).(domain.CustomError)
-}
+// TestExportTasksJSON tests the JSON array and NDJSON exports
+func (suite *TaskControllerTestSuite) TestExportTasksJSON() {
+	suite.mockTaskUsecase.On("ExportTasks", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(domain.CustomError{}, [][]domain.Task{{{ID: "1", Title: "Task 1"}, {ID: "2", Title: "Task 2"}}})
 
-func (m *MockUserUsecase) PromoteUser(c context.Context, username string) domain.CustomError {
-	args := m.Called(c, username)
-	return args.Get(0).(domain.CustomError)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/export", nil)
+
+	suite.controller.ExportTasks(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	var tasks []domain.Task
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &tasks))
+	suite.Len(tasks, 2)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/export?format=ndjson", nil)
+
+	suite.controller.ExportTasks(c)
+
+	suite.Equal("application/x-ndjson", w.Header().Get("Content-Type"))
+	suite.Len(strings.Split(strings.TrimSpace(w.Body.String()), "\n"), 2)
 }
 
-// UserControllerTestSuite defines a suite of tests for the UserController
-type UserControllerTestSuite struct {
-	suite.Suite
-	controller    *controllers.UserController
-	mockUserUsecase *MockUserUsecase
+// TestExportTasksError tests that errors found before the first page are answered as JSON
+func (suite *TaskControllerTestSuite) TestExportTasksError() {
+	suite.mockTaskUsecase.On("ExportTasks", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "unknown sort field", Field: "sort"}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/export?format=csv&sort=colour", nil)
+
+	suite.controller.ExportTasks(c)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.JSONEq(`{"message": "unknown sort field", "field": "sort"}`, w.Body.String())
 }
 
-// SetupTest sets up the test environment before each test
-func (suite *UserControllerTestSuite) SetupTest() {
-	gin.SetMode(gin.TestMode)
-	suite.mockUserUsecase = new(MockUserUsecase)
-	suite.controller = controllers.NewUserController(suite.mockUserUsecase)
+// importRequest builds a multipart upload of an import file.
+func importRequest(url string, filename string, content string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", filename)
+	part.Write([]byte(content))
+	writer.Close()
+	req, _ := http.NewRequest(http.MethodPost, url, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
 }
 
-func (suite *UserControllerTestSuite) TearDownTest() {
-	suite.mockUserUsecase.AssertExpectations(suite.T())
+// TestImportTasksDryRun tests that a dry run reads the file by its extension and returns the report
+func (suite *TaskControllerTestSuite) TestImportTasksDryRun() {
+	rows := []domain.ImportRow{{Line: 2, Input: domain.TaskInput{Title: "Task 1", ProjectID: "p1"}}}
+	report := domain.ImportReport{DryRun: true, Rows: 1, Valid: 1, Errors: []domain.ImportError{}, Created: []domain.ImportedTask{}}
+	suite.mockTaskUsecase.On("ImportTasks", mock.Anything, mock.Anything, rows, true).Return(report, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = importRequest("/tasks/import?dry_run=true", "tasks.csv", "title,project_id\nTask 1,p1\n")
+
+	suite.controller.ImportTasks(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"dry_run": true, "rows": 1, "valid": 1, "imported": 0, "errors": [], "created": []}`, w.Body.String())
 }
 
+// TestImportTasksInvalidRows tests that a file with invalid rows answers 422
+func (suite *TaskControllerTestSuite) TestImportTasksInvalidRows() {
+	report := domain.ImportReport{Rows: 1, Errors: []domain.ImportError{{Line: 1, Field: "title", Message: "title is required"}}}
+	suite.mockTaskUsecase.On("ImportTasks", mock.Anything, mock.Anything, mock.Anything, false).Return(report, domain.CustomError{})
 
-// TestRegisterUser tests the RegisterUser method
-func (suite *UserControllerTestSuite) TestRegisterUser() {
-	userJSON := `{"username": "newuser", "password": "password"}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = importRequest("/tasks/import?format=ndjson", "tasks.txt", `{"project_id": "p1"}`)
 
-	suite.mockUserUsecase.On("RegisterUser", mock.Anything, mock.Anything).Return(domain.CustomError{})
+	suite.controller.ImportTasks(c)
+
+	suite.Equal(http.StatusUnprocessableEntity, w.Code)
+}
 
+// TestImportTasksNoFile tests that an import needs a file
+func (suite *TaskControllerTestSuite) TestImportTasksNoFile() {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest(http.MethodPost, "/register", strings.NewReader(userJSON))
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks/import", strings.NewReader(`{}`))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	suite.controller.RegisterUser(c)
+	suite.controller.ImportTasks(c)
 
-	suite.Equal(http.StatusCreated, w.Code)
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.JSONEq(`{"message": "Upload the tasks as the file form field", "field": "file"}`, w.Body.String())
 }
 
-// TestLoginUser tests the LoginUser method
-func (suite *UserControllerTestSuite) TestLoginUser() {
-	loginJSON := `{"username": "user1", "password": "password"}`
+// TestBatchTasks tests that POST /tasks:batch returns the per-item results
+func (suite *TaskControllerTestSuite) TestBatchTasks() {
+	request := domain.BatchRequest{Operations: []domain.BatchOperation{{Op: domain.BatchDelete, ID: "1"}}}
+	response := domain.BatchResponse{Succeeded: 1, Results: []domain.BatchResult{{Index: 0, Op: domain.BatchDelete, ID: "1", Status: http.StatusOK}}}
+	suite.mockTaskUsecase.On("BatchTasks", mock.Anything, mock.Anything, request).Return(response, domain.CustomError{})
+	router := gin.New()
+	router.POST("/tasks:batch", suite.controller.BatchTasks)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/tasks:batch", strings.NewReader(`{"operations": [{"op": "delete", "id": "1"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
 
-	suite.mockUserUsecase.On("AuthenticateUser", mock.Anything, "user1", "password").Return("mocked_token", domain.CustomError{})
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"atomic": false, "succeeded": 1, "failed": 0, "rolled_back": false, "results": [{"index": 0, "op": "delete", "id": "1", "status": 200}]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/tasksbatch", strings.NewReader(`{}`))
+	router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+// TestBatchTasksRolledBack tests that a rolled back atomic batch answers 409
+func (suite *TaskControllerTestSuite) TestBatchTasksRolledBack() {
+	response := domain.BatchResponse{Atomic: true, Failed: 1, RolledBack: true, Results: []domain.BatchResult{{Index: 0, Op: domain.BatchCreate, Status: http.StatusBadRequest, Error: "title is required", Field: "title"}}}
+	suite.mockTaskUsecase.On("BatchTasks", mock.Anything, mock.Anything, mock.Anything).Return(response, domain.CustomError{})
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest(http.MethodPost, "/login", strings.NewReader(loginJSON))
+	c.Params = gin.Params{{Key: "batch", Value: ":batch"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks:batch", strings.NewReader(`{"atomic": true, "operations": [{"op": "create", "task": {}}]}`))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	suite.controller.LoginUser(c)
+	suite.controller.BatchTasks(c)
 
-	suite.Equal(http.StatusOK, w.Code)
-	suite.JSONEq(`{"token": "mocked_token"}`, w.Body.String())
+	suite.Equal(http.StatusConflict, w.Code)
+	suite.Contains(w.Body.String(), `"rolled_back":true`)
 }
 
-// TestPromoteUser tests the PromoteUser method
-func (suite *UserControllerTestSuite) TestPromoteUser() {
-	promoteJSON := `{"username": "user1"}`
+// TestGetTasksInvalidLimit tests the GetTasks method with a malformed limit
+func (suite *TaskControllerTestSuite) TestGetTasksInvalidLimit() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks?limit=abc", nil)
+
+	suite.controller.GetTasks(c)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
 
-	suite.mockUserUsecase.On("PromoteUser", mock.Anything, "user1").Return(domain.CustomError{})
+// TestCreateTask tests the CreateTask method
+func (suite *TaskControllerTestSuite) TestCreateTask() {
+	taskJSON := `{"title": "New Task", "description": "New Description"}`
+
+	suite.mockTaskUsecase.On("CreateTask", mock.Anything, mock.Anything, mock.Anything).Return(domain.Task{ID: "1", Title: "New Task"}, domain.CustomError{})
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest(http.MethodPost, "/promote", strings.NewReader(promoteJSON))
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks", strings.NewReader(taskJSON))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	suite.controller.PromoteUser(c)
+	suite.controller.CreateTask(c)
 
-	suite.Equal( http.StatusOK, w.Code)
-	suite.JSONEq( `{"message": "User promoted successfully"}`, w.Body.String())
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	suite.JSONEq(`{"message": "Task created successfully", "id": "1"}`, w.Body.String())
 }
 
-// TestLoginUserInvalidJSON tests the LoginUser method with invalid JSON
-func (suite *UserControllerTestSuite) TestLoginUserInvalidJSON() {
-    loginJSON := `{"username": "user1", "password": }` // Invalid JSON
+//TestGetTaskById tests the GetTaskByID method
+func (suite *TaskControllerTestSuite) TestGetTaskByID() {
+	mockTask := domain.Task{ID: "1", Title: "Task 1", Description: "Description 1", Version: 3}
 
-    w := httptest.NewRecorder()
-    c, _ := gin.CreateTestContext(w)
-    c.Request, _ = http.NewRequest(http.MethodPost, "/login", strings.NewReader(loginJSON))
-    c.Request.Header.Set("Content-Type", "application/json")
+	suite.mockTaskUsecase.On("GetTaskByID", mock.Anything, mock.Anything, "1").Return(mockTask, domain.CustomError{})
 
-    suite.controller.LoginUser(c)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
 
-    suite.Equal(http.StatusBadRequest, w.Code)
-    suite.Contains(w.Body.String(), "Invalid JSON")
+	suite.controller.GetTaskByID(c)
+
+	suite.Equal( http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), "Task 1")
+	suite.Equal(`"3"`, w.Header().Get("ETag"))
 }
 
-// TestRegisterUserConflict tests the RegisterUser method when the user already exists
-func (suite *UserControllerTestSuite) TestRegisterUserConflict() {
-    userJSON := `{"username": "newuser", "password": "password"}`
+// TestUpdateTaskByID tests the UpdateTaskByID method
+func (suite *TaskControllerTestSuite) TestUpdateTaskByID() {
+	taskJSON := `{"title": "Updated Task", "description": "Updated Description"}`
 
-    suite.mockUserUsecase.On("RegisterUser", mock.Anything, mock.Anything).Return(domain.CustomError{
-        ErrCode: http.StatusConflict,
-        ErrMessage: "User already exists",
-    })
+	suite.mockTaskUsecase.On("UpdateTaskByID", mock.Anything, mock.Anything, "1", mock.AnythingOfType("domain.TaskInput"), (*int64)(nil), domain.ScopeThisOccurrence).Return(domain.Task{ID: "1", Version: 4}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPut, "/tasks/1", strings.NewReader(taskJSON))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.UpdateTaskByID(c)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal(`"4"`, w.Header().Get("ETag"))
+}
+
+// TestUpdateTaskByID_IfMatch tests that the If-Match version is passed on and a stale one is rejected
+func (suite *TaskControllerTestSuite) TestUpdateTaskByID_IfMatch() {
+	taskJSON := `{"title": "Updated Task"}`
+
+	suite.mockTaskUsecase.On("UpdateTaskByID", mock.Anything, mock.Anything, "1", mock.AnythingOfType("domain.TaskInput"), mock.MatchedBy(func(version *int64) bool {
+		return version != nil && *version == 3
+	}), domain.ScopeThisOccurrence).Return(domain.Task{}, domain.CustomError{ErrCode: http.StatusPreconditionFailed, ErrMessage: "Task was modified by someone else, reload the task and try again", Details: map[string]interface{}{"current_version": int64(5)}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPut, "/tasks/1", strings.NewReader(taskJSON))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"3"`)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.UpdateTaskByID(c)
+	suite.Equal(http.StatusPreconditionFailed, w.Code)
+	suite.Contains(w.Body.String(), `"current_version":5`)
+}
+
+// TestUpdateTaskByID_InvalidIfMatch tests that an If-Match tag that is not a task version never matches
+func (suite *TaskControllerTestSuite) TestUpdateTaskByID_InvalidIfMatch() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPut, "/tasks/1", strings.NewReader(`{"title": "Updated Task"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"abc"`)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.UpdateTaskByID(c)
+	suite.Equal(http.StatusPreconditionFailed, w.Code)
+	suite.mockTaskUsecase.AssertNotCalled(suite.T(), "UpdateTaskByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestPatchTaskByID tests that PatchTaskByID passes the merge patch on, nulls included
+func (suite *TaskControllerTestSuite) TestPatchTaskByID() {
+	patch := domain.TaskPatch{"due_date": json.RawMessage(`null`)}
+	suite.mockTaskUsecase.On("PatchTaskByID", mock.Anything, mock.Anything, "1", patch, (*int64)(nil), domain.ScopeThisOccurrence).Return(domain.Task{ID: "1", Version: 2}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/tasks/1", strings.NewReader(`{"due_date": null}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.PatchTaskByID(c)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal(`"2"`, w.Header().Get("ETag"))
+}
+
+// TestPatchTaskByID_FutureScope tests that the scope query parameter is passed on and an unknown scope is rejected
+func (suite *TaskControllerTestSuite) TestPatchTaskByID_FutureScope() {
+	patch := domain.TaskPatch{"title": json.RawMessage(`"Weekly report"`)}
+	suite.mockTaskUsecase.On("PatchTaskByID", mock.Anything, mock.Anything, "1", patch, (*int64)(nil), domain.ScopeFutureOccurrences).Return(domain.Task{ID: "1", Version: 2}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/tasks/1?scope=future", strings.NewReader(`{"title": "Weekly report"}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.PatchTaskByID(c)
+	suite.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/tasks/1?scope=all", strings.NewReader(`{"title": "Weekly report"}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.PatchTaskByID(c)
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.JSONEq(`{"message": "scope must be this or future", "field": "scope"}`, w.Body.String())
+}
+
+// TestPatchTaskByID_UnsupportedMediaType tests that PatchTaskByID rejects other content types
+func (suite *TaskControllerTestSuite) TestPatchTaskByID_UnsupportedMediaType() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/tasks/1", strings.NewReader(`title=x`))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.PatchTaskByID(c)
+	suite.Equal(http.StatusUnsupportedMediaType, w.Code)
+}
+
+// TestGetOccurrences tests that GetOccurrences passes the count on and returns the preview
+func (suite *TaskControllerTestSuite) TestGetOccurrences() {
+	next := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	suite.mockTaskUsecase.On("GetOccurrences", mock.Anything, mock.Anything, "1", 1).Return(domain.OccurrencePreview{TaskID: "1", Rule: "FREQ=WEEKLY;BYDAY=MO", Occurrences: []time.Time{next}}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/1/occurrences?count=1", nil)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.GetOccurrences(c)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"task_id": "1", "rule": "FREQ=WEEKLY;BYDAY=MO", "occurrences": ["2026-10-19T09:00:00Z"]}`, w.Body.String())
+}
+
+// TestPatchTaskByID_NotAnObject tests that PatchTaskByID rejects a patch that is not a JSON object
+func (suite *TaskControllerTestSuite) TestPatchTaskByID_NotAnObject() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/tasks/1", strings.NewReader(`null`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.PatchTaskByID(c)
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
+
+// TestDeleteTaskByID tests the DeleteTaskByID method
+func (suite *TaskControllerTestSuite) TestDeleteTaskByID() {
+	suite.mockTaskUsecase.On("DeleteTaskByID", mock.Anything, mock.Anything, "1").Return(domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.DeleteTaskByID(c)
+	suite.Equal(http.StatusOK, w.Code)
+}
+
+// TestGetTrash tests the GetTrash method
+func (suite *TaskControllerTestSuite) TestGetTrash() {
+	deletedAt := time.Now()
+	mockPage := domain.TaskPage{Tasks: []domain.Task{{ID: "1", Title: "Deleted Task", DeletedAt: &deletedAt}}, Total: 1}
+	suite.mockTaskUsecase.On("GetTrash", mock.Anything, mock.Anything, mock.AnythingOfType("domain.TaskQuery")).Return(mockPage, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/trash", nil)
+
+	suite.controller.GetTrash(c)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), "deleted_at")
+}
+
+// TestRestoreTaskByID tests the RestoreTaskByID method
+func (suite *TaskControllerTestSuite) TestRestoreTaskByID() {
+	suite.mockTaskUsecase.On("RestoreTaskByID", mock.Anything, mock.Anything, "1").Return(domain.Task{ID: "1", Title: "Task 1", Version: 3}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.RestoreTaskByID(c)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal(`"3"`, w.Header().Get("ETag"))
+}
+
+// TestPurgeTaskByID_NotInTrash tests that PurgeTaskByID passes on the usecase error
+func (suite *TaskControllerTestSuite) TestPurgeTaskByID_NotInTrash() {
+	suite.mockTaskUsecase.On("PurgeTaskByID", mock.Anything, mock.Anything, "1").Return(domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found in trash"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.PurgeTaskByID(c)
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+// TestGetSubtasks tests the GetSubtasks method
+func (suite *TaskControllerTestSuite) TestGetSubtasks() {
+	mockPage := domain.TaskPage{Tasks: []domain.Task{{ID: "2", Title: "Child", ParentID: "1"}}, Total: 1}
+	suite.mockTaskUsecase.On("GetSubtasks", mock.Anything, mock.Anything, "1", mock.AnythingOfType("domain.TaskQuery")).Return(mockPage, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/1/subtasks", nil)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.GetSubtasks(c)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"parent_id":"1"`)
+}
+
+// TestAddChecklistItem tests the AddChecklistItem method
+func (suite *TaskControllerTestSuite) TestAddChecklistItem() {
+	suite.mockTaskUsecase.On("AddChecklistItem", mock.Anything, mock.Anything, "1", "Write tests").Return(domain.ChecklistItem{ID: "a", Text: "Write tests"}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks/1/checklist", strings.NewReader(`{"text": "Write tests"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.AddChecklistItem(c)
+	suite.Equal(http.StatusCreated, w.Code)
+}
+
+// TestUpdateChecklistItem tests that only the sent fields are passed on
+func (suite *TaskControllerTestSuite) TestUpdateChecklistItem() {
+	suite.mockTaskUsecase.On("UpdateChecklistItem", mock.Anything, mock.Anything, "1", "a", mock.MatchedBy(func(update domain.ChecklistItemUpdate) bool {
+		return update.Text == nil && update.Done != nil && *update.Done
+	})).Return(domain.ChecklistItem{ID: "a", Text: "Write tests", Done: true}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/tasks/1/checklist/a", strings.NewReader(`{"done": true}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"}, gin.Param{Key: "itemId", Value: "a"})
+
+	suite.controller.UpdateChecklistItem(c)
+	suite.Equal(http.StatusOK, w.Code)
+}
+
+// TestAddDependency_Cycle tests that AddDependency reports a cycle as a conflict
+func (suite *TaskControllerTestSuite) TestAddDependency_Cycle() {
+	suite.mockTaskUsecase.On("AddDependency", mock.Anything, mock.Anything, "1", "3").Return(domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "The dependency would create a cycle", Field: "blocker_id"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks/1/dependencies", strings.NewReader(`{"blocker_id": "3"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.AddDependency(c)
+	suite.Equal(http.StatusConflict, w.Code)
+	suite.Contains(w.Body.String(), `"field":"blocker_id"`)
+}
+
+// TestGetDependencies tests the GetDependencies method
+func (suite *TaskControllerTestSuite) TestGetDependencies() {
+	graph := domain.TaskDependencyGraph{TaskID: "1", Upstream: []domain.DependencyNode{{ID: "2", Title: "Blocker", Depth: 1}}, Downstream: []domain.DependencyNode{}, Edges: []domain.DependencyEdge{{BlockerID: "2", BlockedID: "1"}}}
+	suite.mockTaskUsecase.On("GetDependencies", mock.Anything, mock.Anything, "1").Return(graph, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.GetDependencies(c)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"upstream":[{"id":"2"`)
+}
+
+// TestCreateTaskUsesAuthUser tests that CreateTask passes the caller identity to the usecase
+func (suite *TaskControllerTestSuite) TestCreateTaskUsesAuthUser() {
+	taskJSON := `{"title": "New Task"}`
+	authUser := domain.AuthUser{UserID: "user-1", Username: "user", Role: "user"}
+
+	suite.mockTaskUsecase.On("CreateTask", mock.Anything, authUser, mock.Anything).Return(domain.Task{ID: "1"}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks", strings.NewReader(taskJSON))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userId", "user-1")
+	c.Set("username", "user")
+	c.Set("role", "user")
+
+	suite.controller.CreateTask(c)
+
+	suite.Equal(http.StatusCreated, w.Code)
+}
+
+// TestDeleteTaskByIDHasSubtasks tests that a refused delete reports how many subtasks the task has
+func (suite *TaskControllerTestSuite) TestDeleteTaskByIDHasSubtasks() {
+	suite.mockTaskUsecase.On("DeleteTaskByID", mock.Anything, mock.Anything, "1").Return(domain.CustomError{
+		ErrCode:    http.StatusConflict,
+		ErrMessage: "Task has subtasks, delete or move them first",
+		Details:    map[string]interface{}{"subtask_count": 2},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.DeleteTaskByID(c)
+
+	suite.Equal(http.StatusConflict, w.Code)
+	suite.JSONEq(`{"message": "Task has subtasks, delete or move them first", "subtask_count": 2}`, w.Body.String())
+}
+
+// TestTransitionTaskConflict tests that an illegal transition returns the allowed next statuses
+func (suite *TaskControllerTestSuite) TestTransitionTaskConflict() {
+	suite.mockTaskUsecase.On("TransitionTask", mock.Anything, mock.Anything, "1", "done").Return(domain.CustomError{
+		ErrCode:    http.StatusConflict,
+		ErrMessage: "Cannot move task from todo to done",
+		Field:      "status",
+		Details:    map[string]interface{}{"allowed_statuses": []string{"in_progress"}},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks/1/transition", strings.NewReader(`{"status": "done"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.TransitionTask(c)
+
+	suite.Equal(http.StatusConflict, w.Code)
+	suite.JSONEq(`{"message": "Cannot move task from todo to done", "field": "status", "allowed_statuses": ["in_progress"]}`, w.Body.String())
+}
+
+// TestGetTaskHistory tests the GetTaskHistory method
+func (suite *TaskControllerTestSuite) TestGetTaskHistory() {
+	mockPage := domain.TaskHistoryPage{
+		Entries: []domain.TaskHistoryEntry{{TaskID: "1", Action: "updated", ActorUsername: "alice", Changes: []domain.FieldChange{{Field: "title", Before: "Old", After: "New"}}}},
+		Total:   1,
+	}
+	suite.mockTaskUsecase.On("GetTaskHistory", mock.Anything, mock.Anything, "1", "abc", int64(5)).Return(mockPage, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/1/history?cursor=abc&limit=5", nil)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.GetTaskHistory(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"before":"Old"`)
+	suite.Contains(w.Body.String(), `"actor_username":"alice"`)
+}
+
+// TestAssignUsers tests the AssignUsers method
+func (suite *TaskControllerTestSuite) TestAssignUsers() {
+	assigneesJSON := `{"user_ids": ["user-2", "user-3"]}`
+
+	suite.mockTaskUsecase.On("AssignUsers", mock.Anything, mock.Anything, "1", []string{"user-2", "user-3"}).Return(domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks/1/assignees", strings.NewReader(assigneesJSON))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.AssignUsers(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+}
+
+// TestUnassignUser tests the UnassignUser method
+func (suite *TaskControllerTestSuite) TestUnassignUser() {
+	suite.mockTaskUsecase.On("UnassignUser", mock.Anything, mock.Anything, "1", "user-2").Return(domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"}, gin.Param{Key: "userId", Value: "user-2"})
+
+	suite.controller.UnassignUser(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+}
+
+// TestCreateTaskInvalidJSON tests the CreateTask method with invalid JSON
+func (suite *TaskControllerTestSuite) TestCreateTaskInvalidJSON() {
+    taskJSON := `{"title": "New Task", "description": }` // Invalid JSON
 
     w := httptest.NewRecorder()
     c, _ := gin.CreateTestContext(w)
-    c.Request, _ = http.NewRequest(http.MethodPost, "/register", strings.NewReader(userJSON))
+    c.Request, _ = http.NewRequest(http.MethodPost, "/tasks", strings.NewReader(taskJSON))
     c.Request.Header.Set("Content-Type", "application/json")
 
-    suite.controller.RegisterUser(c)
+    suite.controller.CreateTask(c)
 
-    suite.Equal(http.StatusConflict, w.Code)
-    suite.Contains(w.Body.String(), "User already exists")
+    suite.Equal(http.StatusBadRequest, w.Code)
+    suite.Contains(w.Body.String(), "Invalid JSON")
+}
+
+// TestGetTaskByIDNotFound tests the GetTaskByID method when the task is not found
+func (suite *TaskControllerTestSuite) TestGetTaskByIDNotFound() {
+    suite.mockTaskUsecase.On("GetTaskByID", mock.Anything, mock.Anything, "1").Return(domain.Task{}, domain.CustomError{
+        ErrCode: http.StatusNotFound,
+        ErrMessage: "Task not found",
+    })
+
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+    suite.controller.GetTaskByID(c)
+
+    suite.Equal(http.StatusNotFound, w.Code)
+    suite.Contains(w.Body.String(), "Task not found")
+}
+
+// TestDeleteTaskByIDNotFound tests the DeleteTaskByID method when the task is not found
+func (suite *TaskControllerTestSuite) TestDeleteTaskByIDNotFound() {
+    suite.mockTaskUsecase.On("DeleteTaskByID", mock.Anything, mock.Anything, "1").Return(domain.CustomError{
+        ErrCode: http.StatusNotFound,
+        ErrMessage: "Task not found",
+    })
+
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+    suite.controller.DeleteTaskByID(c)
+
+    suite.Equal(http.StatusNotFound, w.Code)
+    suite.Contains(w.Body.String(), "Task not found")
+}
+
+
+
+// Mock for UserUsecase
+type MockUserUsecase struct {
+	mock.Mock
+}
+
+func (m *MockUserUsecase) RegisterUser(c context.Context, user domain.User) domain.CustomError {
+	args := m.Called(c, user)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockUserUsecase) AuthenticateUser(c context.Context, username, password string) (domain.TokenPair, domain.CustomError) {
+	args := m.Called(c, username, password)
+	return args.Get(0).(domain.TokenPair), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockUserUsecase) RefreshSession(c context.Context, refreshToken string) (domain.TokenPair, domain.CustomError) {
+	args := m.Called(c, refreshToken)
+	return args.Get(0).(domain.TokenPair), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockUserUsecase) Logout(c context.Context, user domain.AuthUser) domain.CustomError {
+	args := m.Called(c, user)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockUserUsecase) GetJWKS() domain.JWKSet {
+	return m.Called().Get(0).(domain.JWKSet)
+}
+
+func (m *MockUserUsecase) CheckTokenRevocation(c context.Context, userID string, tokenID string, issuedAt time.Time) domain.CustomError {
+	args := m.Called(c, userID, tokenID, issuedAt)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockUserUsecase) PromoteUser(c context.Context, username string, role string) domain.CustomError {
+	args := m.Called(c, username, role)
+	return args.Get(0).(domain.CustomError)
+}
+
+// UserControllerTestSuite defines a suite of tests for the UserController
+type UserControllerTestSuite struct {
+	suite.Suite
+	controller    *controllers.UserController
+	mockUserUsecase *MockUserUsecase
+}
+
+// SetupTest sets up the test environment before each test
+func (suite *UserControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUserUsecase = new(MockUserUsecase)
+	suite.controller = controllers.NewUserController(suite.mockUserUsecase)
+}
+
+func (suite *UserControllerTestSuite) TearDownTest() {
+	suite.mockUserUsecase.AssertExpectations(suite.T())
+}
+
+
+// TestRegisterUser tests the RegisterUser method
+func (suite *UserControllerTestSuite) TestRegisterUser() {
+	userJSON := `{"username": "newuser", "password": "password"}`
+
+	suite.mockUserUsecase.On("RegisterUser", mock.Anything, mock.Anything).Return(domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/register", strings.NewReader(userJSON))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.RegisterUser(c)
+
+	suite.Equal(http.StatusCreated, w.Code)
+}
+
+// TestLoginUser tests the LoginUser method
+func (suite *UserControllerTestSuite) TestLoginUser() {
+	loginJSON := `{"username": "user1", "password": "password"}`
+
+	tokens := domain.TokenPair{Token: "mocked_token", RefreshToken: "mocked_refresh", TokenType: "Bearer", ExpiresIn: 900}
+	suite.mockUserUsecase.On("AuthenticateUser", mock.Anything, "user1", "password").Return(tokens, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/login", strings.NewReader(loginJSON))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.LoginUser(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"token": "mocked_token", "refresh_token": "mocked_refresh", "token_type": "Bearer", "expires_in": 900}`, w.Body.String())
+}
+
+// TestGetJWKS tests the GetJWKS method publishes the verification keys
+func (suite *UserControllerTestSuite) TestGetJWKS() {
+	keys := domain.JWKSet{Keys: []domain.JWK{{Kty: "OKP", Use: "sig", Alg: "EdDSA", Kid: "2026-10", Crv: "Ed25519", X: "abc"}}}
+	suite.mockUserUsecase.On("GetJWKS").Return(keys)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+
+	suite.controller.GetJWKS(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal("public, max-age=300", w.Header().Get("Cache-Control"))
+	suite.JSONEq(`{"keys": [{"kty": "OKP", "use": "sig", "alg": "EdDSA", "kid": "2026-10", "crv": "Ed25519", "x": "abc"}]}`, w.Body.String())
+}
+
+// TestRefreshToken tests the RefreshToken method
+func (suite *UserControllerTestSuite) TestRefreshToken() {
+	tokens := domain.TokenPair{Token: "new_token", RefreshToken: "new_refresh", TokenType: "Bearer", ExpiresIn: 900}
+	suite.mockUserUsecase.On("RefreshSession", mock.Anything, "old_refresh").Return(tokens, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/token/refresh", strings.NewReader(`{"refresh_token": "old_refresh"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.RefreshToken(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"refresh_token":"new_refresh"`)
+}
+
+// TestRefreshTokenReused tests the RefreshToken method when the refresh token was already used
+func (suite *UserControllerTestSuite) TestRefreshTokenReused() {
+	suite.mockUserUsecase.On("RefreshSession", mock.Anything, "old_refresh").Return(domain.TokenPair{}, domain.CustomError{
+		ErrCode:    http.StatusUnauthorized,
+		ErrMessage: "Refresh token was already used; the session has been revoked",
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/token/refresh", strings.NewReader(`{"refresh_token": "old_refresh"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.RefreshToken(c)
+
+	suite.Equal(http.StatusUnauthorized, w.Code)
+	suite.Contains(w.Body.String(), "already used")
+}
+
+// TestRefreshTokenMissing tests the RefreshToken method without a refresh token
+func (suite *UserControllerTestSuite) TestRefreshTokenMissing() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/token/refresh", strings.NewReader(`{}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.RefreshToken(c)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.mockUserUsecase.AssertNotCalled(suite.T(), "RefreshSession", mock.Anything, mock.Anything)
+}
+
+// TestLogout tests the Logout method revokes the session of the caller's token
+func (suite *UserControllerTestSuite) TestLogout() {
+	user := domain.AuthUser{UserID: "user-1", Username: "user1", Role: "user", SessionID: "session-1", TokenID: "token-1"}
+	suite.mockUserUsecase.On("Logout", mock.Anything, user).Return(domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logout", nil)
+	c.Set("userId", "user-1")
+	c.Set("username", "user1")
+	c.Set("role", "user")
+	c.Set("sessionId", "session-1")
+	c.Set("tokenId", "token-1")
+
+	suite.controller.Logout(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"message": "Logged out"}`, w.Body.String())
+}
+
+// TestPromoteUser tests the PromoteUser method
+func (suite *UserControllerTestSuite) TestPromoteUser() {
+	promoteJSON := `{"username": "user1", "role": "manager"}`
+
+	suite.mockUserUsecase.On("PromoteUser", mock.Anything, "user1", "manager").Return(domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/promote", strings.NewReader(promoteJSON))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.PromoteUser(c)
+
+	suite.Equal( http.StatusOK, w.Code)
+	suite.JSONEq( `{"message": "User promoted successfully"}`, w.Body.String())
+}
+
+// TestLoginUserInvalidJSON tests the LoginUser method with invalid JSON
+func (suite *UserControllerTestSuite) TestLoginUserInvalidJSON() {
+    loginJSON := `{"username": "user1", "password": }` // Invalid JSON
+
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Request, _ = http.NewRequest(http.MethodPost, "/login", strings.NewReader(loginJSON))
+    c.Request.Header.Set("Content-Type", "application/json")
+
+    suite.controller.LoginUser(c)
+
+    suite.Equal(http.StatusBadRequest, w.Code)
+    suite.Contains(w.Body.String(), "Invalid JSON")
+}
+
+// TestRegisterUserConflict tests the RegisterUser method when the user already exists
+func (suite *UserControllerTestSuite) TestRegisterUserConflict() {
+    userJSON := `{"username": "newuser", "password": "password"}`
+
+    suite.mockUserUsecase.On("RegisterUser", mock.Anything, mock.Anything).Return(domain.CustomError{
+        ErrCode: http.StatusConflict,
+        ErrMessage: "User already exists",
+    })
+
+    w := httptest.NewRecorder()
+    c, _ := gin.CreateTestContext(w)
+    c.Request, _ = http.NewRequest(http.MethodPost, "/register", strings.NewReader(userJSON))
+    c.Request.Header.Set("Content-Type", "application/json")
+
+    suite.controller.RegisterUser(c)
+
+    suite.Equal(http.StatusConflict, w.Code)
+    suite.Contains(w.Body.String(), "User already exists")
+}
+
+type MockCommentUsecase struct {
+	mock.Mock
+}
+
+func (m *MockCommentUsecase) CreateComment(c context.Context, user domain.AuthUser, taskID string, input domain.CommentInput) (domain.Comment, domain.CustomError) {
+	args := m.Called(c, user, taskID, input)
+	return args.Get(0).(domain.Comment), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCommentUsecase) GetComments(c context.Context, user domain.AuthUser, taskID string, cursor string, limit int64) (domain.CommentPage, domain.CustomError) {
+	args := m.Called(c, user, taskID, cursor, limit)
+	return args.Get(0).(domain.CommentPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCommentUsecase) UpdateComment(c context.Context, user domain.AuthUser, taskID string, commentID string, body string) (domain.Comment, domain.CustomError) {
+	args := m.Called(c, user, taskID, commentID, body)
+	return args.Get(0).(domain.Comment), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCommentUsecase) DeleteComment(c context.Context, user domain.AuthUser, taskID string, commentID string) domain.CustomError {
+	args := m.Called(c, user, taskID, commentID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockCommentUsecase) GetMentions(c context.Context, user domain.AuthUser, cursor string, limit int64) (domain.CommentPage, domain.CustomError) {
+	args := m.Called(c, user, cursor, limit)
+	return args.Get(0).(domain.CommentPage), args.Get(1).(domain.CustomError)
+}
+
+// CommentControllerTestSuite defines a suite of tests for the CommentController
+type CommentControllerTestSuite struct {
+	suite.Suite
+	controller         *controllers.CommentController
+	mockCommentUsecase *MockCommentUsecase
+}
+
+// SetupTest sets up the test environment before each test
+func (suite *CommentControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockCommentUsecase = new(MockCommentUsecase)
+	suite.controller = controllers.NewCommentController(suite.mockCommentUsecase)
+}
+
+func (suite *CommentControllerTestSuite) TearDownTest() {
+	suite.mockCommentUsecase.AssertExpectations(suite.T())
+}
+
+// TestCreateComment tests that the author is taken from the authenticated user
+func (suite *CommentControllerTestSuite) TestCreateComment() {
+	author := domain.AuthUser{UserID: "user-1", Username: "alice", Role: "user"}
+	input := domain.CommentInput{Body: "Looks good @bob", ParentID: "comment-1"}
+	suite.mockCommentUsecase.On("CreateComment", mock.Anything, author, "1", input).Return(domain.Comment{ID: "comment-2", TaskID: "1", AuthorID: "user-1", Body: input.Body}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks/1/comments", strings.NewReader(`{"body": "Looks good @bob", "parent_id": "comment-1", "author_id": "someone-else"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+	c.Set("userId", "user-1")
+	c.Set("username", "alice")
+	c.Set("role", "user")
+
+	suite.controller.CreateComment(c)
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Contains(w.Body.String(), `"author_id":"user-1"`)
+}
+
+// TestCreateCommentMissingBody tests that a comment needs a body
+func (suite *CommentControllerTestSuite) TestCreateCommentMissingBody() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks/1/comments", strings.NewReader(`{}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.CreateComment(c)
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
+
+// TestUpdateCommentWindowExpired tests that an expired edit window is reported as forbidden
+func (suite *CommentControllerTestSuite) TestUpdateCommentWindowExpired() {
+	suite.mockCommentUsecase.On("UpdateComment", mock.Anything, mock.Anything, "1", "comment-1", "Edited").Return(domain.Comment{}, domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "Comments can only be edited within 15m0s of posting"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/tasks/1/comments/comment-1", strings.NewReader(`{"body": "Edited"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"}, gin.Param{Key: "commentId", Value: "comment-1"})
+
+	suite.controller.UpdateComment(c)
+	suite.Equal(http.StatusForbidden, w.Code)
+}
+
+// TestGetComments tests paging through a task's comments
+func (suite *CommentControllerTestSuite) TestGetComments() {
+	page := domain.CommentPage{Comments: []domain.Comment{{ID: "comment-1", Replies: []domain.Comment{{ID: "comment-2", ParentID: "comment-1"}}}}, Total: 1}
+	suite.mockCommentUsecase.On("GetComments", mock.Anything, mock.Anything, "1", "abc", int64(10)).Return(page, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks/1/comments?limit=10&cursor=abc", nil)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
+
+	suite.controller.GetComments(c)
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"replies":[{"_id":"comment-2"`)
+}
+
+type MockLabelUsecase struct {
+	mock.Mock
+}
+
+func (m *MockLabelUsecase) CreateLabel(c context.Context, input domain.LabelInput) (domain.Label, domain.CustomError) {
+	args := m.Called(c, input)
+	return args.Get(0).(domain.Label), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockLabelUsecase) GetLabels(c context.Context) ([]domain.Label, domain.CustomError) {
+	args := m.Called(c)
+	return args.Get(0).([]domain.Label), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockLabelUsecase) GetLabelByID(c context.Context, labelID string) (domain.Label, domain.CustomError) {
+	args := m.Called(c, labelID)
+	return args.Get(0).(domain.Label), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockLabelUsecase) UpdateLabel(c context.Context, labelID string, input domain.LabelInput) (domain.Label, domain.CustomError) {
+	args := m.Called(c, labelID, input)
+	return args.Get(0).(domain.Label), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockLabelUsecase) DeleteLabel(c context.Context, labelID string) domain.CustomError {
+	args := m.Called(c, labelID)
+	return args.Get(0).(domain.CustomError)
+}
+
+// LabelControllerTestSuite defines a suite of tests for the LabelController
+type LabelControllerTestSuite struct {
+	suite.Suite
+	controller       *controllers.LabelController
+	mockLabelUsecase *MockLabelUsecase
+}
+
+// SetupTest sets up the test environment before each test
+func (suite *LabelControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockLabelUsecase = new(MockLabelUsecase)
+	suite.controller = controllers.NewLabelController(suite.mockLabelUsecase)
+}
+
+func (suite *LabelControllerTestSuite) TearDownTest() {
+	suite.mockLabelUsecase.AssertExpectations(suite.T())
+}
+
+// TestCreateLabel tests the CreateLabel method
+func (suite *LabelControllerTestSuite) TestCreateLabel() {
+	input := domain.LabelInput{Name: "backend", Color: "#1d76db"}
+	suite.mockLabelUsecase.On("CreateLabel", mock.Anything, input).Return(domain.Label{ID: "label-1", Name: "backend", Color: "#1d76db"}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/labels", strings.NewReader(`{"name": "backend", "color": "#1d76db"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.CreateLabel(c)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Contains(w.Body.String(), `"_id":"label-1"`)
+}
+
+// TestCreateLabelConflict tests that a taken name is reported with its field
+func (suite *LabelControllerTestSuite) TestCreateLabelConflict() {
+	suite.mockLabelUsecase.On("CreateLabel", mock.Anything, mock.Anything).Return(domain.Label{}, domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "A label with this name already exists", Field: "name"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/labels", strings.NewReader(`{"name": "backend", "color": "#1d76db"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.CreateLabel(c)
+
+	suite.Equal(http.StatusConflict, w.Code)
+	suite.Contains(w.Body.String(), `"field":"name"`)
+}
+
+// TestDeleteLabel tests the DeleteLabel method
+func (suite *LabelControllerTestSuite) TestDeleteLabel() {
+	suite.mockLabelUsecase.On("DeleteLabel", mock.Anything, "label-1").Return(domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "label-1"})
+
+	suite.controller.DeleteLabel(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+}
+
+type MockRoleUsecase struct {
+	mock.Mock
+}
+
+func (m *MockRoleUsecase) GetPermissions() []domain.PermissionInfo {
+	return m.Called().Get(0).([]domain.PermissionInfo)
+}
+
+func (m *MockRoleUsecase) GetRoles(c context.Context) ([]domain.Role, domain.CustomError) {
+	args := m.Called(c)
+	return args.Get(0).([]domain.Role), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockRoleUsecase) GetRoleByName(c context.Context, name string) (domain.Role, domain.CustomError) {
+	args := m.Called(c, name)
+	return args.Get(0).(domain.Role), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockRoleUsecase) CreateRole(c context.Context, input domain.RoleInput) (domain.Role, domain.CustomError) {
+	args := m.Called(c, input)
+	return args.Get(0).(domain.Role), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockRoleUsecase) UpdateRole(c context.Context, name string, input domain.RoleInput) (domain.Role, domain.CustomError) {
+	args := m.Called(c, name, input)
+	return args.Get(0).(domain.Role), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockRoleUsecase) DeleteRole(c context.Context, name string) domain.CustomError {
+	args := m.Called(c, name)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockRoleUsecase) GetRolePermissions(c context.Context, role string) ([]domain.Permission, domain.CustomError) {
+	args := m.Called(c, role)
+	return args.Get(0).([]domain.Permission), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockRoleUsecase) EnsureBuiltInRoles(c context.Context) domain.CustomError {
+	args := m.Called(c)
+	return args.Get(0).(domain.CustomError)
+}
+
+// RoleControllerTestSuite defines a suite of tests for the RoleController
+type RoleControllerTestSuite struct {
+	suite.Suite
+	controller      *controllers.RoleController
+	mockRoleUsecase *MockRoleUsecase
+}
+
+// SetupTest sets up the test environment before each test
+func (suite *RoleControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockRoleUsecase = new(MockRoleUsecase)
+	suite.controller = controllers.NewRoleController(suite.mockRoleUsecase)
+}
+
+func (suite *RoleControllerTestSuite) TearDownTest() {
+	suite.mockRoleUsecase.AssertExpectations(suite.T())
+}
+
+// TestCreateRole tests the CreateRole method
+func (suite *RoleControllerTestSuite) TestCreateRole() {
+	input := domain.RoleInput{Name: "viewer", Permissions: []string{"task:update"}}
+	suite.mockRoleUsecase.On("CreateRole", mock.Anything, input).Return(domain.Role{Name: "viewer", Permissions: []domain.Permission{domain.PermissionTaskUpdate}}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/roles", strings.NewReader(`{"name": "viewer", "permissions": ["task:update"]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.CreateRole(c)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Contains(w.Body.String(), `"permissions":["task:update"]`)
+}
+
+// TestCreateRoleMissingPermissions tests that a role needs a list of permissions, even an empty one
+func (suite *RoleControllerTestSuite) TestCreateRoleMissingPermissions() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/roles", strings.NewReader(`{"name": "viewer"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.CreateRole(c)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
+
+// TestDeleteRoleInUse tests that a role still given to users is not deleted
+func (suite *RoleControllerTestSuite) TestDeleteRoleInUse() {
+	suite.mockRoleUsecase.On("DeleteRole", mock.Anything, "manager").Return(domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "The role is given to 2 users"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "name", Value: "manager"})
+
+	suite.controller.DeleteRole(c)
+
+	suite.Equal(http.StatusConflict, w.Code)
+	suite.JSONEq(`{"message": "The role is given to 2 users"}`, w.Body.String())
+}
+
+type MockProjectUsecase struct {
+	mock.Mock
+}
+
+func (m *MockProjectUsecase) CreateProject(c context.Context, user domain.AuthUser, input domain.ProjectInput) (domain.Project, domain.CustomError) {
+	args := m.Called(c, user, input)
+	return args.Get(0).(domain.Project), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockProjectUsecase) GetProjects(c context.Context, user domain.AuthUser) ([]domain.Project, domain.CustomError) {
+	args := m.Called(c, user)
+	return args.Get(0).([]domain.Project), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockProjectUsecase) GetProjectByID(c context.Context, user domain.AuthUser, projectID string) (domain.Project, domain.CustomError) {
+	args := m.Called(c, user, projectID)
+	return args.Get(0).(domain.Project), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockProjectUsecase) UpdateProject(c context.Context, user domain.AuthUser, projectID string, input domain.ProjectInput) (domain.Project, domain.CustomError) {
+	args := m.Called(c, user, projectID, input)
+	return args.Get(0).(domain.Project), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockProjectUsecase) DeleteProject(c context.Context, user domain.AuthUser, projectID string) domain.CustomError {
+	args := m.Called(c, user, projectID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockProjectUsecase) SetMember(c context.Context, user domain.AuthUser, projectID string, userID string, role string) (domain.Project, domain.CustomError) {
+	args := m.Called(c, user, projectID, userID, role)
+	return args.Get(0).(domain.Project), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockProjectUsecase) RemoveMember(c context.Context, user domain.AuthUser, projectID string, userID string) domain.CustomError {
+	args := m.Called(c, user, projectID, userID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockProjectUsecase) GetProjectRole(c context.Context, user domain.AuthUser, projectID string) (domain.ProjectRole, domain.CustomError) {
+	args := m.Called(c, user, projectID)
+	return args.Get(0).(domain.ProjectRole), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockProjectUsecase) GetTaskProjectRole(c context.Context, user domain.AuthUser, taskID string) (domain.ProjectRole, domain.CustomError) {
+	args := m.Called(c, user, taskID)
+	return args.Get(0).(domain.ProjectRole), args.Get(1).(domain.CustomError)
+}
+
+// ProjectControllerTestSuite defines a suite of tests for the ProjectController
+type ProjectControllerTestSuite struct {
+	suite.Suite
+	controller         *controllers.ProjectController
+	mockProjectUsecase *MockProjectUsecase
+}
+
+// SetupTest sets up the test environment before each test
+func (suite *ProjectControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockProjectUsecase = new(MockProjectUsecase)
+	suite.controller = controllers.NewProjectController(suite.mockProjectUsecase)
+}
+
+func (suite *ProjectControllerTestSuite) TearDownTest() {
+	suite.mockProjectUsecase.AssertExpectations(suite.T())
+}
+
+// TestCreateProject tests the CreateProject method
+func (suite *ProjectControllerTestSuite) TestCreateProject() {
+	input := domain.ProjectInput{Name: "Website"}
+	suite.mockProjectUsecase.On("CreateProject", mock.Anything, mock.Anything, input).Return(domain.Project{ID: "project-1", Name: "Website"}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/projects", strings.NewReader(`{"name": "Website"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.CreateProject(c)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Contains(w.Body.String(), `"_id":"project-1"`)
+}
+
+// TestSetMember tests the SetMember method
+func (suite *ProjectControllerTestSuite) TestSetMember() {
+	suite.mockProjectUsecase.On("SetMember", mock.Anything, mock.Anything, "project-1", "user-2", "viewer").Return(domain.Project{ID: "project-1"}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "project-1"}, gin.Param{Key: "userId", Value: "user-2"})
+	c.Request, _ = http.NewRequest(http.MethodPut, "/projects/project-1/members/user-2", strings.NewReader(`{"role": "viewer"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.SetMember(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+}
+
+// TestRemoveLastOwner tests that removing the last owner is reported as a conflict
+func (suite *ProjectControllerTestSuite) TestRemoveLastOwner() {
+	suite.mockProjectUsecase.On("RemoveMember", mock.Anything, mock.Anything, "project-1", "user-1").Return(domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "A project needs at least one owner, add another owner first"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "project-1"}, gin.Param{Key: "userId", Value: "user-1"})
+
+	suite.controller.RemoveMember(c)
+
+	suite.Equal(http.StatusConflict, w.Code)
+}
+
+type MockWebhookUsecase struct {
+	mock.Mock
+}
+
+func (m *MockWebhookUsecase) Publish(c context.Context, event domain.TaskEvent) {
+	m.Called(c, event)
+}
+
+func (m *MockWebhookUsecase) CreateWebhook(c context.Context, user domain.AuthUser, input domain.WebhookInput) (domain.Webhook, domain.CustomError) {
+	args := m.Called(c, user, input)
+	return args.Get(0).(domain.Webhook), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookUsecase) GetWebhooks(c context.Context) ([]domain.Webhook, domain.CustomError) {
+	args := m.Called(c)
+	return args.Get(0).([]domain.Webhook), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookUsecase) GetWebhookByID(c context.Context, webhookID string) (domain.Webhook, domain.CustomError) {
+	args := m.Called(c, webhookID)
+	return args.Get(0).(domain.Webhook), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookUsecase) UpdateWebhook(c context.Context, webhookID string, input domain.WebhookInput) (domain.Webhook, domain.CustomError) {
+	args := m.Called(c, webhookID, input)
+	return args.Get(0).(domain.Webhook), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookUsecase) DeleteWebhook(c context.Context, webhookID string) domain.CustomError {
+	args := m.Called(c, webhookID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockWebhookUsecase) GetDeliveries(c context.Context, webhookID string, cursor string, limit int64) (domain.WebhookDeliveryPage, domain.CustomError) {
+	args := m.Called(c, webhookID, cursor, limit)
+	return args.Get(0).(domain.WebhookDeliveryPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookUsecase) GetDeliveryByID(c context.Context, webhookID string, deliveryID string) (domain.WebhookDelivery, domain.CustomError) {
+	args := m.Called(c, webhookID, deliveryID)
+	return args.Get(0).(domain.WebhookDelivery), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookUsecase) Redeliver(c context.Context, webhookID string, deliveryID string) (domain.WebhookDelivery, domain.CustomError) {
+	args := m.Called(c, webhookID, deliveryID)
+	return args.Get(0).(domain.WebhookDelivery), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookUsecase) DispatchDueDeliveries(c context.Context) (int, domain.CustomError) {
+	args := m.Called(c)
+	return args.Int(0), args.Get(1).(domain.CustomError)
+}
+
+// WebhookControllerTestSuite defines a suite of tests for the WebhookController
+type WebhookControllerTestSuite struct {
+	suite.Suite
+	controller         *controllers.WebhookController
+	mockWebhookUsecase *MockWebhookUsecase
+}
+
+// SetupTest sets up the test environment before each test
+func (suite *WebhookControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockWebhookUsecase = new(MockWebhookUsecase)
+	suite.controller = controllers.NewWebhookController(suite.mockWebhookUsecase)
+}
+
+func (suite *WebhookControllerTestSuite) TearDownTest() {
+	suite.mockWebhookUsecase.AssertExpectations(suite.T())
+}
+
+// TestCreateWebhook tests that the secret is accepted but never returned
+func (suite *WebhookControllerTestSuite) TestCreateWebhook() {
+	input := domain.WebhookInput{URL: "https://example.com/hooks", Events: []string{"task.created"}, Secret: "0123456789abcdef"}
+	webhook := domain.Webhook{ID: "hook-1", URL: input.URL, Events: input.Events, Secret: input.Secret}
+	suite.mockWebhookUsecase.On("CreateWebhook", mock.Anything, mock.Anything, input).Return(webhook, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url": "https://example.com/hooks", "events": ["task.created"], "secret": "0123456789abcdef"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.CreateWebhook(c)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Contains(w.Body.String(), `"_id":"hook-1"`)
+	suite.NotContains(w.Body.String(), "0123456789abcdef")
+}
+
+// TestGetDeliveries tests that the delivery log is paged
+func (suite *WebhookControllerTestSuite) TestGetDeliveries() {
+	page := domain.WebhookDeliveryPage{Deliveries: []domain.WebhookDelivery{{ID: "delivery-1", WebhookID: "hook-1", Status: domain.DeliveryFailed}}, Total: 1}
+	suite.mockWebhookUsecase.On("GetDeliveries", mock.Anything, "hook-1", "", int64(10)).Return(page, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "hook-1"})
+	c.Request, _ = http.NewRequest(http.MethodGet, "/webhooks/hook-1/deliveries?limit=10", nil)
+
+	suite.controller.GetDeliveries(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"status":"failed"`)
+}
+
+// TestRedeliver tests that a redelivery returns the new delivery
+func (suite *WebhookControllerTestSuite) TestRedeliver() {
+	delivery := domain.WebhookDelivery{ID: "delivery-2", WebhookID: "hook-1", Status: domain.DeliverySucceeded, RedeliveryOf: "delivery-1"}
+	suite.mockWebhookUsecase.On("Redeliver", mock.Anything, "hook-1", "delivery-1").Return(delivery, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "hook-1"}, gin.Param{Key: "deliveryId", Value: "delivery-1"})
+
+	suite.controller.Redeliver(c)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Contains(w.Body.String(), `"redelivery_of":"delivery-1"`)
+}
+
+type MockCalendarUsecase struct {
+	mock.Mock
+}
+
+func (m *MockCalendarUsecase) CreateFeedToken(c context.Context, user domain.AuthUser) (domain.CalendarToken, domain.CustomError) {
+	args := m.Called(c, user)
+	return args.Get(0).(domain.CalendarToken), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCalendarUsecase) GetFeedToken(c context.Context, user domain.AuthUser) (domain.CalendarFeed, domain.CustomError) {
+	args := m.Called(c, user)
+	return args.Get(0).(domain.CalendarFeed), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCalendarUsecase) RevokeFeedToken(c context.Context, user domain.AuthUser) domain.CustomError {
+	args := m.Called(c, user)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockCalendarUsecase) GetFeed(c context.Context, token string, kind string) (string, domain.CustomError) {
+	args := m.Called(c, token, kind)
+	return args.String(0), args.Get(1).(domain.CustomError)
+}
+
+// CalendarControllerTestSuite defines a suite of tests for the CalendarController
+type CalendarControllerTestSuite struct {
+	suite.Suite
+	controller          *controllers.CalendarController
+	mockCalendarUsecase *MockCalendarUsecase
+}
+
+// SetupTest sets up the test environment before each test
+func (suite *CalendarControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockCalendarUsecase = new(MockCalendarUsecase)
+	suite.controller = controllers.NewCalendarController(suite.mockCalendarUsecase)
+}
+
+func (suite *CalendarControllerTestSuite) TearDownTest() {
+	suite.mockCalendarUsecase.AssertExpectations(suite.T())
+}
+
+// TestCreateFeedToken tests that the new token comes with the URL to subscribe to
+func (suite *CalendarControllerTestSuite) TestCreateFeedToken() {
+	suite.mockCalendarUsecase.On("CreateFeedToken", mock.Anything, domain.AuthUser{UserID: "user-1"}).Return(domain.CalendarToken{Token: "abc"}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userId", "user-1")
+	c.Request, _ = http.NewRequest(http.MethodPost, "/calendar/token", nil)
+	c.Request.Host = "tasks.example.com"
+	c.Request.Header.Set("X-Forwarded-Proto", "https")
+
+	suite.controller.CreateFeedToken(c)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Contains(w.Body.String(), `"url":"https://tasks.example.com/calendar/feed/abc.ics"`)
+}
+
+// TestGetFeed tests that the feed is served as text/calendar
+func (suite *CalendarControllerTestSuite) TestGetFeed() {
+	suite.mockCalendarUsecase.On("GetFeed", mock.Anything, "abc.ics", domain.CalendarKindTodo).Return("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "token", Value: "abc.ics"})
+	c.Request, _ = http.NewRequest(http.MethodGet, "/calendar/feed/abc.ics?kind=todo", nil)
+
+	suite.controller.GetFeed(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal("text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+	suite.Equal("private, no-store", w.Header().Get("Cache-Control"))
+	suite.Equal("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", w.Body.String())
+}
+
+// TestGetFeedUnknownKind tests that the kind is validated
+func (suite *CalendarControllerTestSuite) TestGetFeedUnknownKind() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "token", Value: "abc"})
+	c.Request, _ = http.NewRequest(http.MethodGet, "/calendar/feed/abc?kind=journal", nil)
+
+	suite.controller.GetFeed(c)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
+
+// TestRevokeFeedToken tests revoking a feed the caller does not have
+func (suite *CalendarControllerTestSuite) TestRevokeFeedToken() {
+	suite.mockCalendarUsecase.On("RevokeFeedToken", mock.Anything, mock.Anything).Return(domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Calendar feed not found"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/calendar/token", nil)
+
+	suite.controller.RevokeFeedToken(c)
+
+	suite.Equal(http.StatusNotFound, w.Code)
+	suite.JSONEq(`{"message": "Calendar feed not found"}`, w.Body.String())
+}
+
+type MockUserAdminUsecase struct {
+	mock.Mock
+}
+
+func (m *MockUserAdminUsecase) GetUsers(c context.Context, query domain.UserQuery) (domain.UserPage, domain.CustomError) {
+	args := m.Called(c, query)
+	return args.Get(0).(domain.UserPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockUserAdminUsecase) GetUserByID(c context.Context, userID string) (domain.UserSummary, domain.CustomError) {
+	args := m.Called(c, userID)
+	return args.Get(0).(domain.UserSummary), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockUserAdminUsecase) ChangeRole(c context.Context, userID string, role string) (domain.UserSummary, domain.CustomError) {
+	args := m.Called(c, userID, role)
+	return args.Get(0).(domain.UserSummary), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockUserAdminUsecase) DisableUser(c context.Context, user domain.AuthUser, userID string) (domain.UserSummary, domain.CustomError) {
+	args := m.Called(c, user, userID)
+	return args.Get(0).(domain.UserSummary), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockUserAdminUsecase) EnableUser(c context.Context, userID string) (domain.UserSummary, domain.CustomError) {
+	args := m.Called(c, userID)
+	return args.Get(0).(domain.UserSummary), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockUserAdminUsecase) DeleteUser(c context.Context, user domain.AuthUser, userID string, deletion domain.UserDeletion) (domain.UserDeletionReport, domain.CustomError) {
+	args := m.Called(c, user, userID, deletion)
+	return args.Get(0).(domain.UserDeletionReport), args.Get(1).(domain.CustomError)
+}
+
+// UserAdminControllerTestSuite defines a suite of tests for the UserAdminController
+type UserAdminControllerTestSuite struct {
+	suite.Suite
+	controller           *controllers.UserAdminController
+	mockUserAdminUsecase *MockUserAdminUsecase
+}
+
+func (suite *UserAdminControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUserAdminUsecase = new(MockUserAdminUsecase)
+	suite.controller = controllers.NewUserAdminController(suite.mockUserAdminUsecase)
+}
+
+func (suite *UserAdminControllerTestSuite) TearDownTest() {
+	suite.mockUserAdminUsecase.AssertExpectations(suite.T())
+}
+
+// TestGetUsers tests the GetUsers method reads the filters from the query string
+func (suite *UserAdminControllerTestSuite) TestGetUsers() {
+	disabled := true
+	suite.mockUserAdminUsecase.On("GetUsers", mock.Anything, domain.UserQuery{Search: "ali", Role: "user", Disabled: &disabled, Limit: 5}).Return(domain.UserPage{Users: []domain.UserSummary{{ID: "user-1", Username: "alice"}}, Total: 1}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/users?q=ali&role=user&disabled=true&limit=5", nil)
+
+	suite.controller.GetUsers(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"username":"alice"`)
+	suite.NotContains(w.Body.String(), "password")
+}
+
+// TestGetUsersInvalidDisabled tests that ?disabled= must be a boolean
+func (suite *UserAdminControllerTestSuite) TestGetUsersInvalidDisabled() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/users?disabled=maybe", nil)
+
+	suite.controller.GetUsers(c)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.JSONEq(`{"message": "disabled must be true or false", "field": "disabled"}`, w.Body.String())
+}
+
+// TestChangeRole tests the ChangeRole method
+func (suite *UserAdminControllerTestSuite) TestChangeRole() {
+	suite.mockUserAdminUsecase.On("ChangeRole", mock.Anything, "user-1", "user").Return(domain.UserSummary{ID: "user-1", Role: "user"}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "user-1"})
+	c.Request, _ = http.NewRequest(http.MethodPut, "/users/user-1/role", strings.NewReader(`{"role": "user"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	suite.controller.ChangeRole(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"role":"user"`)
+}
+
+// TestDisableUser tests the DisableUser method passes the caller
+func (suite *UserAdminControllerTestSuite) TestDisableUser() {
+	suite.mockUserAdminUsecase.On("DisableUser", mock.Anything, mock.MatchedBy(func(user domain.AuthUser) bool {
+		return user.UserID == "admin-1"
+	}), "user-1").Return(domain.UserSummary{ID: "user-1", Disabled: true}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userId", "admin-1")
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "user-1"})
+	c.Request, _ = http.NewRequest(http.MethodPost, "/users/user-1/disable", nil)
+
+	suite.controller.DisableUser(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"disabled":true`)
+}
+
+// TestDeleteUser tests the DeleteUser method reads what to do with the tasks
+func (suite *UserAdminControllerTestSuite) TestDeleteUser() {
+	deletion := domain.UserDeletion{Tasks: domain.UserTasksTransfer, TransferTo: "user-2"}
+	suite.mockUserAdminUsecase.On("DeleteUser", mock.Anything, mock.Anything, "user-1", deletion).Return(domain.UserDeletionReport{Tasks: domain.UserTasksTransfer, TransferTo: "user-2", Transferred: 2}, domain.CustomError{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "user-1"})
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/users/user-1?tasks=transfer&to=user-2", nil)
+
+	suite.controller.DeleteUser(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"tasks": "transfer", "transfer_to": "user-2", "transferred": 2, "deleted": 0, "unassigned": 0}`, w.Body.String())
+}
+
+// TestDeleteUserMissingTasks tests that deleting a user requires ?tasks=
+func (suite *UserAdminControllerTestSuite) TestDeleteUserMissingTasks() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: "user-1"})
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/users/user-1", nil)
+
+	suite.controller.DeleteUser(c)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.Contains(w.Body.String(), `"field":"tasks"`)
 }
 
-// TestControllerTestSuite runs the suites of the task tests and user tests
+// TestControllerTestSuite runs the suites of the task, user, comment, label, role, project, webhook, calendar and user admin tests
 func TestControllerTestSuite(t *testing.T) {
 	suite.Run(t, new(TaskControllerTestSuite))
 	suite.Run(t, new(UserControllerTestSuite))
+	suite.Run(t, new(CommentControllerTestSuite))
+	suite.Run(t, new(LabelControllerTestSuite))
+	suite.Run(t, new(RoleControllerTestSuite))
+	suite.Run(t, new(ProjectControllerTestSuite))
+	suite.Run(t, new(WebhookControllerTestSuite))
+	suite.Run(t, new(CalendarControllerTestSuite))
+	suite.Run(t, new(UserAdminControllerTestSuite))
 }
@@ -6,6 +6,7 @@ import (
 	bootstrap "task_managment_api"
 	"task_managment_api/delivery/controllers"
 	"task_managment_api/delivery/router"
+	"task_managment_api/domain"
 	"task_managment_api/infrastructure"
 	"task_managment_api/repositories"
 	"task_managment_api/usecases"
@@ -43,6 +44,56 @@ func NewMongoDatabase(env *bootstrap.Env) *mongo.Database {
 		log.Fatal(err)
 	}
 
+	err = EnsureTaskIndexes(db, env.DbTaskCollection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = EnsureTaskHistoryIndexes(db, env.DbTaskHistoryCollection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = EnsureCommentIndexes(db, env.DbCommentCollection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = EnsureLabelIndexes(db, env.DbLabelCollection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = EnsureProjectIndexes(db, env.DbProjectCollection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = EnsureReminderIndexes(db, env.DbReminderCollection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = EnsureWebhookDeliveryIndexes(db, env.DbWebhookDeliveryCollection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = EnsureCalendarFeedIndexes(db, env.DbCalendarFeedCollection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = EnsureRefreshTokenIndexes(db, env.DbRefreshTokenCollection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = EnsureRevokedTokenIndexes(db, env.DbRevokedTokenCollection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	return db
 }
 
@@ -58,6 +109,138 @@ func EnsureIndexes(db *mongo.Database, userCollectionString string) error {
 	return err
 }
 
+//support the visibility filters and every sort order of GET /tasks, full-text search, the occurrences of recurring tasks, and the trash sweeper
+func EnsureTaskIndexes(db *mongo.Database, taskCollectionString string) error {
+	taskCollection := db.Collection(taskCollectionString)
+	indexModels := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_by", Value: 1}}},
+		{Keys: bson.D{{Key: "assignee_ids", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "due_date", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "blocked_by", Value: 1}}},
+		{Keys: bson.D{{Key: "label_ids", Value: 1}}},
+		{Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "recurrence.series_id", Value: 1}, {Key: "recurrence.index", Value: 1}}},
+		// GET /tasks/search; matches in the title count for more than matches in the description
+		{
+			Keys:    bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().SetName("title_description_text").SetWeights(bson.D{{Key: "title", Value: 5}, {Key: "description", Value: 1}}),
+		},
+	}
+
+	_, err := taskCollection.Indexes().CreateMany(context.TODO(), indexModels)
+	return err
+}
+//list a task's history newest first
+func EnsureTaskHistoryIndexes(db *mongo.Database, historyCollectionString string) error {
+	historyCollection := db.Collection(historyCollectionString)
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "_id", Value: -1}},
+	}
+
+	_, err := historyCollection.Indexes().CreateOne(context.TODO(), indexModel)
+	return err
+}
+
+//list a task's threads and their replies in order, and find the comments that mention a user
+func EnsureCommentIndexes(db *mongo.Database, commentCollectionString string) error {
+	commentCollection := db.Collection(commentCollectionString)
+	indexModels := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "mentions", Value: 1}, {Key: "_id", Value: -1}}},
+	}
+
+	_, err := commentCollection.Indexes().CreateMany(context.TODO(), indexModels)
+	return err
+}
+
+//make sure label names are unique regardless of case
+func EnsureLabelIndexes(db *mongo.Database, labelCollectionString string) error {
+	labelCollection := db.Collection(labelCollectionString)
+	indexModel := mongo.IndexModel{
+		Keys:    bson.M{"name": 1},
+		Options: options.Index().SetUnique(true).SetCollation(repositories.LabelNameCollation),
+	}
+
+	_, err := labelCollection.Indexes().CreateOne(context.TODO(), indexModel)
+	return err
+}
+
+//find the projects a user is a member of
+func EnsureProjectIndexes(db *mongo.Database, projectCollectionString string) error {
+	projectCollection := db.Collection(projectCollectionString)
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "members.user_id", Value: 1}},
+	}
+
+	_, err := projectCollection.Indexes().CreateOne(context.TODO(), indexModel)
+	return err
+}
+
+//make sure a user is reminded of a task only once per due date
+func EnsureReminderIndexes(db *mongo.Database, reminderCollectionString string) error {
+	reminderCollection := db.Collection(reminderCollectionString)
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "task_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "due_date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+
+	_, err := reminderCollection.Indexes().CreateOne(context.TODO(), indexModel)
+	return err
+}
+
+//list a webhook's deliveries newest first, and find the deliveries that are due
+func EnsureWebhookDeliveryIndexes(db *mongo.Database, deliveryCollectionString string) error {
+	deliveryCollection := db.Collection(deliveryCollectionString)
+	indexModels := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+	}
+
+	_, err := deliveryCollection.Indexes().CreateMany(context.TODO(), indexModels)
+	return err
+}
+
+//one feed per user, looked up by the hash of its token
+func EnsureCalendarFeedIndexes(db *mongo.Database, feedCollectionString string) error {
+	feedCollection := db.Collection(feedCollectionString)
+	indexModels := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+	}
+
+	_, err := feedCollection.Indexes().CreateMany(context.TODO(), indexModels)
+	return err
+}
+
+//look refresh tokens up by their hash, revoke a session's tokens together, and drop expired tokens
+func EnsureRefreshTokenIndexes(db *mongo.Database, refreshTokenCollectionString string) error {
+	refreshTokenCollection := db.Collection(refreshTokenCollectionString)
+	indexModels := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "family_id", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}
+
+	_, err := refreshTokenCollection.Indexes().CreateMany(context.TODO(), indexModels)
+	return err
+}
+
+//forget revoked access tokens once they have expired anyway
+func EnsureRevokedTokenIndexes(db *mongo.Database, revokedTokenCollectionString string) error {
+	revokedTokenCollection := db.Collection(revokedTokenCollectionString)
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+
+	_, err := revokedTokenCollection.Indexes().CreateOne(context.TODO(), indexModel)
+	return err
+}
 
 func main() {
 
@@ -65,14 +248,76 @@ func main() {
 
 	tr := repositories.NewTaskRepository(app.Db, app.Env.DbTaskCollection)
 	tc := repositories.NewUserRepository(app.Db, app.Env.DbUserCollection)
+	hr := repositories.NewTaskHistoryRepository(app.Db, app.Env.DbTaskHistoryCollection)
+	cr := repositories.NewCommentRepository(app.Db, app.Env.DbCommentCollection)
+	lr := repositories.NewLabelRepository(app.Db, app.Env.DbLabelCollection)
+	pr := repositories.NewProjectRepository(app.Db, app.Env.DbProjectCollection)
+	sr := repositories.NewTaskSeriesRepository(app.Db, app.Env.DbTaskSeriesCollection)
+	rr := repositories.NewReminderRepository(app.Db, app.Env.DbReminderCollection)
+	whr := repositories.NewWebhookRepository(app.Db, app.Env.DbWebhookCollection)
+	wdr := repositories.NewWebhookDeliveryRepository(app.Db, app.Env.DbWebhookDeliveryCollection)
+	cfr := repositories.NewCalendarFeedRepository(app.Db, app.Env.DbCalendarFeedCollection)
+	rtr := repositories.NewRefreshTokenRepository(app.Db, app.Env.DbRefreshTokenCollection)
+	vtr := repositories.NewRevokedTokenRepository(app.Db, app.Env.DbRevokedTokenCollection)
+	rlr := repositories.NewRoleRepository(app.Db, app.Env.DbRoleCollection)
 	ps := infrastructure.NewPasswordService()
 
-	js := infrastructure.NewJWTService(app.Env.AccessTokenSecret)	
-	as := infrastructure.NewAuthService(js)
-	taskController := controllers.NewTaskController(usecases.NewTaskUsecase(tr)) 
-	userController := controllers.NewUserController(usecases.NewUserUsecase(tc, js,ps))
+	js := infrastructure.NewJWTService(app.Env.AccessTokenSecret, app.Env.AccessTokenTTL)	
+	if app.Env.JWTSigningKeyFile != "" {
+		signingKey, verificationKeys, err := infrastructure.LoadJWTKeys(app.Env.JWTSigningKeyID, app.Env.JWTSigningKeyFile, app.Env.JWTVerificationKeys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		js, err = infrastructure.NewKeyedJWTService(signingKey, verificationKeys, app.Env.AccessTokenTTL)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	userUsecase := usecases.NewUserUsecase(tc, js,ps, rtr, vtr, rlr, app.Env.RefreshTokenTTL)
+	roleUsecase := usecases.NewRoleUsecase(rlr, tc)
+	if err := roleUsecase.EnsureBuiltInRoles(context.Background()); err.ErrCode != 0 {
+		log.Fatal(err.ErrMessage)
+	}
+	as := infrastructure.NewAuthService(js, userUsecase, roleUsecase, app.Env.TokenRevocationCacheTTL)
+	subtaskDeletePolicy, err := domain.ParseSubtaskDeletePolicy(app.Env.SubtaskDeletePolicy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	statusWorkflow, err := domain.ParseStatusWorkflow(app.Env.StatusWorkflow)
+	if err != nil {
+		log.Fatal(err)
+	}
+	webhookUsecase := usecases.NewWebhookUsecase(whr, wdr, infrastructure.NewWebhookSender(app.Env.WebhookTimeout), app.Env.WebhookMaxAttempts)
+	taskUsecase := usecases.NewTaskUsecase(tr, tc, rlr, hr, lr, pr, sr, webhookUsecase, repositories.NewTransactionRunner(app.Db), statusWorkflow, subtaskDeletePolicy)
+	taskController := controllers.NewTaskController(taskUsecase)
+	userController := controllers.NewUserController(userUsecase)
+	commentController := controllers.NewCommentController(usecases.NewCommentUsecase(cr, tr, tc, pr, app.Env.CommentEditWindow))
+	labelController := controllers.NewLabelController(usecases.NewLabelUsecase(lr, tr))
+	projectUsecase := usecases.NewProjectUsecase(pr, tr, tc)
+	projectController := controllers.NewProjectController(projectUsecase)
+	pms := infrastructure.NewProjectService(projectUsecase)
+	webhookController := controllers.NewWebhookController(webhookUsecase)
+	calendarController := controllers.NewCalendarController(usecases.NewCalendarUsecase(cfr, tc, rlr, taskUsecase))
+	roleController := controllers.NewRoleController(roleUsecase)
+	userAdminController := controllers.NewUserAdminController(usecases.NewUserAdminUsecase(tc, rlr, pr, cfr, taskUsecase))
+
+
+	infrastructure.NewTrashSweeper(taskUsecase, app.Env.TrashRetention, app.Env.TrashSweepInterval).Start(context.Background())
 
+	notifier, err := infrastructure.NewNotifier(app.Env.Notifier, infrastructure.SMTPConfig{
+		Host:     app.Env.SMTPHost,
+		Port:     app.Env.SMTPPort,
+		Username: app.Env.SMTPUsername,
+		Password: app.Env.SMTPPassword,
+		From:     app.Env.SMTPFrom,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	reminderUsecase := usecases.NewReminderUsecase(tr, tc, rr, notifier)
+	infrastructure.NewReminderScheduler(reminderUsecase, app.Env.ReminderWindow, app.Env.ReminderInterval).Start(context.Background())
+	infrastructure.NewWebhookDispatcher(webhookUsecase, app.Env.WebhookDispatchInterval).Start(context.Background())
 
-	r := router.SetupRouter(app.Db, taskController, userController,as )
+	r := router.SetupRouter(app.Db, taskController, userController, commentController, labelController, projectController, webhookController, calendarController, roleController, userAdminController, as, pms)
 	r.Run(":8080")	
 }
@@ -2,13 +2,14 @@ package router
 
 import (
 	"task_managment_api/delivery/controllers"
+	"task_managment_api/domain"
 	"task_managment_api/infrastructure"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func SetupRouter(db *mongo.Database, taskController *controllers.TaskController, userController *controllers.UserController, authService infrastructure.AuthMiddlewareService) *gin.Engine {
+func SetupRouter(db *mongo.Database, taskController *controllers.TaskController, userController *controllers.UserController, commentController *controllers.CommentController, labelController *controllers.LabelController, projectController *controllers.ProjectController, webhookController *controllers.WebhookController, calendarController *controllers.CalendarController, roleController *controllers.RoleController, userAdminController *controllers.UserAdminController, authService infrastructure.AuthMiddlewareService, projectService infrastructure.ProjectMiddlewareService) *gin.Engine {
 
 	
 	router := gin.Default()
@@ -16,6 +17,10 @@ func SetupRouter(db *mongo.Database, taskController *controllers.TaskController,
 	// public routes
 	router.POST("/register", userController.RegisterUser)
 	router.POST("/login", userController.LoginUser)
+	router.POST("/token/refresh", userController.RefreshToken)
+	router.GET("/.well-known/jwks.json", userController.GetJWKS)
+	// calendar clients cannot send a JWT, so the feed is authenticated by the token in its URL
+	router.GET("/calendar/feed/:token", calendarController.GetFeed)
 
 
 
@@ -23,15 +28,111 @@ func SetupRouter(db *mongo.Database, taskController *controllers.TaskController,
 	authorized := router.Group("/")
 	authorized.Use(authService.AuthMiddleware())
 
+	// project membership checks for routes whose :id is a task; the usecases repeat them for
+	// trashed tasks, which these routes cannot load
+	canReadTask := projectService.TaskRoleMiddleware(domain.ProjectRoleViewer)
+	canWriteTask := projectService.TaskRoleMiddleware(domain.ProjectRoleMember)
+	// permission checks of the caller's role, which apply on top of project membership
+	canCreateTasks := authService.RequirePermission(domain.PermissionTaskCreate)
+	canUpdateTasks := authService.RequirePermission(domain.PermissionTaskUpdate)
+
 	// task routes
 	authorized.GET("/tasks", taskController.GetTasks)
-	authorized.GET("/tasks/:id", taskController.GetTaskByID)
-	authorized.POST("/tasks", authService.AdminMiddleware(), taskController.CreateTask)
-	authorized.PUT("/tasks/:id", authService.AdminMiddleware(), taskController.UpdateTaskByID)
-	authorized.DELETE("/tasks/:id", authService.AdminMiddleware(), taskController.DeleteTaskByID)
+	authorized.GET("/tasks/search", taskController.SearchTasks)
+	authorized.GET("/tasks/overdue", taskController.GetOverdueTasks)
+	authorized.GET("/tasks/upcoming", taskController.GetUpcomingTasks)
+	authorized.GET("/tasks/export", taskController.ExportTasks)
+	authorized.POST("/tasks/import", canCreateTasks, taskController.ImportTasks)
+	authorized.GET("/tasks/:id", canReadTask, taskController.GetTaskByID)
+	authorized.GET("/tasks/:id/history", taskController.GetTaskHistory)
+	authorized.POST("/tasks", canCreateTasks, taskController.CreateTask)
+	authorized.POST("/tasks:batch", taskController.BatchTasks)
+	authorized.PUT("/tasks/:id", canUpdateTasks, canWriteTask, taskController.UpdateTaskByID)
+	authorized.PATCH("/tasks/:id", canUpdateTasks, canWriteTask, taskController.PatchTaskByID)
+	authorized.DELETE("/tasks/:id", authService.RequirePermission(domain.PermissionTaskDelete), canWriteTask, taskController.DeleteTaskByID)
+	authorized.POST("/tasks/:id/transition", canUpdateTasks, canWriteTask, taskController.TransitionTask)
+	authorized.POST("/tasks/:id/assignees", canUpdateTasks, canWriteTask, taskController.AssignUsers)
+	authorized.DELETE("/tasks/:id/assignees/:userId", canUpdateTasks, canWriteTask, taskController.UnassignUser)
+	authorized.GET("/tasks/:id/subtasks", canReadTask, taskController.GetSubtasks)
+	authorized.GET("/tasks/:id/occurrences", canReadTask, taskController.GetOccurrences)
+	authorized.POST("/tasks/:id/checklist", canUpdateTasks, canWriteTask, taskController.AddChecklistItem)
+	authorized.PATCH("/tasks/:id/checklist/:itemId", canUpdateTasks, canWriteTask, taskController.UpdateChecklistItem)
+	authorized.DELETE("/tasks/:id/checklist/:itemId", canUpdateTasks, canWriteTask, taskController.RemoveChecklistItem)
+	authorized.POST("/tasks/:id/labels", canUpdateTasks, canWriteTask, taskController.AddLabels)
+	authorized.DELETE("/tasks/:id/labels/:labelId", canUpdateTasks, canWriteTask, taskController.RemoveLabel)
+	authorized.GET("/tasks/:id/dependencies", canReadTask, taskController.GetDependencies)
+	authorized.POST("/tasks/:id/dependencies", canUpdateTasks, canWriteTask, taskController.AddDependency)
+	authorized.DELETE("/tasks/:id/dependencies/:blockerId", canUpdateTasks, canWriteTask, taskController.RemoveDependency)
+
+	// comment routes
+	authorized.GET("/tasks/:id/comments", canReadTask, commentController.GetComments)
+	authorized.POST("/tasks/:id/comments", canWriteTask, commentController.CreateComment)
+	authorized.PATCH("/tasks/:id/comments/:commentId", canWriteTask, commentController.UpdateComment)
+	authorized.DELETE("/tasks/:id/comments/:commentId", canWriteTask, commentController.DeleteComment)
+	authorized.GET("/mentions", commentController.GetMentions)
+
+	// project routes
+	authorized.GET("/projects", projectController.GetProjects)
+	authorized.POST("/projects", authService.RequirePermission(domain.PermissionProjectCreate), projectController.CreateProject)
+	authorized.GET("/projects/:id", projectService.ProjectRoleMiddleware(domain.ProjectRoleViewer), projectController.GetProjectByID)
+	authorized.PUT("/projects/:id", projectService.ProjectRoleMiddleware(domain.ProjectRoleOwner), projectController.UpdateProject)
+	authorized.DELETE("/projects/:id", projectService.ProjectRoleMiddleware(domain.ProjectRoleOwner), projectController.DeleteProject)
+	authorized.PUT("/projects/:id/members/:userId", projectService.ProjectRoleMiddleware(domain.ProjectRoleOwner), projectController.SetMember)
+	// members may remove themselves, so the usecase decides who else can remove a member
+	authorized.DELETE("/projects/:id/members/:userId", projectService.ProjectRoleMiddleware(domain.ProjectRoleViewer), projectController.RemoveMember)
+
+	// label routes
+	canManageLabels := authService.RequirePermission(domain.PermissionLabelManage)
+	authorized.GET("/labels", labelController.GetLabels)
+	authorized.GET("/labels/:id", labelController.GetLabelByID)
+	authorized.POST("/labels", canManageLabels, labelController.CreateLabel)
+	authorized.PUT("/labels/:id", canManageLabels, labelController.UpdateLabel)
+	authorized.DELETE("/labels/:id", canManageLabels, labelController.DeleteLabel)
+
+	// webhook routes
+	canManageWebhooks := authService.RequirePermission(domain.PermissionWebhookManage)
+	authorized.GET("/webhooks", canManageWebhooks, webhookController.GetWebhooks)
+	authorized.POST("/webhooks", canManageWebhooks, webhookController.CreateWebhook)
+	authorized.GET("/webhooks/:id", canManageWebhooks, webhookController.GetWebhookByID)
+	authorized.PUT("/webhooks/:id", canManageWebhooks, webhookController.UpdateWebhook)
+	authorized.DELETE("/webhooks/:id", canManageWebhooks, webhookController.DeleteWebhook)
+	authorized.GET("/webhooks/:id/deliveries", canManageWebhooks, webhookController.GetDeliveries)
+	authorized.GET("/webhooks/:id/deliveries/:deliveryId", canManageWebhooks, webhookController.GetDeliveryByID)
+	authorized.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", canManageWebhooks, webhookController.Redeliver)
+
+	// calendar feed token routes
+	authorized.GET("/calendar/token", calendarController.GetFeedToken)
+	authorized.POST("/calendar/token", calendarController.CreateFeedToken)
+	authorized.DELETE("/calendar/token", calendarController.RevokeFeedToken)
+
+	// trash routes
+	authorized.GET("/trash", taskController.GetTrash)
+	authorized.POST("/tasks/:id/restore", taskController.RestoreTaskByID)
+	authorized.DELETE("/trash/:id", authService.RequirePermission(domain.PermissionTaskPurge), taskController.PurgeTaskByID)
+
+	// session routes
+	authorized.POST("/logout", userController.Logout)
 
 	// user promotion route
-	authorized.POST("/promote", authService.AdminMiddleware(), userController.PromoteUser)
+	authorized.POST("/promote", authService.RequirePermission(domain.PermissionUserPromote), userController.PromoteUser)
+
+	// role routes
+	canManageRoles := authService.RequirePermission(domain.PermissionRoleManage)
+	authorized.GET("/permissions", canManageRoles, roleController.GetPermissions)
+	authorized.GET("/roles", canManageRoles, roleController.GetRoles)
+	authorized.POST("/roles", canManageRoles, roleController.CreateRole)
+	authorized.GET("/roles/:name", canManageRoles, roleController.GetRoleByName)
+	authorized.PUT("/roles/:name", canManageRoles, roleController.UpdateRole)
+	authorized.DELETE("/roles/:name", canManageRoles, roleController.DeleteRole)
+
+	// user management routes
+	canManageUsers := authService.RequirePermission(domain.PermissionUserManage)
+	authorized.GET("/users", canManageUsers, userAdminController.GetUsers)
+	authorized.GET("/users/:id", canManageUsers, userAdminController.GetUserByID)
+	authorized.PUT("/users/:id/role", authService.RequirePermission(domain.PermissionUserPromote), userAdminController.ChangeRole)
+	authorized.POST("/users/:id/disable", canManageUsers, userAdminController.DisableUser)
+	authorized.POST("/users/:id/enable", canManageUsers, userAdminController.EnableUser)
+	authorized.DELETE("/users/:id", canManageUsers, userAdminController.DeleteUser)
 
 	return router
 }
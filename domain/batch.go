@@ -0,0 +1,56 @@
+package domain
+
+import "context"
+
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+
+	// MaxBatchSize is the most operations one batch may hold.
+	MaxBatchSize = 500
+)
+
+// BatchOperation is one item of a batch. Create needs Task, update needs ID and Task, delete needs ID.
+// Version and Scope do for an update what If-Match and ?scope= do for PUT /tasks/:id.
+type BatchOperation struct {
+	Op      string     `json:"op"`
+	ID      string     `json:"id"`
+	Task    *TaskInput `json:"task"`
+	Version *int64     `json:"version"`
+	Scope   string     `json:"scope"`
+}
+
+// BatchRequest is the body of POST /tasks:batch. An atomic batch runs in a transaction and is rolled
+// back as a whole when any operation fails.
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchResult is the outcome of one operation, in the order the operations were sent. Status is the
+// status code the matching single request would have answered with.
+type BatchResult struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op"`
+	ID      string `json:"id,omitempty"`
+	Status  int    `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Version int64  `json:"version,omitempty"`
+}
+
+type BatchResponse struct {
+	Atomic     bool          `json:"atomic"`
+	Succeeded  int           `json:"succeeded"`
+	Failed     int           `json:"failed"`
+	RolledBack bool          `json:"rolled_back"`
+	Results    []BatchResult `json:"results"`
+}
+
+// TransactionRunner runs fn in a database transaction. The transaction is committed when fn succeeds and
+// aborted when it returns an error, which is then returned. The context passed to fn must be used for
+// every write that belongs to the transaction. fn may run more than once when the transaction is retried.
+type TransactionRunner interface {
+	RunInTransaction(c context.Context, fn func(c context.Context) CustomError) CustomError
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	CalendarKindEvent = "event"
+	CalendarKindTodo  = "todo"
+
+	// CalendarFeedLookback is how long after their due date tasks stay in a calendar feed.
+	CalendarFeedLookback = 30 * 24 * time.Hour
+	// CalendarUIDDomain makes the UIDs of feed entries globally unique, as RFC 5545 asks.
+	CalendarUIDDomain = "task-management-api"
+)
+
+// CalendarFeed is a user's calendar subscription. Only a hash of the token is stored; the token itself is
+// shown once, when it is created.
+type CalendarFeed struct {
+	ID        string    `json:"-" bson:"_id,omitempty"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	TokenHash string    `json:"-" bson:"token_hash"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// CalendarToken is a newly created feed token and the URL to subscribe to.
+type CalendarToken struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ParseCalendarKind reads which iCalendar component tasks are written as; an empty value means events.
+func ParseCalendarKind(value string) (string, error) {
+	switch kind := strings.ToLower(strings.TrimSpace(value)); kind {
+	case "":
+		return CalendarKindEvent, nil
+	case CalendarKindEvent, CalendarKindTodo:
+		return kind, nil
+	}
+	return "", fmt.Errorf("unknown kind %q, expected event or todo", value)
+}
+
+// BuildCalendar writes tasks as an RFC 5545 VCALENDAR of VEVENT or VTODO components. A task due at
+// midnight UTC, which is how date-only due dates are stored, becomes an all-day entry. UIDs come from the
+// task IDs, so calendar clients update entries instead of duplicating them.
+func BuildCalendar(name string, tasks []Task, kind string, now time.Time) string {
+	var b strings.Builder
+	writeLine := func(line string) {
+		b.WriteString(foldCalendarLine(line))
+		b.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//Task Management API//Tasks//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("METHOD:PUBLISH")
+	writeLine("X-WR-CALNAME:" + escapeCalendarText(name))
+	stamp := now.UTC().Format(calendarTimeLayout)
+	for _, task := range tasks {
+		if task.DueDate == nil {
+			continue
+		}
+		due := task.DueDate.UTC()
+		allDay := due.Equal(due.Truncate(24 * time.Hour))
+
+		component := "VEVENT"
+		if kind == CalendarKindTodo {
+			component = "VTODO"
+		}
+		writeLine("BEGIN:" + component)
+		writeLine(fmt.Sprintf("UID:%s@%s", task.ID, CalendarUIDDomain))
+		writeLine("DTSTAMP:" + stamp)
+		if task.Version > 1 {
+			writeLine(fmt.Sprintf("SEQUENCE:%d", task.Version-1))
+		}
+		writeLine("SUMMARY:" + escapeCalendarText(task.Title))
+		if task.Description != "" {
+			writeLine("DESCRIPTION:" + escapeCalendarText(task.Description))
+		}
+		if component == "VTODO" {
+			writeLine(calendarDate("DUE", due, allDay))
+			writeLine("STATUS:" + todoStatus(task.Status))
+		} else {
+			writeLine(calendarDate("DTSTART", due, allDay))
+			if allDay {
+				writeLine(calendarDate("DTEND", due.AddDate(0, 0, 1), true))
+			}
+			if task.Status == StatusDone {
+				writeLine("TRANSP:TRANSPARENT")
+			}
+		}
+		writeLine("END:" + component)
+	}
+	writeLine("END:VCALENDAR")
+	return b.String()
+}
+
+const (
+	calendarTimeLayout = "20060102T150405Z"
+	calendarDateLayout = "20060102"
+)
+
+func calendarDate(property string, t time.Time, allDay bool) string {
+	if allDay {
+		return property + ";VALUE=DATE:" + t.Format(calendarDateLayout)
+	}
+	return property + ":" + t.Format(calendarTimeLayout)
+}
+
+func todoStatus(status TaskStatus) string {
+	switch status {
+	case StatusDone:
+		return "COMPLETED"
+	case StatusTodo:
+		return "NEEDS-ACTION"
+	}
+	return "IN-PROCESS"
+}
+
+// escapeCalendarText escapes a TEXT value (RFC 5545, section 3.3.11).
+func escapeCalendarText(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(value)
+}
+
+// foldCalendarLine splits a content line into lines of at most 75 octets, continued by a leading space
+// (RFC 5545, section 3.1). It never splits a UTF-8 character.
+func foldCalendarLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			// the leading space counts towards the next line
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultCommentEditWindow is how long authors can edit their comments when COMMENT_EDIT_WINDOW is not set.
+const DefaultCommentEditWindow = 15 * time.Minute
+
+// MaxCommentLength is the longest comment body that is accepted, in characters.
+const MaxCommentLength = 10000
+
+// Comment is a message in a task's discussion. A reply points at the top-level comment that started
+// its thread; replies to a reply join the same thread.
+type Comment struct {
+	ID             string `json:"_id" bson:"_id,omitempty"`
+	TaskID         string `json:"task_id" bson:"task_id"`
+	ParentID       string `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	AuthorID       string `json:"author_id" bson:"author_id"`
+	AuthorUsername string `json:"author_username" bson:"author_username"`
+	Body           string `json:"body" bson:"body"`
+	// Mentions holds the IDs of the users mentioned with @username in the body.
+	Mentions  []string   `json:"mentions" bson:"mentions"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+	EditedBy  string     `json:"edited_by,omitempty" bson:"edited_by,omitempty"`
+	// Replies is filled in for top-level comments when a task's comments are listed and never stored.
+	Replies []Comment `json:"replies,omitempty" bson:"-"`
+}
+
+type CommentInput struct {
+	Body     string `json:"body" binding:"required"`
+	ParentID string `json:"parent_id"`
+}
+
+type CommentUpdate struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CommentPage is one page of a task's threads, oldest first, or of the comments mentioning a user, newest first.
+type CommentPage struct {
+	Comments   []Comment `json:"comments"`
+	NextCursor string    `json:"next_cursor"`
+	Total      int64     `json:"total"`
+}
+
+var mentionPattern = regexp.MustCompile(`(^|[^\w@])@([A-Za-z0-9_.-]+)`)
+
+// ParseMentions lists the distinct usernames mentioned with @username, in the order they first appear.
+// Trailing dots are dropped so a mention can end a sentence.
+func ParseMentions(body string) []string {
+	usernames := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		username := strings.TrimRight(match[2], ".")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
@@ -0,0 +1,42 @@
+package domain
+
+// BlockedStatuses are the statuses a task cannot move to while one of its blockers is still open.
+var BlockedStatuses = []TaskStatus{StatusInProgress, StatusDone}
+
+// IsBlockedStatus reports whether moving to the status requires every blocker to be done.
+func IsBlockedStatus(status TaskStatus) bool {
+	for _, blocked := range BlockedStatuses {
+		if blocked == status {
+			return true
+		}
+	}
+	return false
+}
+
+type TaskDependencyInput struct {
+	BlockerID string `json:"blocker_id" binding:"required"`
+}
+
+// DependencyNode is one task in a dependency graph. Depth counts the links between it and the task the
+// graph was requested for. Tasks the caller cannot see are listed without their title.
+type DependencyNode struct {
+	ID     string     `json:"id"`
+	Title  string     `json:"title,omitempty"`
+	Status TaskStatus `json:"status"`
+	Depth  int        `json:"depth"`
+	Hidden bool       `json:"hidden,omitempty"`
+}
+
+// DependencyEdge says that BlockedID cannot start until BlockerID is done.
+type DependencyEdge struct {
+	BlockerID string `json:"blocker_id"`
+	BlockedID string `json:"blocked_id"`
+}
+
+// TaskDependencyGraph holds the tasks a task is waiting on (upstream) and the tasks waiting on it (downstream).
+type TaskDependencyGraph struct {
+	TaskID     string           `json:"task_id"`
+	Upstream   []DependencyNode `json:"upstream"`
+	Downstream []DependencyNode `json:"downstream"`
+	Edges      []DependencyEdge `json:"edges"`
+}
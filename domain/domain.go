@@ -2,6 +2,9 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"time"
+
 	"github.com/dgrijalva/jwt-go"
 )
 
@@ -9,16 +12,166 @@ type Claims struct {
 	UserId string `json:"userId"`
     Username string `json:"username"`
     Role     string `json:"role"`
+    // SessionID is the refresh token family the token was issued for.
+    SessionID string `json:"sid,omitempty"`
     jwt.StandardClaims
 }
 
 
 type Task struct {
-	ID          string `json:"_id" bson:"_id,omitempty"`
-	Title       string `json:"title" bson:"title"`
-	Description string `json:"description" bson:"description"`
-	DueDate     string `json:"due_date" bson:"due_date"`
-	Status      string `json:"status" bson:"status"`
+	ID          string             `json:"_id" bson:"_id,omitempty"`
+	// ProjectID is the project the task belongs to. Tasks created before projects existed have none.
+	ProjectID   string             `json:"project_id,omitempty" bson:"project_id,omitempty"`
+	Title       string             `json:"title" bson:"title"`
+	Description string             `json:"description" bson:"description"`
+	DueDate     *time.Time         `json:"due_date" bson:"due_date"`
+	Status      TaskStatus         `json:"status" bson:"status"`
+	CreatedBy   string             `json:"created_by" bson:"created_by"`
+	AssigneeIDs []string           `json:"assignee_ids" bson:"assignee_ids,omitempty"`
+	Transitions []StatusTransition `json:"transitions" bson:"transitions,omitempty"`
+	// Version is incremented on every write and backs the ETag / If-Match checks.
+	Version int64 `json:"version" bson:"version"`
+	// DeletedAt is set while the task is in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	// ParentID makes the task a subtask of another task.
+	ParentID  string          `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	Checklist []ChecklistItem `json:"checklist" bson:"checklist,omitempty"`
+	// BlockedBy lists the tasks that must be done before this one can start.
+	BlockedBy []string `json:"blocked_by" bson:"blocked_by,omitempty"`
+	// LabelIDs lists the labels put on the task.
+	LabelIDs []string `json:"label_ids" bson:"label_ids,omitempty"`
+	// Recurrence is set on the occurrences of a recurring task.
+	Recurrence *TaskRecurrence `json:"recurrence,omitempty" bson:"recurrence,omitempty"`
+	// Progress is computed by GET /tasks/:id and never stored.
+	Progress *int `json:"progress,omitempty" bson:"-"`
+}
+
+// TaskInput is a task as sent by the client. DueDate is still a raw string so the
+// usecase can validate it and report a field-level error.
+type TaskInput struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	DueDate     string   `json:"due_date"`
+	Status      string   `json:"status"`
+	AssigneeIDs []string `json:"assignee_ids"`
+	ParentID    string   `json:"parent_id"`
+	ProjectID   string   `json:"project_id"`
+	// Recurrence is an RRULE that makes the task repeat. Left out, it keeps the current rule; an empty
+	// string stops the task repeating.
+	Recurrence *string `json:"recurrence"`
+}
+
+const dateOnlyLayout = "2006-01-02"
+
+// ParseDueDate accepts an RFC 3339 timestamp or a plain date, which is read as midnight UTC.
+// An empty string means the task has no due date.
+func ParseDueDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		t = t.UTC()
+		return &t, nil
+	}
+	if t, err := time.Parse(dateOnlyLayout, value); err == nil {
+		return &t, nil
+	}
+	return nil, errors.New("due_date must be an RFC 3339 date-time or a YYYY-MM-DD date")
+}
+
+// AuthUser is the caller identity that AuthMiddleware extracts from the JWT.
+type AuthUser struct {
+	UserID   string
+	Username string
+	Role     string
+	// SessionID is the session the access token belongs to; tokens issued before sessions existed have none.
+	SessionID string
+	// TokenID is the jti claim of the access token.
+	TokenID string
+	// Permissions are those of the role, as AuthMiddleware found them.
+	Permissions []Permission
+}
+
+// Can reports whether the user has a permission. Identities built without looking up their role, such as
+// those of background jobs, have the permissions the built-in role of that name is created with.
+func (u AuthUser) Can(permission Permission) bool {
+	if u.Permissions == nil {
+		return HasPermission(BuiltInPermissions(u.Role), permission)
+	}
+	return HasPermission(u.Permissions, permission)
+}
+
+// IsOwnedOrAssigned reports whether the user created the task or is assigned to it.
+func (t Task) IsOwnedOrAssigned(userID string) bool {
+	if t.CreatedBy == userID {
+		return true
+	}
+	for _, id := range t.AssigneeIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskQuery describes the filters, ordering and page of a task listing.
+type TaskQuery struct {
+	Status string
+	// OpenOnly leaves out tasks whose status is done.
+	OpenOnly   bool
+	DueFrom    *time.Time
+	DueTo      *time.Time
+	Title      string
+	AssigneeID string
+	SortBy     string
+	SortOrder  string
+	Limit      int64
+	Cursor     string
+	// VisibleTo restricts the results to the tasks in VisibleProjectIDs and the tasks without a project
+	// that this user created or is assigned to.
+	VisibleTo         string
+	VisibleProjectIDs []string
+	// ProjectID restricts the results to the tasks of one project.
+	ProjectID string
+	// Search restricts the results to the tasks whose title or description contain its words.
+	Search string
+	// Deleted lists the tasks in the trash instead of the active ones.
+	Deleted bool
+	// ParentID restricts the results to the direct subtasks of this task.
+	ParentID string
+	// LabelIDs restricts the results to tasks carrying any (LabelMatchAny, the default) or all (LabelMatchAll)
+	// of these labels.
+	LabelIDs   []string
+	LabelMatch string
+}
+
+type TaskPage struct {
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"next_cursor"`
+	Total      int64  `json:"total"`
+}
+
+const (
+	DefaultUpcomingWindow = 72 * time.Hour
+	DefaultTaskPageSize   = 20
+	MaxTaskPageSize       = 100
+	// DefaultTrashRetention is how long deleted tasks stay in the trash when TRASH_RETENTION is not set.
+	DefaultTrashRetention = 30 * 24 * time.Hour
+	// DefaultTrashSweepInterval is how often the trash is swept when TRASH_SWEEP_INTERVAL is not set.
+	DefaultTrashSweepInterval = time.Hour
+)
+
+// TaskSortFields maps the accepted sort names to the stored field names.
+var TaskSortFields = map[string]string{
+	"created":  "_id",
+	"title":    "title",
+	"due_date": "due_date",
+	"status":   "status",
+}
+
+type TaskAssignees struct {
+	UserIDs []string `json:"user_ids" binding:"required"`
 }
 
 type User struct {
@@ -26,46 +179,300 @@ type User struct {
 	Username string `json:"username" binding:"required" bson:"username"`
 	Password string `json:"password" binding:"required" bson:"password"`
 	Role     string `json:"role" bson:"role"`
+	// Email is where reminders are sent when they go out by email. It is optional.
+	Email string `json:"email,omitempty" bson:"email,omitempty"`
+	// TokensValidAfter revokes the access tokens issued before it, so that role and account changes
+	// take effect at once.
+	TokensValidAfter *time.Time `json:"-" bson:"tokens_valid_after,omitempty"`
+	// Disabled accounts cannot log in or refresh their sessions.
+	Disabled bool `json:"disabled" bson:"disabled"`
+	// DisabledAt is stored even when it is nil, so that enabling an account clears it.
+	DisabledAt *time.Time `json:"disabled_at,omitempty" bson:"disabled_at"`
 }
 
 type UserToPromote struct {
 	Username string `json:"username" binding:"required"`
+	// Role is the role the user is given; it defaults to admin.
+	Role string `json:"role"`
 }
 
 type CustomError struct{
 	ErrCode int
 	ErrMessage string
+	// Field names the request field that failed validation, if any.
+	Field string
+	// Details carries extra values for the error response, such as the allowed next statuses.
+	Details map[string]interface{}
 }
 
 
 type TaskRepository interface {
-	GetTasks(c context.Context) ([]Task, CustomError)
+	GetTasks(c context.Context, query TaskQuery) (TaskPage, CustomError)
+	SearchTasks(c context.Context, query TaskQuery) (TaskSearchPage, CustomError)
 	GetTaskByID(c context.Context, taskID string) (Task, CustomError)
-	CreateTask(c context.Context, task Task) CustomError
-	UpdateTaskByID(c context.Context, updatedTask Task) CustomError
-	DeleteTaskByID(c context.Context, taskID string) CustomError
+	CreateTask(c context.Context, task Task) (string, CustomError)
+	UpdateTaskByID(c context.Context, updatedTask Task, transition *StatusTransition) CustomError
+	TransitionTaskStatus(c context.Context, taskID string, transition StatusTransition) CustomError
+	DeleteTaskByID(c context.Context, taskID string, deletedBy string, deletedAt time.Time) CustomError
+	GetDeletedTaskByID(c context.Context, taskID string) (Task, CustomError)
+	RestoreTaskByID(c context.Context, taskID string) CustomError
+	PurgeTaskByID(c context.Context, taskID string) CustomError
+	PurgeDeletedBefore(c context.Context, cutoff time.Time) (int64, CustomError)
+	GetSubtasks(c context.Context, parentIDs []string) ([]Task, CustomError)
+	AddChecklistItem(c context.Context, taskID string, item ChecklistItem) (ChecklistItem, CustomError)
+	UpdateChecklistItem(c context.Context, taskID string, item ChecklistItem) CustomError
+	RemoveChecklistItem(c context.Context, taskID string, itemID string) CustomError
+	GetTasksByIDs(c context.Context, taskIDs []string) ([]Task, CustomError)
+	GetDependents(c context.Context, taskIDs []string) ([]Task, CustomError)
+	AddDependency(c context.Context, taskID string, blockerID string) CustomError
+	RemoveDependency(c context.Context, taskID string, blockerID string) CustomError
+	AddLabels(c context.Context, taskID string, labelIDs []string) CustomError
+	RemoveLabel(c context.Context, taskID string, labelID string) CustomError
+	RemoveLabelFromTasks(c context.Context, labelID string) (int64, CustomError)
+	AddAssignees(c context.Context, taskID string, userIDs []string) CustomError
+	RemoveAssignee(c context.Context, taskID string, userID string) CustomError
+	GetSeriesTasks(c context.Context, seriesID string, afterIndex int) ([]Task, CustomError)
+	GetTasksCreatedBy(c context.Context, userID string) ([]Task, CustomError)
+	// TransferTasks makes another user the creator of a user's tasks, including the tasks in the trash.
+	TransferTasks(c context.Context, fromUserID string, toUserID string) (int64, CustomError)
+	// ReplaceAssignee assigns another user to every task a user is assigned to, in their place.
+	ReplaceAssignee(c context.Context, fromUserID string, toUserID string) (int64, CustomError)
+	RemoveAssigneeFromTasks(c context.Context, userID string) (int64, CustomError)
 }
 
 
 type TaskUsecase interface {
-	GetTasks(c context.Context) ([]Task, CustomError)
-	GetTaskByID(c context.Context, taskID string) (Task, CustomError)
-	CreateTask(c context.Context, task Task) CustomError
-	UpdateTaskByID(c context.Context, taskID string, updatedTask Task) CustomError
-	DeleteTaskByID(c context.Context, taskID string) CustomError
+	GetTasks(c context.Context, user AuthUser, query TaskQuery) (TaskPage, CustomError)
+	SearchTasks(c context.Context, user AuthUser, query TaskQuery) (TaskSearchPage, CustomError)
+	GetTaskByID(c context.Context, user AuthUser, taskID string) (Task, CustomError)
+	GetOverdueTasks(c context.Context, user AuthUser, query TaskQuery) (TaskPage, CustomError)
+	GetUpcomingTasks(c context.Context, user AuthUser, within time.Duration, query TaskQuery) (TaskPage, CustomError)
+	CreateTask(c context.Context, user AuthUser, input TaskInput) (Task, CustomError)
+	UpdateTaskByID(c context.Context, user AuthUser, taskID string, input TaskInput, expectedVersion *int64, scope RecurrenceScope) (Task, CustomError)
+	PatchTaskByID(c context.Context, user AuthUser, taskID string, patch TaskPatch, expectedVersion *int64, scope RecurrenceScope) (Task, CustomError)
+	TransitionTask(c context.Context, user AuthUser, taskID string, status string) CustomError
+	DeleteTaskByID(c context.Context, user AuthUser, taskID string) CustomError
+	GetTrash(c context.Context, user AuthUser, query TaskQuery) (TaskPage, CustomError)
+	RestoreTaskByID(c context.Context, user AuthUser, taskID string) (Task, CustomError)
+	PurgeTaskByID(c context.Context, user AuthUser, taskID string) CustomError
+	PurgeExpiredTasks(c context.Context, retention time.Duration) (int64, CustomError)
+	GetSubtasks(c context.Context, user AuthUser, taskID string, query TaskQuery) (TaskPage, CustomError)
+	AddChecklistItem(c context.Context, user AuthUser, taskID string, text string) (ChecklistItem, CustomError)
+	UpdateChecklistItem(c context.Context, user AuthUser, taskID string, itemID string, update ChecklistItemUpdate) (ChecklistItem, CustomError)
+	RemoveChecklistItem(c context.Context, user AuthUser, taskID string, itemID string) CustomError
+	GetDependencies(c context.Context, user AuthUser, taskID string) (TaskDependencyGraph, CustomError)
+	AddDependency(c context.Context, user AuthUser, taskID string, blockerID string) CustomError
+	RemoveDependency(c context.Context, user AuthUser, taskID string, blockerID string) CustomError
+	AddLabels(c context.Context, user AuthUser, taskID string, labelIDs []string) CustomError
+	RemoveLabel(c context.Context, user AuthUser, taskID string, labelID string) CustomError
+	GetTaskHistory(c context.Context, user AuthUser, taskID string, cursor string, limit int64) (TaskHistoryPage, CustomError)
+	AssignUsers(c context.Context, user AuthUser, taskID string, userIDs []string) CustomError
+	UnassignUser(c context.Context, user AuthUser, taskID string, userID string) CustomError
+	GetOccurrences(c context.Context, user AuthUser, taskID string, count int) (OccurrencePreview, CustomError)
+	BatchTasks(c context.Context, user AuthUser, request BatchRequest) (BatchResponse, CustomError)
+	ExportTasks(c context.Context, user AuthUser, query TaskQuery, write func(tasks []Task) error) CustomError
+	ImportTasks(c context.Context, user AuthUser, rows []ImportRow, dryRun bool) (ImportReport, CustomError)
+	// ReleaseUserTasks hands the tasks of a user who is being deleted over to another user or moves them to
+	// the trash, and takes the user off the tasks they are assigned to.
+	ReleaseUserTasks(c context.Context, user AuthUser, userID string, deletion UserDeletion) (UserDeletionReport, CustomError)
+}
+
+type TaskSeriesRepository interface {
+	CreateSeries(c context.Context, series TaskSeries) (string, CustomError)
+	GetSeriesByID(c context.Context, seriesID string) (TaskSeries, CustomError)
+	UpdateSeries(c context.Context, series TaskSeries) CustomError
+	AdvanceSeries(c context.Context, seriesID string, fromIndex int) (bool, CustomError)
+}
+
+type TaskHistoryRepository interface {
+	AddEntry(c context.Context, entry TaskHistoryEntry) CustomError
+	GetEntries(c context.Context, taskID string, cursor string, limit int64) (TaskHistoryPage, CustomError)
+	GetDeletionEntry(c context.Context, taskID string) (TaskHistoryEntry, CustomError)
+}
+
+type CommentRepository interface {
+	CreateComment(c context.Context, comment Comment) (string, CustomError)
+	GetCommentByID(c context.Context, commentID string) (Comment, CustomError)
+	GetThreads(c context.Context, taskID string, cursor string, limit int64) (CommentPage, CustomError)
+	GetReplies(c context.Context, parentIDs []string) ([]Comment, CustomError)
+	GetMentions(c context.Context, userID string, cursor string, limit int64) (CommentPage, CustomError)
+	UpdateComment(c context.Context, comment Comment) CustomError
+	DeleteComment(c context.Context, commentID string) CustomError
+}
+
+type CommentUsecase interface {
+	CreateComment(c context.Context, user AuthUser, taskID string, input CommentInput) (Comment, CustomError)
+	GetComments(c context.Context, user AuthUser, taskID string, cursor string, limit int64) (CommentPage, CustomError)
+	UpdateComment(c context.Context, user AuthUser, taskID string, commentID string, body string) (Comment, CustomError)
+	DeleteComment(c context.Context, user AuthUser, taskID string, commentID string) CustomError
+	GetMentions(c context.Context, user AuthUser, cursor string, limit int64) (CommentPage, CustomError)
+}
+
+type LabelRepository interface {
+	CreateLabel(c context.Context, label Label) (string, CustomError)
+	GetLabels(c context.Context) ([]Label, CustomError)
+	GetLabelByID(c context.Context, labelID string) (Label, CustomError)
+	GetLabelByName(c context.Context, name string) (Label, CustomError)
+	GetLabelsByIDs(c context.Context, labelIDs []string) ([]Label, CustomError)
+	UpdateLabel(c context.Context, label Label) CustomError
+	DeleteLabel(c context.Context, labelID string) CustomError
+}
+
+type LabelUsecase interface {
+	CreateLabel(c context.Context, input LabelInput) (Label, CustomError)
+	GetLabels(c context.Context) ([]Label, CustomError)
+	GetLabelByID(c context.Context, labelID string) (Label, CustomError)
+	UpdateLabel(c context.Context, labelID string, input LabelInput) (Label, CustomError)
+	DeleteLabel(c context.Context, labelID string) CustomError
+}
+
+type ProjectRepository interface {
+	CreateProject(c context.Context, project Project) (string, CustomError)
+	GetProjects(c context.Context, memberID string) ([]Project, CustomError)
+	GetProjectByID(c context.Context, projectID string) (Project, CustomError)
+	UpdateProject(c context.Context, project Project) CustomError
+	DeleteProject(c context.Context, projectID string) CustomError
+	SetMember(c context.Context, projectID string, member ProjectMember) CustomError
+	RemoveMember(c context.Context, projectID string, userID string) CustomError
+}
+
+type ProjectUsecase interface {
+	CreateProject(c context.Context, user AuthUser, input ProjectInput) (Project, CustomError)
+	GetProjects(c context.Context, user AuthUser) ([]Project, CustomError)
+	GetProjectByID(c context.Context, user AuthUser, projectID string) (Project, CustomError)
+	UpdateProject(c context.Context, user AuthUser, projectID string, input ProjectInput) (Project, CustomError)
+	DeleteProject(c context.Context, user AuthUser, projectID string) CustomError
+	SetMember(c context.Context, user AuthUser, projectID string, userID string, role string) (Project, CustomError)
+	RemoveMember(c context.Context, user AuthUser, projectID string, userID string) CustomError
+	GetProjectRole(c context.Context, user AuthUser, projectID string) (ProjectRole, CustomError)
+	GetTaskProjectRole(c context.Context, user AuthUser, taskID string) (ProjectRole, CustomError)
+}
+
+type WebhookRepository interface {
+	CreateWebhook(c context.Context, webhook Webhook) (string, CustomError)
+	GetWebhooks(c context.Context) ([]Webhook, CustomError)
+	GetWebhookByID(c context.Context, webhookID string) (Webhook, CustomError)
+	UpdateWebhook(c context.Context, webhook Webhook) CustomError
+	DeleteWebhook(c context.Context, webhookID string) CustomError
+}
+
+type WebhookDeliveryRepository interface {
+	CreateDelivery(c context.Context, delivery WebhookDelivery) (string, CustomError)
+	GetDeliveries(c context.Context, webhookID string, cursor string, limit int64) (WebhookDeliveryPage, CustomError)
+	GetDeliveryByID(c context.Context, deliveryID string) (WebhookDelivery, CustomError)
+	GetDueDeliveries(c context.Context, now time.Time, limit int64) ([]WebhookDelivery, CustomError)
+	UpdateDelivery(c context.Context, delivery WebhookDelivery) CustomError
+	DeleteDeliveries(c context.Context, webhookID string) CustomError
+}
+
+// TaskEventPublisher hands task events on to the webhooks subscribed to them. Publishing never fails the
+// change that raised the event.
+type TaskEventPublisher interface {
+	Publish(c context.Context, event TaskEvent)
+}
+
+type WebhookUsecase interface {
+	TaskEventPublisher
+	CreateWebhook(c context.Context, user AuthUser, input WebhookInput) (Webhook, CustomError)
+	GetWebhooks(c context.Context) ([]Webhook, CustomError)
+	GetWebhookByID(c context.Context, webhookID string) (Webhook, CustomError)
+	UpdateWebhook(c context.Context, webhookID string, input WebhookInput) (Webhook, CustomError)
+	DeleteWebhook(c context.Context, webhookID string) CustomError
+	GetDeliveries(c context.Context, webhookID string, cursor string, limit int64) (WebhookDeliveryPage, CustomError)
+	GetDeliveryByID(c context.Context, webhookID string, deliveryID string) (WebhookDelivery, CustomError)
+	Redeliver(c context.Context, webhookID string, deliveryID string) (WebhookDelivery, CustomError)
+	DispatchDueDeliveries(c context.Context) (int, CustomError)
+}
+
+type ReminderRepository interface {
+	RecordReminder(c context.Context, reminder Reminder) (bool, CustomError)
+	DeleteReminder(c context.Context, reminder Reminder) CustomError
+}
+
+type ReminderUsecase interface {
+	SendDueReminders(c context.Context, window time.Duration) (int, CustomError)
+}
+
+type CalendarFeedRepository interface {
+	ReplaceFeed(c context.Context, feed CalendarFeed) CustomError
+	GetFeedByUserID(c context.Context, userID string) (CalendarFeed, CustomError)
+	GetFeedByTokenHash(c context.Context, tokenHash string) (CalendarFeed, CustomError)
+	DeleteFeed(c context.Context, userID string) CustomError
+}
+
+type CalendarUsecase interface {
+	CreateFeedToken(c context.Context, user AuthUser) (CalendarToken, CustomError)
+	GetFeedToken(c context.Context, user AuthUser) (CalendarFeed, CustomError)
+	RevokeFeedToken(c context.Context, user AuthUser) CustomError
+	GetFeed(c context.Context, token string, kind string) (string, CustomError)
 }
 
 type UserRepository interface {
 	CreateUser(c context.Context, user User) CustomError
 	GetUserByUsername(c context.Context, username string) (User, CustomError)
+	GetUserByID(c context.Context, userID string) (User, CustomError)
 	UpdateUser(c context.Context, user User) CustomError
 	GetUserCount(c context.Context)(int64,CustomError)
+	CountUsersWithRole(c context.Context, role string) (int64, CustomError)
+	// CountEnabledUsersWithRole counts the users with the role whose account is not disabled.
+	CountEnabledUsersWithRole(c context.Context, role string) (int64, CustomError)
+	GetUsers(c context.Context, query UserQuery) (UserPage, CustomError)
+	DeleteUser(c context.Context, userID string) CustomError
+}
+
+type RoleRepository interface {
+	CreateRole(c context.Context, role Role) CustomError
+	// EnsureRole creates the role unless a role with its name exists, which is kept as it is.
+	EnsureRole(c context.Context, role Role) CustomError
+	GetRoles(c context.Context) ([]Role, CustomError)
+	GetRoleByName(c context.Context, name string) (Role, CustomError)
+	UpdateRole(c context.Context, role Role) CustomError
+	DeleteRole(c context.Context, name string) CustomError
+}
+
+type RoleUsecase interface {
+	GetPermissions() []PermissionInfo
+	GetRoles(c context.Context) ([]Role, CustomError)
+	GetRoleByName(c context.Context, name string) (Role, CustomError)
+	CreateRole(c context.Context, input RoleInput) (Role, CustomError)
+	UpdateRole(c context.Context, name string, input RoleInput) (Role, CustomError)
+	DeleteRole(c context.Context, name string) CustomError
+	// GetRolePermissions returns what a role allows; a role that does not exist allows nothing.
+	GetRolePermissions(c context.Context, role string) ([]Permission, CustomError)
+	EnsureBuiltInRoles(c context.Context) CustomError
+}
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(c context.Context, token RefreshToken) CustomError
+	GetRefreshTokenByHash(c context.Context, tokenHash string) (RefreshToken, CustomError)
+	// RotateRefreshToken marks a token as exchanged. It fails with 409 when the token was already rotated
+	// or revoked, so only one of two concurrent refreshes wins.
+	RotateRefreshToken(c context.Context, tokenID string, at time.Time) CustomError
+	RevokeRefreshTokenFamily(c context.Context, familyID string, at time.Time) CustomError
+}
+
+type RevokedTokenRepository interface {
+	RevokeToken(c context.Context, token RevokedToken) CustomError
+	IsTokenRevoked(c context.Context, tokenID string) (bool, CustomError)
 }
 
 type UserUsecase interface {
 	RegisterUser(c context.Context, user User) CustomError
-	AuthenticateUser(c context.Context, username string, password string) (string, CustomError)
-	PromoteUser(c context.Context, username string) CustomError
+	AuthenticateUser(c context.Context, username string, password string) (TokenPair, CustomError)
+	RefreshSession(c context.Context, refreshToken string) (TokenPair, CustomError)
+	Logout(c context.Context, user AuthUser) CustomError
+	PromoteUser(c context.Context, username string, role string) CustomError
+	CheckTokenRevocation(c context.Context, userID string, tokenID string, issuedAt time.Time) CustomError
+	GetJWKS() JWKSet
+}
+
+type UserAdminUsecase interface {
+	GetUsers(c context.Context, query UserQuery) (UserPage, CustomError)
+	GetUserByID(c context.Context, userID string) (UserSummary, CustomError)
+	ChangeRole(c context.Context, userID string, role string) (UserSummary, CustomError)
+	DisableUser(c context.Context, user AuthUser, userID string) (UserSummary, CustomError)
+	EnableUser(c context.Context, userID string) (UserSummary, CustomError)
+	DeleteUser(c context.Context, user AuthUser, userID string, deletion UserDeletion) (UserDeletionReport, CustomError)
 }
 
 
@@ -1,7 +1,12 @@
 package domain
 
 import (
+	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -18,20 +23,21 @@ func (suite *DomainTestSuite) SetupTest() {
 
 // TestTaskInstantiation tests the instantiation of the Task model
 func (suite *DomainTestSuite) TestTaskInstantiation() {
+	dueDate := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
 	task := Task{
 		ID:          "123",
 		Title:       "Test Task",
 		Description: "This is a test task.",
-		DueDate:     "2024-12-31",
-		Status:      "Pending",
+		DueDate:     &dueDate,
+		Status:      StatusTodo,
 	}
 
 	assert.NotNil(suite.T(), task)
 	assert.Equal(suite.T(), "123", task.ID)
 	assert.Equal(suite.T(), "Test Task", task.Title)
 	assert.Equal(suite.T(), "This is a test task.", task.Description)
-	assert.Equal(suite.T(), "2024-12-31", task.DueDate)
-	assert.Equal(suite.T(), "Pending", task.Status)
+	assert.Equal(suite.T(), dueDate, *task.DueDate)
+	assert.Equal(suite.T(), StatusTodo, task.Status)
 }
 
 // TestUserInstantiation tests the instantiation of the User model
@@ -86,7 +92,445 @@ func (suite *DomainTestSuite) TestCustomErrorInstantiation() {
 	assert.Equal(suite.T(), "Bad Request", customError.ErrMessage)
 }
 
+// TestTaskIsOwnedOrAssigned tests the task visibility check
+func (suite *DomainTestSuite) TestTaskIsOwnedOrAssigned() {
+	task := Task{
+		CreatedBy:   "owner",
+		AssigneeIDs: []string{"assignee"},
+	}
+
+	assert.True(suite.T(), task.IsOwnedOrAssigned("owner"))
+	assert.True(suite.T(), task.IsOwnedOrAssigned("assignee"))
+	assert.False(suite.T(), task.IsOwnedOrAssigned("stranger"))
+}
+
+// TestParseDueDate tests the accepted due date formats
+func (suite *DomainTestSuite) TestParseDueDate() {
+	dueDate, err := ParseDueDate("2024-12-31T10:00:00+03:00")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), time.Date(2024, 12, 31, 7, 0, 0, 0, time.UTC), *dueDate)
+
+	dueDate, err = ParseDueDate("2024-12-31")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), *dueDate)
+
+	dueDate, err = ParseDueDate("")
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), dueDate)
+
+	_, err = ParseDueDate("tomorrow")
+	assert.Error(suite.T(), err)
+}
+
+// TestStatusWorkflowParseStatus tests status normalization and rejection of unknown statuses
+func (suite *DomainTestSuite) TestStatusWorkflowParseStatus() {
+	status, err := DefaultStatusWorkflow.ParseStatus(" In Progress ")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), StatusInProgress, status)
+
+	_, err = DefaultStatusWorkflow.ParseStatus("completed")
+	assert.EqualError(suite.T(), err, "status must be one of todo, in_progress, review, done")
+}
+
+// TestStatusWorkflowCanTransition tests the default transition graph
+func (suite *DomainTestSuite) TestStatusWorkflowCanTransition() {
+	assert.True(suite.T(), DefaultStatusWorkflow.CanTransition(StatusTodo, StatusInProgress))
+	assert.True(suite.T(), DefaultStatusWorkflow.CanTransition(StatusReview, StatusDone))
+	assert.True(suite.T(), DefaultStatusWorkflow.CanTransition(StatusDone, StatusTodo))
+	assert.False(suite.T(), DefaultStatusWorkflow.CanTransition(StatusTodo, StatusDone))
+}
+
+// TestStatusWorkflowCanTransitionUnknownStatus tests that tasks with a status outside the workflow can move to any status
+func (suite *DomainTestSuite) TestStatusWorkflowCanTransitionUnknownStatus() {
+	for _, to := range []TaskStatus{StatusTodo, StatusInProgress, StatusReview, StatusDone} {
+		assert.True(suite.T(), DefaultStatusWorkflow.CanTransition("Pending", to))
+		assert.True(suite.T(), DefaultStatusWorkflow.CanTransition("completed", to))
+	}
+	assert.Empty(suite.T(), DefaultStatusWorkflow.Allowed("completed"))
+}
+
+// TestStatusWorkflowCustom tests that a custom workflow can add statuses
+func (suite *DomainTestSuite) TestStatusWorkflowCustom() {
+	workflow := StatusWorkflow{
+		StatusTodo: {"blocked", StatusDone},
+		"blocked":  {StatusTodo},
+		StatusDone: {},
+	}
+
+	assert.Equal(suite.T(), []string{"todo", "done", "blocked"}, workflow.Statuses())
+	assert.True(suite.T(), workflow.CanTransition(StatusTodo, "blocked"))
+	assert.False(suite.T(), workflow.CanTransition(StatusDone, StatusTodo))
+}
+
+// TestParseStatusWorkflow tests reading and validating the configured workflow
+func (suite *DomainTestSuite) TestParseStatusWorkflow() {
+	workflow, err := ParseStatusWorkflow("")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), DefaultStatusWorkflow, workflow)
+
+	workflow, err = ParseStatusWorkflow(`{"todo": ["blocked", "done"], "blocked": ["todo"], "done": null}`)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), StatusWorkflow{StatusTodo: {"blocked", StatusDone}, "blocked": {StatusTodo}, StatusDone: {}}, workflow)
+
+	_, err = ParseStatusWorkflow(`["todo", "done"]`)
+	assert.Error(suite.T(), err)
+	_, err = ParseStatusWorkflow(`{"todo": ["review"], "review": []}`)
+	assert.EqualError(suite.T(), err, "status workflow must include done")
+	_, err = ParseStatusWorkflow(`{"todo": ["review"], "done": []}`)
+	assert.EqualError(suite.T(), err, "status todo moves to review, which is not part of the workflow")
+	_, err = ParseStatusWorkflow(`{"todo": ["todo"], "done": []}`)
+	assert.EqualError(suite.T(), err, "status todo cannot move to itself")
+	_, err = ParseStatusWorkflow(`{"todo": [], "done": [], "On Hold": []}`)
+	assert.Error(suite.T(), err)
+}
+
+// TestDiffTasks tests that only changed fields are reported
+func (suite *DomainTestSuite) TestDiffTasks() {
+	dueDate := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	before := Task{Title: "Old", Description: "Same", Status: StatusTodo}
+	after := Task{Title: "New", Description: "Same", Status: StatusTodo, DueDate: &dueDate, AssigneeIDs: []string{"user-1"}}
+
+	changes := DiffTasks(before, after)
+
+	assert.Equal(suite.T(), []FieldChange{
+		{Field: "title", Before: "Old", After: "New"},
+		{Field: "due_date", Before: nil, After: dueDate},
+		{Field: "assignee_ids", Before: []string{}, After: []string{"user-1"}},
+	}, changes)
+	assert.Empty(suite.T(), DiffTasks(before, before))
+}
+
+// TestTaskPatchApply tests merging a JSON Merge Patch into a task input
+func (suite *DomainTestSuite) TestTaskPatchApply() {
+	dueDate := time.Date(2024, 12, 31, 16, 30, 0, 0, time.UTC)
+	input := TaskInputFromTask(Task{Title: "Task", Description: "Description", DueDate: &dueDate, Status: StatusDone})
+	assert.Equal(suite.T(), TaskInput{Title: "Task", Description: "Description", DueDate: "2024-12-31T16:30:00Z"}, input)
+
+	patched, err := TaskPatch{"description": json.RawMessage(`null`), "title": json.RawMessage(`"Renamed"`)}.Apply(input)
+	assert.Zero(suite.T(), err.ErrCode)
+	assert.Equal(suite.T(), TaskInput{Title: "Renamed", DueDate: "2024-12-31T16:30:00Z"}, patched)
+
+	_, err = TaskPatch{"status": json.RawMessage(`null`)}.Apply(input)
+	assert.Equal(suite.T(), "status", err.Field)
+
+	_, err = TaskPatch{"title": json.RawMessage(`42`)}.Apply(input)
+	assert.Equal(suite.T(), "title must be a string", err.ErrMessage)
+
+	_, err = TaskPatch{"created_by": json.RawMessage(`"someone"`)}.Apply(input)
+	assert.Equal(suite.T(), "created_by", err.Field)
+}
+
+// TestTaskProgress tests the progress roll-up from subtasks and checklist items
+func (suite *DomainTestSuite) TestTaskProgress() {
+	assert.Equal(suite.T(), 0, TaskProgress(Task{Status: StatusTodo}, nil))
+	assert.Equal(suite.T(), 100, TaskProgress(Task{Status: StatusDone}, nil))
+
+	task := Task{Checklist: []ChecklistItem{{ID: "a", Done: true}, {ID: "b"}}}
+	subtasks := []Task{{Status: StatusDone}}
+	assert.Equal(suite.T(), 66, TaskProgress(task, subtasks))
+}
+
+// TestParseSubtaskDeletePolicy tests reading the configured delete policy
+func (suite *DomainTestSuite) TestParseSubtaskDeletePolicy() {
+	policy, err := ParseSubtaskDeletePolicy("")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), SubtaskDeleteBlock, policy)
+
+	policy, err = ParseSubtaskDeletePolicy(" Cascade ")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), SubtaskDeleteCascade, policy)
+
+	_, err = ParseSubtaskDeletePolicy("orphan")
+	assert.Error(suite.T(), err)
+}
+
+// TestIsBlockedStatus tests which statuses wait on open blockers
+func (suite *DomainTestSuite) TestIsBlockedStatus() {
+	assert.True(suite.T(), IsBlockedStatus(StatusInProgress))
+	assert.True(suite.T(), IsBlockedStatus(StatusDone))
+	assert.False(suite.T(), IsBlockedStatus(StatusTodo))
+}
+
+// TestParseMentions tests finding @username mentions in a comment
+func (suite *DomainTestSuite) TestParseMentions() {
+	assert.Equal(suite.T(), []string{"bob", "carol.smith"}, ParseMentions("@bob can you ask @carol.smith. Thanks @bob"))
+	assert.Empty(suite.T(), ParseMentions("mail alice@example.com or @@nobody"))
+}
+
+// TestNormalizeLabel tests the label name and color rules
+func (suite *DomainTestSuite) TestNormalizeLabel() {
+	label, err := NormalizeLabel(LabelInput{Name: " customer-x ", Color: "#ABCDEF"})
+	assert.Empty(suite.T(), err.ErrMessage)
+	assert.Equal(suite.T(), Label{Name: "customer-x", Color: "#abcdef"}, label)
+
+	_, err = NormalizeLabel(LabelInput{Name: "urgent", Color: "#abc"})
+	assert.Equal(suite.T(), "color", err.Field)
+
+	_, err = NormalizeLabel(LabelInput{Name: "   ", Color: "#abcdef"})
+	assert.Equal(suite.T(), "name", err.Field)
+}
+
+// TestNormalizeRole tests checking role names and permissions
+func (suite *DomainTestSuite) TestNormalizeRole() {
+	role, err := NormalizeRole(RoleInput{Name: " Manager ", Permissions: []string{"task:delete", "task:delete", "label:manage"}})
+	assert.Empty(suite.T(), err.ErrMessage)
+	assert.Equal(suite.T(), "manager", role.Name)
+	assert.Equal(suite.T(), []Permission{PermissionTaskDelete, PermissionLabelManage}, role.Permissions)
+
+	_, err = NormalizeRole(RoleInput{Name: "1st", Permissions: []string{}})
+	assert.Equal(suite.T(), "name", err.Field)
+
+	_, err = NormalizeRole(RoleInput{Name: "manager", Permissions: []string{"task:fly"}})
+	assert.Equal(suite.T(), "permissions", err.Field)
+}
+
+// TestAuthUserCan tests checking permissions with and without those of the role looked up
+func (suite *DomainTestSuite) TestAuthUserCan() {
+	assert.True(suite.T(), AuthUser{Role: RoleAdmin}.Can(PermissionRoleManage))
+	assert.True(suite.T(), AuthUser{Role: RoleUser}.Can(PermissionTaskCreate))
+	assert.False(suite.T(), AuthUser{Role: RoleUser}.Can(PermissionTaskDelete))
+	assert.False(suite.T(), AuthUser{Role: "manager"}.Can(PermissionTaskCreate))
+
+	assert.True(suite.T(), AuthUser{Role: "manager", Permissions: []Permission{PermissionTaskDelete}}.Can(PermissionTaskDelete))
+	assert.False(suite.T(), AuthUser{Role: RoleAdmin, Permissions: []Permission{}}.Can(PermissionTaskDelete))
+}
+
+// TestProjectRoles tests parsing and ranking project roles
+func (suite *DomainTestSuite) TestProjectRoles() {
+	role, err := ParseProjectRole(" Viewer ")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ProjectRoleViewer, role)
+
+	_, err = ParseProjectRole("admin")
+	assert.Error(suite.T(), err)
+
+	assert.True(suite.T(), ProjectRoleOwner.AtLeast(ProjectRoleMember))
+	assert.False(suite.T(), ProjectRoleViewer.AtLeast(ProjectRoleMember))
+	assert.False(suite.T(), ProjectRole("").AtLeast(ProjectRoleViewer))
+
+	project := Project{Members: []ProjectMember{{UserID: "u1", Role: ProjectRoleOwner}, {UserID: "u2", Role: ProjectRoleViewer}}}
+	assert.Equal(suite.T(), ProjectRoleViewer, project.RoleOf("u2"))
+	assert.Empty(suite.T(), project.RoleOf("u3"))
+	assert.Equal(suite.T(), 1, project.OwnerCount())
+}
+
+// TestSearchTerms tests splitting search text into the words to highlight
+func (suite *DomainTestSuite) TestSearchTerms() {
+	assert.Equal(suite.T(), []string{"deploy", "api", "v2"}, SearchTerms(`Deploy "API v2" -staging deploy`))
+}
+
+// TestHighlightTask tests that long descriptions are cut around the first match
+func (suite *DomainTestSuite) TestHighlightTask() {
+	description := strings.Repeat("filler ", 40) + "the release is blocked " + strings.Repeat("padding ", 40)
+	highlights := HighlightTask(Task{Title: "Release notes", Description: description}, []string{"releases"})
+
+	assert.Equal(suite.T(), "<mark>Release</mark> notes", highlights.Title)
+	assert.True(suite.T(), strings.HasPrefix(highlights.Description, "…"))
+	assert.True(suite.T(), strings.HasSuffix(highlights.Description, "…"))
+	assert.Contains(suite.T(), highlights.Description, "the <mark>release</mark> is blocked")
+}
+
+// TestParseRRule tests reading recurrence rules and the ones that are refused
+func (suite *DomainTestSuite) TestParseRRule() {
+	rule, err := ParseRRule("rrule:freq=weekly;byday=mo,we;interval=1;count=10")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10", rule.String())
+
+	rule, err = ParseRRule("FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20261231")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20261231T235959Z", rule.String())
+
+	for _, invalid := range []string{"", "FREQ=YEARLY", "BYDAY=MO", "FREQ=DAILY;COUNT=0", "FREQ=DAILY;COUNT=2;UNTIL=20261231", "FREQ=WEEKLY;BYDAY=1MO", "FREQ=DAILY;BYHOUR=9", "FREQ=DAILY;FREQ=WEEKLY"} {
+		_, err = ParseRRule(invalid)
+		assert.Error(suite.T(), err, invalid)
+	}
+
+	scope, err := ParseRecurrenceScope("")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ScopeThisOccurrence, scope)
+	_, err = ParseRecurrenceScope("all")
+	assert.Error(suite.T(), err)
+}
+
+// TestRRuleOccurrences tests the due dates the supported rules produce
+func (suite *DomainTestSuite) TestRRuleOccurrences() {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+	}
+	occurrences := func(value string, start time.Time, n int) []time.Time {
+		rule, err := ParseRRule(value)
+		assert.NoError(suite.T(), err)
+		return rule.OccurrencesAfter(start, 1, n)
+	}
+
+	assert.Equal(suite.T(), []time.Time{at(2026, 10, 21), at(2026, 10, 26), at(2026, 10, 28)}, occurrences("FREQ=WEEKLY;BYDAY=MO,WE", at(2026, 10, 19), 3))
+	assert.Equal(suite.T(), []time.Time{at(2026, 11, 2)}, occurrences("FREQ=WEEKLY;INTERVAL=2", at(2026, 10, 19), 1))
+	assert.Equal(suite.T(), []time.Time{at(2026, 11, 27), at(2026, 12, 25)}, occurrences("FREQ=MONTHLY;BYDAY=-1FR", at(2026, 10, 30), 2))
+	// months without a 31st are skipped
+	assert.Equal(suite.T(), []time.Time{at(2026, 3, 31), at(2026, 5, 31)}, occurrences("FREQ=MONTHLY", at(2026, 1, 31), 2))
+	// the count includes the first occurrence
+	assert.Equal(suite.T(), []time.Time{at(2026, 10, 21), at(2026, 10, 23)}, occurrences("FREQ=DAILY;INTERVAL=2;COUNT=3", at(2026, 10, 19), 5))
+	// an UNTIL date includes the whole day
+	assert.Equal(suite.T(), []time.Time{at(2026, 10, 26)}, occurrences("FREQ=WEEKLY;UNTIL=20261026", at(2026, 10, 19), 5))
+
+	rule, _ := ParseRRule("FREQ=DAILY;COUNT=3")
+	third, ok := rule.Occurrence(at(2026, 10, 19), 3)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), at(2026, 10, 21), third)
+	_, ok = rule.Occurrence(at(2026, 10, 19), 4)
+	assert.False(suite.T(), ok)
+}
+
+// TestTaskPatchRecurrence tests that a patch can change the recurrence or stop it with null
+func (suite *DomainTestSuite) TestTaskPatchRecurrence() {
+	input, err := TaskPatch{"recurrence": json.RawMessage(`"FREQ=DAILY"`)}.Apply(TaskInput{Title: "Standup"})
+	assert.Empty(suite.T(), err.ErrMessage)
+	assert.Equal(suite.T(), "FREQ=DAILY", *input.Recurrence)
+
+	input, err = TaskPatch{"recurrence": json.RawMessage(`null`)}.Apply(TaskInput{Title: "Standup"})
+	assert.Empty(suite.T(), err.ErrMessage)
+	assert.Equal(suite.T(), "", *input.Recurrence)
+
+	assert.Nil(suite.T(), TaskInputFromTask(Task{Title: "Standup", Recurrence: &TaskRecurrence{Rule: "FREQ=DAILY"}}).Recurrence)
+}
+
+// TestNormalizeWebhook tests the validation of webhook subscriptions
+func (suite *DomainTestSuite) TestNormalizeWebhook() {
+	webhook, err := NormalizeWebhook(WebhookInput{URL: " https://example.com/hooks ", Events: []string{"Task.Updated", "task.updated", "task.deleted"}})
+	assert.Empty(suite.T(), err.ErrMessage)
+	assert.Equal(suite.T(), "https://example.com/hooks", webhook.URL)
+	assert.Equal(suite.T(), []string{EventTaskUpdated, EventTaskDeleted}, webhook.Events)
+	assert.True(suite.T(), webhook.Subscribes(EventTaskDeleted))
+	assert.False(suite.T(), webhook.Subscribes(EventTaskCreated))
+
+	webhook, _ = NormalizeWebhook(WebhookInput{URL: "http://localhost:9000/hooks"})
+	assert.True(suite.T(), webhook.Subscribes(EventTaskStatusChanged))
+
+	_, err = NormalizeWebhook(WebhookInput{URL: "ftp://example.com"})
+	assert.Equal(suite.T(), "url", err.Field)
+	_, err = NormalizeWebhook(WebhookInput{URL: "https://example.com", Secret: "short"})
+	assert.Equal(suite.T(), "secret", err.Field)
+	_, err = NormalizeWebhook(WebhookInput{URL: "https://example.com", Events: []string{"task.renamed"}})
+	assert.Equal(suite.T(), "events", err.Field)
+}
+
+// TestWebhookBackoff tests that the wait doubles after every failure up to the maximum
+func (suite *DomainTestSuite) TestWebhookBackoff() {
+	assert.Equal(suite.T(), 30*time.Second, WebhookBackoff(1))
+	assert.Equal(suite.T(), time.Minute, WebhookBackoff(2))
+	assert.Equal(suite.T(), 4*time.Minute, WebhookBackoff(4))
+	assert.Equal(suite.T(), time.Hour, WebhookBackoff(20))
+}
+
+// TestParseTaskImportCSV tests that CSV rows are read by column name and reported by line
+func (suite *DomainTestSuite) TestParseTaskImportCSV() {
+	data := "\xef\xbb\xbfTitle,project_id,assignee_ids,recurrence,id\n" +
+		"Task 1,p1,u1;u2,FREQ=WEEKLY,abc\n" +
+		"\"Two\nlines\",p1,,,\n" +
+		"'=SUM(A1),p1,,,\n" +
+		"Task 4,p1\n"
+	rows, err := ParseTaskImport(FormatCSV, []byte(data))
+
+	assert.Empty(suite.T(), err.ErrMessage)
+	assert.Len(suite.T(), rows, 4)
+	assert.Equal(suite.T(), 2, rows[0].Line)
+	assert.Equal(suite.T(), []string{"u1", "u2"}, rows[0].Input.AssigneeIDs)
+	assert.Equal(suite.T(), "FREQ=WEEKLY", *rows[0].Input.Recurrence)
+	assert.Equal(suite.T(), 3, rows[1].Line)
+	assert.Equal(suite.T(), "Two\nlines", rows[1].Input.Title)
+	assert.Equal(suite.T(), "=SUM(A1)", rows[2].Input.Title)
+	assert.Equal(suite.T(), 6, rows[3].Line)
+	assert.Equal(suite.T(), http.StatusBadRequest, rows[3].Err.ErrCode)
+
+	_, err = ParseTaskImport(FormatCSV, []byte("name,project_id\nTask,p1\n"))
+	assert.Equal(suite.T(), "file", err.Field)
+}
+
+// TestParseTaskImportJSON tests that JSON array items and NDJSON lines are reported by line
+func (suite *DomainTestSuite) TestParseTaskImportJSON() {
+	data := `[
+  {"title": "Task 1", "project_id": "p1", "recurrence": {"series_id": "s1", "rule": "FREQ=DAILY", "index": 2}},
+  {"title": 5, "project_id": "p1"},
+  {"title": "Task 3", "project_id": "p1", "recurrence": "FREQ=WEEKLY"}
+]`
+	rows, err := ParseTaskImport(FormatJSON, []byte(data))
+
+	assert.Empty(suite.T(), err.ErrMessage)
+	assert.Len(suite.T(), rows, 3)
+	assert.Equal(suite.T(), []int{2, 3, 4}, []int{rows[0].Line, rows[1].Line, rows[2].Line})
+	assert.Equal(suite.T(), "FREQ=DAILY", *rows[0].Input.Recurrence)
+	assert.Equal(suite.T(), "title", rows[1].Err.Field)
+	assert.Equal(suite.T(), "FREQ=WEEKLY", *rows[2].Input.Recurrence)
+
+	_, err = ParseTaskImport(FormatJSON, []byte(`{"title": "Task 1"}`))
+	assert.Equal(suite.T(), http.StatusBadRequest, err.ErrCode)
+
+	rows, err = ParseTaskImport(FormatNDJSON, []byte("{\"title\": \"Task 1\"}\n\nnot json\n"))
+	assert.Empty(suite.T(), err.ErrMessage)
+	assert.Equal(suite.T(), 3, rows[1].Line)
+	assert.Equal(suite.T(), "The line is not valid JSON", rows[1].Err.ErrMessage)
+}
+
+// TestTaskCSVRecord tests that exported values cannot run as spreadsheet formulas
+func (suite *DomainTestSuite) TestTaskCSVRecord() {
+	record := TaskCSVRecord(Task{ID: "1", Title: "=HYPERLINK(\"x\")", Description: "-1", Status: StatusTodo, Version: 3})
+
+	assert.Equal(suite.T(), len(TaskCSVHeader), len(record))
+	assert.Equal(suite.T(), "'=HYPERLINK(\"x\")", record[1])
+	assert.Equal(suite.T(), "'-1", record[2])
+	assert.Equal(suite.T(), "3", record[11])
+}
+
+// TestBuildCalendar tests the iCalendar output of all-day and timed tasks
+func (suite *DomainTestSuite) TestBuildCalendar() {
+	allDay := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	timed := time.Date(2026, 10, 21, 16, 30, 0, 0, time.UTC)
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	tasks := []Task{
+		{ID: "t1", Title: "Plan; review, ship", Description: "Line one\nLine two", DueDate: &allDay, Status: StatusTodo, Version: 3},
+		{ID: "t2", Title: strings.Repeat("é", 60), DueDate: &timed, Status: StatusDone, Version: 1},
+		{ID: "t3", Title: "No due date"},
+	}
+
+	calendar := BuildCalendar("Tasks of ada", tasks, CalendarKindEvent, now)
+
+	assert.True(suite.T(), strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(suite.T(), strings.HasSuffix(calendar, "END:VCALENDAR\r\n"))
+	assert.Contains(suite.T(), calendar, "UID:t1@task-management-api\r\nDTSTAMP:20261017T090000Z\r\nSEQUENCE:2\r\n")
+	assert.Contains(suite.T(), calendar, "SUMMARY:Plan\\; review\\, ship\r\n")
+	assert.Contains(suite.T(), calendar, "DESCRIPTION:Line one\\nLine two\r\n")
+	assert.Contains(suite.T(), calendar, "DTSTART;VALUE=DATE:20261020\r\nDTEND;VALUE=DATE:20261021\r\n")
+	assert.Contains(suite.T(), calendar, "DTSTART:20261021T163000Z\r\nTRANSP:TRANSPARENT\r\n")
+	assert.NotContains(suite.T(), calendar, "t3@")
+	for _, line := range strings.Split(calendar, "\r\n") {
+		assert.LessOrEqual(suite.T(), len(line), 75)
+	}
+
+	todos := BuildCalendar("Tasks of ada", tasks, CalendarKindTodo, now)
+	assert.Contains(suite.T(), todos, "BEGIN:VTODO\r\n")
+	assert.Contains(suite.T(), todos, "DUE;VALUE=DATE:20261020\r\nSTATUS:NEEDS-ACTION\r\n")
+	assert.Contains(suite.T(), todos, "DUE:20261021T163000Z\r\nSTATUS:COMPLETED\r\n")
+}
+
 // Run the test suite
+// TestParseUserTaskPolicy tests reading what to do with the tasks of a deleted user
+func (suite *DomainTestSuite) TestParseUserTaskPolicy() {
+	policy, err := ParseUserTaskPolicy("transfer")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), UserTasksTransfer, policy)
+
+	_, err = ParseUserTaskPolicy("")
+	assert.Error(suite.T(), err)
+	_, err = ParseUserTaskPolicy("keep")
+	assert.Error(suite.T(), err)
+}
+
+// TestUserSummary tests the summary of a user leaves out the password
+func (suite *DomainTestSuite) TestUserSummary() {
+	summary := User{ID: "u1", Username: "alice", Password: "hash", Role: "user", Disabled: true}.Summary()
+	assert.Equal(suite.T(), UserSummary{ID: "u1", Username: "alice", Role: "user", Disabled: true}, summary)
+}
+
 func TestDomainTestSuite(t *testing.T) {
 	suite.Run(t, new(DomainTestSuite))
 }
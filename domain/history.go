@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"reflect"
+	"time"
+)
+
+const (
+	HistoryActionCreated  = "created"
+	HistoryActionUpdated  = "updated"
+	HistoryActionDeleted  = "deleted"
+	HistoryActionRestored = "restored"
+	HistoryActionPurged   = "purged"
+)
+
+// FieldChange holds the value of a task field before and after a change.
+type FieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// TaskHistoryEntry is one recorded change to a task. Entries are kept after the task is deleted;
+// the deletion entry carries a snapshot of the task so access can still be checked.
+type TaskHistoryEntry struct {
+	ID            string        `json:"_id" bson:"_id,omitempty"`
+	TaskID        string        `json:"task_id" bson:"task_id"`
+	Action        string        `json:"action" bson:"action"`
+	ActorID       string        `json:"actor_id" bson:"actor_id"`
+	ActorUsername string        `json:"actor_username" bson:"actor_username"`
+	At            time.Time     `json:"at" bson:"at"`
+	Changes       []FieldChange `json:"changes" bson:"changes"`
+	Snapshot      *Task         `json:"-" bson:"snapshot,omitempty"`
+}
+
+type TaskHistoryPage struct {
+	Entries    []TaskHistoryEntry `json:"entries"`
+	NextCursor string             `json:"next_cursor"`
+	Total      int64              `json:"total"`
+}
+
+// DiffTasks lists the user-editable fields whose value differs between two versions of a task.
+func DiffTasks(before, after Task) []FieldChange {
+	changes := []FieldChange{}
+	add := func(field string, oldValue, newValue interface{}) {
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, FieldChange{Field: field, Before: oldValue, After: newValue})
+		}
+	}
+
+	add("title", before.Title, after.Title)
+	add("description", before.Description, after.Description)
+	add("due_date", timeValue(before.DueDate), timeValue(after.DueDate))
+	add("status", string(before.Status), string(after.Status))
+	add("assignee_ids", stringsValue(before.AssigneeIDs), stringsValue(after.AssigneeIDs))
+	add("parent_id", before.ParentID, after.ParentID)
+	add("checklist", checklistValue(before.Checklist), checklistValue(after.Checklist))
+	add("blocked_by", stringsValue(before.BlockedBy), stringsValue(after.BlockedBy))
+	add("label_ids", stringsValue(before.LabelIDs), stringsValue(after.LabelIDs))
+	add("recurrence", recurrenceValue(before.Recurrence), recurrenceValue(after.Recurrence))
+	return changes
+}
+
+// timeValue unwraps optional dates so an unset date is recorded as null rather than a typed nil pointer.
+func timeValue(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// recurrenceValue records the rule of a recurring task, or null for a task that does not repeat.
+func recurrenceValue(recurrence *TaskRecurrence) interface{} {
+	if recurrence == nil {
+		return nil
+	}
+	return recurrence.Rule
+}
+
+func stringsValue(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func checklistValue(items []ChecklistItem) []ChecklistItem {
+	if items == nil {
+		return []ChecklistItem{}
+	}
+	return items
+}
@@ -0,0 +1,19 @@
+package domain
+
+// JWK is a public key in JSON Web Key form (RFC 7517). RSA keys set N and E; Ed25519 keys set Crv and X.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the body of GET /.well-known/jwks.json: every key that access tokens may currently be
+// verified with. It is empty while tokens are signed with the shared HS256 secret, which must stay private.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
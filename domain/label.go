@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	// LabelMatchAny matches the tasks that carry at least one of the requested labels.
+	LabelMatchAny = "any"
+	// LabelMatchAll matches the tasks that carry every requested label.
+	LabelMatchAll = "all"
+	// MaxLabelNameLength is the longest label name that is accepted, in characters.
+	MaxLabelNameLength = 50
+)
+
+// Label is a managed tag, such as "backend" or "urgent", that can be put on tasks. Names are unique
+// regardless of case.
+type Label struct {
+	ID    string `json:"_id" bson:"_id,omitempty"`
+	Name  string `json:"name" bson:"name"`
+	Color string `json:"color" bson:"color"`
+}
+
+type LabelInput struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color" binding:"required"`
+}
+
+type TaskLabels struct {
+	LabelIDs []string `json:"label_ids" binding:"required"`
+}
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// NormalizeLabel trims the name and lower-cases the color, and reports the first field that is invalid.
+// Colors are hex codes such as "#1d76db".
+func NormalizeLabel(input LabelInput) (Label, CustomError) {
+	label := Label{
+		Name:  strings.TrimSpace(input.Name),
+		Color: strings.ToLower(strings.TrimSpace(input.Color)),
+	}
+	if label.Name == "" {
+		return Label{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "name is required", Field: "name"}
+	}
+	if len([]rune(label.Name)) > MaxLabelNameLength {
+		return Label{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("name must be at most %d characters", MaxLabelNameLength), Field: "name"}
+	}
+	if !labelColorPattern.MatchString(label.Color) {
+		return Label{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "color must be a hex color such as #1d76db", Field: "color"}
+	}
+	return label, CustomError{}
+}
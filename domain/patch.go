@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// TaskPatch is a JSON Merge Patch (RFC 7396) for a task. Members that are absent leave the field
+// alone and members set to null clear it.
+type TaskPatch map[string]json.RawMessage
+
+// TaskInputFromTask turns a stored task into the input that would replace it unchanged. The status and
+// recurrence are left empty, which keeps the current ones.
+func TaskInputFromTask(task Task) TaskInput {
+	input := TaskInput{Title: task.Title, Description: task.Description, ParentID: task.ParentID, ProjectID: task.ProjectID}
+	if task.DueDate != nil {
+		input.DueDate = task.DueDate.UTC().Format(time.RFC3339Nano)
+	}
+	return input
+}
+
+// Apply merges the patch into a full task input. Only title, description, due_date, status, parent_id and
+// recurrence can be patched, and of those only description, due_date, parent_id and recurrence can be cleared.
+func (p TaskPatch) Apply(input TaskInput) (TaskInput, CustomError) {
+	fields := make([]string, 0, len(p))
+	for field := range p {
+		fields = append(fields, field)
+	}
+	// a stable order so a patch with several bad members always reports the same one
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		raw := p[field]
+		var target *string
+		clearable := false
+		switch field {
+		case "title":
+			target = &input.Title
+		case "description":
+			target, clearable = &input.Description, true
+		case "due_date":
+			target, clearable = &input.DueDate, true
+		case "status":
+			target = &input.Status
+		case "parent_id":
+			target, clearable = &input.ParentID, true
+		case "recurrence":
+			// null stops the task repeating
+			rule := ""
+			if string(raw) != "null" && json.Unmarshal(raw, &rule) != nil {
+				return TaskInput{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: field + " must be a string", Field: field}
+			}
+			input.Recurrence = &rule
+			continue
+		case "assignee_ids":
+			return TaskInput{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "assignee_ids are changed through /tasks/:id/assignees", Field: field}
+		case "label_ids":
+			return TaskInput{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "label_ids are changed through /tasks/:id/labels", Field: field}
+		default:
+			return TaskInput{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: field + " cannot be changed", Field: field}
+		}
+
+		if string(raw) == "null" {
+			if !clearable {
+				return TaskInput{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: field + " cannot be cleared", Field: field}
+			}
+			*target = ""
+			continue
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			return TaskInput{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: field + " must be a string", Field: field}
+		}
+	}
+	return input, CustomError{}
+}
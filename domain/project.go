@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProjectRole is what a member may do in a project: viewers read its tasks, members also change them,
+// and owners also manage the project and its members.
+type ProjectRole string
+
+const (
+	ProjectRoleOwner  ProjectRole = "owner"
+	ProjectRoleMember ProjectRole = "member"
+	ProjectRoleViewer ProjectRole = "viewer"
+)
+
+var projectRoleRanks = map[ProjectRole]int{
+	ProjectRoleViewer: 1,
+	ProjectRoleMember: 2,
+	ProjectRoleOwner:  3,
+}
+
+// ParseProjectRole accepts a role name in any case.
+func ParseProjectRole(value string) (ProjectRole, error) {
+	role := ProjectRole(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := projectRoleRanks[role]; !ok {
+		return "", fmt.Errorf("role must be one of %s, %s, %s", ProjectRoleOwner, ProjectRoleMember, ProjectRoleViewer)
+	}
+	return role, nil
+}
+
+// AtLeast reports whether the role grants everything the required role does. The empty role, for
+// users outside the project, grants nothing.
+func (r ProjectRole) AtLeast(required ProjectRole) bool {
+	return r != "" && projectRoleRanks[r] >= projectRoleRanks[required]
+}
+
+type ProjectMember struct {
+	UserID string      `json:"user_id" bson:"user_id"`
+	Role   ProjectRole `json:"role" bson:"role"`
+}
+
+// Project groups tasks into a board that only its members can see.
+type Project struct {
+	ID          string          `json:"_id" bson:"_id,omitempty"`
+	Name        string          `json:"name" bson:"name"`
+	Description string          `json:"description" bson:"description"`
+	CreatedBy   string          `json:"created_by" bson:"created_by"`
+	CreatedAt   time.Time       `json:"created_at" bson:"created_at"`
+	Members     []ProjectMember `json:"members" bson:"members"`
+}
+
+// RoleOf returns the user's role in the project, or the empty role if they are not a member.
+func (p Project) RoleOf(userID string) ProjectRole {
+	for _, member := range p.Members {
+		if member.UserID == userID {
+			return member.Role
+		}
+	}
+	return ""
+}
+
+// OwnerCount counts the members who own the project.
+func (p Project) OwnerCount() int {
+	owners := 0
+	for _, member := range p.Members {
+		if member.Role == ProjectRoleOwner {
+			owners++
+		}
+	}
+	return owners
+}
+
+type ProjectInput struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type ProjectMemberInput struct {
+	Role string `json:"role" binding:"required"`
+}
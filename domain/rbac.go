@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Permission names something a role allows, as resource:action.
+type Permission string
+
+const (
+	PermissionTaskCreate Permission = "task:create"
+	PermissionTaskUpdate Permission = "task:update"
+	PermissionTaskDelete Permission = "task:delete"
+	PermissionTaskPurge  Permission = "task:purge"
+	// PermissionProjectCreate allows creating projects, whose creator becomes their owner.
+	PermissionProjectCreate Permission = "project:create"
+	// PermissionProjectManageAll makes the holder act as the owner of every project and see every task.
+	PermissionProjectManageAll Permission = "project:manage_all"
+	PermissionCommentModerate  Permission = "comment:moderate"
+	PermissionLabelManage      Permission = "label:manage"
+	PermissionWebhookManage    Permission = "webhook:manage"
+	PermissionUserPromote      Permission = "user:promote"
+	PermissionUserManage       Permission = "user:manage"
+	PermissionRoleManage       Permission = "role:manage"
+)
+
+// PermissionInfo describes a permission for GET /permissions.
+type PermissionInfo struct {
+	Name        Permission `json:"name"`
+	Description string     `json:"description"`
+}
+
+// Permissions lists every permission there is.
+var Permissions = []PermissionInfo{
+	{PermissionTaskCreate, "Create tasks, alone, in batches or by importing them"},
+	{PermissionTaskUpdate, "Change the tasks the caller can change in their projects"},
+	{PermissionTaskDelete, "Move to the trash and restore the tasks the caller can change in their projects"},
+	{PermissionTaskPurge, "Permanently delete tasks from the trash"},
+	{PermissionProjectCreate, "Create projects"},
+	{PermissionProjectManageAll, "Act as the owner of every project and see every task"},
+	{PermissionCommentModerate, "Edit and delete the comments of others"},
+	{PermissionLabelManage, "Create, rename and delete labels"},
+	{PermissionWebhookManage, "Manage webhooks and their deliveries"},
+	{PermissionUserPromote, "Change the role of users"},
+	{PermissionUserManage, "List, disable, enable and delete users"},
+	{PermissionRoleManage, "Create, change and delete roles"},
+}
+
+const (
+	// RoleAdmin always has every permission, including those added later, and cannot be changed.
+	RoleAdmin = "admin"
+	// RoleUser is the role of everyone who registers after the first user.
+	RoleUser = "user"
+
+	// MaxRoleNameLength is the longest role name that is accepted.
+	MaxRoleNameLength = 32
+)
+
+// BuiltInRoles are created when the server starts, unless they exist. Admins can change the
+// permissions of the user role; the admin role cannot be changed and neither can be deleted.
+var BuiltInRoles = []Role{
+	{
+		Name:        RoleAdmin,
+		Description: "Full access",
+		Permissions: AllPermissions(),
+		BuiltIn:     true,
+	},
+	{
+		Name:        RoleUser,
+		Description: "Works on the tasks of their projects",
+		Permissions: []Permission{PermissionTaskCreate, PermissionTaskUpdate, PermissionProjectCreate},
+		BuiltIn:     true,
+	},
+}
+
+// Role is a named set of permissions that users are given. The name is what the role claim of access
+// tokens holds.
+type Role struct {
+	Name        string       `json:"name" bson:"_id"`
+	Description string       `json:"description" bson:"description"`
+	Permissions []Permission `json:"permissions" bson:"permissions"`
+	BuiltIn     bool         `json:"built_in" bson:"built_in"`
+	CreatedAt   time.Time    `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at" bson:"updated_at"`
+}
+
+// RoleInput is the body of POST /roles and PUT /roles/:name. The name cannot be changed by PUT.
+type RoleInput struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// AllPermissions returns the names of every permission.
+func AllPermissions() []Permission {
+	all := make([]Permission, 0, len(Permissions))
+	for _, info := range Permissions {
+		all = append(all, info.Name)
+	}
+	return all
+}
+
+// BuiltInPermissions returns the permissions a built-in role is created with, and none for other roles.
+func BuiltInPermissions(role string) []Permission {
+	for _, builtIn := range BuiltInRoles {
+		if builtIn.Name == role {
+			return builtIn.Permissions
+		}
+	}
+	return []Permission{}
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// NormalizeRole checks the name and the permissions of a role and removes duplicate permissions.
+func NormalizeRole(input RoleInput) (Role, CustomError) {
+	role := Role{
+		Name:        strings.ToLower(strings.TrimSpace(input.Name)),
+		Description: strings.TrimSpace(input.Description),
+		Permissions: []Permission{},
+	}
+	if role.Name == "" {
+		return Role{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "name is required", Field: "name"}
+	}
+	if len(role.Name) > MaxRoleNameLength || !roleNamePattern.MatchString(role.Name) {
+		return Role{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("name must start with a letter and hold at most %d lower-case letters, digits, - and _", MaxRoleNameLength), Field: "name"}
+	}
+
+	known := map[Permission]bool{}
+	for _, info := range Permissions {
+		known[info.Name] = true
+	}
+	seen := map[Permission]bool{}
+	for _, name := range input.Permissions {
+		permission := Permission(strings.TrimSpace(name))
+		if !known[permission] {
+			return Role{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("unknown permission %q", name), Field: "permissions"}
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			role.Permissions = append(role.Permissions, permission)
+		}
+	}
+	return role, CustomError{}
+}
+
+// HasPermission reports whether a list of permissions holds the permission.
+func HasPermission(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionRequired is the error of a request that needs a permission the caller lacks.
+func PermissionRequired(permission Permission) CustomError {
+	return CustomError{ErrCode: http.StatusForbidden, ErrMessage: fmt.Sprintf("This requires the %s permission", permission)}
+}
@@ -0,0 +1,360 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultOccurrencePreview is how many occurrences GET /tasks/:id/occurrences lists when no count is given.
+	DefaultOccurrencePreview = 5
+	// MaxOccurrencePreview is the most occurrences that can be previewed at once.
+	MaxOccurrencePreview = 50
+	// maxEmptyPeriods stops the iteration of a rule that has no occurrences left, such as the 5th Friday
+	// of every 12th month.
+	maxEmptyPeriods = 100
+)
+
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+// RecurrenceScope says which occurrences of a recurring task an edit applies to.
+type RecurrenceScope string
+
+const (
+	// ScopeThisOccurrence changes only the occurrence that is edited.
+	ScopeThisOccurrence RecurrenceScope = "this"
+	// ScopeFutureOccurrences changes the edited occurrence, the open occurrences after it and the ones still to come.
+	ScopeFutureOccurrences RecurrenceScope = "future"
+)
+
+// ParseRecurrenceScope reads the scope of an edit; an empty value means this occurrence only.
+func ParseRecurrenceScope(value string) (RecurrenceScope, error) {
+	switch scope := RecurrenceScope(strings.ToLower(strings.TrimSpace(value))); scope {
+	case "":
+		return ScopeThisOccurrence, nil
+	case ScopeThisOccurrence, ScopeFutureOccurrences:
+		return scope, nil
+	}
+	return "", fmt.Errorf("scope must be %s or %s", ScopeThisOccurrence, ScopeFutureOccurrences)
+}
+
+// TaskRecurrence links an occurrence of a recurring task to its series.
+type TaskRecurrence struct {
+	SeriesID string `json:"series_id" bson:"series_id"`
+	Rule     string `json:"rule" bson:"rule"`
+	// Index is the position of the occurrence in the series, starting at 1.
+	Index int `json:"index" bson:"index"`
+}
+
+// TaskSeries is what the occurrences of a recurring task are built from. Occurrence 1 is due at Start
+// and the rule gives the due dates of the others.
+type TaskSeries struct {
+	ID          string    `json:"_id" bson:"_id,omitempty"`
+	ProjectID   string    `json:"project_id" bson:"project_id"`
+	Rule        string    `json:"rule" bson:"rule"`
+	Start       time.Time `json:"start" bson:"start"`
+	Title       string    `json:"title" bson:"title"`
+	Description string    `json:"description" bson:"description"`
+	CreatedBy   string    `json:"created_by" bson:"created_by"`
+	// LastIndex is the index of the latest occurrence created so far.
+	LastIndex int `json:"last_index" bson:"last_index"`
+}
+
+// OccurrencePreview lists the due dates of the occurrences that will follow a task.
+type OccurrencePreview struct {
+	TaskID      string      `json:"task_id"`
+	Rule        string      `json:"rule"`
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+// RRuleWeekday is a BYDAY entry. Ordinal picks one such weekday in the month: 1 is the first, -1 the last
+// and 0 every one of them.
+type RRuleWeekday struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// RRule is the subset of an RFC 5545 recurrence rule that tasks support: FREQ (DAILY, WEEKLY or MONTHLY),
+// INTERVAL, BYDAY, and either COUNT or UNTIL.
+type RRule struct {
+	Freq     string
+	Interval int
+	ByDay    []RRuleWeekday
+	Count    int
+	Until    *time.Time
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+var weekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ParseRRule reads a recurrence rule such as FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10. An "RRULE:" prefix is allowed.
+func ParseRRule(value string) (RRule, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	value = strings.TrimPrefix(value, "RRULE:")
+	if value == "" {
+		return RRule{}, errors.New("recurrence must be an RRULE such as FREQ=WEEKLY;BYDAY=MO")
+	}
+
+	rule := RRule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return RRule{}, fmt.Errorf("%q is not a KEY=VALUE part", part)
+		}
+		if seen[key] {
+			return RRule{}, fmt.Errorf("%s is given more than once", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			if val != FreqDaily && val != FreqWeekly && val != FreqMonthly {
+				return RRule{}, errors.New("FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+			rule.Freq = val
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return RRule{}, errors.New("INTERVAL must be a positive integer")
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return RRule{}, errors.New("COUNT must be a positive integer")
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return RRule{}, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				weekday, err := parseRRuleWeekday(code)
+				if err != nil {
+					return RRule{}, err
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		default:
+			return RRule{}, fmt.Errorf("%s is not supported", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return RRule{}, errors.New("FREQ is required")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return RRule{}, errors.New("COUNT and UNTIL cannot be used together")
+	}
+	for _, weekday := range rule.ByDay {
+		if weekday.Ordinal != 0 && rule.Freq != FreqMonthly {
+			return RRule{}, errors.New("BYDAY can only pick the nth weekday with FREQ=MONTHLY")
+		}
+	}
+	return rule, nil
+}
+
+func parseRRuleWeekday(code string) (RRuleWeekday, error) {
+	if len(code) < 2 {
+		return RRuleWeekday{}, fmt.Errorf("%q is not a BYDAY weekday", code)
+	}
+	weekday, ok := weekdayCodes[code[len(code)-2:]]
+	if !ok {
+		return RRuleWeekday{}, fmt.Errorf("%q is not a BYDAY weekday", code)
+	}
+	ordinal := 0
+	if prefix := code[:len(code)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return RRuleWeekday{}, fmt.Errorf("%q is not a BYDAY weekday", code)
+		}
+		ordinal = n
+	}
+	return RRuleWeekday{Ordinal: ordinal, Weekday: weekday}, nil
+}
+
+// parseUntil accepts a UTC date-time (20261231T170000Z), a floating date-time that is read as UTC, or a
+// date, which includes the whole day.
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			return until, nil
+		}
+	}
+	if day, err := time.Parse("20060102", value); err == nil {
+		return day.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, errors.New("UNTIL must be a date such as 20261231 or a UTC date-time such as 20261231T170000Z")
+}
+
+// String formats the rule in a canonical form, which is how rules are stored.
+func (r RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, weekday := range r.ByDay {
+			code := weekdayNames[weekday.Weekday]
+			if weekday.Ordinal != 0 {
+				code = strconv.Itoa(weekday.Ordinal) + code
+			}
+			codes = append(codes, code)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrence returns the due date of the occurrence with the given index, counting start as occurrence 1.
+// It reports false when the rule ends before that occurrence.
+func (r RRule) Occurrence(start time.Time, index int) (time.Time, bool) {
+	var found time.Time
+	ok := false
+	r.iterate(start, func(i int, at time.Time) bool {
+		if i == index {
+			found, ok = at, true
+			return false
+		}
+		return true
+	})
+	return found, ok
+}
+
+// OccurrencesAfter returns the due dates of at most n occurrences that follow the occurrence with the given index.
+func (r RRule) OccurrencesAfter(start time.Time, index int, n int) []time.Time {
+	occurrences := []time.Time{}
+	if n <= 0 {
+		return occurrences
+	}
+	r.iterate(start, func(i int, at time.Time) bool {
+		if i > index {
+			occurrences = append(occurrences, at)
+		}
+		return len(occurrences) < n
+	})
+	return occurrences
+}
+
+// iterate calls visit with every occurrence in order, numbered from 1, until visit returns false or the
+// rule ends. As in RFC 5545, start is always the first occurrence even if the rule would not produce it.
+func (r RRule) iterate(start time.Time, visit func(index int, at time.Time) bool) {
+	index := 1
+	if !visit(index, start) {
+		return
+	}
+	empty := 0
+	for period := 0; empty < maxEmptyPeriods; period++ {
+		found := false
+		for _, at := range r.candidates(start, period) {
+			if !at.After(start) {
+				continue
+			}
+			if r.Until != nil && at.After(*r.Until) {
+				return
+			}
+			index++
+			if r.Count > 0 && index > r.Count {
+				return
+			}
+			found = true
+			if !visit(index, at) {
+				return
+			}
+		}
+		if found {
+			empty = 0
+		} else {
+			empty++
+		}
+	}
+}
+
+// candidates lists, in order, the times the rule allows in the given period after start, keeping the
+// time of day of start.
+func (r RRule) candidates(start time.Time, period int) []time.Time {
+	step := period * r.Interval
+	candidates := []time.Time{}
+	switch r.Freq {
+	case FreqDaily:
+		day := start.AddDate(0, 0, step)
+		if len(r.ByDay) == 0 || r.hasWeekday(day.Weekday()) {
+			candidates = append(candidates, day)
+		}
+	case FreqWeekly:
+		// weeks start on Monday, as RFC 5545 assumes when WKST is not given
+		monday := start.AddDate(0, 0, -((int(start.Weekday())+6)%7)+7*step)
+		for offset := 0; offset < 7; offset++ {
+			day := monday.AddDate(0, 0, offset)
+			if (len(r.ByDay) == 0 && day.Weekday() == start.Weekday()) || r.hasWeekday(day.Weekday()) {
+				candidates = append(candidates, day)
+			}
+		}
+	case FreqMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		daysInMonth := first.AddDate(0, 1, -1).Day()
+		if len(r.ByDay) == 0 {
+			// months too short for the day of start are skipped
+			if start.Day() <= daysInMonth {
+				candidates = append(candidates, first.AddDate(0, 0, start.Day()-1))
+			}
+			break
+		}
+		for dayOfMonth := 1; dayOfMonth <= daysInMonth; dayOfMonth++ {
+			day := first.AddDate(0, 0, dayOfMonth-1)
+			if r.matchesMonthDay(day, dayOfMonth, daysInMonth) {
+				candidates = append(candidates, day)
+			}
+		}
+	}
+	return candidates
+}
+
+func (r RRule) hasWeekday(weekday time.Weekday) bool {
+	for _, byDay := range r.ByDay {
+		if byDay.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay reports whether a day of the month matches a BYDAY entry, taking ordinals into account.
+func (r RRule) matchesMonthDay(day time.Time, dayOfMonth int, daysInMonth int) bool {
+	for _, byDay := range r.ByDay {
+		if byDay.Weekday != day.Weekday() {
+			continue
+		}
+		switch {
+		case byDay.Ordinal == 0:
+			return true
+		case byDay.Ordinal > 0 && (dayOfMonth-1)/7+1 == byDay.Ordinal:
+			return true
+		case byDay.Ordinal < 0 && (daysInMonth-dayOfMonth)/7+1 == -byDay.Ordinal:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+const (
+	// DefaultReminderWindow is how far ahead of their due date tasks are reminded of when REMINDER_WINDOW is
+	// not set.
+	DefaultReminderWindow = 24 * time.Hour
+	// DefaultReminderInterval is how often due tasks are looked for when REMINDER_INTERVAL is not set.
+	DefaultReminderInterval = 15 * time.Minute
+)
+
+// Reminder records that a user was reminded of a task coming due. A task is reminded of once per
+// recipient and due date, so moving the due date brings a new reminder.
+type Reminder struct {
+	ID      string    `json:"_id" bson:"_id,omitempty"`
+	TaskID  string    `json:"task_id" bson:"task_id"`
+	UserID  string    `json:"user_id" bson:"user_id"`
+	DueDate time.Time `json:"due_date" bson:"due_date"`
+	SentAt  time.Time `json:"sent_at" bson:"sent_at"`
+}
+
+// ReminderNotice is what a notifier sends: the task coming due and the user to remind.
+type ReminderNotice struct {
+	Task      Task
+	Recipient User
+}
+
+// ReminderRecipients lists the users reminded of a task: its assignees, or its creator when nobody is
+// assigned.
+func ReminderRecipients(task Task) []string {
+	if len(task.AssigneeIDs) > 0 {
+		return task.AssigneeIDs
+	}
+	if task.CreatedBy != "" {
+		return []string{task.CreatedBy}
+	}
+	return nil
+}
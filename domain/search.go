@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxSearchLength is the longest search text that is accepted, in characters.
+	MaxSearchLength = 200
+	// SnippetLength is roughly how many characters of a description a search snippet shows.
+	SnippetLength = 160
+	// HighlightStart and HighlightEnd surround the matching words in search snippets.
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// TaskSearchResult is a task that matched a search, with its relevance score and highlighted snippets.
+type TaskSearchResult struct {
+	Task       Task           `json:"task"`
+	Score      float64        `json:"score"`
+	Highlights TaskHighlights `json:"highlights"`
+}
+
+// TaskHighlights holds HTML-escaped snippets of a task with the matching words wrapped in <mark> tags.
+type TaskHighlights struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type TaskSearchPage struct {
+	Results    []TaskSearchResult `json:"results"`
+	NextCursor string             `json:"next_cursor"`
+	Total      int64              `json:"total"`
+}
+
+// SearchTerms lists the lower-cased words of a search, leaving out the words excluded with a leading "-".
+func SearchTerms(search string) []string {
+	terms := []string{}
+	for _, field := range strings.Fields(search) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		for _, word := range splitWords(strings.ToLower(field)) {
+			if !containsTerm(terms, word) {
+				terms = append(terms, word)
+			}
+		}
+	}
+	return terms
+}
+
+// HighlightTask builds the snippets of a task for the given search terms. The title is shown whole and
+// the description is cut down to about SnippetLength characters around its first match.
+func HighlightTask(task Task, terms []string) TaskHighlights {
+	return TaskHighlights{
+		Title:       highlight([]rune(task.Title), terms),
+		Description: highlight(snippet([]rune(task.Description), terms), terms),
+	}
+}
+
+// snippet cuts the text down to SnippetLength characters, starting a little before the first match.
+func snippet(text []rune, terms []string) []rune {
+	if len(text) <= SnippetLength {
+		return text
+	}
+	start := 0
+	for _, word := range wordSpans(text) {
+		if matchesTerm(string(text[word[0]:word[1]]), terms) {
+			start = word[0] - SnippetLength/4
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start+SnippetLength > len(text) {
+		start = len(text) - SnippetLength
+	}
+	// move to the start of a word so the snippet does not open mid-word
+	for start > 0 && !unicode.IsSpace(text[start-1]) {
+		start--
+	}
+
+	end := start + SnippetLength
+	if end > len(text) {
+		end = len(text)
+	}
+	for end < len(text) && !unicode.IsSpace(text[end]) {
+		end++
+	}
+
+	cut := append([]rune{}, text[start:end]...)
+	if start > 0 {
+		cut = append([]rune("…"), cut...)
+	}
+	if end < len(text) {
+		cut = append(cut, []rune("…")...)
+	}
+	return cut
+}
+
+// highlight escapes the text for HTML and wraps the words that match a search term.
+func highlight(text []rune, terms []string) string {
+	var out strings.Builder
+	last := 0
+	for _, word := range wordSpans(text) {
+		if !matchesTerm(string(text[word[0]:word[1]]), terms) {
+			continue
+		}
+		out.WriteString(html.EscapeString(string(text[last:word[0]])))
+		out.WriteString(HighlightStart)
+		out.WriteString(html.EscapeString(string(text[word[0]:word[1]])))
+		out.WriteString(HighlightEnd)
+		last = word[1]
+	}
+	out.WriteString(html.EscapeString(string(text[last:])))
+	return out.String()
+}
+
+// matchesTerm reports whether a word matches a search term. MongoDB matches words by their stem, so
+// "deploys" and "deploying" both match a search for "deploy"; comparing stems approximates that.
+func matchesTerm(word string, terms []string) bool {
+	word = stem(strings.ToLower(word))
+	for _, term := range terms {
+		if word == stem(term) {
+			return true
+		}
+	}
+	return false
+}
+
+func stem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "s"} {
+		if len(word) > len(suffix)+2 && strings.HasSuffix(word, suffix) {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// wordSpans returns the start and end of every run of letters and digits in the text.
+func wordSpans(text []rune) [][2]int {
+	spans := [][2]int{}
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(text)})
+	}
+	return spans
+}
+
+func splitWords(text string) []string {
+	runes := []rune(text)
+	words := []string{}
+	for _, span := range wordSpans(runes) {
+		words = append(words, string(runes[span[0]:span[1]]))
+	}
+	return words
+}
+
+func containsTerm(terms []string, term string) bool {
+	for _, existing := range terms {
+		if existing == term {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+const (
+	// DefaultAccessTokenTTL is how long an access token is valid when ACCESS_TOKEN_TTL is not set.
+	DefaultAccessTokenTTL = 15 * time.Minute
+	// DefaultRefreshTokenTTL is how long a refresh token is valid when REFRESH_TOKEN_TTL is not set.
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	// DefaultRevocationCacheTTL is how long AuthMiddleware trusts a revocation check when
+	// TOKEN_REVOCATION_CACHE_TTL is not set.
+	DefaultRevocationCacheTTL = 10 * time.Second
+)
+
+// RevokedToken is an access token that was revoked before it expired, such as the token of a session
+// that was logged out. ID is the token's jti claim. It only needs to be kept until the token expires.
+type RevokedToken struct {
+	ID        string    `json:"jti" bson:"_id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	RevokedAt time.Time `json:"revoked_at" bson:"revoked_at"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// RefreshToken is one refresh token of a session. Every refresh replaces the token with a new one of the
+// same family, which is the session; the family ID is also the sid claim of the session's access tokens.
+// Only a hash of the token is stored.
+type RefreshToken struct {
+	ID        string    `json:"-" bson:"_id,omitempty"`
+	FamilyID  string    `json:"family_id" bson:"family_id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	TokenHash string    `json:"-" bson:"token_hash"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+	// RotatedAt is set once the token has been exchanged for a new one. Using it again means it leaked.
+	RotatedAt *time.Time `json:"rotated_at,omitempty" bson:"rotated_at,omitempty"`
+	// RevokedAt is set when the session is logged out or its tokens were reused.
+	RevokedAt *time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// TokenPair is what logging in and refreshing return. Token is the access token; ExpiresIn is its
+// lifetime in seconds.
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// RefreshRequest is the body of POST /token/refresh.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type TaskStatus string
+
+const (
+	StatusTodo       TaskStatus = "todo"
+	StatusInProgress TaskStatus = "in_progress"
+	StatusReview     TaskStatus = "review"
+	StatusDone       TaskStatus = "done"
+)
+
+// StatusWorkflow maps each status to the statuses a task may move to next.
+// Every status a task can have must appear as a key, even if it has no outgoing transitions.
+type StatusWorkflow map[TaskStatus][]TaskStatus
+
+// DefaultStatusWorkflow moves tasks forward through review, allows stepping back, and lets done tasks be reopened.
+var DefaultStatusWorkflow = StatusWorkflow{
+	StatusTodo:       {StatusInProgress},
+	StatusInProgress: {StatusReview, StatusTodo},
+	StatusReview:     {StatusDone, StatusInProgress},
+	StatusDone:       {StatusTodo, StatusInProgress},
+}
+
+var statusNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ParseStatusWorkflow reads the configured workflow, a JSON object that maps each status to the statuses a
+// task may move to next; an empty value means DefaultStatusWorkflow. New tasks start as todo and done
+// completes a task, so both must be part of it.
+func ParseStatusWorkflow(value string) (StatusWorkflow, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultStatusWorkflow, nil
+	}
+	workflow := StatusWorkflow{}
+	if err := json.Unmarshal([]byte(value), &workflow); err != nil {
+		return nil, fmt.Errorf("status workflow must be a JSON object that maps each status to its next statuses")
+	}
+	for _, required := range []TaskStatus{StatusTodo, StatusDone} {
+		if _, ok := workflow[required]; !ok {
+			return nil, fmt.Errorf("status workflow must include %s", required)
+		}
+	}
+	for _, name := range workflow.Statuses() {
+		from := TaskStatus(name)
+		if !statusNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("status %q must be lowercase letters, digits and underscores", name)
+		}
+		for _, to := range workflow[from] {
+			if to == from {
+				return nil, fmt.Errorf("status %s cannot move to itself", from)
+			}
+			if _, ok := workflow[to]; !ok {
+				return nil, fmt.Errorf("status %s moves to %s, which is not part of the workflow", from, to)
+			}
+		}
+		if workflow[from] == nil {
+			workflow[from] = []TaskStatus{}
+		}
+	}
+	return workflow, nil
+}
+
+// StatusTransition records a single status change of a task.
+type StatusTransition struct {
+	From TaskStatus `json:"from" bson:"from"`
+	To   TaskStatus `json:"to" bson:"to"`
+	By   string     `json:"by" bson:"by"`
+	At   time.Time  `json:"at" bson:"at"`
+}
+
+type TaskTransitionRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// ParseStatus normalizes the client value ("In Progress", "DONE") and checks that the workflow knows it.
+func (w StatusWorkflow) ParseStatus(value string) (TaskStatus, error) {
+	normalized := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(value)), " ", "_")
+	status := TaskStatus(normalized)
+	if _, ok := w[status]; !ok {
+		return "", fmt.Errorf("status must be one of %s", strings.Join(w.Statuses(), ", "))
+	}
+	return status, nil
+}
+
+// Statuses lists the known statuses in a stable order.
+func (w StatusWorkflow) Statuses() []string {
+	statuses := []string{}
+	for _, status := range []TaskStatus{StatusTodo, StatusInProgress, StatusReview, StatusDone} {
+		if _, ok := w[status]; ok {
+			statuses = append(statuses, string(status))
+		}
+	}
+	custom := []string{}
+	for status := range w {
+		if !containsString(statuses, string(status)) {
+			custom = append(custom, string(status))
+		}
+	}
+	sort.Strings(custom)
+	return append(statuses, custom...)
+}
+
+// Allowed returns the statuses a task in the given status may move to.
+func (w StatusWorkflow) Allowed(from TaskStatus) []TaskStatus {
+	return w[from]
+}
+
+// CanTransition reports whether a task may move from one status to another. Tasks whose current status is
+// not part of the workflow, because it was stored before the workflow existed or was taken out of the
+// configured one, may move to any status, so that they can be brought back into the workflow.
+func (w StatusWorkflow) CanTransition(from, to TaskStatus) bool {
+	next, known := w[from]
+	if !known {
+		return true
+	}
+	for _, status := range next {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
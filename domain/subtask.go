@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxTaskDepth is how many levels a task hierarchy may have, counting the top-level task.
+const MaxTaskDepth = 5
+
+// SubtaskDeletePolicy decides what happens to the subtasks of a task that is deleted.
+type SubtaskDeletePolicy string
+
+const (
+	// SubtaskDeleteBlock refuses to delete a task that still has subtasks.
+	SubtaskDeleteBlock SubtaskDeletePolicy = "block"
+	// SubtaskDeleteCascade deletes the subtasks along with the task.
+	SubtaskDeleteCascade SubtaskDeletePolicy = "cascade"
+)
+
+// ParseSubtaskDeletePolicy reads the configured policy; an empty value means block.
+func ParseSubtaskDeletePolicy(value string) (SubtaskDeletePolicy, error) {
+	switch policy := SubtaskDeletePolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return SubtaskDeleteBlock, nil
+	case SubtaskDeleteBlock, SubtaskDeleteCascade:
+		return policy, nil
+	}
+	return "", fmt.Errorf("subtask delete policy must be %s or %s", SubtaskDeleteBlock, SubtaskDeleteCascade)
+}
+
+// ChecklistItem is a lightweight step inside a task that is not worth a subtask of its own.
+type ChecklistItem struct {
+	ID   string `json:"id" bson:"id"`
+	Text string `json:"text" bson:"text"`
+	Done bool   `json:"done" bson:"done"`
+}
+
+type ChecklistItemCreate struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// ChecklistItemUpdate changes the fields that are sent and leaves the others alone.
+type ChecklistItemUpdate struct {
+	Text *string `json:"text"`
+	Done *bool   `json:"done"`
+}
+
+// TaskProgress is the percentage of a task's direct subtasks that are done and checklist items that are
+// checked. A task with neither is either 0% or, once done, 100%.
+func TaskProgress(task Task, subtasks []Task) int {
+	total := len(subtasks) + len(task.Checklist)
+	if total == 0 {
+		if task.Status == StatusDone {
+			return 100
+		}
+		return 0
+	}
+
+	completed := 0
+	for _, subtask := range subtasks {
+		if subtask.Status == StatusDone {
+			completed++
+		}
+	}
+	for _, item := range task.Checklist {
+		if item.Done {
+			completed++
+		}
+	}
+	return completed * 100 / total
+}
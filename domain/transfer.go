@@ -0,0 +1,319 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+
+	// MaxImportSize is the largest file POST /tasks/import accepts, in bytes.
+	MaxImportSize = 5 << 20
+	// MaxImportRows is the most tasks one import may hold.
+	MaxImportRows = 1000
+)
+
+// TaskCSVHeader lists the columns of a CSV export. Imports read the columns that TaskInput has and
+// ignore the others, so an export can be imported again.
+var TaskCSVHeader = []string{"id", "title", "description", "status", "due_date", "project_id", "parent_id", "assignee_ids", "label_ids", "recurrence", "created_by", "version"}
+
+// csvListSeparator joins the values of the list columns.
+const csvListSeparator = ";"
+
+// ParseTransferFormat reads an export or import format; an empty value means JSON.
+func ParseTransferFormat(value string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(value)); format {
+	case "":
+		return FormatJSON, nil
+	case FormatCSV, FormatJSON, FormatNDJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected csv, json or ndjson", value)
+}
+
+// TaskCSVRecord formats a task as a row of TaskCSVHeader.
+func TaskCSVRecord(task Task) []string {
+	dueDate := ""
+	if task.DueDate != nil {
+		dueDate = task.DueDate.UTC().Format(time.RFC3339)
+	}
+	recurrence := ""
+	if task.Recurrence != nil {
+		recurrence = task.Recurrence.Rule
+	}
+	record := []string{
+		task.ID,
+		task.Title,
+		task.Description,
+		string(task.Status),
+		dueDate,
+		task.ProjectID,
+		task.ParentID,
+		strings.Join(task.AssigneeIDs, csvListSeparator),
+		strings.Join(task.LabelIDs, csvListSeparator),
+		recurrence,
+		task.CreatedBy,
+		strconv.FormatInt(task.Version, 10),
+	}
+	for i := range record {
+		record[i] = escapeCSVFormula(record[i])
+	}
+	return record
+}
+
+// escapeCSVFormula keeps spreadsheets from running a value as a formula by quoting it with a leading
+// apostrophe. Imports take the apostrophe off again.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func unescapeCSVFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// ImportRow is one task of an import file. Line is where the task starts in the file. Err is set when the
+// row itself could not be read; it is reported like a validation error of the row.
+type ImportRow struct {
+	Line  int
+	Input TaskInput
+	Err   CustomError
+}
+
+// ImportError is one line of an import report.
+type ImportError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportedTask is a task created by an import and the line it came from.
+type ImportedTask struct {
+	Line int    `json:"line"`
+	ID   string `json:"id"`
+}
+
+// ImportReport is the outcome of an import. Valid counts the rows that passed validation; Imported
+// those that were created, which is none for a dry run or a file with errors.
+type ImportReport struct {
+	DryRun   bool           `json:"dry_run"`
+	Rows     int            `json:"rows"`
+	Valid    int            `json:"valid"`
+	Imported int            `json:"imported"`
+	Errors   []ImportError  `json:"errors"`
+	Created  []ImportedTask `json:"created"`
+}
+
+// importRecord is a task of a JSON import. Recurrence is either an RRULE or the recurrence object of an
+// export.
+type importRecord struct {
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	DueDate     string          `json:"due_date"`
+	Status      string          `json:"status"`
+	AssigneeIDs []string        `json:"assignee_ids"`
+	ParentID    string          `json:"parent_id"`
+	ProjectID   string          `json:"project_id"`
+	Recurrence  json.RawMessage `json:"recurrence"`
+}
+
+func (r importRecord) input() (TaskInput, CustomError) {
+	input := TaskInput{Title: r.Title, Description: r.Description, DueDate: r.DueDate, Status: r.Status, AssigneeIDs: r.AssigneeIDs, ParentID: r.ParentID, ProjectID: r.ProjectID}
+	if len(r.Recurrence) == 0 || string(r.Recurrence) == "null" {
+		return input, CustomError{}
+	}
+	var rule string
+	if err := json.Unmarshal(r.Recurrence, &rule); err != nil {
+		var recurrence TaskRecurrence
+		if err := json.Unmarshal(r.Recurrence, &recurrence); err != nil {
+			return TaskInput{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "recurrence must be an RRULE", Field: "recurrence"}
+		}
+		rule = recurrence.Rule
+	}
+	input.Recurrence = &rule
+	return input, CustomError{}
+}
+
+// ParseTaskImport reads the tasks of an import file. Rows that cannot be read are returned with their
+// error so they show up in the report; a file that cannot be read at all is refused.
+func ParseTaskImport(format string, data []byte) ([]ImportRow, CustomError) {
+	var rows []ImportRow
+	var err CustomError
+	switch format {
+	case FormatCSV:
+		rows, err = parseCSVImport(data)
+	case FormatJSON:
+		rows, err = parseJSONImport(data)
+	case FormatNDJSON:
+		rows, err = parseNDJSONImport(data)
+	default:
+		return nil, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("unknown format %q, expected csv, json or ndjson", format), Field: "format"}
+	}
+	if err.ErrCode != 0 {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "The file holds no tasks", Field: "file"}
+	}
+	if len(rows) > MaxImportRows {
+		return nil, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("An import holds at most %d tasks", MaxImportRows), Field: "file"}
+	}
+	return rows, CustomError{}
+}
+
+func invalidImportFile(format string, line int, err error) CustomError {
+	return CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("The file is not valid %s (line %d): %v", strings.ToUpper(format), line, err), Field: "file"}
+}
+
+// parseCSVImport reads a CSV file whose first row names the columns. A title column is required.
+func parseCSVImport(data []byte) ([]ImportRow, CustomError) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	header, err := reader.Read()
+	if err != nil {
+		return nil, invalidImportFile(FormatCSV, 1, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, found := columns["title"]; !found {
+		return nil, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "The CSV header has no title column", Field: "file"}
+	}
+
+	rows := []ImportRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, CustomError{}
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, invalidImportFile(FormatCSV, 0, err)
+			}
+			if errors.Is(parseErr.Err, csv.ErrFieldCount) {
+				rows = append(rows, ImportRow{Line: parseErr.StartLine, Err: CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("expected %d columns, found %d", len(header), len(record))}})
+				continue
+			}
+			return nil, invalidImportFile(FormatCSV, parseErr.Line, parseErr.Err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		value := func(column string) string {
+			if i, found := columns[column]; found {
+				return unescapeCSVFormula(strings.TrimSpace(record[i]))
+			}
+			return ""
+		}
+		input := TaskInput{
+			Title:       value("title"),
+			Description: value("description"),
+			DueDate:     value("due_date"),
+			Status:      value("status"),
+			ProjectID:   value("project_id"),
+			ParentID:    value("parent_id"),
+		}
+		for _, assigneeID := range strings.Split(value("assignee_ids"), csvListSeparator) {
+			if assigneeID = strings.TrimSpace(assigneeID); assigneeID != "" {
+				input.AssigneeIDs = append(input.AssigneeIDs, assigneeID)
+			}
+		}
+		if rule := value("recurrence"); rule != "" {
+			input.Recurrence = &rule
+		}
+		rows = append(rows, ImportRow{Line: line, Input: input})
+	}
+}
+
+// parseJSONImport reads a JSON array of tasks.
+func parseJSONImport(data []byte) ([]ImportRow, CustomError) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, invalidImportFile(FormatJSON, lineAt(data, decoder.InputOffset()), err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "The JSON file must hold an array of tasks", Field: "file"}
+	}
+
+	rows := []ImportRow{}
+	for decoder.More() {
+		line := lineAt(data, nextValueOffset(data, decoder.InputOffset()))
+		var record importRecord
+		if err := decoder.Decode(&record); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				return nil, invalidImportFile(FormatJSON, line, err)
+			}
+			rows = append(rows, ImportRow{Line: line, Err: importTypeError(typeErr)})
+			continue
+		}
+		input, inputErr := record.input()
+		rows = append(rows, ImportRow{Line: line, Input: input, Err: inputErr})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, invalidImportFile(FormatJSON, lineAt(data, decoder.InputOffset()), err)
+	}
+	return rows, CustomError{}
+}
+
+// parseNDJSONImport reads one JSON task per line. Blank lines are skipped.
+func parseNDJSONImport(data []byte) ([]ImportRow, CustomError) {
+	rows := []ImportRow{}
+	for i, text := range bytes.Split(data, []byte("\n")) {
+		text = bytes.TrimSpace(text)
+		if len(text) == 0 {
+			continue
+		}
+		line := i + 1
+		var record importRecord
+		if err := json.Unmarshal(text, &record); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				rows = append(rows, ImportRow{Line: line, Err: importTypeError(typeErr)})
+			} else {
+				rows = append(rows, ImportRow{Line: line, Err: CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "The line is not valid JSON"}})
+			}
+			continue
+		}
+		input, inputErr := record.input()
+		rows = append(rows, ImportRow{Line: line, Input: input, Err: inputErr})
+	}
+	return rows, CustomError{}
+}
+
+func importTypeError(err *json.UnmarshalTypeError) CustomError {
+	return CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("%s must be a %s", err.Field, err.Type), Field: err.Field}
+}
+
+// nextValueOffset skips the whitespace and comma between the values of an array.
+func nextValueOffset(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.ContainsRune(" \t\r\n,", rune(data[offset])) {
+		offset++
+	}
+	return offset
+}
+
+// lineAt returns the line of a byte offset, starting at 1.
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// UserSummary is what the user management endpoints show of a user; it leaves out the password hash.
+type UserSummary struct {
+	ID         string     `json:"_id"`
+	Username   string     `json:"username"`
+	Role       string     `json:"role"`
+	Email      string     `json:"email,omitempty"`
+	Disabled   bool       `json:"disabled"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+}
+
+// Summary returns the parts of the user that admins can see.
+func (u User) Summary() UserSummary {
+	return UserSummary{
+		ID:         u.ID,
+		Username:   u.Username,
+		Role:       u.Role,
+		Email:      u.Email,
+		Disabled:   u.Disabled,
+		DisabledAt: u.DisabledAt,
+	}
+}
+
+// UserQuery filters and pages GET /users. Search matches part of the username or email, ignoring case.
+type UserQuery struct {
+	Search   string
+	Role     string
+	Disabled *bool
+	Cursor   string
+	Limit    int64
+}
+
+// UserPage is one page of users in the order they registered.
+type UserPage struct {
+	Users      []UserSummary `json:"users"`
+	NextCursor string        `json:"next_cursor"`
+	Total      int64         `json:"total"`
+}
+
+// RoleChange is the body of PUT /users/:id/role.
+type RoleChange struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UserTaskPolicy says what happens to the tasks of a user who is deleted.
+type UserTaskPolicy string
+
+const (
+	// UserTasksTransfer makes another user the creator and assignee of the tasks in place of the deleted
+	// user, and gives them the deleted user's project roles where those are higher than their own.
+	UserTasksTransfer UserTaskPolicy = "transfer"
+	// UserTasksDelete moves the tasks the user created, with their subtasks, to the trash, and takes the
+	// user off the other tasks they are assigned to.
+	UserTasksDelete UserTaskPolicy = "delete"
+)
+
+// UserDeletion is what DELETE /users/:id reads from ?tasks= and ?to=.
+type UserDeletion struct {
+	Tasks      UserTaskPolicy
+	TransferTo string
+}
+
+// UserDeletionReport counts what deleting a user did to tasks.
+type UserDeletionReport struct {
+	Tasks       UserTaskPolicy `json:"tasks"`
+	TransferTo  string         `json:"transfer_to,omitempty"`
+	Transferred int64          `json:"transferred"`
+	Deleted     int64          `json:"deleted"`
+	Unassigned  int64          `json:"unassigned"`
+}
+
+// ParseUserTaskPolicy reads the ?tasks= parameter of DELETE /users/:id, which is required.
+func ParseUserTaskPolicy(value string) (UserTaskPolicy, error) {
+	switch policy := UserTaskPolicy(value); policy {
+	case UserTasksTransfer, UserTasksDelete:
+		return policy, nil
+	case "":
+		return "", fmt.Errorf("tasks is required, expected %s or %s", UserTasksTransfer, UserTasksDelete)
+	default:
+		return "", fmt.Errorf("unknown tasks %q, expected %s or %s", value, UserTasksTransfer, UserTasksDelete)
+	}
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	EventTaskCreated       = "task.created"
+	EventTaskUpdated       = "task.updated"
+	EventTaskStatusChanged = "task.status_changed"
+	EventTaskDeleted       = "task.deleted"
+	EventTaskRestored      = "task.restored"
+	// WebhookEventAll subscribes a webhook to every event.
+	WebhookEventAll = "*"
+
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+
+	// MinWebhookSecretLength is the shortest signing secret that is accepted.
+	MinWebhookSecretLength = 16
+	// DefaultWebhookMaxAttempts is how often a delivery is tried before it fails when WEBHOOK_MAX_ATTEMPTS
+	// is not set.
+	DefaultWebhookMaxAttempts = 6
+	// WebhookBaseBackoff is the wait after the first failed attempt; every further failure doubles it, up
+	// to WebhookMaxBackoff.
+	WebhookBaseBackoff = 30 * time.Second
+	WebhookMaxBackoff  = time.Hour
+	// DefaultWebhookDispatchInterval is how often due deliveries are sent when WEBHOOK_DISPATCH_INTERVAL is
+	// not set.
+	DefaultWebhookDispatchInterval = 5 * time.Second
+	// WebhookDispatchBatch is the most deliveries sent in one dispatch run.
+	WebhookDispatchBatch = 100
+)
+
+// WebhookEvents lists the events a webhook can subscribe to.
+var WebhookEvents = []string{EventTaskCreated, EventTaskUpdated, EventTaskStatusChanged, EventTaskDeleted, EventTaskRestored}
+
+// Webhook is a subscription to task events. Deliveries are POSTed to URL and signed with Secret, which is
+// never returned by the API.
+type Webhook struct {
+	ID        string    `json:"_id" bson:"_id,omitempty"`
+	URL       string    `json:"url" bson:"url"`
+	Events    []string  `json:"events" bson:"events"`
+	Secret    string    `json:"-" bson:"secret"`
+	CreatedBy string    `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// WebhookInput creates or replaces a webhook. No events, or "*", subscribes to every event. The secret
+// is required when creating a webhook; leaving it out of an update keeps the current one.
+type WebhookInput struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// NormalizeWebhook validates the URL, events and secret of a webhook. Events are lower-cased and
+// deduplicated.
+func NormalizeWebhook(input WebhookInput) (Webhook, CustomError) {
+	target, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return Webhook{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "url must be an absolute http or https URL", Field: "url"}
+	}
+	if input.Secret != "" && len(input.Secret) < MinWebhookSecretLength {
+		return Webhook{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("secret must be at least %d characters", MinWebhookSecretLength), Field: "secret"}
+	}
+
+	events := []string{}
+	for _, event := range input.Events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if event == WebhookEventAll {
+			events = []string{WebhookEventAll}
+			break
+		}
+		if !isWebhookEvent(event) {
+			return Webhook{}, CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("unknown event %q, expected one of %s", event, strings.Join(WebhookEvents, ", ")), Field: "events"}
+		}
+		if !containsString(events, event) {
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		events = []string{WebhookEventAll}
+	}
+	return Webhook{URL: target.String(), Events: events, Secret: input.Secret}, CustomError{}
+}
+
+// Subscribes reports whether the webhook wants the event.
+func (w Webhook) Subscribes(event string) bool {
+	return containsString(w.Events, WebhookEventAll) || containsString(w.Events, event)
+}
+
+func isWebhookEvent(event string) bool {
+	return containsString(WebhookEvents, event)
+}
+
+// TaskEvent is the body of a webhook delivery. Changes lists the changed fields of task.updated and
+// task.status_changed events.
+type TaskEvent struct {
+	ID         string        `json:"id"`
+	Type       string        `json:"type"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	ActorID    string        `json:"actor_id"`
+	Task       Task          `json:"task"`
+	Changes    []FieldChange `json:"changes,omitempty"`
+}
+
+// WebhookDelivery is one event sent, or still to be sent, to one webhook. Payload is the exact body that
+// is signed and sent. A redelivery is a new delivery of the same payload.
+type WebhookDelivery struct {
+	ID             string     `json:"_id" bson:"_id,omitempty"`
+	WebhookID      string     `json:"webhook_id" bson:"webhook_id"`
+	EventID        string     `json:"event_id" bson:"event_id"`
+	Event          string     `json:"event" bson:"event"`
+	Payload        string     `json:"payload" bson:"payload"`
+	Status         string     `json:"status" bson:"status"`
+	Attempts       int        `json:"attempts" bson:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty" bson:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" bson:"created_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty" bson:"last_attempt_at,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty" bson:"next_attempt_at,omitempty"`
+	RedeliveryOf   string     `json:"redelivery_of,omitempty" bson:"redelivery_of,omitempty"`
+}
+
+type WebhookDeliveryPage struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	NextCursor string            `json:"next_cursor"`
+	Total      int64             `json:"total"`
+}
+
+// WebhookBackoff is how long to wait before the next attempt after the given number of failed attempts.
+func WebhookBackoff(attempts int) time.Duration {
+	backoff := WebhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= WebhookMaxBackoff {
+			return WebhookMaxBackoff
+		}
+	}
+	return backoff
+}
@@ -3,6 +3,7 @@ package bootstrap
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -13,7 +14,41 @@ type Env struct {
 	DbName                 string `mapstructure:"DB_NAME"`
 	DbTaskCollection                 string `mapstructure:"DB_TASK_COLLECTION"`
 	DbUserCollection                 string `mapstructure:"DB_USER_COLLECTION"`
+	DbTaskHistoryCollection          string `mapstructure:"DB_TASK_HISTORY_COLLECTION"`
+	DbCommentCollection              string `mapstructure:"DB_COMMENT_COLLECTION"`
+	DbLabelCollection                string `mapstructure:"DB_LABEL_COLLECTION"`
+	DbProjectCollection              string `mapstructure:"DB_PROJECT_COLLECTION"`
+	DbTaskSeriesCollection           string `mapstructure:"DB_TASK_SERIES_COLLECTION"`
+	DbReminderCollection             string `mapstructure:"DB_REMINDER_COLLECTION"`
+	DbWebhookCollection              string `mapstructure:"DB_WEBHOOK_COLLECTION"`
+	DbWebhookDeliveryCollection      string `mapstructure:"DB_WEBHOOK_DELIVERY_COLLECTION"`
+	DbCalendarFeedCollection         string `mapstructure:"DB_CALENDAR_FEED_COLLECTION"`
+	DbRefreshTokenCollection         string `mapstructure:"DB_REFRESH_TOKEN_COLLECTION"`
+	DbRevokedTokenCollection         string `mapstructure:"DB_REVOKED_TOKEN_COLLECTION"`
+	DbRoleCollection                 string `mapstructure:"DB_ROLE_COLLECTION"`
 	AccessTokenSecret      string `mapstructure:"ACCESS_TOKEN_SECRET"`
+	JWTSigningKeyID                  string        `mapstructure:"JWT_SIGNING_KEY_ID"`
+	JWTSigningKeyFile                string        `mapstructure:"JWT_SIGNING_KEY_FILE"`
+	JWTVerificationKeys              string        `mapstructure:"JWT_VERIFICATION_KEYS"`
+	AccessTokenTTL                   time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL                  time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+	TokenRevocationCacheTTL          time.Duration `mapstructure:"TOKEN_REVOCATION_CACHE_TTL"`
+	TrashRetention                   time.Duration `mapstructure:"TRASH_RETENTION"`
+	TrashSweepInterval               time.Duration `mapstructure:"TRASH_SWEEP_INTERVAL"`
+	SubtaskDeletePolicy              string        `mapstructure:"SUBTASK_DELETE_POLICY"`
+	StatusWorkflow                   string        `mapstructure:"STATUS_WORKFLOW"`
+	CommentEditWindow                time.Duration `mapstructure:"COMMENT_EDIT_WINDOW"`
+	ReminderWindow                   time.Duration `mapstructure:"REMINDER_WINDOW"`
+	ReminderInterval                 time.Duration `mapstructure:"REMINDER_INTERVAL"`
+	Notifier                         string        `mapstructure:"NOTIFIER"`
+	SMTPHost                         string        `mapstructure:"SMTP_HOST"`
+	SMTPPort                         int           `mapstructure:"SMTP_PORT"`
+	SMTPUsername                     string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword                     string        `mapstructure:"SMTP_PASSWORD"`
+	SMTPFrom                         string        `mapstructure:"SMTP_FROM"`
+	WebhookMaxAttempts               int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookDispatchInterval          time.Duration `mapstructure:"WEBHOOK_DISPATCH_INTERVAL"`
+	WebhookTimeout                   time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
 }
 
 func NewEnv() *Env {
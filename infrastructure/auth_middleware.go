@@ -1,23 +1,57 @@
 package infrastructure
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strings"
+	"sync"
+	"task_managment_api/domain"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// TokenRevocationChecker tells whether a valid access token has been revoked since it was issued. The
+// user usecase implements it.
+type TokenRevocationChecker interface {
+	CheckTokenRevocation(c context.Context, userID string, tokenID string, issuedAt time.Time) domain.CustomError
+}
+
+// RolePermissionResolver looks up the permissions of a role. The role usecase implements it.
+type RolePermissionResolver interface {
+	GetRolePermissions(c context.Context, role string) ([]domain.Permission, domain.CustomError)
+}
+
 type AuthMiddlewareService interface {
 	AuthMiddleware() gin.HandlerFunc
-	AdminMiddleware() gin.HandlerFunc
+	// RequirePermission lets a request through only if the caller's role has every one of the permissions.
+	// It runs after AuthMiddleware.
+	RequirePermission(permissions ...domain.Permission) gin.HandlerFunc
 }
 
 type AuthService struct {
 	jwtService JWTService
+	revocations TokenRevocationChecker
+	roles RolePermissionResolver
+	cache *ttlCache[domain.CustomError]
+	permissions *ttlCache[[]domain.Permission]
 }
 
-func NewAuthService(jwtService JWTService) AuthMiddlewareService {
-	return &AuthService{jwtService: jwtService}
+// NewAuthService creates the auth middlewares. The outcome of a revocation check and the permissions of
+// a role are cached for cacheTTL, so a revocation or a change to a role may take that long to reach a
+// token that was used just before.
+func NewAuthService(jwtService JWTService, revocations TokenRevocationChecker, roles RolePermissionResolver, cacheTTL time.Duration) AuthMiddlewareService {
+	if cacheTTL <= 0 {
+		cacheTTL = domain.DefaultRevocationCacheTTL
+	}
+	return &AuthService{
+		jwtService: jwtService,
+		revocations: revocations,
+		roles: roles,
+		cache: newTTLCache[domain.CustomError](cacheTTL),
+		permissions: newTTLCache[[]domain.Permission](cacheTTL),
+	}
 }
 
 
@@ -44,22 +78,126 @@ func (am *AuthService) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if err := am.checkRevocation(c, claims); err.ErrCode != 0 {
+			c.AbortWithStatusJSON(err.ErrCode, gin.H{"message": err.ErrMessage})
+			return
+		}
+
+		role, _ := claims["role"].(string)
+		permissions, err := am.rolePermissions(c, role)
+		if err.ErrCode != 0 {
+			c.AbortWithStatusJSON(err.ErrCode, gin.H{"message": err.ErrMessage})
+			return
+		}
+
 		c.Set("userId", claims["userId"])
 		c.Set("username", claims["username"])
 		c.Set("role", claims["role"])
+		c.Set("permissions", permissions)
+		c.Set("sessionId", claims["sid"])
+		c.Set("tokenId", claims["jti"])
 		c.Next()
 	}
 }
 
+// checkRevocation asks whether the token was revoked, or remembers the answer from a recent request
+// with the same token.
+func (am *AuthService) checkRevocation(c context.Context, claims map[string]interface{}) domain.CustomError {
+	userID, _ := claims["userId"].(string)
+	tokenID, _ := claims["jti"].(string)
+	// tokens issued before they had an iat count as issued at the epoch, so any revocation covers them
+	issuedAt := time.Unix(0, 0)
+	if iat, ok := claims["iat"].(float64); ok {
+		issuedAt = time.Unix(int64(iat), 0)
+	}
+
+	key := tokenID
+	if key == "" {
+		key = userID + "@" + issuedAt.String()
+	}
+	if err, found := am.cache.get(key); found {
+		return err
+	}
+	err := am.revocations.CheckTokenRevocation(c, userID, tokenID, issuedAt)
+	// a failed lookup is not an answer, so it is asked again on the next request
+	if err.ErrCode < http.StatusInternalServerError {
+		am.cache.put(key, err)
+	}
+	return err
+}
+
 
-func (am *AuthService) AdminMiddleware() gin.HandlerFunc {
+// rolePermissions looks up the permissions of the token's role, or remembers them from a recent request.
+func (am *AuthService) rolePermissions(c context.Context, role string) ([]domain.Permission, domain.CustomError) {
+	if permissions, found := am.permissions.get(role); found {
+		return permissions, domain.CustomError{}
+	}
+	permissions, err := am.roles.GetRolePermissions(c, role)
+	if err.ErrCode != 0 {
+		return nil, err
+	}
+	am.permissions.put(role, permissions)
+	return permissions, domain.CustomError{}
+}
+
+func (am *AuthService) RequirePermission(permissions ...domain.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists || role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"message": "Admins only"})
-			c.Abort()
-			return
+		user := contextUser(c)
+		for _, permission := range permissions {
+			if !user.Can(permission) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("This requires the %s permission", permission)})
+				return
+			}
 		}
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// maxCacheEntries bounds the memory of the caches of AuthMiddleware.
+const maxCacheEntries = 10000
+
+type ttlCacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// ttlCache remembers values for a short while, such as the outcome of revocation checks per token, so
+// that AuthMiddleware does not query the database on every request.
+type ttlCache[V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]ttlCacheEntry[V]
+}
+
+func newTTLCache[V any](ttl time.Duration) *ttlCache[V] {
+	return &ttlCache[V]{ttl: ttl, entries: map[string]ttlCacheEntry[V]{}}
+}
+
+func (tc *ttlCache[V]) get(key string) (V, bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	entry, found := tc.entries[key]
+	if !found || time.Now().After(entry.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (tc *ttlCache[V]) put(key string, value V) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	now := time.Now()
+	if len(tc.entries) >= maxCacheEntries {
+		for k, entry := range tc.entries {
+			if now.After(entry.expiresAt) {
+				delete(tc.entries, k)
+			}
+		}
+		// still full of live entries: start over rather than grow
+		if len(tc.entries) >= maxCacheEntries {
+			tc.entries = map[string]ttlCacheEntry[V]{}
+		}
+	}
+	tc.entries[key] = ttlCacheEntry[V]{value: value, expiresAt: now.Add(tc.ttl)}
+}
@@ -1,11 +1,13 @@
 package infrastructure_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"task_managment_api/domain"
 	"task_managment_api/infrastructure"
 	"testing"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
@@ -17,21 +19,50 @@ type MockJWTService struct {
 	mock.Mock
 }
 
-func (m *MockJWTService) GenerateUserToken(user domain.User) (string, domain.CustomError) {
-	args := m.Called(user)
+func (m *MockJWTService) GenerateUserToken(user domain.User, sessionID string) (string, domain.CustomError) {
+	args := m.Called(user, sessionID)
 	return args.Get(0).(string), args.Get(1).(domain.CustomError)
 }
 
+func (m *MockJWTService) AccessTokenTTL() time.Duration {
+	return m.Called().Get(0).(time.Duration)
+}
+
+func (m *MockJWTService) JWKS() domain.JWKSet {
+	return m.Called().Get(0).(domain.JWKSet)
+}
+
 func (m *MockJWTService) ValidateToken(tokenString string) (jwt.MapClaims, domain.CustomError) {
 	args := m.Called(tokenString)
 	return args.Get(0).(jwt.MapClaims), args.Get(1).(domain.CustomError)
 }
 
+type MockTokenRevocationChecker struct {
+	mock.Mock
+}
+
+func (m *MockTokenRevocationChecker) CheckTokenRevocation(c context.Context, userID string, tokenID string, issuedAt time.Time) domain.CustomError {
+	args := m.Called(c, userID, tokenID, issuedAt)
+	return args.Get(0).(domain.CustomError)
+}
+
+type MockRolePermissionResolver struct {
+	mock.Mock
+}
+
+func (m *MockRolePermissionResolver) GetRolePermissions(c context.Context, role string) ([]domain.Permission, domain.CustomError) {
+	args := m.Called(c, role)
+	return args.Get(0).([]domain.Permission), args.Get(1).(domain.CustomError)
+}
+
 type MiddlewareTestSuite struct {
 	suite.Suite
 	mockService *MockJWTService
+	mockRevocations *MockTokenRevocationChecker
+	mockRoles   *MockRolePermissionResolver
 	user        domain.User
 	token       string
+	issuedAt    time.Time
 	authService infrastructure.AuthMiddlewareService
 }
 
@@ -41,20 +72,26 @@ func (suite *MiddlewareTestSuite) SetupTest() {
 	suite.user = domain.User{
 		ID:       "user-id-123",
 		Username: "testuser",
-		Role:     "admin", // Set the role to "admin" for testing AdminMiddleware
+		Role:     "manager",
 	}
-	suite.authService = infrastructure.NewAuthService(suite.mockService)
+	suite.mockRevocations = new(MockTokenRevocationChecker)
+	suite.mockRoles = new(MockRolePermissionResolver)
+	suite.authService = infrastructure.NewAuthService(suite.mockService, suite.mockRevocations, suite.mockRoles, time.Minute)
+	suite.issuedAt = time.Unix(time.Now().Unix(), 0)
 
 	// Stub the token generation and validation methods
-	suite.mockService.On("GenerateUserToken", suite.user).Return("mocked-token", domain.CustomError{})
+	suite.mockService.On("GenerateUserToken", suite.user, "session-1").Return("mocked-token", domain.CustomError{})
 	suite.mockService.On("ValidateToken", "mocked-token").Return(jwt.MapClaims{
 		"userId":   suite.user.ID,
 		"username": suite.user.Username,
 		"role":     suite.user.Role,
+		"sid":      "session-1",
+		"jti":      "token-1",
+		"iat":      float64(suite.issuedAt.Unix()),
 	}, domain.CustomError{})
 
 	// Generate a valid JWT token for the user
-	token, err := suite.mockService.GenerateUserToken(suite.user)
+	token, err := suite.mockService.GenerateUserToken(suite.user, "session-1")
 	if err.ErrCode != 0 {
 		suite.T().Fatal("Failed to generate token:", err.ErrMessage)
 	}
@@ -68,13 +105,56 @@ func (suite *MiddlewareTestSuite) TestAuthMiddlewareSuccess() {
 	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
 	c.Request.Header.Set("Authorization", "Bearer "+suite.token)
 
+	suite.mockRevocations.On("CheckTokenRevocation", mock.Anything, suite.user.ID, "token-1", suite.issuedAt).Return(domain.CustomError{})
+	suite.mockRoles.On("GetRolePermissions", mock.Anything, "manager").Return([]domain.Permission{domain.PermissionTaskDelete}, domain.CustomError{})
+
 	middleware := suite.authService.AuthMiddleware()
 	middleware(c)
 
 	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal([]domain.Permission{domain.PermissionTaskDelete}, c.MustGet("permissions"))
+	suite.Equal("token-1", c.MustGet("tokenId"))
 	suite.Equal(suite.user.ID, c.MustGet("userId"))
 	suite.Equal(suite.user.Username, c.MustGet("username"))
 	suite.Equal(suite.user.Role, c.MustGet("role"))
+	suite.Equal("session-1", c.MustGet("sessionId"))
+}
+
+// TestAuthMiddlewareRevokedToken tests a revoked token is refused, and the answer is cached
+func (suite *MiddlewareTestSuite) TestAuthMiddlewareRevokedToken() {
+	suite.mockRevocations.On("CheckTokenRevocation", mock.Anything, suite.user.ID, "token-1", suite.issuedAt).Return(domain.CustomError{ErrCode: http.StatusUnauthorized, ErrMessage: "Token has been revoked"}).Once()
+	middleware := suite.authService.AuthMiddleware()
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer "+suite.token)
+
+		middleware(c)
+
+		suite.Equal(http.StatusUnauthorized, w.Code)
+		suite.JSONEq(`{"message": "Token has been revoked"}`, w.Body.String())
+	}
+	suite.mockRevocations.AssertNumberOfCalls(suite.T(), "CheckTokenRevocation", 1)
+}
+
+// TestAuthMiddlewareRevocationCheckFailure tests a failed revocation check is not cached
+func (suite *MiddlewareTestSuite) TestAuthMiddlewareRevocationCheckFailure() {
+	suite.mockRevocations.On("CheckTokenRevocation", mock.Anything, suite.user.ID, "token-1", suite.issuedAt).Return(domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while checking token revocation"})
+	middleware := suite.authService.AuthMiddleware()
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer "+suite.token)
+
+		middleware(c)
+
+		suite.Equal(http.StatusInternalServerError, w.Code)
+	}
+	suite.mockRevocations.AssertNumberOfCalls(suite.T(), "CheckTokenRevocation", 2)
 }
 
 // TestAuthMiddlewareMissingAuthorizationHeader tests missing authorization header
@@ -104,29 +184,52 @@ func (suite *MiddlewareTestSuite) TestAuthMiddlewareInvalidFormat() {
 	suite.JSONEq(`{"message": "Authorization format must be Bearer {token}"}`, w.Body.String())
 }
 
-// TestAdminMiddlewareSuccess tests successful admin authorization
-func (suite *MiddlewareTestSuite) TestAdminMiddlewareSuccess() {
+// TestAuthMiddlewareRolePermissionsCached tests the permissions of a role are looked up once
+func (suite *MiddlewareTestSuite) TestAuthMiddlewareRolePermissionsCached() {
+	suite.mockRevocations.On("CheckTokenRevocation", mock.Anything, suite.user.ID, "token-1", suite.issuedAt).Return(domain.CustomError{})
+	suite.mockRoles.On("GetRolePermissions", mock.Anything, "manager").Return([]domain.Permission{}, domain.CustomError{}).Once()
+	middleware := suite.authService.AuthMiddleware()
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer "+suite.token)
+
+		middleware(c)
+
+		suite.Equal(http.StatusOK, w.Code)
+	}
+	suite.mockRoles.AssertNumberOfCalls(suite.T(), "GetRolePermissions", 1)
+}
+
+// TestRequirePermissionSuccess tests a role with every required permission is let through
+func (suite *MiddlewareTestSuite) TestRequirePermissionSuccess() {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Set("role", "admin") // Simulate setting the role from AuthMiddleware
+	c.Set("role", "manager") // Simulate what AuthMiddleware sets
+	c.Set("permissions", []domain.Permission{domain.PermissionLabelManage, domain.PermissionTaskDelete})
 
-	middleware := suite.authService.AdminMiddleware()
+	middleware := suite.authService.RequirePermission(domain.PermissionLabelManage, domain.PermissionTaskDelete)
 	middleware(c)
 
 	suite.Equal(http.StatusOK, w.Code)
+	suite.False(c.IsAborted())
 }
 
-// TestAdminMiddlewareForbidden tests forbidden access for non-admins
-func (suite *MiddlewareTestSuite) TestAdminMiddlewareForbidden() {
+// TestRequirePermissionForbidden tests a role that lacks a permission is refused
+func (suite *MiddlewareTestSuite) TestRequirePermissionForbidden() {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Set("role", "user") // Simulate setting a non-admin role
+	c.Set("role", "admin") // the permissions of the role decide, not its name
+	c.Set("permissions", []domain.Permission{domain.PermissionLabelManage})
 
-	middleware := suite.authService.AdminMiddleware()
+	middleware := suite.authService.RequirePermission(domain.PermissionLabelManage, domain.PermissionTaskDelete)
 	middleware(c)
 
 	suite.Equal(http.StatusForbidden, w.Code)
-	suite.JSONEq(`{"message": "Admins only"}`, w.Body.String())
+	suite.True(c.IsAborted())
+	suite.JSONEq(`{"message": "This requires the task:delete permission"}`, w.Body.String())
 }
 
 func TestMiddlewareTestSuite(t *testing.T) {
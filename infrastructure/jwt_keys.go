@@ -0,0 +1,176 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"task_managment_api/domain"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted for signing or verifying tokens.
+const minRSAKeyBits = 2048
+
+// JWTKey is an asymmetric key that access tokens are signed or verified with. ID is the kid header of the
+// tokens it signs. PrivateKey is only set on the signing key.
+type JWTKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+// LoadJWTKeys reads the signing key and the extra verification keys from PEM files. verificationKeys is a
+// comma-separated list of kid=path pairs, such as the previous key while tokens it signed are still
+// around. The public half of the signing key is always a verification key.
+func LoadJWTKeys(signingKeyID string, signingKeyFile string, verificationKeys string) (JWTKey, []JWTKey, error) {
+	if signingKeyID == "" {
+		return JWTKey{}, nil, errors.New("JWT_SIGNING_KEY_ID is required with JWT_SIGNING_KEY_FILE")
+	}
+	data, err := os.ReadFile(signingKeyFile)
+	if err != nil {
+		return JWTKey{}, nil, fmt.Errorf("reading signing key: %w", err)
+	}
+	signingKey, err := ParseJWTKey(signingKeyID, data)
+	if err != nil {
+		return JWTKey{}, nil, fmt.Errorf("signing key %q: %w", signingKeyID, err)
+	}
+	if signingKey.PrivateKey == nil {
+		return JWTKey{}, nil, fmt.Errorf("signing key %q: the file holds a public key, not a private key", signingKeyID)
+	}
+
+	keys := []JWTKey{}
+	for _, entry := range strings.Split(verificationKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, path, found := strings.Cut(entry, "=")
+		kid, path = strings.TrimSpace(kid), strings.TrimSpace(path)
+		if !found || kid == "" || path == "" {
+			return JWTKey{}, nil, fmt.Errorf("verification key %q: expected kid=path", entry)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return JWTKey{}, nil, fmt.Errorf("reading verification key %q: %w", kid, err)
+		}
+		key, err := ParseJWTKey(kid, data)
+		if err != nil {
+			return JWTKey{}, nil, fmt.Errorf("verification key %q: %w", kid, err)
+		}
+		// verification keys never sign
+		key.PrivateKey = nil
+		keys = append(keys, key)
+	}
+	return signingKey, keys, nil
+}
+
+// ParseJWTKey reads an RSA or Ed25519 key from PEM. A private key, in PKCS #8 or PKCS #1 form, gives both
+// halves; a public key, in PKIX or PKCS #1 form, only the public one. RSA keys sign with RS256 and Ed25519
+// keys with EdDSA.
+func ParseJWTKey(kid string, data []byte) (JWTKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return JWTKey{}, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return JWTKey{}, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return JWTKey{}, err
+	}
+
+	key := JWTKey{ID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.PrivateKey, key.PublicKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.PublicKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.PrivateKey, key.PublicKey = SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.PublicKey = SigningMethodEdDSA, k
+	default:
+		return JWTKey{}, fmt.Errorf("unsupported key type %T, expected RSA or Ed25519", parsed)
+	}
+	if rsaKey, ok := key.PublicKey.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSAKeyBits {
+		return JWTKey{}, fmt.Errorf("RSA keys need at least %d bits", minRSAKeyBits)
+	}
+	return key, nil
+}
+
+// JWK returns the public half of the key in JSON Web Key form.
+func (k JWTKey) JWK() domain.JWK {
+	jwk := domain.JWK{Use: "sig", Alg: k.Method.Alg(), Kid: k.ID}
+	switch public := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
+
+// signingMethodEdDSA signs tokens with Ed25519 (RFC 8037), which jwt-go does not provide itself.
+type signingMethodEdDSA struct{}
+
+// SigningMethodEdDSA is registered with jwt-go so that tokens with "alg": "EdDSA" can be parsed.
+var SigningMethodEdDSA jwt.SigningMethod = &signingMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
+
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package infrastructure_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"task_managment_api/domain"
+	"task_managment_api/infrastructure"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/suite"
+)
+
+type JWTKeysTestSuite struct {
+	suite.Suite
+	user   domain.User
+	rsaKey *rsa.PrivateKey
+}
+
+func (suite *JWTKeysTestSuite) SetupSuite() {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().NoError(err)
+	suite.rsaKey = rsaKey
+	suite.user = domain.User{ID: "user-id-123", Username: "testuser", Role: "user"}
+}
+
+func (suite *JWTKeysTestSuite) ed25519Key(kid string) infrastructure.JWTKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	suite.Require().NoError(err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	suite.Require().NoError(err)
+	key, err := infrastructure.ParseJWTKey(kid, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	suite.Require().NoError(err)
+	return key
+}
+
+func (suite *JWTKeysTestSuite) keyedService(signingKey infrastructure.JWTKey, verificationKeys ...infrastructure.JWTKey) infrastructure.JWTService {
+	service, err := infrastructure.NewKeyedJWTService(signingKey, verificationKeys, time.Minute)
+	suite.Require().NoError(err)
+	return service
+}
+
+// TestRS256 tests tokens signed with an RSA key carry its kid and verify
+func (suite *JWTKeysTestSuite) TestRS256() {
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(suite.rsaKey)})
+	key, err := infrastructure.ParseJWTKey("rsa-1", pemData)
+	suite.Require().NoError(err)
+	service := suite.keyedService(key)
+
+	tokenString, customErr := service.GenerateUserToken(suite.user, "session-1")
+	suite.Empty(customErr.ErrCode)
+
+	parsed, _ := jwt.Parse(tokenString, nil)
+	suite.Equal("RS256", parsed.Header["alg"])
+	suite.Equal("rsa-1", parsed.Header["kid"])
+
+	claims, customErr := service.ValidateToken(tokenString)
+	suite.Empty(customErr.ErrCode)
+	suite.Equal(suite.user.ID, claims["userId"])
+}
+
+// TestEdDSA tests tokens signed with an Ed25519 key verify, and a forged signature does not
+func (suite *JWTKeysTestSuite) TestEdDSA() {
+	service := suite.keyedService(suite.ed25519Key("ed-1"))
+
+	tokenString, customErr := service.GenerateUserToken(suite.user, "session-1")
+	suite.Empty(customErr.ErrCode)
+	parsed, _ := jwt.Parse(tokenString, nil)
+	suite.Equal("EdDSA", parsed.Header["alg"])
+
+	_, customErr = service.ValidateToken(tokenString)
+	suite.Empty(customErr.ErrCode)
+
+	parts := strings.Split(tokenString, ".")
+	forged := parts[0] + "." + parts[1] + "." + jwt.EncodeSegment(make([]byte, ed25519.SignatureSize))
+	_, customErr = service.ValidateToken(forged)
+	suite.Equal(http.StatusUnauthorized, customErr.ErrCode)
+}
+
+// TestKeyRotation tests tokens of the previous key stay valid while it is still a verification key
+func (suite *JWTKeysTestSuite) TestKeyRotation() {
+	oldKey := suite.ed25519Key("old")
+	newKey := suite.ed25519Key("new")
+	oldToken, _ := suite.keyedService(oldKey).GenerateUserToken(suite.user, "session-1")
+
+	rotated := suite.keyedService(newKey, oldKey)
+	_, err := rotated.ValidateToken(oldToken)
+	suite.Empty(err.ErrCode)
+
+	retired := suite.keyedService(newKey)
+	_, err = retired.ValidateToken(oldToken)
+	suite.Equal(http.StatusUnauthorized, err.ErrCode)
+}
+
+// TestSharedSecretRefused tests HS256 tokens are refused once tokens are signed with keys, even when the
+// public key is used as the HMAC secret
+func (suite *JWTKeysTestSuite) TestSharedSecretRefused() {
+	key := suite.ed25519Key("ed-1")
+	service := suite.keyedService(key)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"userId": suite.user.ID, "exp": time.Now().Add(time.Minute).Unix()})
+	token.Header["kid"] = "ed-1"
+	tokenString, _ := token.SignedString([]byte(key.PublicKey.(ed25519.PublicKey)))
+
+	_, err := service.ValidateToken(tokenString)
+	suite.Equal(http.StatusUnauthorized, err.ErrCode)
+}
+
+// TestJWKS tests the published key set holds the public halves of every verification key
+func (suite *JWTKeysTestSuite) TestJWKS() {
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(suite.rsaKey)})
+	rsaKey, err := infrastructure.ParseJWTKey("rsa-1", pemData)
+	suite.Require().NoError(err)
+	edKey := suite.ed25519Key("ed-1")
+
+	keys := suite.keyedService(edKey, rsaKey).JWKS().Keys
+
+	suite.Require().Len(keys, 2)
+	suite.Equal(domain.JWK{Kty: "OKP", Use: "sig", Alg: "EdDSA", Kid: "ed-1", Crv: "Ed25519", X: jwt.EncodeSegment(edKey.PublicKey.(ed25519.PublicKey))}, keys[0])
+	suite.Equal("RSA", keys[1].Kty)
+	suite.Equal("RS256", keys[1].Alg)
+	suite.Equal("AQAB", keys[1].E)
+	suite.Equal(jwt.EncodeSegment(suite.rsaKey.N.Bytes()), keys[1].N)
+
+	suite.Empty(infrastructure.NewJWTService("secret", time.Minute).JWKS().Keys)
+}
+
+// TestLoadJWTKeys tests keys are read from PEM files, public keys only for verification
+func (suite *JWTKeysTestSuite) TestLoadJWTKeys() {
+	dir := suite.T().TempDir()
+	signingFile := filepath.Join(dir, "signing.pem")
+	suite.Require().NoError(os.WriteFile(signingFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(suite.rsaKey)}), 0600))
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	suite.Require().NoError(err)
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	suite.Require().NoError(err)
+	publicFile := filepath.Join(dir, "old.pem")
+	suite.Require().NoError(os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	signingKey, verificationKeys, err := infrastructure.LoadJWTKeys("new", signingFile, " old = "+publicFile+" ,")
+	suite.Require().NoError(err)
+	suite.Equal("new", signingKey.ID)
+	suite.NotNil(signingKey.PrivateKey)
+	suite.Require().Len(verificationKeys, 1)
+	suite.Equal("old", verificationKeys[0].ID)
+	suite.Equal("EdDSA", verificationKeys[0].Method.Alg())
+
+	_, _, err = infrastructure.LoadJWTKeys("new", publicFile, "")
+	suite.Error(err)
+	_, _, err = infrastructure.LoadJWTKeys("", signingFile, "")
+	suite.Error(err)
+	_, _, err = infrastructure.LoadJWTKeys("new", signingFile, "old")
+	suite.Error(err)
+	_, err = infrastructure.NewKeyedJWTService(signingKey, []infrastructure.JWTKey{{ID: "new", Method: signingKey.Method, PublicKey: signingKey.PublicKey}}, time.Minute)
+	suite.Error(err)
+}
+
+// TestWeakRSAKeyRefused tests RSA keys shorter than 2048 bits are refused
+func (suite *JWTKeysTestSuite) TestWeakRSAKeyRefused() {
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	suite.Require().NoError(err)
+
+	_, err = infrastructure.ParseJWTKey("weak", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(weak)}))
+	suite.Error(err)
+}
+
+func TestJWTKeysTestSuite(t *testing.T) {
+	suite.Run(t, new(JWTKeysTestSuite))
+}
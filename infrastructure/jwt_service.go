@@ -1,6 +1,8 @@
 package infrastructure
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"task_managment_api/domain"
@@ -10,35 +12,109 @@ import (
 )
 
 type JWTService interface {
-	GenerateUserToken(user domain.User) (string, domain.CustomError)
+	// GenerateUserToken issues an access token for a session; sessionID becomes its sid claim.
+	GenerateUserToken(user domain.User, sessionID string) (string, domain.CustomError)
 	ValidateToken(tokenString string) (jwt.MapClaims, domain.CustomError)
+	AccessTokenTTL() time.Duration
+	// JWKS lists the public keys tokens may be verified with.
+	JWKS() domain.JWKSet
 }
 
 type jwtService struct{
 	AccessTokenSecret string
+	accessTokenTTL time.Duration
+	// signingKey is set when tokens are signed with an asymmetric key instead of the secret.
+	signingKey *JWTKey
+	verificationKeys map[string]JWTKey
+	keyOrder []string
 }
 
-func NewJWTService(secret string) JWTService {
+func NewJWTService(secret string, accessTokenTTL time.Duration) JWTService {
+	if accessTokenTTL <= 0 {
+		accessTokenTTL = domain.DefaultAccessTokenTTL
+	}
 	return &jwtService{
 		AccessTokenSecret: secret,
+		accessTokenTTL: accessTokenTTL,
 	}
 }
 
+// NewKeyedJWTService creates a service that signs tokens with signingKey and names it in their kid header.
+// Tokens are accepted when their kid is the signing key or one of verificationKeys and the algorithm is
+// that of the key, so a new key can be rolled out while tokens signed with the old one are still valid.
+// Tokens signed with the shared secret are no longer accepted.
+func NewKeyedJWTService(signingKey JWTKey, verificationKeys []JWTKey, accessTokenTTL time.Duration) (JWTService, error) {
+	if accessTokenTTL <= 0 {
+		accessTokenTTL = domain.DefaultAccessTokenTTL
+	}
+	if signingKey.PrivateKey == nil {
+		return nil, fmt.Errorf("signing key %q has no private key", signingKey.ID)
+	}
+	js := &jwtService{
+		accessTokenTTL: accessTokenTTL,
+		signingKey: &signingKey,
+		verificationKeys: map[string]JWTKey{},
+	}
+	for _, key := range append([]JWTKey{signingKey}, verificationKeys...) {
+		if _, found := js.verificationKeys[key.ID]; found {
+			return nil, fmt.Errorf("two keys have the kid %q", key.ID)
+		}
+		key.PrivateKey = nil
+		js.verificationKeys[key.ID] = key
+		js.keyOrder = append(js.keyOrder, key.ID)
+	}
+	return js, nil
+}
+
+// JWKS lists the verification keys, the signing key first. Nothing is published for the shared secret.
+func (js *jwtService) JWKS() domain.JWKSet {
+	set := domain.JWKSet{Keys: []domain.JWK{}}
+	for _, kid := range js.keyOrder {
+		set.Keys = append(set.Keys, js.verificationKeys[kid].JWK())
+	}
+	return set
+}
+
+// AccessTokenTTL is how long the access tokens it issues are valid.
+func (js *jwtService) AccessTokenTTL() time.Duration {
+	return js.accessTokenTTL
+}
 
-func (js *jwtService) GenerateUserToken(user domain.User) (string, domain.CustomError) {{
 
-	expirationTime := time.Now().Add(24 * time.Hour)
+func (js *jwtService) GenerateUserToken(user domain.User, sessionID string) (string, domain.CustomError) {{
+
+	tokenID := make([]byte, 16)
+	if _, err := rand.Read(tokenID); err != nil {
+		return "", domain.CustomError{ErrCode: http.StatusInternalServerError,ErrMessage:  "Error while generating token"}
+	}
+
+	now := time.Now()
+	expirationTime := now.Add(js.accessTokenTTL)
 	claims := &domain.Claims{
 		UserId:   user.ID,
 		Username: user.Username,
 		Role:     user.Role,
+		SessionID: sessionID,
 		StandardClaims: jwt.StandardClaims{
+			// the jti lets a single token be revoked, the iat lets every token of a user issued before a
+			// role or account change be revoked
+			Id:        hex.EncodeToString(tokenID),
+			IssuedAt:  now.Unix(),
 			ExpiresAt: expirationTime.Unix(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(js.AccessTokenSecret))
+	var token *jwt.Token
+	var key interface{}
+	if js.signingKey != nil {
+		token = jwt.NewWithClaims(js.signingKey.Method, claims)
+		token.Header["kid"] = js.signingKey.ID
+		key = js.signingKey.PrivateKey
+	} else {
+		token = jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		key = []byte(js.AccessTokenSecret)
+	}
+	tokenString, err := token.SignedString(key)
 	if err != nil {
 		return "", domain.CustomError{ErrCode: http.StatusInternalServerError,ErrMessage:  "Error while generating token"}
 	}
@@ -47,12 +123,7 @@ func (js *jwtService) GenerateUserToken(user domain.User) (string, domain.Custom
 }}
 
 func  (js *jwtService) ValidateToken(tokenString string) (jwt.MapClaims, domain.CustomError) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(js.AccessTokenSecret), nil
-	})
+	token, err := jwt.Parse(tokenString, js.verificationKey)
 
 	if err != nil || !token.Valid {
 		return nil, domain.CustomError{ErrCode: http.StatusUnauthorized, ErrMessage: "Invalid token"}
@@ -66,4 +137,23 @@ func  (js *jwtService) ValidateToken(tokenString string) (jwt.MapClaims, domain.
 	return claims, domain.CustomError{}
 }
 
+// verificationKey picks the key a token is verified with. With asymmetric keys it is the key named by the
+// kid header, and only for that key's algorithm, so a token cannot pick a weaker way to be checked.
+func (js *jwtService) verificationKey(token *jwt.Token) (interface{}, error) {
+	if js.signingKey == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(js.AccessTokenSecret), nil
+	}
 
+	kid, _ := token.Header["kid"].(string)
+	key, found := js.verificationKeys[kid]
+	if !found {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v for key %q", token.Header["alg"], kid)
+	}
+	return key.PublicKey, nil
+}
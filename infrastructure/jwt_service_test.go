@@ -22,7 +22,7 @@ type JWTServiceTestSuite struct {
 func (suite *JWTServiceTestSuite) SetupTest() {
 	suite.secret = "testsecret" // Mocked secret key for testing
 	suite.secretToken = "secretToken"
-	suite.service = infrastructure.NewJWTService(suite.secretToken)
+	suite.service = infrastructure.NewJWTService(suite.secretToken, 0)
 	suite.user = domain.User{
 		ID:       "user-id-123",
 		Username: "testuser",
@@ -33,7 +33,7 @@ func (suite *JWTServiceTestSuite) SetupTest() {
 
 // TestGenerateUserTokenSuccess tests successful token generation
 func (suite *JWTServiceTestSuite) TestGenerateUserTokenSuccess() {
-	token, err := suite.service.GenerateUserToken(suite.user)
+	token, err := suite.service.GenerateUserToken(suite.user, "session-1")
 
 	suite.Empty(err.ErrCode)
 	suite.NotEmpty(token)
@@ -41,7 +41,7 @@ func (suite *JWTServiceTestSuite) TestGenerateUserTokenSuccess() {
 
 // TestValidateTokenSuccess tests successful token validation
 func (suite *JWTServiceTestSuite) TestValidateTokenSuccess() {
-	token, _ := suite.service.GenerateUserToken(suite.user)
+	token, _ := suite.service.GenerateUserToken(suite.user, "session-1")
 
 	claims, err := suite.service.ValidateToken(token)
 
@@ -49,6 +49,19 @@ func (suite *JWTServiceTestSuite) TestValidateTokenSuccess() {
 	suite.Equal( suite.user.ID, claims["userId"])
 	suite.Equal( suite.user.Username, claims["username"])
 	suite.Equal( suite.user.Role, claims["role"])
+	suite.Equal("session-1", claims["sid"])
+}
+
+// TestAccessTokenTTL tests tokens expire after the configured lifetime
+func (suite *JWTServiceTestSuite) TestAccessTokenTTL() {
+	suite.Equal(domain.DefaultAccessTokenTTL, suite.service.AccessTokenTTL())
+
+	service := infrastructure.NewJWTService(suite.secretToken, 5*time.Minute)
+	token, _ := service.GenerateUserToken(suite.user, "session-1")
+	claims, err := service.ValidateToken(token)
+
+	suite.Empty(err.ErrCode)
+	suite.InDelta(time.Now().Add(5*time.Minute).Unix(), claims["exp"], 5)
 }
 
 // TestValidateTokenFailure tests validation failure for an invalid token
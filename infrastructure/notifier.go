@@ -0,0 +1,116 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"task_managment_api/domain"
+	"time"
+)
+
+const (
+	// NotifierLog writes reminders to the server log.
+	NotifierLog = "log"
+	// NotifierSMTP sends reminders by email.
+	NotifierSMTP = "smtp"
+)
+
+// Notifier delivers reminders to users.
+type Notifier interface {
+	Notify(c context.Context, notice domain.ReminderNotice) domain.CustomError
+}
+
+// SMTPConfig is where the SMTP notifier sends its mail. Username and Password can be left empty for
+// servers that do not need authentication, such as a local fake SMTP server.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// NewNotifier builds the notifier named by kind. An empty kind means the log notifier.
+func NewNotifier(kind string, config SMTPConfig) (Notifier, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", NotifierLog:
+		return NewLogNotifier(), nil
+	case NotifierSMTP:
+		if config.Host == "" || config.From == "" {
+			return nil, fmt.Errorf("the smtp notifier needs SMTP_HOST and SMTP_FROM")
+		}
+		return NewSMTPNotifier(config), nil
+	}
+	return nil, fmt.Errorf("NOTIFIER must be %s or %s, got %q", NotifierLog, NotifierSMTP, kind)
+}
+
+type logNotifier struct {
+}
+
+// NewLogNotifier creates a notifier that writes reminders to the server log.
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (ln *logNotifier) Notify(c context.Context, notice domain.ReminderNotice) domain.CustomError {
+	log.Printf("reminder for %s: task %q (%s) is due %s", notice.Recipient.Username, notice.Task.Title, notice.Task.ID, notice.Task.DueDate.Format(time.RFC3339))
+	return domain.CustomError{}
+}
+
+type smtpNotifier struct {
+	config SMTPConfig
+}
+
+// NewSMTPNotifier creates a notifier that emails reminders to the users' email addresses.
+func NewSMTPNotifier(config SMTPConfig) Notifier {
+	if config.Port == 0 {
+		config.Port = 25
+	}
+	return &smtpNotifier{config: config}
+}
+
+// Notify emails the reminder. Users without an email address are skipped.
+func (sn *smtpNotifier) Notify(c context.Context, notice domain.ReminderNotice) domain.CustomError {
+	if notice.Recipient.Email == "" {
+		log.Printf("reminder for %s skipped: no email address", notice.Recipient.Username)
+		return domain.CustomError{}
+	}
+
+	var auth smtp.Auth
+	if sn.config.Username != "" {
+		auth = smtp.PlainAuth("", sn.config.Username, sn.config.Password, sn.config.Host)
+	}
+	addr := net.JoinHostPort(sn.config.Host, strconv.Itoa(sn.config.Port))
+	err := smtp.SendMail(addr, auth, sn.config.From, []string{notice.Recipient.Email}, reminderMessage(sn.config.From, notice))
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadGateway, ErrMessage: "Error while sending reminder email: " + err.Error()}
+	}
+	return domain.CustomError{}
+}
+
+// reminderMessage builds the email for a reminder.
+func reminderMessage(from string, notice domain.ReminderNotice) []byte {
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", notice.Recipient.Email)
+	fmt.Fprintf(&message, "Subject: Reminder: %s is due soon\r\n", headerValue(notice.Task.Title))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	fmt.Fprintf(&message, "Hi %s,\r\n\r\n", notice.Recipient.Username)
+	fmt.Fprintf(&message, "The task %q is due %s.\r\n", notice.Task.Title, notice.Task.DueDate.Format(time.RFC1123))
+	if notice.Task.Description != "" {
+		fmt.Fprintf(&message, "\r\n%s\r\n", notice.Task.Description)
+	}
+	return []byte(message.String())
+}
+
+// headerValue keeps user input from adding headers of its own.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
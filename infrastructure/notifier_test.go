@@ -0,0 +1,163 @@
+package infrastructure_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"task_managment_api/domain"
+	"task_managment_api/infrastructure"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeSMTPServer accepts a single message and hands its recipient and data over on a channel.
+type fakeSMTPServer struct {
+	listener net.Listener
+	messages chan fakeMail
+}
+
+type fakeMail struct {
+	from string
+	to   string
+	data string
+}
+
+func startFakeSMTPServer() (*fakeSMTPServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	server := &fakeSMTPServer{listener: listener, messages: make(chan fakeMail, 1)}
+	go server.serve()
+	return server, nil
+}
+
+func (s *fakeSMTPServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	var mail fakeMail
+	reply("220 localhost fake SMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			mail.from = strings.Trim(strings.TrimSpace(line)[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			mail.to = strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>")
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			mail.data = data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			s.messages <- mail
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+type NotifierTestSuite struct {
+	suite.Suite
+	notice domain.ReminderNotice
+}
+
+func (suite *NotifierTestSuite) SetupTest() {
+	dueDate := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	suite.notice = domain.ReminderNotice{
+		Task:      domain.Task{ID: "task-1", Title: "Ship the release", Description: "Tag and publish", DueDate: &dueDate},
+		Recipient: domain.User{ID: "user-1", Username: "alice", Email: "alice@example.com"},
+	}
+}
+
+// TestSMTPNotifierSendsEmail tests that a reminder is mailed to the recipient through the SMTP server
+func (suite *NotifierTestSuite) TestSMTPNotifierSendsEmail() {
+	server, err := startFakeSMTPServer()
+	suite.Require().NoError(err)
+	defer server.listener.Close()
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	notifier := infrastructure.NewSMTPNotifier(infrastructure.SMTPConfig{Host: host, Port: portNumber, From: "tasks@example.com"})
+
+	notifyErr := notifier.Notify(context.TODO(), suite.notice)
+	suite.Empty(notifyErr.ErrCode)
+
+	select {
+	case mail := <-server.messages:
+		suite.Equal("tasks@example.com", mail.from)
+		suite.Equal("alice@example.com", mail.to)
+		suite.Contains(mail.data, "Subject: Reminder: Ship the release is due soon")
+		suite.Contains(mail.data, "Tag and publish")
+	case <-time.After(time.Second):
+		suite.Fail("no email was sent")
+	}
+}
+
+// TestSMTPNotifierSkipsUsersWithoutEmail tests that users without an email address are skipped
+func (suite *NotifierTestSuite) TestSMTPNotifierSkipsUsersWithoutEmail() {
+	suite.notice.Recipient.Email = ""
+	notifier := infrastructure.NewSMTPNotifier(infrastructure.SMTPConfig{Host: "127.0.0.1", Port: 1, From: "tasks@example.com"})
+
+	err := notifier.Notify(context.TODO(), suite.notice)
+	suite.Empty(err.ErrCode)
+}
+
+// TestSMTPNotifierUnreachable tests that a server that cannot be reached is reported
+func (suite *NotifierTestSuite) TestSMTPNotifierUnreachable() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	addr := listener.Addr().(*net.TCPAddr)
+	listener.Close()
+	notifier := infrastructure.NewSMTPNotifier(infrastructure.SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "tasks@example.com"})
+
+	notifyErr := notifier.Notify(context.TODO(), suite.notice)
+	suite.NotEmpty(notifyErr.ErrCode)
+}
+
+// TestNewNotifier tests that the notifier is picked by name
+func (suite *NotifierTestSuite) TestNewNotifier() {
+	_, err := infrastructure.NewNotifier("", infrastructure.SMTPConfig{})
+	suite.NoError(err)
+	_, err = infrastructure.NewNotifier("log", infrastructure.SMTPConfig{})
+	suite.NoError(err)
+	_, err = infrastructure.NewNotifier("smtp", infrastructure.SMTPConfig{})
+	suite.Error(err)
+	_, err = infrastructure.NewNotifier("SMTP", infrastructure.SMTPConfig{Host: "localhost", From: "tasks@example.com"})
+	suite.NoError(err)
+	_, err = infrastructure.NewNotifier("pigeon", infrastructure.SMTPConfig{})
+	suite.Error(err)
+}
+
+func TestNotifierTestSuite(t *testing.T) {
+	suite.Run(t, new(NotifierTestSuite))
+}
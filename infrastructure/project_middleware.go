@@ -0,0 +1,80 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"task_managment_api/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProjectRoleResolver looks up the caller's role in a project. The project usecase implements it.
+type ProjectRoleResolver interface {
+	GetProjectRole(c context.Context, user domain.AuthUser, projectID string) (domain.ProjectRole, domain.CustomError)
+	GetTaskProjectRole(c context.Context, user domain.AuthUser, taskID string) (domain.ProjectRole, domain.CustomError)
+}
+
+// ProjectMiddlewareService checks project membership before a request reaches its handler. It runs
+// after AuthMiddleware, which identifies the caller.
+type ProjectMiddlewareService interface {
+	// ProjectRoleMiddleware guards routes whose :id is a project.
+	ProjectRoleMiddleware(required domain.ProjectRole) gin.HandlerFunc
+	// TaskRoleMiddleware guards routes whose :id is a task, using the role in the task's project.
+	TaskRoleMiddleware(required domain.ProjectRole) gin.HandlerFunc
+}
+
+type ProjectService struct {
+	resolver ProjectRoleResolver
+}
+
+func NewProjectService(resolver ProjectRoleResolver) ProjectMiddlewareService {
+	return &ProjectService{resolver: resolver}
+}
+
+func (ps *ProjectService) ProjectRoleMiddleware(required domain.ProjectRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, err := ps.resolver.GetProjectRole(c, contextUser(c), c.Param("id"))
+		checkProjectRole(c, role, err, required)
+	}
+}
+
+func (ps *ProjectService) TaskRoleMiddleware(required domain.ProjectRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, err := ps.resolver.GetTaskProjectRole(c, contextUser(c), c.Param("id"))
+		checkProjectRole(c, role, err, required)
+	}
+}
+
+func checkProjectRole(c *gin.Context, role domain.ProjectRole, err domain.CustomError, required domain.ProjectRole) {
+	if err.ErrCode != 0 {
+		c.AbortWithStatusJSON(err.ErrCode, gin.H{"message": err.ErrMessage})
+		return
+	}
+	if role == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "You are not a member of this project"})
+		return
+	}
+	if !role.AtLeast(required) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("This requires the %s role in the project", required)})
+		return
+	}
+	c.Set("projectRole", string(role))
+	c.Next()
+}
+
+// contextUser reads the caller that AuthMiddleware stored on the context.
+func contextUser(c *gin.Context) domain.AuthUser {
+	return domain.AuthUser{
+		UserID:   c.GetString("userId"),
+		Username: c.GetString("username"),
+		Role:     c.GetString("role"),
+		Permissions: contextPermissions(c),
+	}
+}
+
+// contextPermissions returns the permissions AuthMiddleware found for the caller's role.
+func contextPermissions(c *gin.Context) []domain.Permission {
+	permissions, _ := c.Value("permissions").([]domain.Permission)
+	return permissions
+}
@@ -0,0 +1,101 @@
+package infrastructure_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"task_managment_api/domain"
+	"task_managment_api/infrastructure"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockProjectRoleResolver struct {
+	mock.Mock
+}
+
+func (m *MockProjectRoleResolver) GetProjectRole(c context.Context, user domain.AuthUser, projectID string) (domain.ProjectRole, domain.CustomError) {
+	args := m.Called(user, projectID)
+	return args.Get(0).(domain.ProjectRole), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockProjectRoleResolver) GetTaskProjectRole(c context.Context, user domain.AuthUser, taskID string) (domain.ProjectRole, domain.CustomError) {
+	args := m.Called(user, taskID)
+	return args.Get(0).(domain.ProjectRole), args.Get(1).(domain.CustomError)
+}
+
+type ProjectMiddlewareTestSuite struct {
+	suite.Suite
+	resolver       *MockProjectRoleResolver
+	projectService infrastructure.ProjectMiddlewareService
+	user           domain.AuthUser
+}
+
+func (suite *ProjectMiddlewareTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.resolver = new(MockProjectRoleResolver)
+	suite.projectService = infrastructure.NewProjectService(suite.resolver)
+	suite.user = domain.AuthUser{UserID: "user-1", Username: "alice", Role: "user"}
+}
+
+func (suite *ProjectMiddlewareTestSuite) newContext(id string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userId", suite.user.UserID)
+	c.Set("username", suite.user.Username)
+	c.Set("role", suite.user.Role)
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: id})
+	return c, w
+}
+
+// TestProjectRoleMiddlewareSuccess tests that a member with the required role gets through
+func (suite *ProjectMiddlewareTestSuite) TestProjectRoleMiddlewareSuccess() {
+	suite.resolver.On("GetProjectRole", suite.user, "project-1").Return(domain.ProjectRoleOwner, domain.CustomError{})
+	c, w := suite.newContext("project-1")
+
+	suite.projectService.ProjectRoleMiddleware(domain.ProjectRoleMember)(c)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.False(c.IsAborted())
+	suite.Equal("owner", c.GetString("projectRole"))
+}
+
+// TestProjectRoleMiddlewareNotMember tests that users outside the project are refused
+func (suite *ProjectMiddlewareTestSuite) TestProjectRoleMiddlewareNotMember() {
+	suite.resolver.On("GetProjectRole", suite.user, "project-1").Return(domain.ProjectRole(""), domain.CustomError{})
+	c, w := suite.newContext("project-1")
+
+	suite.projectService.ProjectRoleMiddleware(domain.ProjectRoleViewer)(c)
+
+	suite.Equal(http.StatusForbidden, w.Code)
+	suite.JSONEq(`{"message": "You are not a member of this project"}`, w.Body.String())
+}
+
+// TestTaskRoleMiddlewareViewerCannotWrite tests that viewers cannot reach routes that change a task
+func (suite *ProjectMiddlewareTestSuite) TestTaskRoleMiddlewareViewerCannotWrite() {
+	suite.resolver.On("GetTaskProjectRole", suite.user, "task-1").Return(domain.ProjectRoleViewer, domain.CustomError{})
+	c, w := suite.newContext("task-1")
+
+	suite.projectService.TaskRoleMiddleware(domain.ProjectRoleMember)(c)
+
+	suite.Equal(http.StatusForbidden, w.Code)
+	suite.True(c.IsAborted())
+}
+
+// TestTaskRoleMiddlewareTaskNotFound tests that lookup errors are passed on
+func (suite *ProjectMiddlewareTestSuite) TestTaskRoleMiddlewareTaskNotFound() {
+	suite.resolver.On("GetTaskProjectRole", suite.user, "task-1").Return(domain.ProjectRole(""), domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"})
+	c, w := suite.newContext("task-1")
+
+	suite.projectService.TaskRoleMiddleware(domain.ProjectRoleViewer)(c)
+
+	suite.Equal(http.StatusNotFound, w.Code)
+	suite.JSONEq(`{"message": "Task not found"}`, w.Body.String())
+}
+
+func TestProjectMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(ProjectMiddlewareTestSuite))
+}
@@ -0,0 +1,58 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	"task_managment_api/domain"
+	"time"
+)
+
+// ReminderSender is the part of the reminder usecase the scheduler needs.
+type ReminderSender interface {
+	SendDueReminders(c context.Context, window time.Duration) (int, domain.CustomError)
+}
+
+// ReminderScheduler periodically reminds users of the tasks that are coming due.
+type ReminderScheduler struct {
+	sender   ReminderSender
+	window   time.Duration
+	interval time.Duration
+}
+
+func NewReminderScheduler(sender ReminderSender, window time.Duration, interval time.Duration) *ReminderScheduler {
+	if window <= 0 {
+		window = domain.DefaultReminderWindow
+	}
+	if interval <= 0 {
+		interval = domain.DefaultReminderInterval
+	}
+	return &ReminderScheduler{sender: sender, window: window, interval: interval}
+}
+
+// Start sends reminders once right away and then on every interval until the context is cancelled.
+func (rs *ReminderScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(rs.interval)
+		defer ticker.Stop()
+		for {
+			rs.Remind(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Remind sends the reminders that are due and logs the outcome.
+func (rs *ReminderScheduler) Remind(ctx context.Context) int {
+	sent, err := rs.sender.SendDueReminders(ctx, rs.window)
+	if err.ErrCode != 0 {
+		log.Println("sending reminders failed:", err.ErrMessage)
+	}
+	if sent > 0 {
+		log.Printf("sent %d reminder(s)", sent)
+	}
+	return sent
+}
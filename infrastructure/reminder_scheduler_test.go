@@ -0,0 +1,82 @@
+package infrastructure_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/infrastructure"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockReminderSender struct {
+	mock.Mock
+}
+
+func (m *MockReminderSender) SendDueReminders(c context.Context, window time.Duration) (int, domain.CustomError) {
+	args := m.Called(c, window)
+	return args.Int(0), args.Get(1).(domain.CustomError)
+}
+
+type ReminderSchedulerTestSuite struct {
+	suite.Suite
+	sender *MockReminderSender
+}
+
+func (suite *ReminderSchedulerTestSuite) SetupTest() {
+	suite.sender = new(MockReminderSender)
+}
+
+// TestRemindUsesWindow tests that a run looks for tasks due within the configured window
+func (suite *ReminderSchedulerTestSuite) TestRemindUsesWindow() {
+	suite.sender.On("SendDueReminders", mock.Anything, 6*time.Hour).Return(2, domain.CustomError{})
+	scheduler := infrastructure.NewReminderScheduler(suite.sender, 6*time.Hour, time.Minute)
+
+	suite.Equal(2, scheduler.Remind(context.TODO()))
+	suite.sender.AssertExpectations(suite.T())
+}
+
+// TestRemindDefaultWindow tests that an unset window falls back to the default
+func (suite *ReminderSchedulerTestSuite) TestRemindDefaultWindow() {
+	suite.sender.On("SendDueReminders", mock.Anything, domain.DefaultReminderWindow).Return(0, domain.CustomError{})
+	scheduler := infrastructure.NewReminderScheduler(suite.sender, 0, 0)
+
+	suite.Equal(0, scheduler.Remind(context.TODO()))
+	suite.sender.AssertExpectations(suite.T())
+}
+
+// TestRemindPartialFailure tests that the reminders sent before a failure are still counted
+func (suite *ReminderSchedulerTestSuite) TestRemindPartialFailure() {
+	suite.sender.On("SendDueReminders", mock.Anything, mock.Anything).Return(1, domain.CustomError{ErrCode: http.StatusBadGateway, ErrMessage: "Error while sending reminder email"})
+	scheduler := infrastructure.NewReminderScheduler(suite.sender, time.Hour, time.Minute)
+
+	suite.Equal(1, scheduler.Remind(context.TODO()))
+}
+
+// TestStartRemindsUntilCancelled tests that Start runs right away and stops with its context
+func (suite *ReminderSchedulerTestSuite) TestStartRemindsUntilCancelled() {
+	ran := make(chan struct{}, 1)
+	suite.sender.On("SendDueReminders", mock.Anything, time.Hour).Return(0, domain.CustomError{}).Run(func(args mock.Arguments) {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	infrastructure.NewReminderScheduler(suite.sender, time.Hour, time.Hour).Start(ctx)
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		suite.Fail("the scheduler did not run")
+	}
+}
+
+func TestReminderSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(ReminderSchedulerTestSuite))
+}
@@ -0,0 +1,59 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	"task_managment_api/domain"
+	"time"
+)
+
+// TrashPurger is the part of the task usecase the sweeper needs.
+type TrashPurger interface {
+	PurgeExpiredTasks(c context.Context, retention time.Duration) (int64, domain.CustomError)
+}
+
+// TrashSweeper periodically hard-deletes the tasks that have outlived the trash retention period.
+type TrashSweeper struct {
+	purger    TrashPurger
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewTrashSweeper(purger TrashPurger, retention time.Duration, interval time.Duration) *TrashSweeper {
+	if retention <= 0 {
+		retention = domain.DefaultTrashRetention
+	}
+	if interval <= 0 {
+		interval = domain.DefaultTrashSweepInterval
+	}
+	return &TrashSweeper{purger: purger, retention: retention, interval: interval}
+}
+
+// Start sweeps once right away and then on every interval until the context is cancelled.
+func (ts *TrashSweeper) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(ts.interval)
+		defer ticker.Stop()
+		for {
+			ts.Sweep(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Sweep runs a single purge and logs the outcome.
+func (ts *TrashSweeper) Sweep(ctx context.Context) int64 {
+	purged, err := ts.purger.PurgeExpiredTasks(ctx, ts.retention)
+	if err.ErrCode != 0 {
+		log.Println("trash sweep failed:", err.ErrMessage)
+		return 0
+	}
+	if purged > 0 {
+		log.Printf("trash sweep purged %d task(s)", purged)
+	}
+	return purged
+}
@@ -0,0 +1,82 @@
+package infrastructure_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/infrastructure"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockTrashPurger struct {
+	mock.Mock
+}
+
+func (m *MockTrashPurger) PurgeExpiredTasks(c context.Context, retention time.Duration) (int64, domain.CustomError) {
+	args := m.Called(c, retention)
+	return args.Get(0).(int64), args.Get(1).(domain.CustomError)
+}
+
+type TrashSweeperTestSuite struct {
+	suite.Suite
+	purger *MockTrashPurger
+}
+
+func (suite *TrashSweeperTestSuite) SetupTest() {
+	suite.purger = new(MockTrashPurger)
+}
+
+// TestSweepUsesRetention tests that a sweep purges with the configured retention
+func (suite *TrashSweeperTestSuite) TestSweepUsesRetention() {
+	suite.purger.On("PurgeExpiredTasks", mock.Anything, 48*time.Hour).Return(int64(3), domain.CustomError{})
+	sweeper := infrastructure.NewTrashSweeper(suite.purger, 48*time.Hour, time.Minute)
+
+	suite.Equal(int64(3), sweeper.Sweep(context.TODO()))
+	suite.purger.AssertExpectations(suite.T())
+}
+
+// TestSweepDefaultRetention tests that an unset retention falls back to the default
+func (suite *TrashSweeperTestSuite) TestSweepDefaultRetention() {
+	suite.purger.On("PurgeExpiredTasks", mock.Anything, domain.DefaultTrashRetention).Return(int64(0), domain.CustomError{})
+	sweeper := infrastructure.NewTrashSweeper(suite.purger, 0, 0)
+
+	suite.Equal(int64(0), sweeper.Sweep(context.TODO()))
+	suite.purger.AssertExpectations(suite.T())
+}
+
+// TestSweepFailure tests that a failed sweep reports nothing purged
+func (suite *TrashSweeperTestSuite) TestSweepFailure() {
+	suite.purger.On("PurgeExpiredTasks", mock.Anything, mock.Anything).Return(int64(0), domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while purging tasks"})
+	sweeper := infrastructure.NewTrashSweeper(suite.purger, time.Hour, time.Minute)
+
+	suite.Equal(int64(0), sweeper.Sweep(context.TODO()))
+}
+
+// TestStartSweepsUntilCancelled tests that Start sweeps right away and stops with its context
+func (suite *TrashSweeperTestSuite) TestStartSweepsUntilCancelled() {
+	swept := make(chan struct{}, 1)
+	suite.purger.On("PurgeExpiredTasks", mock.Anything, time.Hour).Return(int64(0), domain.CustomError{}).Run(func(args mock.Arguments) {
+		select {
+		case swept <- struct{}{}:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	infrastructure.NewTrashSweeper(suite.purger, time.Hour, time.Hour).Start(ctx)
+
+	select {
+	case <-swept:
+	case <-time.After(time.Second):
+		suite.Fail("the sweeper did not run")
+	}
+}
+
+func TestTrashSweeperTestSuite(t *testing.T) {
+	suite.Run(t, new(TrashSweeperTestSuite))
+}
@@ -0,0 +1,52 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	"task_managment_api/domain"
+	"time"
+)
+
+// DeliveryDispatcher is the part of the webhook usecase the dispatcher needs.
+type DeliveryDispatcher interface {
+	DispatchDueDeliveries(c context.Context) (int, domain.CustomError)
+}
+
+// WebhookDispatcher periodically sends the webhook deliveries that are due, which includes the retries of
+// failed ones.
+type WebhookDispatcher struct {
+	dispatcher DeliveryDispatcher
+	interval   time.Duration
+}
+
+func NewWebhookDispatcher(dispatcher DeliveryDispatcher, interval time.Duration) *WebhookDispatcher {
+	if interval <= 0 {
+		interval = domain.DefaultWebhookDispatchInterval
+	}
+	return &WebhookDispatcher{dispatcher: dispatcher, interval: interval}
+}
+
+// Start dispatches once right away and then on every interval until the context is cancelled.
+func (wd *WebhookDispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(wd.interval)
+		defer ticker.Stop()
+		for {
+			wd.Dispatch(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Dispatch sends the due deliveries and logs failures.
+func (wd *WebhookDispatcher) Dispatch(ctx context.Context) int {
+	delivered, err := wd.dispatcher.DispatchDueDeliveries(ctx)
+	if err.ErrCode != 0 {
+		log.Println("webhook dispatch failed:", err.ErrMessage)
+	}
+	return delivered
+}
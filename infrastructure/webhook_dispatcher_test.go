@@ -0,0 +1,70 @@
+package infrastructure_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/infrastructure"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockDeliveryDispatcher struct {
+	mock.Mock
+}
+
+func (m *MockDeliveryDispatcher) DispatchDueDeliveries(c context.Context) (int, domain.CustomError) {
+	args := m.Called(c)
+	return args.Int(0), args.Get(1).(domain.CustomError)
+}
+
+type WebhookDispatcherTestSuite struct {
+	suite.Suite
+	dispatcher *MockDeliveryDispatcher
+}
+
+func (suite *WebhookDispatcherTestSuite) SetupTest() {
+	suite.dispatcher = new(MockDeliveryDispatcher)
+}
+
+// TestDispatch tests that a run reports the deliveries that succeeded
+func (suite *WebhookDispatcherTestSuite) TestDispatch() {
+	suite.dispatcher.On("DispatchDueDeliveries", mock.Anything).Return(3, domain.CustomError{})
+
+	suite.Equal(3, infrastructure.NewWebhookDispatcher(suite.dispatcher, time.Minute).Dispatch(context.TODO()))
+}
+
+// TestDispatchFailure tests that a failed run still reports what was delivered before it failed
+func (suite *WebhookDispatcherTestSuite) TestDispatchFailure() {
+	suite.dispatcher.On("DispatchDueDeliveries", mock.Anything).Return(1, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating webhook delivery"})
+
+	suite.Equal(1, infrastructure.NewWebhookDispatcher(suite.dispatcher, 0).Dispatch(context.TODO()))
+}
+
+// TestStartDispatchesUntilCancelled tests that Start runs right away and stops with its context
+func (suite *WebhookDispatcherTestSuite) TestStartDispatchesUntilCancelled() {
+	ran := make(chan struct{}, 1)
+	suite.dispatcher.On("DispatchDueDeliveries", mock.Anything).Return(0, domain.CustomError{}).Run(func(args mock.Arguments) {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	infrastructure.NewWebhookDispatcher(suite.dispatcher, time.Hour).Start(ctx)
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		suite.Fail("the dispatcher did not run")
+	}
+}
+
+func TestWebhookDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookDispatcherTestSuite))
+}
@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"task_managment_api/domain"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader carries the HMAC-SHA256 of the body, keyed with the webhook's secret, as
+	// "sha256=<hex>".
+	WebhookSignatureHeader = "X-Webhook-Signature-256"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	// defaultWebhookTimeout bounds how long a receiver may take to answer.
+	defaultWebhookTimeout = 10 * time.Second
+)
+
+// WebhookSender POSTs deliveries to webhooks.
+type WebhookSender interface {
+	Send(c context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, domain.CustomError)
+}
+
+type webhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender creates a sender whose requests give up after the timeout.
+func NewWebhookSender(timeout time.Duration) WebhookSender {
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &webhookSender{client: &http.Client{Timeout: timeout}}
+}
+
+// Send POSTs the delivery's payload, signed with the webhook's secret, and returns the response status.
+// Any status outside 2xx counts as a failure.
+func (ws *webhookSender) Send(c context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, domain.CustomError) {
+	body := []byte(delivery.Payload)
+	request, err := http.NewRequestWithContext(c, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, domain.CustomError{ErrCode: http.StatusBadGateway, ErrMessage: "Invalid webhook request: " + err.Error()}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "task-management-webhooks")
+	request.Header.Set(WebhookEventHeader, delivery.Event)
+	request.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	request.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, body))
+
+	response, err := ws.client.Do(request)
+	if err != nil {
+		return 0, domain.CustomError{ErrCode: http.StatusBadGateway, ErrMessage: "Webhook request failed: " + err.Error()}
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, domain.CustomError{ErrCode: http.StatusBadGateway, ErrMessage: fmt.Sprintf("Webhook answered with status %d", response.StatusCode)}
+	}
+	return response.StatusCode, domain.CustomError{}
+}
+
+// SignWebhookPayload computes the signature receivers check a delivery against.
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
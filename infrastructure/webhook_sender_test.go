@@ -0,0 +1,71 @@
+package infrastructure_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"task_managment_api/domain"
+	"task_managment_api/infrastructure"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookSenderTestSuite struct {
+	suite.Suite
+	sender   infrastructure.WebhookSender
+	delivery domain.WebhookDelivery
+}
+
+func (suite *WebhookSenderTestSuite) SetupTest() {
+	suite.sender = infrastructure.NewWebhookSender(time.Second)
+	suite.delivery = domain.WebhookDelivery{ID: "delivery-1", Event: domain.EventTaskCreated, Payload: `{"type":"task.created"}`}
+}
+
+// TestSendSignsPayload tests that the receiver gets the payload with its HMAC-SHA256 signature
+func (suite *WebhookSenderTestSuite) TestSendSignsPayload() {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	webhook := domain.Webhook{URL: server.URL, Secret: "0123456789abcdef"}
+
+	status, err := suite.sender.Send(context.TODO(), webhook, suite.delivery)
+
+	suite.Empty(err.ErrCode)
+	suite.Equal(http.StatusNoContent, status)
+	request := <-received
+	suite.Equal(suite.delivery.Payload, <-bodies)
+	suite.Equal(domain.EventTaskCreated, request.Header.Get(infrastructure.WebhookEventHeader))
+	suite.Equal("delivery-1", request.Header.Get(infrastructure.WebhookDeliveryHeader))
+	suite.Equal(infrastructure.SignWebhookPayload("0123456789abcdef", []byte(suite.delivery.Payload)), request.Header.Get(infrastructure.WebhookSignatureHeader))
+}
+
+// TestSendFailureStatus tests that a status outside 2xx is reported as a failure
+func (suite *WebhookSenderTestSuite) TestSendFailureStatus() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	status, err := suite.sender.Send(context.TODO(), domain.Webhook{URL: server.URL}, suite.delivery)
+
+	suite.Equal(http.StatusServiceUnavailable, status)
+	suite.Equal(http.StatusBadGateway, err.ErrCode)
+}
+
+// TestSignWebhookPayload tests the signature against a known HMAC-SHA256 value
+func (suite *WebhookSenderTestSuite) TestSignWebhookPayload() {
+	suite.Equal("sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", infrastructure.SignWebhookPayload("key", []byte("The quick brown fox jumps over the lazy dog")))
+}
+
+func TestWebhookSenderTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookSenderTestSuite))
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type calendarFeedRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCalendarFeedRepository creates a new repository for calendar feeds. The collection needs unique
+// indexes on user_id and token_hash.
+func NewCalendarFeedRepository(db *mongo.Database, feedCollectionString string) domain.CalendarFeedRepository {
+	return &calendarFeedRepository{
+		collection: db.Collection(feedCollectionString),
+	}
+}
+
+// ReplaceFeed stores the user's feed, replacing the one they had, so the old token stops working.
+func (fr *calendarFeedRepository) ReplaceFeed(c context.Context, feed domain.CalendarFeed) domain.CustomError {
+	feed.ID = ""
+	_, err := fr.collection.ReplaceOne(c, bson.M{"user_id": feed.UserID}, feed, options.Replace().SetUpsert(true))
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while saving calendar feed"}
+	}
+	return domain.CustomError{}
+}
+
+func (fr *calendarFeedRepository) GetFeedByUserID(c context.Context, userID string) (domain.CalendarFeed, domain.CustomError) {
+	return fr.findFeed(c, bson.M{"user_id": userID})
+}
+
+func (fr *calendarFeedRepository) GetFeedByTokenHash(c context.Context, tokenHash string) (domain.CalendarFeed, domain.CustomError) {
+	return fr.findFeed(c, bson.M{"token_hash": tokenHash})
+}
+
+func (fr *calendarFeedRepository) findFeed(c context.Context, filter bson.M) (domain.CalendarFeed, domain.CustomError) {
+	var feed domain.CalendarFeed
+	err := fr.collection.FindOne(c, filter).Decode(&feed)
+	if err == mongo.ErrNoDocuments {
+		return domain.CalendarFeed{}, calendarFeedNotFound()
+	}
+	if err != nil {
+		return domain.CalendarFeed{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving calendar feed"}
+	}
+	return feed, domain.CustomError{}
+}
+
+// DeleteFeed revokes the user's feed token.
+func (fr *calendarFeedRepository) DeleteFeed(c context.Context, userID string) domain.CustomError {
+	result, err := fr.collection.DeleteOne(c, bson.M{"user_id": userID})
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while deleting calendar feed"}
+	}
+	if result.DeletedCount == 0 {
+		return calendarFeedNotFound()
+	}
+	return domain.CustomError{}
+}
+
+func calendarFeedNotFound() domain.CustomError {
+	return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Calendar feed not found"}
+}
@@ -0,0 +1,70 @@
+package repositories_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CalendarFeedRepositorySuite struct {
+	suite.Suite
+	db         *mongo.Database
+	collection *mongo.Collection
+	repo       domain.CalendarFeedRepository
+}
+
+func (suite *CalendarFeedRepositorySuite) SetupTest() {
+	// Clear the collection before each test
+	suite.collection.DeleteMany(context.TODO(), bson.D{})
+}
+
+func (suite *CalendarFeedRepositorySuite) SetupSuite() {
+	// Set up a test MongoDB instance
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	suite.Require().NoError(err)
+
+	suite.db = client.Database("task_management_test")
+	suite.collection = suite.db.Collection("calendar_feeds")
+
+	suite.repo = repositories.NewCalendarFeedRepository(suite.db, "calendar_feeds")
+}
+
+// Test a new feed replaces the user's old one, so the old token stops working
+func (suite *CalendarFeedRepositorySuite) TestReplaceFeed() {
+	suite.Empty(suite.repo.ReplaceFeed(context.TODO(), domain.CalendarFeed{UserID: "user-1", TokenHash: "old", CreatedAt: time.Now().UTC()}).ErrCode)
+	suite.Empty(suite.repo.ReplaceFeed(context.TODO(), domain.CalendarFeed{UserID: "user-1", TokenHash: "new", CreatedAt: time.Now().UTC()}).ErrCode)
+
+	_, err := suite.repo.GetFeedByTokenHash(context.TODO(), "old")
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+
+	feed, err := suite.repo.GetFeedByTokenHash(context.TODO(), "new")
+	suite.Empty(err.ErrCode)
+	suite.Equal("user-1", feed.UserID)
+
+	count, _ := suite.collection.CountDocuments(context.TODO(), bson.M{"user_id": "user-1"})
+	suite.Equal(int64(1), count)
+}
+
+// Test a revoked feed is gone
+func (suite *CalendarFeedRepositorySuite) TestDeleteFeed() {
+	suite.Empty(suite.repo.ReplaceFeed(context.TODO(), domain.CalendarFeed{UserID: "user-1", TokenHash: "hash"}).ErrCode)
+
+	suite.Empty(suite.repo.DeleteFeed(context.TODO(), "user-1").ErrCode)
+
+	_, err := suite.repo.GetFeedByUserID(context.TODO(), "user-1")
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+	suite.Equal(http.StatusNotFound, suite.repo.DeleteFeed(context.TODO(), "user-1").ErrCode)
+}
+
+func TestCalendarFeedRepositorySuite(t *testing.T) {
+	suite.Run(t, new(CalendarFeedRepositorySuite))
+}
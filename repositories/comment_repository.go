@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type commentRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCommentRepository creates a new comment repository instance.
+func NewCommentRepository(db *mongo.Database, commentCollectionString string) domain.CommentRepository {
+	return &commentRepository{
+		collection: db.Collection(commentCollectionString),
+	}
+}
+
+// CreateComment stores a comment and returns its ID.
+func (cr *commentRepository) CreateComment(c context.Context, comment domain.Comment) (string, domain.CustomError) {
+	comment.ID = ""
+	result, err := cr.collection.InsertOne(c, comment)
+	if err != nil {
+		return "", domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating comment"}
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), domain.CustomError{}
+}
+
+// GetCommentByID retrieves a single comment.
+func (cr *commentRepository) GetCommentByID(c context.Context, commentID string) (domain.Comment, domain.CustomError) {
+	objectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return domain.Comment{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Comment not found"}
+	}
+
+	var comment domain.Comment
+	err = cr.collection.FindOne(c, bson.M{"_id": objectID}).Decode(&comment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Comment{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Comment not found"}
+		}
+		return domain.Comment{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving comment"}
+	}
+	return comment, domain.CustomError{}
+}
+
+// GetThreads retrieves one page of a task's top-level comments, oldest first. The cursor is the ID of
+// the last comment of the previous page.
+func (cr *commentRepository) GetThreads(c context.Context, taskID string, cursor string, limit int64) (domain.CommentPage, domain.CustomError) {
+	filter := bson.M{"task_id": taskID, "parent_id": bson.M{"$exists": false}}
+	return cr.findPage(c, filter, cursor, limit, 1)
+}
+
+// GetMentions retrieves one page of the comments that mention a user, newest first.
+func (cr *commentRepository) GetMentions(c context.Context, userID string, cursor string, limit int64) (domain.CommentPage, domain.CustomError) {
+	return cr.findPage(c, bson.M{"mentions": userID}, cursor, limit, -1)
+}
+
+// findPage pages through the comments matching the filter in _id order, ascending for 1 and descending for -1.
+func (cr *commentRepository) findPage(c context.Context, filter bson.M, cursor string, limit int64, direction int) (domain.CommentPage, domain.CustomError) {
+	total, err := cr.collection.CountDocuments(c, filter)
+	if err != nil {
+		return domain.CommentPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while counting comments"}
+	}
+
+	if cursor != "" {
+		after, err := primitive.ObjectIDFromHex(cursor)
+		if err != nil {
+			return domain.CommentPage{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid cursor"}
+		}
+		operator := "$gt"
+		if direction < 0 {
+			operator = "$lt"
+		}
+		filter["_id"] = bson.M{operator: after}
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: direction}}).SetLimit(limit + 1)
+	results, err := cr.collection.Find(c, filter, findOptions)
+	if err != nil {
+		return domain.CommentPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving comments"}
+	}
+
+	comments := []domain.Comment{}
+	if err := results.All(c, &comments); err != nil {
+		return domain.CommentPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving comments"}
+	}
+
+	page := domain.CommentPage{Comments: comments, Total: total}
+	if int64(len(comments)) > limit {
+		page.Comments = comments[:limit]
+		page.NextCursor = page.Comments[len(page.Comments)-1].ID
+	}
+	return page, domain.CustomError{}
+}
+
+// GetReplies retrieves the replies to the given comments, oldest first.
+func (cr *commentRepository) GetReplies(c context.Context, parentIDs []string) ([]domain.Comment, domain.CustomError) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	results, err := cr.collection.Find(c, bson.M{"parent_id": bson.M{"$in": parentIDs}}, findOptions)
+	if err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving comments"}
+	}
+
+	replies := []domain.Comment{}
+	if err := results.All(c, &replies); err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving comments"}
+	}
+	return replies, domain.CustomError{}
+}
+
+// UpdateComment stores a comment's new body, mentions and edit stamp.
+func (cr *commentRepository) UpdateComment(c context.Context, comment domain.Comment) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(comment.ID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Comment not found"}
+	}
+
+	update := bson.M{"$set": bson.M{
+		"body":      comment.Body,
+		"mentions":  comment.Mentions,
+		"edited_at": comment.EditedAt,
+		"edited_by": comment.EditedBy,
+	}}
+	result, err := cr.collection.UpdateOne(c, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating comment"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Comment not found"}
+	}
+	return domain.CustomError{}
+}
+
+// DeleteComment removes a comment together with its replies.
+func (cr *commentRepository) DeleteComment(c context.Context, commentID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Comment not found"}
+	}
+
+	result, err := cr.collection.DeleteOne(c, bson.M{"_id": objectID})
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while deleting comment"}
+	}
+	if result.DeletedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Comment not found"}
+	}
+
+	_, err = cr.collection.DeleteMany(c, bson.M{"parent_id": commentID})
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while deleting comment replies"}
+	}
+	return domain.CustomError{}
+}
@@ -0,0 +1,104 @@
+package repositories_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CommentRepositorySuite struct {
+	suite.Suite
+	db         *mongo.Database
+	collection *mongo.Collection
+	repo       domain.CommentRepository
+}
+
+func (suite *CommentRepositorySuite) SetupTest() {
+	// Clear the collection before each test
+	suite.collection.DeleteMany(context.TODO(), bson.D{})
+}
+
+func (suite *CommentRepositorySuite) SetupSuite() {
+	// Set up a test MongoDB instance
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	suite.Require().NoError(err)
+
+	suite.db = client.Database("task_management_test")
+	suite.collection = suite.db.Collection("comments")
+
+	suite.repo = repositories.NewCommentRepository(suite.db, "comments")
+}
+
+// Test GetThreads pages through top-level comments oldest first and GetReplies finds their replies
+func (suite *CommentRepositorySuite) TestThreadsAndReplies() {
+	firstID, err := suite.repo.CreateComment(context.TODO(), domain.Comment{TaskID: "task-1", Body: "First", Mentions: []string{}})
+	suite.Empty(err.ErrCode)
+	_, err = suite.repo.CreateComment(context.TODO(), domain.Comment{TaskID: "task-1", Body: "Second", Mentions: []string{}})
+	suite.Empty(err.ErrCode)
+	_, err = suite.repo.CreateComment(context.TODO(), domain.Comment{TaskID: "task-1", ParentID: firstID, Body: "Reply", Mentions: []string{}})
+	suite.Empty(err.ErrCode)
+	_, err = suite.repo.CreateComment(context.TODO(), domain.Comment{TaskID: "task-2", Body: "Elsewhere", Mentions: []string{}})
+	suite.Empty(err.ErrCode)
+
+	first, err := suite.repo.GetThreads(context.TODO(), "task-1", "", 1)
+	suite.Empty(err.ErrCode)
+	suite.Equal(int64(2), first.Total)
+	suite.Equal("First", first.Comments[0].Body)
+	suite.NotEmpty(first.NextCursor)
+
+	second, err := suite.repo.GetThreads(context.TODO(), "task-1", first.NextCursor, 1)
+	suite.Empty(err.ErrCode)
+	suite.Equal("Second", second.Comments[0].Body)
+	suite.Empty(second.NextCursor)
+
+	replies, err := suite.repo.GetReplies(context.TODO(), []string{firstID})
+	suite.Empty(err.ErrCode)
+	suite.Len(replies, 1)
+	suite.Equal("Reply", replies[0].Body)
+}
+
+// Test updating a comment and finding it through its mentions
+func (suite *CommentRepositorySuite) TestUpdateCommentAndMentions() {
+	commentID, err := suite.repo.CreateComment(context.TODO(), domain.Comment{TaskID: "task-1", Body: "Hello", Mentions: []string{}})
+	suite.Empty(err.ErrCode)
+
+	editedAt := time.Now().UTC().Truncate(time.Millisecond)
+	err = suite.repo.UpdateComment(context.TODO(), domain.Comment{ID: commentID, Body: "Hello @bob", Mentions: []string{"user-2"}, EditedAt: &editedAt, EditedBy: "user-1"})
+	suite.Empty(err.ErrCode)
+
+	page, err := suite.repo.GetMentions(context.TODO(), "user-2", "", 10)
+	suite.Empty(err.ErrCode)
+	suite.Len(page.Comments, 1)
+	suite.Equal("Hello @bob", page.Comments[0].Body)
+	suite.Equal("user-1", page.Comments[0].EditedBy)
+}
+
+// Test deleting a top-level comment removes its replies
+func (suite *CommentRepositorySuite) TestDeleteComment() {
+	commentID, err := suite.repo.CreateComment(context.TODO(), domain.Comment{TaskID: "task-1", Body: "Thread", Mentions: []string{}})
+	suite.Empty(err.ErrCode)
+	replyID, err := suite.repo.CreateComment(context.TODO(), domain.Comment{TaskID: "task-1", ParentID: commentID, Body: "Reply", Mentions: []string{}})
+	suite.Empty(err.ErrCode)
+
+	err = suite.repo.DeleteComment(context.TODO(), commentID)
+	suite.Empty(err.ErrCode)
+
+	_, err = suite.repo.GetCommentByID(context.TODO(), replyID)
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+
+	err = suite.repo.DeleteComment(context.TODO(), commentID)
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+func TestCommentRepositorySuite(t *testing.T) {
+	suite.Run(t, new(CommentRepositorySuite))
+}
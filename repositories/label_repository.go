@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LabelNameCollation compares label names regardless of case. The unique index on name uses it too.
+var LabelNameCollation = &options.Collation{Locale: "en", Strength: 2}
+
+type labelRepository struct {
+	collection *mongo.Collection
+}
+
+// NewLabelRepository creates a new label repository instance.
+func NewLabelRepository(db *mongo.Database, labelCollectionString string) domain.LabelRepository {
+	return &labelRepository{
+		collection: db.Collection(labelCollectionString),
+	}
+}
+
+// CreateLabel stores a label and returns its ID.
+func (lr *labelRepository) CreateLabel(c context.Context, label domain.Label) (string, domain.CustomError) {
+	label.ID = ""
+	result, err := lr.collection.InsertOne(c, label)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", labelNameTaken()
+		}
+		return "", domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating label"}
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), domain.CustomError{}
+}
+
+// GetLabels retrieves every label, ordered by name.
+func (lr *labelRepository) GetLabels(c context.Context) ([]domain.Label, domain.CustomError) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetCollation(LabelNameCollation)
+	return lr.findLabels(c, bson.M{}, findOptions)
+}
+
+// GetLabelByID retrieves a single label.
+func (lr *labelRepository) GetLabelByID(c context.Context, labelID string) (domain.Label, domain.CustomError) {
+	objectID, err := primitive.ObjectIDFromHex(labelID)
+	if err != nil {
+		return domain.Label{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Label not found"}
+	}
+	return lr.findLabel(c, bson.M{"_id": objectID}, options.FindOne())
+}
+
+// GetLabelByName retrieves the label with the given name, ignoring case.
+func (lr *labelRepository) GetLabelByName(c context.Context, name string) (domain.Label, domain.CustomError) {
+	return lr.findLabel(c, bson.M{"name": name}, options.FindOne().SetCollation(LabelNameCollation))
+}
+
+// GetLabelsByIDs retrieves the labels with the given IDs. IDs that are not valid or not found are skipped.
+func (lr *labelRepository) GetLabelsByIDs(c context.Context, labelIDs []string) ([]domain.Label, domain.CustomError) {
+	objectIDs := []primitive.ObjectID{}
+	for _, id := range labelIDs {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+	return lr.findLabels(c, bson.M{"_id": bson.M{"$in": objectIDs}}, options.Find())
+}
+
+// UpdateLabel stores a label's new name and color.
+func (lr *labelRepository) UpdateLabel(c context.Context, label domain.Label) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(label.ID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Label not found"}
+	}
+
+	update := bson.M{"$set": bson.M{"name": label.Name, "color": label.Color}}
+	result, err := lr.collection.UpdateOne(c, bson.M{"_id": objectID}, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return labelNameTaken()
+		}
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating label"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Label not found"}
+	}
+	return domain.CustomError{}
+}
+
+// DeleteLabel removes a label. Tasks carrying it are cleaned up by the task repository.
+func (lr *labelRepository) DeleteLabel(c context.Context, labelID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(labelID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Label not found"}
+	}
+
+	result, err := lr.collection.DeleteOne(c, bson.M{"_id": objectID})
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while deleting label"}
+	}
+	if result.DeletedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Label not found"}
+	}
+	return domain.CustomError{}
+}
+
+func (lr *labelRepository) findLabel(c context.Context, filter bson.M, findOptions *options.FindOneOptions) (domain.Label, domain.CustomError) {
+	var label domain.Label
+	err := lr.collection.FindOne(c, filter, findOptions).Decode(&label)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Label{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Label not found"}
+		}
+		return domain.Label{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving label"}
+	}
+	return label, domain.CustomError{}
+}
+
+func (lr *labelRepository) findLabels(c context.Context, filter bson.M, findOptions *options.FindOptions) ([]domain.Label, domain.CustomError) {
+	results, err := lr.collection.Find(c, filter, findOptions)
+	if err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving labels"}
+	}
+
+	labels := []domain.Label{}
+	if err := results.All(c, &labels); err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving labels"}
+	}
+	return labels, domain.CustomError{}
+}
+
+func labelNameTaken() domain.CustomError {
+	return domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "A label with this name already exists", Field: "name"}
+}
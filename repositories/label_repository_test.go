@@ -0,0 +1,95 @@
+package repositories_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LabelRepositorySuite struct {
+	suite.Suite
+	db         *mongo.Database
+	collection *mongo.Collection
+	repo       domain.LabelRepository
+}
+
+func (suite *LabelRepositorySuite) SetupTest() {
+	// Clear the collection before each test
+	suite.collection.DeleteMany(context.TODO(), bson.D{})
+}
+
+func (suite *LabelRepositorySuite) SetupSuite() {
+	// Set up a test MongoDB instance
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	suite.Require().NoError(err)
+
+	suite.db = client.Database("task_management_test")
+	suite.collection = suite.db.Collection("labels")
+	_, err = suite.collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.M{"name": 1},
+		Options: options.Index().SetUnique(true).SetCollation(repositories.LabelNameCollation),
+	})
+	suite.Require().NoError(err)
+
+	suite.repo = repositories.NewLabelRepository(suite.db, "labels")
+}
+
+// Test labels are listed by name and found by name regardless of case
+func (suite *LabelRepositorySuite) TestCreateAndFindLabels() {
+	urgentID, err := suite.repo.CreateLabel(context.TODO(), domain.Label{Name: "urgent", Color: "#ff0000"})
+	suite.Empty(err.ErrCode)
+	_, err = suite.repo.CreateLabel(context.TODO(), domain.Label{Name: "Backend", Color: "#1d76db"})
+	suite.Empty(err.ErrCode)
+
+	labels, err := suite.repo.GetLabels(context.TODO())
+	suite.Empty(err.ErrCode)
+	suite.Equal([]string{"Backend", "urgent"}, []string{labels[0].Name, labels[1].Name})
+
+	label, err := suite.repo.GetLabelByName(context.TODO(), "URGENT")
+	suite.Empty(err.ErrCode)
+	suite.Equal(urgentID, label.ID)
+
+	found, err := suite.repo.GetLabelsByIDs(context.TODO(), []string{urgentID, "not-an-id"})
+	suite.Empty(err.ErrCode)
+	suite.Len(found, 1)
+}
+
+// Test the unique index rejects a name that differs only in case
+func (suite *LabelRepositorySuite) TestCreateLabel_Duplicate() {
+	_, err := suite.repo.CreateLabel(context.TODO(), domain.Label{Name: "urgent", Color: "#ff0000"})
+	suite.Empty(err.ErrCode)
+
+	_, err = suite.repo.CreateLabel(context.TODO(), domain.Label{Name: "Urgent", Color: "#aa0000"})
+	suite.Equal(http.StatusConflict, err.ErrCode)
+}
+
+// Test updating and deleting a label
+func (suite *LabelRepositorySuite) TestUpdateAndDeleteLabel() {
+	labelID, err := suite.repo.CreateLabel(context.TODO(), domain.Label{Name: "urgent", Color: "#ff0000"})
+	suite.Empty(err.ErrCode)
+
+	err = suite.repo.UpdateLabel(context.TODO(), domain.Label{ID: labelID, Name: "critical", Color: "#aa0000"})
+	suite.Empty(err.ErrCode)
+
+	label, err := suite.repo.GetLabelByID(context.TODO(), labelID)
+	suite.Empty(err.ErrCode)
+	suite.Equal("critical", label.Name)
+
+	err = suite.repo.DeleteLabel(context.TODO(), labelID)
+	suite.Empty(err.ErrCode)
+
+	_, err = suite.repo.GetLabelByID(context.TODO(), labelID)
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+func TestLabelRepositorySuite(t *testing.T) {
+	suite.Run(t, new(LabelRepositorySuite))
+}
@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type projectRepository struct {
+	collection *mongo.Collection
+}
+
+// NewProjectRepository creates a new project repository instance.
+func NewProjectRepository(db *mongo.Database, projectCollectionString string) domain.ProjectRepository {
+	return &projectRepository{
+		collection: db.Collection(projectCollectionString),
+	}
+}
+
+// CreateProject stores a project and returns its ID.
+func (pr *projectRepository) CreateProject(c context.Context, project domain.Project) (string, domain.CustomError) {
+	project.ID = ""
+	if project.Members == nil {
+		project.Members = []domain.ProjectMember{}
+	}
+	result, err := pr.collection.InsertOne(c, project)
+	if err != nil {
+		return "", domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating project"}
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), domain.CustomError{}
+}
+
+// GetProjects retrieves the projects the user is a member of, ordered by name. An empty member ID
+// retrieves every project.
+func (pr *projectRepository) GetProjects(c context.Context, memberID string) ([]domain.Project, domain.CustomError) {
+	filter := bson.M{}
+	if memberID != "" {
+		filter["members.user_id"] = memberID
+	}
+
+	results, err := pr.collection.Find(c, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving projects"}
+	}
+
+	projects := []domain.Project{}
+	if err := results.All(c, &projects); err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving projects"}
+	}
+	return projects, domain.CustomError{}
+}
+
+// GetProjectByID retrieves a single project.
+func (pr *projectRepository) GetProjectByID(c context.Context, projectID string) (domain.Project, domain.CustomError) {
+	objectID, err := primitive.ObjectIDFromHex(projectID)
+	if err != nil {
+		return domain.Project{}, projectNotFound()
+	}
+
+	var project domain.Project
+	err = pr.collection.FindOne(c, bson.M{"_id": objectID}).Decode(&project)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Project{}, projectNotFound()
+		}
+		return domain.Project{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving project"}
+	}
+	return project, domain.CustomError{}
+}
+
+// UpdateProject stores a project's new name and description. Members are changed through SetMember and RemoveMember.
+func (pr *projectRepository) UpdateProject(c context.Context, project domain.Project) domain.CustomError {
+	update := bson.M{"$set": bson.M{"name": project.Name, "description": project.Description}}
+	return pr.updateProject(c, project.ID, bson.M{}, update, "Error while updating project")
+}
+
+// DeleteProject removes a project.
+func (pr *projectRepository) DeleteProject(c context.Context, projectID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(projectID)
+	if err != nil {
+		return projectNotFound()
+	}
+
+	result, err := pr.collection.DeleteOne(c, bson.M{"_id": objectID})
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while deleting project"}
+	}
+	if result.DeletedCount == 0 {
+		return projectNotFound()
+	}
+	return domain.CustomError{}
+}
+
+// SetMember changes the role of a member, or adds the user to the project if they are not a member yet.
+func (pr *projectRepository) SetMember(c context.Context, projectID string, member domain.ProjectMember) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(projectID)
+	if err != nil {
+		return projectNotFound()
+	}
+
+	result, err := pr.collection.UpdateOne(c,
+		bson.M{"_id": objectID, "members.user_id": member.UserID},
+		bson.M{"$set": bson.M{"members.$.role": member.Role}},
+	)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating project members"}
+	}
+	if result.MatchedCount > 0 {
+		return domain.CustomError{}
+	}
+
+	// Not a member yet. The filter keeps two concurrent calls from adding the user twice.
+	filter := bson.M{"members.user_id": bson.M{"$ne": member.UserID}}
+	return pr.updateProject(c, projectID, filter, bson.M{"$push": bson.M{"members": member}}, "Error while updating project members")
+}
+
+// RemoveMember takes the user out of the project.
+func (pr *projectRepository) RemoveMember(c context.Context, projectID string, userID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(projectID)
+	if err != nil {
+		return projectNotFound()
+	}
+
+	result, err := pr.collection.UpdateOne(c,
+		bson.M{"_id": objectID, "members.user_id": userID},
+		bson.M{"$pull": bson.M{"members": bson.M{"user_id": userID}}},
+	)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating project members"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Member not found"}
+	}
+	return domain.CustomError{}
+}
+
+func (pr *projectRepository) updateProject(c context.Context, projectID string, filter bson.M, update bson.M, errMessage string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(projectID)
+	if err != nil {
+		return projectNotFound()
+	}
+
+	filter["_id"] = objectID
+	result, err := pr.collection.UpdateOne(c, filter, update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: errMessage}
+	}
+	if result.MatchedCount == 0 {
+		return projectNotFound()
+	}
+	return domain.CustomError{}
+}
+
+func projectNotFound() domain.CustomError {
+	return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Project not found"}
+}
@@ -0,0 +1,92 @@
+package repositories_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ProjectRepositorySuite struct {
+	suite.Suite
+	db         *mongo.Database
+	collection *mongo.Collection
+	repo       domain.ProjectRepository
+}
+
+func (suite *ProjectRepositorySuite) SetupTest() {
+	// Clear the collection before each test
+	suite.collection.DeleteMany(context.TODO(), bson.D{})
+}
+
+func (suite *ProjectRepositorySuite) SetupSuite() {
+	// Set up a test MongoDB instance
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	suite.Require().NoError(err)
+
+	suite.db = client.Database("task_management_test")
+	suite.collection = suite.db.Collection("projects")
+
+	suite.repo = repositories.NewProjectRepository(suite.db, "projects")
+}
+
+// Test GetProjects only lists the projects a user is a member of
+func (suite *ProjectRepositorySuite) TestGetProjects() {
+	_, err := suite.repo.CreateProject(context.TODO(), domain.Project{Name: "Website", Members: []domain.ProjectMember{{UserID: "user-1", Role: domain.ProjectRoleOwner}}})
+	suite.Empty(err.ErrCode)
+	_, err = suite.repo.CreateProject(context.TODO(), domain.Project{Name: "App", Members: []domain.ProjectMember{{UserID: "user-2", Role: domain.ProjectRoleOwner}}})
+	suite.Empty(err.ErrCode)
+
+	projects, err := suite.repo.GetProjects(context.TODO(), "user-1")
+	suite.Empty(err.ErrCode)
+	suite.Len(projects, 1)
+	suite.Equal("Website", projects[0].Name)
+
+	projects, err = suite.repo.GetProjects(context.TODO(), "")
+	suite.Empty(err.ErrCode)
+	suite.Len(projects, 2)
+}
+
+// Test SetMember adds a member once and then changes their role, and RemoveMember takes them out
+func (suite *ProjectRepositorySuite) TestMembers() {
+	projectID, err := suite.repo.CreateProject(context.TODO(), domain.Project{Name: "Website", Members: []domain.ProjectMember{{UserID: "user-1", Role: domain.ProjectRoleOwner}}})
+	suite.Empty(err.ErrCode)
+
+	err = suite.repo.SetMember(context.TODO(), projectID, domain.ProjectMember{UserID: "user-2", Role: domain.ProjectRoleViewer})
+	suite.Empty(err.ErrCode)
+	err = suite.repo.SetMember(context.TODO(), projectID, domain.ProjectMember{UserID: "user-2", Role: domain.ProjectRoleMember})
+	suite.Empty(err.ErrCode)
+
+	project, err := suite.repo.GetProjectByID(context.TODO(), projectID)
+	suite.Empty(err.ErrCode)
+	suite.Len(project.Members, 2)
+	suite.Equal(domain.ProjectRoleMember, project.RoleOf("user-2"))
+
+	err = suite.repo.RemoveMember(context.TODO(), projectID, "user-2")
+	suite.Empty(err.ErrCode)
+	err = suite.repo.RemoveMember(context.TODO(), projectID, "user-2")
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+// Test DeleteProject removes the project
+func (suite *ProjectRepositorySuite) TestDeleteProject() {
+	projectID, err := suite.repo.CreateProject(context.TODO(), domain.Project{Name: "Website"})
+	suite.Empty(err.ErrCode)
+
+	err = suite.repo.DeleteProject(context.TODO(), projectID)
+	suite.Empty(err.ErrCode)
+
+	_, err = suite.repo.GetProjectByID(context.TODO(), projectID)
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+func TestProjectRepositorySuite(t *testing.T) {
+	suite.Run(t, new(ProjectRepositorySuite))
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type refreshTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRefreshTokenRepository creates a new repository for refresh tokens. The collection needs a unique
+// index on token_hash, an index on family_id, and a TTL index on expires_at that removes expired tokens.
+func NewRefreshTokenRepository(db *mongo.Database, refreshTokenCollectionString string) domain.RefreshTokenRepository {
+	return &refreshTokenRepository{
+		collection: db.Collection(refreshTokenCollectionString),
+	}
+}
+
+func (rr *refreshTokenRepository) CreateRefreshToken(c context.Context, token domain.RefreshToken) domain.CustomError {
+	token.ID = ""
+	if _, err := rr.collection.InsertOne(c, token); err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating refresh token"}
+	}
+	return domain.CustomError{}
+}
+
+func (rr *refreshTokenRepository) GetRefreshTokenByHash(c context.Context, tokenHash string) (domain.RefreshToken, domain.CustomError) {
+	var token domain.RefreshToken
+	err := rr.collection.FindOne(c, bson.M{"token_hash": tokenHash}).Decode(&token)
+	if err == mongo.ErrNoDocuments {
+		return domain.RefreshToken{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Refresh token not found"}
+	}
+	if err != nil {
+		return domain.RefreshToken{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving refresh token"}
+	}
+	return token, domain.CustomError{}
+}
+
+// RotateRefreshToken marks the token as exchanged, unless it already was or has been revoked.
+func (rr *refreshTokenRepository) RotateRefreshToken(c context.Context, tokenID string, at time.Time) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(tokenID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Refresh token not found"}
+	}
+	filter := bson.M{"_id": objectID, "rotated_at": bson.M{"$exists": false}, "revoked_at": bson.M{"$exists": false}}
+	result, err := rr.collection.UpdateOne(c, filter, bson.M{"$set": bson.M{"rotated_at": at}})
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while rotating refresh token"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "Refresh token already used"}
+	}
+	return domain.CustomError{}
+}
+
+// RevokeRefreshTokenFamily revokes every token of a session. Revoking a session twice is not an error.
+func (rr *refreshTokenRepository) RevokeRefreshTokenFamily(c context.Context, familyID string, at time.Time) domain.CustomError {
+	filter := bson.M{"family_id": familyID, "revoked_at": bson.M{"$exists": false}}
+	if _, err := rr.collection.UpdateMany(c, filter, bson.M{"$set": bson.M{"revoked_at": at}}); err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while revoking session"}
+	}
+	return domain.CustomError{}
+}
@@ -0,0 +1,83 @@
+package repositories_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RefreshTokenRepositorySuite struct {
+	suite.Suite
+	db         *mongo.Database
+	collection *mongo.Collection
+	repo       domain.RefreshTokenRepository
+}
+
+func (suite *RefreshTokenRepositorySuite) SetupTest() {
+	// Clear the collection before each test
+	suite.collection.DeleteMany(context.TODO(), bson.D{})
+}
+
+func (suite *RefreshTokenRepositorySuite) SetupSuite() {
+	// Set up a test MongoDB instance
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	suite.Require().NoError(err)
+
+	suite.db = client.Database("task_management_test")
+	suite.collection = suite.db.Collection("refresh_tokens")
+
+	suite.repo = repositories.NewRefreshTokenRepository(suite.db, "refresh_tokens")
+}
+
+func (suite *RefreshTokenRepositorySuite) createToken(familyID string, hash string) domain.RefreshToken {
+	now := time.Now().UTC()
+	token := domain.RefreshToken{FamilyID: familyID, UserID: "user-1", TokenHash: hash, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	suite.Require().Empty(suite.repo.CreateRefreshToken(context.TODO(), token).ErrCode)
+	stored, err := suite.repo.GetRefreshTokenByHash(context.TODO(), hash)
+	suite.Require().Empty(err.ErrCode)
+	return stored
+}
+
+// Test a token can be rotated only once
+func (suite *RefreshTokenRepositorySuite) TestRotateRefreshToken() {
+	token := suite.createToken("family-1", "hash-1")
+
+	suite.Empty(suite.repo.RotateRefreshToken(context.TODO(), token.ID, time.Now().UTC()).ErrCode)
+	suite.Equal(http.StatusConflict, suite.repo.RotateRefreshToken(context.TODO(), token.ID, time.Now().UTC()).ErrCode)
+
+	stored, _ := suite.repo.GetRefreshTokenByHash(context.TODO(), "hash-1")
+	suite.NotNil(stored.RotatedAt)
+}
+
+// Test revoking a family revokes all of its tokens and no others
+func (suite *RefreshTokenRepositorySuite) TestRevokeRefreshTokenFamily() {
+	suite.createToken("family-1", "hash-1")
+	second := suite.createToken("family-1", "hash-2")
+	suite.createToken("family-2", "hash-3")
+
+	suite.Empty(suite.repo.RevokeRefreshTokenFamily(context.TODO(), "family-1", time.Now().UTC()).ErrCode)
+	suite.Empty(suite.repo.RevokeRefreshTokenFamily(context.TODO(), "family-1", time.Now().UTC()).ErrCode)
+
+	revoked, _ := suite.collection.CountDocuments(context.TODO(), bson.M{"revoked_at": bson.M{"$exists": true}})
+	suite.Equal(int64(2), revoked)
+	suite.Equal(http.StatusConflict, suite.repo.RotateRefreshToken(context.TODO(), second.ID, time.Now().UTC()).ErrCode)
+}
+
+// Test an unknown token is not found
+func (suite *RefreshTokenRepositorySuite) TestGetRefreshTokenByHashNotFound() {
+	_, err := suite.repo.GetRefreshTokenByHash(context.TODO(), "missing")
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+func TestRefreshTokenRepositorySuite(t *testing.T) {
+	suite.Run(t, new(RefreshTokenRepositorySuite))
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type reminderRepository struct {
+	collection *mongo.Collection
+}
+
+// NewReminderRepository creates a new repository for the reminders that were sent. The collection needs
+// a unique index on task_id, user_id and due_date.
+func NewReminderRepository(db *mongo.Database, reminderCollectionString string) domain.ReminderRepository {
+	return &reminderRepository{
+		collection: db.Collection(reminderCollectionString),
+	}
+}
+
+// RecordReminder stores a reminder before it is sent. It reports false when the reminder was already
+// recorded, which means it was sent before.
+func (rr *reminderRepository) RecordReminder(c context.Context, reminder domain.Reminder) (bool, domain.CustomError) {
+	reminder.ID = ""
+	_, err := rr.collection.InsertOne(c, reminder)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, domain.CustomError{}
+		}
+		return false, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while recording reminder"}
+	}
+	return true, domain.CustomError{}
+}
+
+// DeleteReminder forgets a reminder that could not be sent, so that it is tried again.
+func (rr *reminderRepository) DeleteReminder(c context.Context, reminder domain.Reminder) domain.CustomError {
+	filter := bson.M{"task_id": reminder.TaskID, "user_id": reminder.UserID, "due_date": reminder.DueDate}
+	_, err := rr.collection.DeleteOne(c, filter)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while deleting reminder"}
+	}
+	return domain.CustomError{}
+}
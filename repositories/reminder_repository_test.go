@@ -0,0 +1,81 @@
+package repositories_test
+
+import (
+	"context"
+	"task_managment_api/domain"
+	"task_managment_api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReminderRepositorySuite struct {
+	suite.Suite
+	db         *mongo.Database
+	collection *mongo.Collection
+	repo       domain.ReminderRepository
+}
+
+func (suite *ReminderRepositorySuite) SetupTest() {
+	// Clear the collection before each test
+	suite.collection.DeleteMany(context.TODO(), bson.D{})
+}
+
+func (suite *ReminderRepositorySuite) SetupSuite() {
+	// Set up a test MongoDB instance
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	suite.Require().NoError(err)
+
+	suite.db = client.Database("task_management_test")
+	suite.collection = suite.db.Collection("reminders")
+	_, err = suite.collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "task_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "due_date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	suite.Require().NoError(err)
+
+	suite.repo = repositories.NewReminderRepository(suite.db, "reminders")
+}
+
+// Test a reminder is only recorded once per task, user and due date
+func (suite *ReminderRepositorySuite) TestRecordReminder() {
+	dueDate := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	reminder := domain.Reminder{TaskID: "task-1", UserID: "user-1", DueDate: dueDate, SentAt: time.Now().UTC()}
+
+	recorded, err := suite.repo.RecordReminder(context.TODO(), reminder)
+	suite.Empty(err.ErrCode)
+	suite.True(recorded)
+
+	recorded, err = suite.repo.RecordReminder(context.TODO(), reminder)
+	suite.Empty(err.ErrCode)
+	suite.False(recorded)
+
+	moved := reminder
+	moved.DueDate = dueDate.Add(24 * time.Hour)
+	recorded, err = suite.repo.RecordReminder(context.TODO(), moved)
+	suite.Empty(err.ErrCode)
+	suite.True(recorded)
+}
+
+// Test a deleted reminder can be recorded again
+func (suite *ReminderRepositorySuite) TestDeleteReminder() {
+	reminder := domain.Reminder{TaskID: "task-1", UserID: "user-1", DueDate: time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)}
+	_, err := suite.repo.RecordReminder(context.TODO(), reminder)
+	suite.Empty(err.ErrCode)
+
+	err = suite.repo.DeleteReminder(context.TODO(), reminder)
+	suite.Empty(err.ErrCode)
+
+	recorded, err := suite.repo.RecordReminder(context.TODO(), reminder)
+	suite.Empty(err.ErrCode)
+	suite.True(recorded)
+}
+
+func TestReminderRepositorySuite(t *testing.T) {
+	suite.Run(t, new(ReminderRepositorySuite))
+}
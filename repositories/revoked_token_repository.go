@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type revokedTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRevokedTokenRepository creates a new repository for revoked access tokens. The collection needs a
+// TTL index on expires_at, which forgets revocations once the tokens have expired anyway.
+func NewRevokedTokenRepository(db *mongo.Database, revokedTokenCollectionString string) domain.RevokedTokenRepository {
+	return &revokedTokenRepository{
+		collection: db.Collection(revokedTokenCollectionString),
+	}
+}
+
+// RevokeToken records a revoked token. Revoking a token twice is not an error.
+func (rr *revokedTokenRepository) RevokeToken(c context.Context, token domain.RevokedToken) domain.CustomError {
+	_, err := rr.collection.ReplaceOne(c, bson.M{"_id": token.ID}, token, options.Replace().SetUpsert(true))
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while revoking token"}
+	}
+	return domain.CustomError{}
+}
+
+func (rr *revokedTokenRepository) IsTokenRevoked(c context.Context, tokenID string) (bool, domain.CustomError) {
+	count, err := rr.collection.CountDocuments(c, bson.M{"_id": tokenID}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while checking token revocation"}
+	}
+	return count > 0, domain.CustomError{}
+}
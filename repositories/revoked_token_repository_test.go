@@ -0,0 +1,59 @@
+package repositories_test
+
+import (
+	"context"
+	"task_managment_api/domain"
+	"task_managment_api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RevokedTokenRepositorySuite struct {
+	suite.Suite
+	db         *mongo.Database
+	collection *mongo.Collection
+	repo       domain.RevokedTokenRepository
+}
+
+func (suite *RevokedTokenRepositorySuite) SetupTest() {
+	// Clear the collection before each test
+	suite.collection.DeleteMany(context.TODO(), bson.D{})
+}
+
+func (suite *RevokedTokenRepositorySuite) SetupSuite() {
+	// Set up a test MongoDB instance
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	suite.Require().NoError(err)
+
+	suite.db = client.Database("task_management_test")
+	suite.collection = suite.db.Collection("revoked_tokens")
+
+	suite.repo = repositories.NewRevokedTokenRepository(suite.db, "revoked_tokens")
+}
+
+// Test a revoked token is reported as revoked, and revoking it again is fine
+func (suite *RevokedTokenRepositorySuite) TestRevokeToken() {
+	now := time.Now().UTC()
+	token := domain.RevokedToken{ID: "jti-1", UserID: "user-1", RevokedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	suite.Empty(suite.repo.RevokeToken(context.TODO(), token).ErrCode)
+	suite.Empty(suite.repo.RevokeToken(context.TODO(), token).ErrCode)
+
+	revoked, err := suite.repo.IsTokenRevoked(context.TODO(), "jti-1")
+	suite.Empty(err.ErrCode)
+	suite.True(revoked)
+
+	revoked, err = suite.repo.IsTokenRevoked(context.TODO(), "jti-2")
+	suite.Empty(err.ErrCode)
+	suite.False(revoked)
+}
+
+func TestRevokedTokenRepositorySuite(t *testing.T) {
+	suite.Run(t, new(RevokedTokenRepositorySuite))
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type roleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRoleRepository creates a new role repository instance. Roles are keyed by their name.
+func NewRoleRepository(db *mongo.Database, roleCollectionString string) domain.RoleRepository {
+	return &roleRepository{
+		collection: db.Collection(roleCollectionString),
+	}
+}
+
+// CreateRole stores a new role.
+func (rr *roleRepository) CreateRole(c context.Context, role domain.Role) domain.CustomError {
+	if _, err := rr.collection.InsertOne(c, role); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "A role with this name already exists", Field: "name"}
+		}
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating role"}
+	}
+	return domain.CustomError{}
+}
+
+// EnsureRole inserts the role unless it exists, so that changes made to it by admins are kept.
+func (rr *roleRepository) EnsureRole(c context.Context, role domain.Role) domain.CustomError {
+	update := bson.M{"$setOnInsert": role}
+	if _, err := rr.collection.UpdateOne(c, bson.M{"_id": role.Name}, update, options.Update().SetUpsert(true)); err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating role"}
+	}
+	return domain.CustomError{}
+}
+
+// GetRoles retrieves every role, ordered by name.
+func (rr *roleRepository) GetRoles(c context.Context) ([]domain.Role, domain.CustomError) {
+	results, err := rr.collection.Find(c, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving roles"}
+	}
+
+	roles := []domain.Role{}
+	if err := results.All(c, &roles); err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving roles"}
+	}
+	return roles, domain.CustomError{}
+}
+
+// GetRoleByName retrieves a single role.
+func (rr *roleRepository) GetRoleByName(c context.Context, name string) (domain.Role, domain.CustomError) {
+	var role domain.Role
+	err := rr.collection.FindOne(c, bson.M{"_id": name}).Decode(&role)
+	if err == mongo.ErrNoDocuments {
+		return domain.Role{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Role not found"}
+	}
+	if err != nil {
+		return domain.Role{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving role"}
+	}
+	return role, domain.CustomError{}
+}
+
+// UpdateRole stores a role's new description and permissions.
+func (rr *roleRepository) UpdateRole(c context.Context, role domain.Role) domain.CustomError {
+	update := bson.M{"$set": bson.M{"description": role.Description, "permissions": role.Permissions, "updated_at": role.UpdatedAt}}
+	result, err := rr.collection.UpdateOne(c, bson.M{"_id": role.Name}, update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating role"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Role not found"}
+	}
+	return domain.CustomError{}
+}
+
+// DeleteRole removes a role.
+func (rr *roleRepository) DeleteRole(c context.Context, name string) domain.CustomError {
+	result, err := rr.collection.DeleteOne(c, bson.M{"_id": name})
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while deleting role"}
+	}
+	if result.DeletedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Role not found"}
+	}
+	return domain.CustomError{}
+}
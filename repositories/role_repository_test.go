@@ -0,0 +1,68 @@
+package repositories_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RoleRepositorySuite struct {
+	suite.Suite
+	db         *mongo.Database
+	collection *mongo.Collection
+	repo       domain.RoleRepository
+}
+
+func (suite *RoleRepositorySuite) SetupTest() {
+	// Clear the collection before each test
+	suite.collection.DeleteMany(context.TODO(), bson.D{})
+}
+
+func (suite *RoleRepositorySuite) SetupSuite() {
+	// Set up a test MongoDB instance
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	suite.Require().NoError(err)
+
+	suite.db = client.Database("task_management_test")
+	suite.collection = suite.db.Collection("roles")
+
+	suite.repo = repositories.NewRoleRepository(suite.db, "roles")
+}
+
+// Test a role name can be taken only once
+func (suite *RoleRepositorySuite) TestCreateRoleDuplicate() {
+	role := domain.Role{Name: "viewer", Permissions: []domain.Permission{}}
+	suite.Empty(suite.repo.CreateRole(context.TODO(), role).ErrCode)
+	suite.Equal(http.StatusConflict, suite.repo.CreateRole(context.TODO(), role).ErrCode)
+}
+
+// Test ensuring a role keeps the changes made to an existing one
+func (suite *RoleRepositorySuite) TestEnsureRoleKeepsExisting() {
+	suite.Require().Empty(suite.repo.EnsureRole(context.TODO(), domain.Role{Name: "user", Permissions: []domain.Permission{domain.PermissionTaskCreate}}).ErrCode)
+	suite.Require().Empty(suite.repo.UpdateRole(context.TODO(), domain.Role{Name: "user", Permissions: []domain.Permission{}}).ErrCode)
+	suite.Require().Empty(suite.repo.EnsureRole(context.TODO(), domain.Role{Name: "user", Permissions: []domain.Permission{domain.PermissionTaskCreate}}).ErrCode)
+
+	role, err := suite.repo.GetRoleByName(context.TODO(), "user")
+	suite.Empty(err.ErrCode)
+	suite.Empty(role.Permissions)
+}
+
+// Test updating and deleting a missing role
+func (suite *RoleRepositorySuite) TestMissingRole() {
+	_, err := suite.repo.GetRoleByName(context.TODO(), "missing")
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+	suite.Equal(http.StatusNotFound, suite.repo.UpdateRole(context.TODO(), domain.Role{Name: "missing"}).ErrCode)
+	suite.Equal(http.StatusNotFound, suite.repo.DeleteRole(context.TODO(), "missing").ErrCode)
+}
+
+func TestRoleRepositorySuite(t *testing.T) {
+	suite.Run(t, new(RoleRepositorySuite))
+}
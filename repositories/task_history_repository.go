@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type taskHistoryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTaskHistoryRepository creates a new task history repository instance.
+func NewTaskHistoryRepository(db *mongo.Database, historyCollectionString string) domain.TaskHistoryRepository {
+	return &taskHistoryRepository{
+		collection: db.Collection(historyCollectionString),
+	}
+}
+
+// AddEntry stores a history entry.
+func (hs *taskHistoryRepository) AddEntry(c context.Context, entry domain.TaskHistoryEntry) domain.CustomError {
+	_, err := hs.collection.InsertOne(c, entry)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while recording task history"}
+	}
+	return domain.CustomError{}
+}
+
+// GetEntries retrieves one page of a task's history, newest first. The cursor is the ID of the
+// last entry of the previous page.
+func (hs *taskHistoryRepository) GetEntries(c context.Context, taskID string, cursor string, limit int64) (domain.TaskHistoryPage, domain.CustomError) {
+	filter := bson.M{"task_id": taskID}
+	total, err := hs.collection.CountDocuments(c, filter)
+	if err != nil {
+		return domain.TaskHistoryPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while counting task history"}
+	}
+
+	if cursor != "" {
+		after, err := primitive.ObjectIDFromHex(cursor)
+		if err != nil {
+			return domain.TaskHistoryPage{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid cursor"}
+		}
+		filter["_id"] = bson.M{"$lt": after}
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit + 1)
+	results, err := hs.collection.Find(c, filter, findOptions)
+	if err != nil {
+		return domain.TaskHistoryPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving task history"}
+	}
+
+	entries := []domain.TaskHistoryEntry{}
+	if err := results.All(c, &entries); err != nil {
+		return domain.TaskHistoryPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving task history"}
+	}
+
+	page := domain.TaskHistoryPage{Entries: entries, Total: total}
+	if int64(len(entries)) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = page.Entries[len(page.Entries)-1].ID
+	}
+	return page, domain.CustomError{}
+}
+
+// GetDeletionEntry retrieves the entry recorded when the task was deleted.
+func (hs *taskHistoryRepository) GetDeletionEntry(c context.Context, taskID string) (domain.TaskHistoryEntry, domain.CustomError) {
+	var entry domain.TaskHistoryEntry
+	filter := bson.M{"task_id": taskID, "action": domain.HistoryActionDeleted}
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	err := hs.collection.FindOne(c, filter, findOptions).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.TaskHistoryEntry{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"}
+		}
+		return domain.TaskHistoryEntry{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving task history"}
+	}
+	return entry, domain.CustomError{}
+}
@@ -0,0 +1,80 @@
+package repositories_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TaskHistoryRepositorySuite struct {
+	suite.Suite
+	db         *mongo.Database
+	collection *mongo.Collection
+	repo       domain.TaskHistoryRepository
+}
+
+func (suite *TaskHistoryRepositorySuite) SetupTest() {
+	// Clear the collection before each test
+	suite.collection.DeleteMany(context.TODO(), bson.D{})
+}
+
+func (suite *TaskHistoryRepositorySuite) SetupSuite() {
+	// Set up a test MongoDB instance
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	suite.Require().NoError(err)
+
+	suite.db = client.Database("task_management_test")
+	suite.collection = suite.db.Collection("task_history")
+
+	suite.repo = repositories.NewTaskHistoryRepository(suite.db, "task_history")
+}
+
+// Test GetEntries pages through a task's history newest first
+func (suite *TaskHistoryRepositorySuite) TestGetEntries() {
+	for _, action := range []string{domain.HistoryActionCreated, domain.HistoryActionUpdated, domain.HistoryActionDeleted} {
+		err := suite.repo.AddEntry(context.TODO(), domain.TaskHistoryEntry{TaskID: "task-1", Action: action, At: time.Now().UTC()})
+		suite.Empty(err.ErrCode)
+	}
+	err := suite.repo.AddEntry(context.TODO(), domain.TaskHistoryEntry{TaskID: "task-2", Action: domain.HistoryActionCreated})
+	suite.Empty(err.ErrCode)
+
+	first, err := suite.repo.GetEntries(context.TODO(), "task-1", "", 2)
+	suite.Empty(err.ErrCode)
+	suite.Equal(int64(3), first.Total)
+	suite.Len(first.Entries, 2)
+	suite.Equal(domain.HistoryActionDeleted, first.Entries[0].Action)
+	suite.NotEmpty(first.NextCursor)
+
+	second, err := suite.repo.GetEntries(context.TODO(), "task-1", first.NextCursor, 2)
+	suite.Empty(err.ErrCode)
+	suite.Len(second.Entries, 1)
+	suite.Equal(domain.HistoryActionCreated, second.Entries[0].Action)
+	suite.Empty(second.NextCursor)
+}
+
+// Test GetDeletionEntry returns the snapshot of a deleted task
+func (suite *TaskHistoryRepositorySuite) TestGetDeletionEntry() {
+	snapshot := domain.Task{Title: "Deleted Task", CreatedBy: "user-1"}
+	err := suite.repo.AddEntry(context.TODO(), domain.TaskHistoryEntry{TaskID: "task-1", Action: domain.HistoryActionDeleted, Snapshot: &snapshot})
+	suite.Empty(err.ErrCode)
+
+	entry, err := suite.repo.GetDeletionEntry(context.TODO(), "task-1")
+	suite.Empty(err.ErrCode)
+	suite.Equal("Deleted Task", entry.Snapshot.Title)
+
+	_, err = suite.repo.GetDeletionEntry(context.TODO(), "task-2")
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+func TestTaskHistoryRepositorySuite(t *testing.T) {
+	suite.Run(t, new(TaskHistoryRepositorySuite))
+}
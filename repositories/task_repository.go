@@ -2,13 +2,17 @@ package repositories
 
 import (
 	"context"
+	"encoding/base64"
 	//"errors"
 	"net/http"
+	"regexp"
 	"task_managment_api/domain"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type taskRepository struct {
@@ -21,21 +25,270 @@ func NewTaskRepository(db *mongo.Database, taskCollectionString string) domain.T
 	}
 }
 
-// GetTasks retrieves all tasks from the database.
-func (ts *taskRepository) GetTasks(c context.Context) ([]domain.Task, domain.CustomError) {
-	var tasks []domain.Task
-	cursor, err := ts.collection.Find(c, bson.D{})
+// GetTasks retrieves one page of tasks matching the query, ordered by the requested field.
+// Pages are chained with an opaque cursor holding the sort value and ID of the last task returned.
+func (ts *taskRepository) GetTasks(c context.Context, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	filter := buildTaskFilter(query)
+
+	total, err := ts.collection.CountDocuments(c, filter)
+	if err != nil {
+		return domain.TaskPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while counting tasks"}
+	}
+
+	sortField := domain.TaskSortFields[query.SortBy]
+	if sortField == "" {
+		sortField = "_id"
+	}
+	direction := 1
+	if query.SortOrder == "desc" {
+		direction = -1
+	}
+
+	if query.Cursor != "" {
+		after, cursorErr := decodeTaskCursor(query.Cursor)
+		if cursorErr.ErrCode != 0 {
+			return domain.TaskPage{}, cursorErr
+		}
+		filter = bson.M{"$and": []bson.M{filter, buildCursorFilter(after, sortField, direction)}}
+	}
+
+	sort := bson.D{{Key: sortField, Value: direction}}
+	if sortField != "_id" {
+		sort = append(sort, bson.E{Key: "_id", Value: direction})
+	}
+	// fetch one extra task to find out whether there is a next page
+	findOptions := options.Find().SetSort(sort).SetLimit(query.Limit + 1)
+
+	cursor, err := ts.collection.Find(c, filter, findOptions)
 	if err != nil {
-		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: err.Error()}
+		return domain.TaskPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: err.Error()}
 	}
 
+	tasks := []domain.Task{}
 	if err := cursor.All(c, &tasks); err != nil {
-		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: err.Error()}
+		return domain.TaskPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: err.Error()}
 	}
-	return tasks, domain.CustomError{}
+
+	page := domain.TaskPage{Tasks: tasks, Total: total}
+	if int64(len(tasks)) > query.Limit {
+		page.Tasks = tasks[:query.Limit]
+		last := page.Tasks[len(page.Tasks)-1]
+		nextCursor, cursorErr := encodeTaskCursor(last, sortField)
+		if cursorErr.ErrCode != 0 {
+			return domain.TaskPage{}, cursorErr
+		}
+		page.NextCursor = nextCursor
+	}
+	return page, domain.CustomError{}
+}
+
+// SearchTasks retrieves one page of the tasks whose title or description match the search text, most
+// relevant first. It needs the text index on title and description. Pages are chained with a cursor
+// holding the score and ID of the last task returned.
+func (ts *taskRepository) SearchTasks(c context.Context, query domain.TaskQuery) (domain.TaskSearchPage, domain.CustomError) {
+	filter := buildTaskFilter(query)
+
+	total, err := ts.collection.CountDocuments(c, filter)
+	if err != nil {
+		return domain.TaskSearchPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while counting tasks"}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+	}
+	if query.Cursor != "" {
+		after, cursorErr := decodeTaskCursor(query.Cursor)
+		if cursorErr.ErrCode != 0 {
+			return domain.TaskSearchPage{}, cursorErr
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": []bson.M{
+			{"score": bson.M{"$lt": after.Value}},
+			{"score": after.Value, "_id": bson.M{"$gt": after.ID}},
+		}}}})
+	}
+	// fetch one extra task to find out whether there is a next page
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: 1}}}},
+		bson.D{{Key: "$limit", Value: query.Limit + 1}},
+	)
+
+	cursor, err := ts.collection.Aggregate(c, pipeline)
+	if err != nil {
+		return domain.TaskSearchPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while searching tasks"}
+	}
+
+	var matches []struct {
+		domain.Task `bson:",inline"`
+		Score       float64 `bson:"score"`
+	}
+	if err := cursor.All(c, &matches); err != nil {
+		return domain.TaskSearchPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while searching tasks"}
+	}
+
+	page := domain.TaskSearchPage{Results: []domain.TaskSearchResult{}, Total: total}
+	for _, match := range matches {
+		page.Results = append(page.Results, domain.TaskSearchResult{Task: match.Task, Score: match.Score})
+	}
+	if int64(len(page.Results)) > query.Limit {
+		page.Results = page.Results[:query.Limit]
+		last := page.Results[len(page.Results)-1]
+		nextCursor, cursorErr := encodeCursor(last.Score, last.Task.ID)
+		if cursorErr.ErrCode != 0 {
+			return domain.TaskSearchPage{}, cursorErr
+		}
+		page.NextCursor = nextCursor
+	}
+	return page, domain.CustomError{}
+}
+
+// buildTaskFilter translates the query filters into a MongoDB filter document.
+func buildTaskFilter(query domain.TaskQuery) bson.M {
+	conditions := []bson.M{}
+
+	if query.Deleted {
+		conditions = append(conditions, bson.M{"deleted_at": bson.M{"$ne": nil}})
+	} else {
+		conditions = append(conditions, bson.M{"deleted_at": nil})
+	}
+
+	if query.VisibleTo != "" {
+		projectIDs := query.VisibleProjectIDs
+		if projectIDs == nil {
+			projectIDs = []string{}
+		}
+		conditions = append(conditions, bson.M{"$or": []bson.M{
+			{"project_id": bson.M{"$in": projectIDs}},
+			{"project_id": nil, "$or": []bson.M{
+				{"created_by": query.VisibleTo},
+				{"assignee_ids": query.VisibleTo},
+			}},
+		}})
+	}
+	if query.ProjectID != "" {
+		conditions = append(conditions, bson.M{"project_id": query.ProjectID})
+	}
+	if query.Search != "" {
+		conditions = append(conditions, bson.M{"$text": bson.M{"$search": query.Search}})
+	}
+	if query.Status != "" {
+		conditions = append(conditions, bson.M{"status": query.Status})
+	}
+	if query.AssigneeID != "" {
+		conditions = append(conditions, bson.M{"assignee_ids": query.AssigneeID})
+	}
+	if query.ParentID != "" {
+		conditions = append(conditions, bson.M{"parent_id": query.ParentID})
+	}
+	if len(query.LabelIDs) > 0 {
+		operator := "$in"
+		if query.LabelMatch == domain.LabelMatchAll {
+			operator = "$all"
+		}
+		conditions = append(conditions, bson.M{"label_ids": bson.M{operator: query.LabelIDs}})
+	}
+	if query.Title != "" {
+		conditions = append(conditions, bson.M{"title": primitive.Regex{Pattern: regexp.QuoteMeta(query.Title), Options: "i"}})
+	}
+	if query.OpenOnly {
+		conditions = append(conditions, bson.M{"status": bson.M{"$ne": domain.StatusDone}})
+	}
+	if query.DueFrom != nil || query.DueTo != nil {
+		dueRange := bson.M{}
+		if query.DueFrom != nil {
+			dueRange["$gte"] = *query.DueFrom
+		}
+		if query.DueTo != nil {
+			dueRange["$lte"] = *query.DueTo
+		}
+		conditions = append(conditions, bson.M{"due_date": dueRange})
+	}
+
+	return bson.M{"$and": conditions}
+}
+
+// activeTaskFilter matches a task that is not in the trash. Tasks stored before soft delete have no deleted_at field.
+func activeTaskFilter(objectID primitive.ObjectID) bson.M {
+	return bson.M{"_id": objectID, "deleted_at": nil}
+}
+
+// deletedTaskFilter matches a task that is in the trash.
+func deletedTaskFilter(objectID primitive.ObjectID) bson.M {
+	return bson.M{"_id": objectID, "deleted_at": bson.M{"$ne": nil}}
+}
+
+// buildCursorFilter selects the tasks that sort after the cursor position. MongoDB sorts
+// missing values (tasks without a due date) before every other value, so they need their own branch.
+func buildCursorFilter(after taskCursor, sortField string, direction int) bson.M {
+	comparison := "$gt"
+	if direction < 0 {
+		comparison = "$lt"
+	}
+	if sortField == "_id" {
+		return bson.M{"_id": bson.M{comparison: after.ID}}
+	}
+
+	sameValue := bson.M{sortField: after.Value, "_id": bson.M{comparison: after.ID}}
+	if after.Value == nil {
+		if direction < 0 {
+			return sameValue
+		}
+		return bson.M{"$or": []bson.M{sameValue, {sortField: bson.M{"$ne": nil}}}}
+	}
+
+	branches := []bson.M{{sortField: bson.M{comparison: after.Value}}, sameValue}
+	if direction < 0 {
+		branches = append(branches, bson.M{sortField: nil})
+	}
+	return bson.M{"$or": branches}
+}
+
+type taskCursor struct {
+	Value interface{}        `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
 }
 
-// GetTaskByID retrieves a task from the database by its ID.
+func encodeTaskCursor(task domain.Task, sortField string) (string, domain.CustomError) {
+	var value interface{}
+	switch sortField {
+	case "title":
+		value = task.Title
+	case "due_date":
+		if task.DueDate != nil {
+			value = *task.DueDate
+		}
+	case "status":
+		value = task.Status
+	}
+	return encodeCursor(value, task.ID)
+}
+
+func encodeCursor(value interface{}, taskID string) (string, domain.CustomError) {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return "", domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while building cursor"}
+	}
+
+	raw, err := bson.Marshal(taskCursor{Value: value, ID: objectID})
+	if err != nil {
+		return "", domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while building cursor"}
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), domain.CustomError{}
+}
+
+func decodeTaskCursor(encoded string) (taskCursor, domain.CustomError) {
+	var cursor taskCursor
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return taskCursor{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid cursor"}
+	}
+	if err := bson.Unmarshal(raw, &cursor); err != nil {
+		return taskCursor{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid cursor"}
+	}
+	return cursor, domain.CustomError{}
+}
+
+// GetTaskByID retrieves a task from the database by its ID. Tasks in the trash are not found.
 func (ts *taskRepository) GetTaskByID(c context.Context, taskID string) (domain.Task, domain.CustomError) {
 	var task domain.Task
 	objectID, err := primitive.ObjectIDFromHex(taskID)
@@ -43,7 +296,7 @@ func (ts *taskRepository) GetTaskByID(c context.Context, taskID string) (domain.
 		return domain.Task{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Invalid task ID"}
 	}
 
-	err = ts.collection.FindOne(c, bson.M{"_id": objectID}).Decode(&task)
+	err = ts.collection.FindOne(c, activeTaskFilter(objectID)).Decode(&task)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return domain.Task{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"}
@@ -54,60 +307,481 @@ func (ts *taskRepository) GetTaskByID(c context.Context, taskID string) (domain.
 	return task, domain.CustomError{}
 }
 
-// CreateTask creates a new task in the database.
-func (ts *taskRepository) CreateTask(c context.Context, task domain.Task) domain.CustomError {
-	_, err := ts.collection.InsertOne(c, task)
+// CreateTask creates a new task in the database and returns its ID.
+func (ts *taskRepository) CreateTask(c context.Context, task domain.Task) (string, domain.CustomError) {
+	result, err := ts.collection.InsertOne(c, task)
 	if err != nil {
-		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating task"}
+		return "", domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating task"}
 	}
-	return domain.CustomError{}
+	return result.InsertedID.(primitive.ObjectID).Hex(), domain.CustomError{}
 }
 
-// UpdateTaskByID updates a task in the database by its ID.
-func (ts *taskRepository) UpdateTaskByID(c context.Context, updatedTask domain.Task) domain.CustomError {
+// UpdateTaskByID replaces a task's title, description, due date, parent and recurrence, clearing the ones that are empty.
+// When a transition is given the task also moves to its status and the transition is recorded, in the same update.
+// updatedTask.Version must be the version the caller read; the update only applies while the stored version is
+// unchanged and then increments it.
+func (ts *taskRepository) UpdateTaskByID(c context.Context, updatedTask domain.Task, transition *domain.StatusTransition) domain.CustomError {
 	objectID, err := primitive.ObjectIDFromHex(updatedTask.ID)
 	if err != nil {
 		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
 	}
 
-	update := bson.M{}
-
-	if updatedTask.Title != "" {
-		update["title"] = updatedTask.Title
+	update := bson.M{
+		"title":       updatedTask.Title,
+		"description": updatedTask.Description,
+		"due_date":    updatedTask.DueDate,
+		"parent_id":   updatedTask.ParentID,
+		"recurrence":  updatedTask.Recurrence,
+	}
+	changes := bson.M{"$set": update, "$inc": bson.M{"version": 1}}
+	if transition != nil {
+		update["status"] = transition.To
+		changes["$push"] = bson.M{"transitions": transition}
 	}
-	if updatedTask.Description != "" {
-		update["description"] = updatedTask.Description
+
+	filter := activeTaskFilter(objectID)
+	filter["version"] = versionFilter(updatedTask.Version)
+	result, err := ts.collection.UpdateOne(c, filter, changes)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating task"}
 	}
-	if updatedTask.DueDate != "" {
-		update["due_date"] = updatedTask.DueDate
+	if result.MatchedCount == 0 {
+		return ts.versionMismatchError(c, objectID)
 	}
-	if updatedTask.Status != "" {
-		update["status"] = updatedTask.Status
+	return domain.CustomError{}
+}
+
+// versionFilter matches the given version. Tasks stored before versioning have no version field and count as version 0.
+func versionFilter(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
 	}
+	return version
+}
 
-	result, err := ts.collection.UpdateOne(c, bson.M{"_id": objectID}, bson.M{"$set": update})
+// versionMismatchError tells apart a conditional update that missed because the task is gone from one that
+// missed because someone else changed the task first.
+func (ts *taskRepository) versionMismatchError(c context.Context, objectID primitive.ObjectID) domain.CustomError {
+	count, err := ts.collection.CountDocuments(c, activeTaskFilter(objectID))
 	if err != nil {
 		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating task"}
 	}
-	if result.MatchedCount == 0 {
+	if count == 0 {
 		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"}
 	}
+	return domain.CustomError{ErrCode: http.StatusPreconditionFailed, ErrMessage: "Task was modified by someone else, reload the task and try again"}
+}
+
+// TransitionTaskStatus moves a task to a new status and records the transition. The update only
+// applies while the task still has the transition's from status, so concurrent transitions cannot both win.
+func (ts *taskRepository) TransitionTaskStatus(c context.Context, taskID string, transition domain.StatusTransition) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	filter := activeTaskFilter(objectID)
+	filter["status"] = transition.From
+	update := bson.M{
+		"$set":  bson.M{"status": transition.To},
+		"$push": bson.M{"transitions": transition},
+		"$inc":  bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, filter, update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while changing task status"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "Task status was changed by someone else, reload the task and try again"}
+	}
 	return domain.CustomError{}
 }
 
-// DeleteTaskByID deletes a task from the database by its ID.
-func (ts *taskRepository) DeleteTaskByID(c context.Context, taskID string) domain.CustomError {
+// DeleteTaskByID moves a task to the trash, recording who deleted it and when.
+func (ts *taskRepository) DeleteTaskByID(c context.Context, taskID string, deletedBy string, deletedAt time.Time) domain.CustomError {
 	objectID, err := primitive.ObjectIDFromHex(taskID)
 	if err != nil {
 		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task id"}
 	}
 
-	result, err := ts.collection.DeleteOne(c, bson.M{"_id": objectID})
+	update := bson.M{
+		"$set": bson.M{"deleted_at": deletedAt, "deleted_by": deletedBy},
+		"$inc": bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, activeTaskFilter(objectID), update)
 	if err != nil {
 		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while deleting task"}
 	}
 
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"}
+	}
+	return domain.CustomError{}
+}
+
+// GetDeletedTaskByID retrieves a task from the trash by its ID.
+func (ts *taskRepository) GetDeletedTaskByID(c context.Context, taskID string) (domain.Task, domain.CustomError) {
+	var task domain.Task
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.Task{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	err = ts.collection.FindOne(c, deletedTaskFilter(objectID)).Decode(&task)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Task{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found in trash"}
+		}
+		return domain.Task{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retriving task"}
+	}
+	return task, domain.CustomError{}
+}
+
+// RestoreTaskByID takes a task out of the trash.
+func (ts *taskRepository) RestoreTaskByID(c context.Context, taskID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	update := bson.M{
+		"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, deletedTaskFilter(objectID), update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while restoring task"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found in trash"}
+	}
+	return domain.CustomError{}
+}
+
+// PurgeTaskByID permanently removes a task that is in the trash.
+func (ts *taskRepository) PurgeTaskByID(c context.Context, taskID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	result, err := ts.collection.DeleteOne(c, deletedTaskFilter(objectID))
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while purging task"}
+	}
 	if result.DeletedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found in trash"}
+	}
+	return domain.CustomError{}
+}
+
+// PurgeDeletedBefore permanently removes the tasks that were moved to the trash before the cutoff and
+// returns how many were removed.
+func (ts *taskRepository) PurgeDeletedBefore(c context.Context, cutoff time.Time) (int64, domain.CustomError) {
+	result, err := ts.collection.DeleteMany(c, bson.M{"deleted_at": bson.M{"$lt": cutoff}})
+	if err != nil {
+		return 0, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while purging tasks"}
+	}
+	return result.DeletedCount, domain.CustomError{}
+}
+
+// GetSubtasks retrieves every active direct subtask of the given tasks.
+func (ts *taskRepository) GetSubtasks(c context.Context, parentIDs []string) ([]domain.Task, domain.CustomError) {
+	filter := bson.M{"parent_id": bson.M{"$in": parentIDs}, "deleted_at": nil}
+	cursor, err := ts.collection.Find(c, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving subtasks"}
+	}
+
+	subtasks := []domain.Task{}
+	if err := cursor.All(c, &subtasks); err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving subtasks"}
+	}
+	return subtasks, domain.CustomError{}
+}
+
+// AddChecklistItem appends an item to the task's checklist and returns it with its new ID.
+func (ts *taskRepository) AddChecklistItem(c context.Context, taskID string, item domain.ChecklistItem) (domain.ChecklistItem, domain.CustomError) {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.ChecklistItem{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	item.ID = primitive.NewObjectID().Hex()
+	update := bson.M{
+		"$push": bson.M{"checklist": item},
+		"$inc":  bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, activeTaskFilter(objectID), update)
+	if err != nil {
+		return domain.ChecklistItem{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while adding checklist item"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.ChecklistItem{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"}
+	}
+	return item, domain.CustomError{}
+}
+
+// UpdateChecklistItem overwrites the text and done flag of a checklist item.
+func (ts *taskRepository) UpdateChecklistItem(c context.Context, taskID string, item domain.ChecklistItem) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	filter := activeTaskFilter(objectID)
+	filter["checklist.id"] = item.ID
+	update := bson.M{
+		"$set": bson.M{"checklist.$.text": item.Text, "checklist.$.done": item.Done},
+		"$inc": bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, filter, update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating checklist item"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Checklist item not found"}
+	}
+	return domain.CustomError{}
+}
+
+// RemoveChecklistItem removes an item from the task's checklist.
+func (ts *taskRepository) RemoveChecklistItem(c context.Context, taskID string, itemID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	filter := activeTaskFilter(objectID)
+	filter["checklist.id"] = itemID
+	update := bson.M{
+		"$pull": bson.M{"checklist": bson.M{"id": itemID}},
+		"$inc":  bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, filter, update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while removing checklist item"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Checklist item not found"}
+	}
+	return domain.CustomError{}
+}
+
+// GetTasksByIDs retrieves the active tasks with the given IDs. IDs that are malformed, unknown or in the
+// trash are skipped.
+func (ts *taskRepository) GetTasksByIDs(c context.Context, taskIDs []string) ([]domain.Task, domain.CustomError) {
+	objectIDs := []primitive.ObjectID{}
+	for _, taskID := range taskIDs {
+		if objectID, err := primitive.ObjectIDFromHex(taskID); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+	return ts.findTasks(c, bson.M{"_id": bson.M{"$in": objectIDs}, "deleted_at": nil})
+}
+
+// GetDependents retrieves the active tasks that are blocked by any of the given tasks.
+func (ts *taskRepository) GetDependents(c context.Context, taskIDs []string) ([]domain.Task, domain.CustomError) {
+	return ts.findTasks(c, bson.M{"blocked_by": bson.M{"$in": taskIDs}, "deleted_at": nil})
+}
+
+// GetSeriesTasks retrieves the active occurrences of a recurring task that come after the given index.
+func (ts *taskRepository) GetSeriesTasks(c context.Context, seriesID string, afterIndex int) ([]domain.Task, domain.CustomError) {
+	return ts.findTasks(c, bson.M{"recurrence.series_id": seriesID, "recurrence.index": bson.M{"$gt": afterIndex}, "deleted_at": nil})
+}
+
+func (ts *taskRepository) findTasks(c context.Context, filter bson.M) ([]domain.Task, domain.CustomError) {
+	cursor, err := ts.collection.Find(c, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving tasks"}
+	}
+
+	tasks := []domain.Task{}
+	if err := cursor.All(c, &tasks); err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving tasks"}
+	}
+	return tasks, domain.CustomError{}
+}
+
+// AddDependency records that a task is blocked by another task, ignoring links that already exist.
+func (ts *taskRepository) AddDependency(c context.Context, taskID string, blockerID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	update := bson.M{
+		"$addToSet": bson.M{"blocked_by": blockerID},
+		"$inc":      bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, activeTaskFilter(objectID), update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while adding dependency"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"}
+	}
+	return domain.CustomError{}
+}
+
+// RemoveDependency removes a blocker from a task.
+func (ts *taskRepository) RemoveDependency(c context.Context, taskID string, blockerID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	update := bson.M{
+		"$pull": bson.M{"blocked_by": blockerID},
+		"$inc":  bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, activeTaskFilter(objectID), update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while removing dependency"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"}
+	}
+	return domain.CustomError{}
+}
+
+// AddLabels puts the given labels on a task, ignoring labels it already carries.
+func (ts *taskRepository) AddLabels(c context.Context, taskID string, labelIDs []string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	update := bson.M{
+		"$addToSet": bson.M{"label_ids": bson.M{"$each": labelIDs}},
+		"$inc":      bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, activeTaskFilter(objectID), update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while adding labels"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"}
+	}
+	return domain.CustomError{}
+}
+
+// RemoveLabel takes a label off a task.
+func (ts *taskRepository) RemoveLabel(c context.Context, taskID string, labelID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	update := bson.M{
+		"$pull": bson.M{"label_ids": labelID},
+		"$inc":  bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, activeTaskFilter(objectID), update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while removing label"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"}
+	}
+	return domain.CustomError{}
+}
+
+// RemoveLabelFromTasks takes a label off every task that carries it, including the tasks in the trash,
+// and returns how many tasks were changed.
+func (ts *taskRepository) RemoveLabelFromTasks(c context.Context, labelID string) (int64, domain.CustomError) {
+	update := bson.M{
+		"$pull": bson.M{"label_ids": labelID},
+		"$inc":  bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateMany(c, bson.M{"label_ids": labelID}, update)
+	if err != nil {
+		return 0, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while removing label from tasks"}
+	}
+	return result.ModifiedCount, domain.CustomError{}
+}
+
+// GetTasksCreatedBy retrieves the tasks a user created that are not in the trash.
+func (ts *taskRepository) GetTasksCreatedBy(c context.Context, userID string) ([]domain.Task, domain.CustomError) {
+	return ts.findTasks(c, bson.M{"created_by": userID, "deleted_at": nil})
+}
+
+// TransferTasks makes another user the creator of every task a user created, including the tasks in the
+// trash, and returns how many tasks were changed.
+func (ts *taskRepository) TransferTasks(c context.Context, fromUserID string, toUserID string) (int64, domain.CustomError) {
+	update := bson.M{
+		"$set": bson.M{"created_by": toUserID},
+		"$inc": bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateMany(c, bson.M{"created_by": fromUserID}, update)
+	if err != nil {
+		return 0, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while transferring tasks"}
+	}
+	return result.ModifiedCount, domain.CustomError{}
+}
+
+// ReplaceAssignee assigns another user to every task a user is assigned to, including the tasks in the
+// trash, and takes the user off them. It returns how many tasks were changed.
+func (ts *taskRepository) ReplaceAssignee(c context.Context, fromUserID string, toUserID string) (int64, domain.CustomError) {
+	filter := bson.M{"assignee_ids": fromUserID}
+	// $addToSet and $pull cannot change the same array in one update
+	if _, err := ts.collection.UpdateMany(c, filter, bson.M{"$addToSet": bson.M{"assignee_ids": toUserID}}); err != nil {
+		return 0, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while reassigning tasks"}
+	}
+	return ts.RemoveAssigneeFromTasks(c, fromUserID)
+}
+
+// RemoveAssigneeFromTasks takes a user off every task they are assigned to, including the tasks in the
+// trash, and returns how many tasks were changed.
+func (ts *taskRepository) RemoveAssigneeFromTasks(c context.Context, userID string) (int64, domain.CustomError) {
+	update := bson.M{
+		"$pull": bson.M{"assignee_ids": userID},
+		"$inc":  bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateMany(c, bson.M{"assignee_ids": userID}, update)
+	if err != nil {
+		return 0, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while unassigning user"}
+	}
+	return result.ModifiedCount, domain.CustomError{}
+}
+
+// AddAssignees adds the given users to the task's assignees, ignoring users already assigned.
+func (ts *taskRepository) AddAssignees(c context.Context, taskID string, userIDs []string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	update := bson.M{
+		"$addToSet": bson.M{"assignee_ids": bson.M{"$each": userIDs}},
+		"$inc":      bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, activeTaskFilter(objectID), update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while assigning users"}
+	}
+	if result.MatchedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"}
+	}
+	return domain.CustomError{}
+}
+
+// RemoveAssignee removes a user from the task's assignees.
+func (ts *taskRepository) RemoveAssignee(c context.Context, taskID string, userID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid task ID"}
+	}
+
+	update := bson.M{
+		"$pull": bson.M{"assignee_ids": userID},
+		"$inc":  bson.M{"version": 1},
+	}
+	result, err := ts.collection.UpdateOne(c, activeTaskFilter(objectID), update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while unassigning user"}
+	}
+	if result.MatchedCount == 0 {
 		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"}
 	}
 	return domain.CustomError{}
@@ -22,6 +22,11 @@ type TaskRepositorySuite struct {
 	repo       domain.TaskRepository
 }
 
+func timePtr(t time.Time) *time.Time {
+	t = t.UTC().Truncate(time.Millisecond)
+	return &t
+}
+
 func (suite *TaskRepositorySuite) SetupTest() {
 	// Clear the collection before each test
 	suite.collection.DeleteMany(context.TODO(), bson.D{})
@@ -49,17 +54,19 @@ func (suite *TaskRepositorySuite) TestCreateTask() {
 	task := domain.Task{
 		Title:       "Test Task",
 		Description: "This is a test task",
-		DueDate:     time.Now().Format(time.RFC3339),
+		DueDate:     timePtr(time.Now()),
 		Status:      "Pending",
 	}
 
-	err := suite.repo.CreateTask(context.TODO(), task)
+	id, err := suite.repo.CreateTask(context.TODO(), task)
 	suite.Empty(err.ErrCode)
+	suite.NotEmpty(id)
 
 	var result domain.Task
 	dbError := suite.collection.FindOne(context.TODO(), bson.M{"title": task.Title}).Decode(&result)
 	suite.NoError(dbError)
 	suite.Equal(task.Title, result.Title)
+	suite.Equal(id, result.ID)
 }
 
 // Test GetTasks
@@ -67,23 +74,126 @@ func (suite *TaskRepositorySuite) TestGetTasks() {
 	_, dbError := suite.collection.InsertOne(context.TODO(), domain.Task{
 		Title:       "Sample Task",
 		Description: "Sample Description",
-		DueDate:     time.Now().Format(time.RFC3339),
+		DueDate:     timePtr(time.Now()),
 		Status:      "Pending",
 	})
 	suite.NoError(dbError)
 
-	tasks, err := suite.repo.GetTasks(context.TODO())
+	page, err := suite.repo.GetTasks(context.TODO(), domain.TaskQuery{Limit: domain.DefaultTaskPageSize})
+	suite.Empty(err.ErrCode)
+	suite.NotEmpty(page.Tasks)
+	suite.Equal(int64(1), page.Total)
+	suite.Empty(page.NextCursor)
+}
+
+// Test GetTasks visibility filter
+func (suite *TaskRepositorySuite) TestGetTasks_VisibleTo() {
+	_, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.Task{Title: "Owned Task", CreatedBy: "user-1"},
+		domain.Task{Title: "Assigned Task", CreatedBy: "user-2", AssigneeIDs: []string{"user-1"}},
+		domain.Task{Title: "Other Task", CreatedBy: "user-2"},
+	})
+	suite.NoError(dbError)
+
+	page, err := suite.repo.GetTasks(context.TODO(), domain.TaskQuery{Limit: domain.DefaultTaskPageSize, VisibleTo: "user-1"})
+	suite.Empty(err.ErrCode)
+	suite.Len(page.Tasks, 2)
+	suite.Equal(int64(2), page.Total)
+}
+
+// Test GetTasks visibility filter with projects
+func (suite *TaskRepositorySuite) TestGetTasks_VisibleProjects() {
+	_, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.Task{Title: "Project Task", ProjectID: "project-1", CreatedBy: "user-2"},
+		domain.Task{Title: "Other Project Task", ProjectID: "project-2", CreatedBy: "user-1"},
+		domain.Task{Title: "Legacy Task", CreatedBy: "user-1"},
+	})
+	suite.NoError(dbError)
+
+	page, err := suite.repo.GetTasks(context.TODO(), domain.TaskQuery{Limit: domain.DefaultTaskPageSize, VisibleTo: "user-1", VisibleProjectIDs: []string{"project-1"}})
+	suite.Empty(err.ErrCode)
+	suite.Equal(int64(2), page.Total)
+
+	page, err = suite.repo.GetTasks(context.TODO(), domain.TaskQuery{Limit: domain.DefaultTaskPageSize, ProjectID: "project-2"})
+	suite.Empty(err.ErrCode)
+	suite.Len(page.Tasks, 1)
+	suite.Equal("Other Project Task", page.Tasks[0].Title)
+}
+
+// Test SearchTasks ranks title matches first and pages through the results
+func (suite *TaskRepositorySuite) TestSearchTasks() {
+	_, indexErr := suite.collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+		Options: options.Index().SetWeights(bson.D{{Key: "title", Value: 5}, {Key: "description", Value: 1}}),
+	})
+	suite.NoError(indexErr)
+	_, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.Task{Title: "Write docs", Description: "Explain how to deploy"},
+		domain.Task{Title: "Deploy the API", Description: "Production"},
+		domain.Task{Title: "Unrelated", Description: "Nothing here"},
+	})
+	suite.NoError(dbError)
+
+	first, err := suite.repo.SearchTasks(context.TODO(), domain.TaskQuery{Search: "deploying", Limit: 1})
+	suite.Empty(err.ErrCode)
+	suite.Equal(int64(2), first.Total)
+	suite.Equal("Deploy the API", first.Results[0].Task.Title)
+	suite.NotEmpty(first.NextCursor)
+
+	second, err := suite.repo.SearchTasks(context.TODO(), domain.TaskQuery{Search: "deploying", Limit: 1, Cursor: first.NextCursor})
 	suite.Empty(err.ErrCode)
-	suite.NotEmpty(tasks)
+	suite.Len(second.Results, 1)
+	suite.Equal("Write docs", second.Results[0].Task.Title)
+	suite.Less(second.Results[0].Score, first.Results[0].Score)
+	suite.Empty(second.NextCursor)
 }
 
+// Test GetSeriesTasks lists the active occurrences after an index
+func (suite *TaskRepositorySuite) TestGetSeriesTasks() {
+	deletedAt := time.Now()
+	_, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.Task{Title: "First", Recurrence: &domain.TaskRecurrence{SeriesID: "series-1", Rule: "FREQ=DAILY", Index: 1}},
+		domain.Task{Title: "Second", Recurrence: &domain.TaskRecurrence{SeriesID: "series-1", Rule: "FREQ=DAILY", Index: 2}},
+		domain.Task{Title: "Deleted", Recurrence: &domain.TaskRecurrence{SeriesID: "series-1", Rule: "FREQ=DAILY", Index: 3}, DeletedAt: &deletedAt},
+		domain.Task{Title: "Other series", Recurrence: &domain.TaskRecurrence{SeriesID: "series-2", Rule: "FREQ=DAILY", Index: 2}},
+	})
+	suite.NoError(dbError)
+
+	tasks, err := suite.repo.GetSeriesTasks(context.TODO(), "series-1", 1)
+	suite.Empty(err.ErrCode)
+	suite.Len(tasks, 1)
+	suite.Equal("Second", tasks[0].Title)
+}
+
+// Test GetTasks cursor pagination
+func (suite *TaskRepositorySuite) TestGetTasks_Pagination() {
+	_, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.Task{Title: "A"},
+		domain.Task{Title: "B"},
+		domain.Task{Title: "C"},
+	})
+	suite.NoError(dbError)
+
+	query := domain.TaskQuery{SortBy: "title", Limit: 2}
+	first, err := suite.repo.GetTasks(context.TODO(), query)
+	suite.Empty(err.ErrCode)
+	suite.Len(first.Tasks, 2)
+	suite.NotEmpty(first.NextCursor)
+
+	query.Cursor = first.NextCursor
+	second, err := suite.repo.GetTasks(context.TODO(), query)
+	suite.Empty(err.ErrCode)
+	suite.Len(second.Tasks, 1)
+	suite.Equal("C", second.Tasks[0].Title)
+	suite.Empty(second.NextCursor)
+}
 
 // Test GetTaskByID
 func (suite *TaskRepositorySuite) TestGetTaskByID() {
 	task := domain.Task{
 		Title:       "GetTaskByID Test",
 		Description: "This task is for testing GetTaskByID",
-		DueDate:     time.Now().Format(time.RFC3339),
+		DueDate:     timePtr(time.Now()),
 		Status:      "Pending",
 	}
 	
@@ -106,7 +216,7 @@ func (suite *TaskRepositorySuite) TestGetTaskByID_NotFound() {
 	_, dbError := suite.collection.InsertOne(context.TODO(), domain.Task{
 		Title:       "Sample Task",
 		Description: "Sample Description",
-		DueDate:     time.Now().Format(time.RFC3339),
+		DueDate:     timePtr(time.Now()),
 		Status:      "Pending",
 	})
 	suite.NoError(dbError)
@@ -121,7 +231,7 @@ func (suite *TaskRepositorySuite) TestUpdateTaskByID() {
 	task := domain.Task{
 		Title:       "Update Task",
 		Description: "This is a task to update",
-		DueDate:     time.Now().Format(time.RFC3339),
+		DueDate:     timePtr(time.Now()),
 		Status:      "Pending",
 	}
 
@@ -132,17 +242,63 @@ func (suite *TaskRepositorySuite) TestUpdateTaskByID() {
 		ID:          insertedResult.InsertedID.(primitive.ObjectID).Hex(),
 		Title:       "Updated Task",
 		Description: "This task has been updated",
-		Status:      "Completed",
 	}
+	transition := domain.StatusTransition{From: "Pending", To: "Completed", By: "user-1", At: time.Now().UTC()}
 
-	err := suite.repo.UpdateTaskByID(context.TODO(), updatedTask)
+	err := suite.repo.UpdateTaskByID(context.TODO(), updatedTask, &transition)
 	suite.Empty(err.ErrCode)
 
+	// the status and its transition are stored in the same update as the fields
 	var result domain.Task
 	dbError = suite.collection.FindOne(context.TODO(), bson.M{"_id": insertedResult.InsertedID}).Decode(&result)
 	suite.NoError(dbError)
 	suite.Equal(updatedTask.Title, result.Title)
-	suite.Equal(updatedTask.Status, result.Status)
+	suite.Equal(domain.TaskStatus("Completed"), result.Status)
+	suite.Len(result.Transitions, 1)
+	suite.Equal(int64(1), result.Version)
+}
+
+// Test UpdateTaskByID with a version that has moved on
+func (suite *TaskRepositorySuite) TestUpdateTaskByID_StaleVersion() {
+	insertedResult, dbError := suite.collection.InsertOne(context.TODO(), domain.Task{Title: "Versioned Task", Version: 2})
+	suite.NoError(dbError)
+	taskID := insertedResult.InsertedID.(primitive.ObjectID).Hex()
+
+	err := suite.repo.UpdateTaskByID(context.TODO(), domain.Task{ID: taskID, Title: "Stale Write", Version: 1}, nil)
+	suite.Equal(http.StatusPreconditionFailed, err.ErrCode)
+
+	err = suite.repo.UpdateTaskByID(context.TODO(), domain.Task{ID: taskID, Title: "Fresh Write", Version: 2}, nil)
+	suite.Empty(err.ErrCode)
+
+	var result domain.Task
+	dbError = suite.collection.FindOne(context.TODO(), bson.M{"_id": insertedResult.InsertedID}).Decode(&result)
+	suite.NoError(dbError)
+	suite.Equal("Fresh Write", result.Title)
+	suite.Equal(int64(3), result.Version)
+
+	err = suite.repo.UpdateTaskByID(context.TODO(), domain.Task{ID: primitive.NewObjectID().Hex(), Title: "Missing", Version: 1}, nil)
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+// Test TransitionTaskStatus
+func (suite *TaskRepositorySuite) TestTransitionTaskStatus() {
+	insertedResult, dbError := suite.collection.InsertOne(context.TODO(), domain.Task{Title: "Transition Task", Status: domain.StatusTodo})
+	suite.NoError(dbError)
+	taskID := insertedResult.InsertedID.(primitive.ObjectID).Hex()
+
+	transition := domain.StatusTransition{From: domain.StatusTodo, To: domain.StatusInProgress, By: "user-1", At: time.Now().UTC().Truncate(time.Millisecond)}
+	err := suite.repo.TransitionTaskStatus(context.TODO(), taskID, transition)
+	suite.Empty(err.ErrCode)
+
+	var result domain.Task
+	dbError = suite.collection.FindOne(context.TODO(), bson.M{"_id": insertedResult.InsertedID}).Decode(&result)
+	suite.NoError(dbError)
+	suite.Equal(domain.StatusInProgress, result.Status)
+	suite.Equal([]domain.StatusTransition{transition}, result.Transitions)
+
+	// the task is no longer in todo, so replaying the same transition conflicts
+	err = suite.repo.TransitionTaskStatus(context.TODO(), taskID, transition)
+	suite.Equal(http.StatusConflict, err.ErrCode)
 }
 
 // Test DeleteTaskByID
@@ -150,32 +306,234 @@ func (suite *TaskRepositorySuite) TestDeleteTaskByID() {
 	task := domain.Task{
 		Title:       "Delete Task",
 		Description: "This task will be deleted",
-		DueDate:     time.Now().Format(time.RFC3339),
+		DueDate:     timePtr(time.Now()),
 		Status:      "Pending",
 	}
 
 	insertedResult, dbError := suite.collection.InsertOne(context.TODO(), task)
 	suite.NoError(dbError)
+	taskID := insertedResult.InsertedID.(primitive.ObjectID).Hex()
 
-	err := suite.repo.DeleteTaskByID(context.TODO(), insertedResult.InsertedID.(primitive.ObjectID).Hex())
+	deletedAt := time.Now().UTC().Truncate(time.Millisecond)
+	err := suite.repo.DeleteTaskByID(context.TODO(), taskID, "admin-1", deletedAt)
 	suite.Empty(err.ErrCode)
 
-	dbError = suite.collection.FindOne(context.TODO(), bson.M{"_id": task.ID}).Err()
-	suite.Equal(mongo.ErrNoDocuments, dbError)
+	// the task is kept in the trash but hidden from normal reads
+	_, err = suite.repo.GetTaskByID(context.TODO(), taskID)
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+
+	deleted, err := suite.repo.GetDeletedTaskByID(context.TODO(), taskID)
+	suite.Empty(err.ErrCode)
+	suite.Equal("admin-1", deleted.DeletedBy)
+	suite.Equal(deletedAt, *deleted.DeletedAt)
+
+	page, err := suite.repo.GetTasks(context.TODO(), domain.TaskQuery{Limit: domain.DefaultTaskPageSize})
+	suite.Empty(err.ErrCode)
+	suite.Empty(page.Tasks)
+
+	trash, err := suite.repo.GetTasks(context.TODO(), domain.TaskQuery{Limit: domain.DefaultTaskPageSize, Deleted: true})
+	suite.Empty(err.ErrCode)
+	suite.Len(trash.Tasks, 1)
 }
 
 // Test DeleteTaskByID_InvalidID
 func (suite *TaskRepositorySuite) TestDeleteTaskByID_InvalidID() {
-	err := suite.repo.DeleteTaskByID(context.TODO(), "invalidID")
+	err := suite.repo.DeleteTaskByID(context.TODO(), "invalidID", "admin-1", time.Now())
 	suite.Equal(http.StatusBadRequest, err.ErrCode)
 }
 
 // Test DeleteTaskByID_NotFound
 func (suite *TaskRepositorySuite) TestDeleteTaskByID_NotFound() {
-	err := suite.repo.DeleteTaskByID(context.TODO(), primitive.NewObjectID().Hex())
+	err := suite.repo.DeleteTaskByID(context.TODO(), primitive.NewObjectID().Hex(), "admin-1", time.Now())
 	suite.Equal(http.StatusNotFound, err.ErrCode)
 }
 
+// Test RestoreTaskByID
+func (suite *TaskRepositorySuite) TestRestoreTaskByID() {
+	insertedResult, dbError := suite.collection.InsertOne(context.TODO(), domain.Task{Title: "Restore Task", DeletedAt: timePtr(time.Now()), DeletedBy: "admin-1"})
+	suite.NoError(dbError)
+	taskID := insertedResult.InsertedID.(primitive.ObjectID).Hex()
+
+	err := suite.repo.RestoreTaskByID(context.TODO(), taskID)
+	suite.Empty(err.ErrCode)
+
+	task, err := suite.repo.GetTaskByID(context.TODO(), taskID)
+	suite.Empty(err.ErrCode)
+	suite.Nil(task.DeletedAt)
+	suite.Empty(task.DeletedBy)
+
+	// a task that is not in the trash cannot be restored
+	err = suite.repo.RestoreTaskByID(context.TODO(), taskID)
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+// Test PurgeTaskByID only removes tasks that are in the trash
+func (suite *TaskRepositorySuite) TestPurgeTaskByID() {
+	insertedResult, dbError := suite.collection.InsertOne(context.TODO(), domain.Task{Title: "Active Task"})
+	suite.NoError(dbError)
+	taskID := insertedResult.InsertedID.(primitive.ObjectID).Hex()
+
+	err := suite.repo.PurgeTaskByID(context.TODO(), taskID)
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+
+	suite.Empty(suite.repo.DeleteTaskByID(context.TODO(), taskID, "admin-1", time.Now()).ErrCode)
+	err = suite.repo.PurgeTaskByID(context.TODO(), taskID)
+	suite.Empty(err.ErrCode)
+
+	dbError = suite.collection.FindOne(context.TODO(), bson.M{"_id": insertedResult.InsertedID}).Err()
+	suite.Equal(mongo.ErrNoDocuments, dbError)
+}
+
+// Test PurgeDeletedBefore
+func (suite *TaskRepositorySuite) TestPurgeDeletedBefore() {
+	now := time.Now()
+	_, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.Task{Title: "Expired", DeletedAt: timePtr(now.Add(-48 * time.Hour))},
+		domain.Task{Title: "Recently deleted", DeletedAt: timePtr(now.Add(-time.Hour))},
+		domain.Task{Title: "Active"},
+	})
+	suite.NoError(dbError)
+
+	purged, err := suite.repo.PurgeDeletedBefore(context.TODO(), now.Add(-24*time.Hour))
+	suite.Empty(err.ErrCode)
+	suite.Equal(int64(1), purged)
+
+	count, dbError := suite.collection.CountDocuments(context.TODO(), bson.M{})
+	suite.NoError(dbError)
+	suite.Equal(int64(2), count)
+}
+
+// Test GetSubtasks leaves out deleted subtasks and other tasks
+func (suite *TaskRepositorySuite) TestGetSubtasks() {
+	_, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.Task{Title: "Child", ParentID: "parent-1"},
+		domain.Task{Title: "Other child", ParentID: "parent-2"},
+		domain.Task{Title: "Deleted child", ParentID: "parent-1", DeletedAt: timePtr(time.Now())},
+		domain.Task{Title: "Top level"},
+	})
+	suite.NoError(dbError)
+
+	subtasks, err := suite.repo.GetSubtasks(context.TODO(), []string{"parent-1"})
+	suite.Empty(err.ErrCode)
+	suite.Len(subtasks, 1)
+	suite.Equal("Child", subtasks[0].Title)
+
+	subtasks, err = suite.repo.GetSubtasks(context.TODO(), []string{"parent-1", "parent-2"})
+	suite.Empty(err.ErrCode)
+	suite.Len(subtasks, 2)
+}
+
+// Test adding, updating and removing checklist items
+func (suite *TaskRepositorySuite) TestChecklistItems() {
+	insertedResult, dbError := suite.collection.InsertOne(context.TODO(), domain.Task{Title: "Checklist Task"})
+	suite.NoError(dbError)
+	taskID := insertedResult.InsertedID.(primitive.ObjectID).Hex()
+
+	item, err := suite.repo.AddChecklistItem(context.TODO(), taskID, domain.ChecklistItem{Text: "Step one"})
+	suite.Empty(err.ErrCode)
+	suite.NotEmpty(item.ID)
+
+	item.Done = true
+	err = suite.repo.UpdateChecklistItem(context.TODO(), taskID, item)
+	suite.Empty(err.ErrCode)
+
+	task, err := suite.repo.GetTaskByID(context.TODO(), taskID)
+	suite.Empty(err.ErrCode)
+	suite.Equal([]domain.ChecklistItem{item}, task.Checklist)
+	suite.Equal(int64(2), task.Version)
+
+	err = suite.repo.RemoveChecklistItem(context.TODO(), taskID, item.ID)
+	suite.Empty(err.ErrCode)
+
+	err = suite.repo.RemoveChecklistItem(context.TODO(), taskID, item.ID)
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+// Test adding and removing dependencies and looking up dependents
+func (suite *TaskRepositorySuite) TestDependencies() {
+	insertedResult, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.Task{Title: "Blocker"},
+		domain.Task{Title: "Blocked"},
+	})
+	suite.NoError(dbError)
+	blockerID := insertedResult.InsertedIDs[0].(primitive.ObjectID).Hex()
+	blockedID := insertedResult.InsertedIDs[1].(primitive.ObjectID).Hex()
+
+	err := suite.repo.AddDependency(context.TODO(), blockedID, blockerID)
+	suite.Empty(err.ErrCode)
+
+	dependents, err := suite.repo.GetDependents(context.TODO(), []string{blockerID})
+	suite.Empty(err.ErrCode)
+	suite.Len(dependents, 1)
+	suite.Equal("Blocked", dependents[0].Title)
+
+	blockers, err := suite.repo.GetTasksByIDs(context.TODO(), []string{blockerID, "not-an-id"})
+	suite.Empty(err.ErrCode)
+	suite.Len(blockers, 1)
+
+	err = suite.repo.RemoveDependency(context.TODO(), blockedID, blockerID)
+	suite.Empty(err.ErrCode)
+
+	task, err := suite.repo.GetTaskByID(context.TODO(), blockedID)
+	suite.Empty(err.ErrCode)
+	suite.Empty(task.BlockedBy)
+	suite.Equal(int64(2), task.Version)
+}
+
+// Test GetTasks with a due date range that only matches open tasks
+func (suite *TaskRepositorySuite) TestGetTasks_DueRange() {
+	now := time.Now()
+	_, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.Task{Title: "Overdue", DueDate: timePtr(now.Add(-time.Hour)), Status: "todo"},
+		domain.Task{Title: "Done", DueDate: timePtr(now.Add(-time.Hour)), Status: domain.StatusDone},
+		domain.Task{Title: "Later", DueDate: timePtr(now.Add(time.Hour))},
+		domain.Task{Title: "No due date"},
+	})
+	suite.NoError(dbError)
+
+	page, err := suite.repo.GetTasks(context.TODO(), domain.TaskQuery{DueTo: timePtr(now), OpenOnly: true, Limit: domain.DefaultTaskPageSize})
+	suite.Empty(err.ErrCode)
+	suite.Len(page.Tasks, 1)
+	suite.Equal("Overdue", page.Tasks[0].Title)
+}
+
+// Test GetTasks with any-of and all-of label filters
+func (suite *TaskRepositorySuite) TestGetTasks_Labels() {
+	_, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.Task{Title: "Backend", LabelIDs: []string{"backend"}},
+		domain.Task{Title: "Urgent backend", LabelIDs: []string{"backend", "urgent"}},
+		domain.Task{Title: "Unlabelled"},
+	})
+	suite.NoError(dbError)
+
+	page, err := suite.repo.GetTasks(context.TODO(), domain.TaskQuery{LabelIDs: []string{"backend", "urgent"}, Limit: domain.DefaultTaskPageSize})
+	suite.Empty(err.ErrCode)
+	suite.Len(page.Tasks, 2)
+
+	page, err = suite.repo.GetTasks(context.TODO(), domain.TaskQuery{LabelIDs: []string{"backend", "urgent"}, LabelMatch: domain.LabelMatchAll, Limit: domain.DefaultTaskPageSize})
+	suite.Empty(err.ErrCode)
+	suite.Len(page.Tasks, 1)
+	suite.Equal("Urgent backend", page.Tasks[0].Title)
+}
+
+// Test RemoveLabelFromTasks takes a label off every task, including the trash
+func (suite *TaskRepositorySuite) TestRemoveLabelFromTasks() {
+	_, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.Task{Title: "Active", LabelIDs: []string{"urgent", "backend"}},
+		domain.Task{Title: "Trashed", LabelIDs: []string{"urgent"}, DeletedAt: timePtr(time.Now())},
+		domain.Task{Title: "Other", LabelIDs: []string{"backend"}},
+	})
+	suite.NoError(dbError)
+
+	removed, err := suite.repo.RemoveLabelFromTasks(context.TODO(), "urgent")
+	suite.Empty(err.ErrCode)
+	suite.Equal(int64(2), removed)
+
+	count, dbError := suite.collection.CountDocuments(context.TODO(), bson.M{"label_ids": "urgent"})
+	suite.NoError(dbError)
+	suite.Zero(count)
+}
+
 func TestTaskRepositorySuite(t *testing.T) {
 	suite.Run(t, new(TaskRepositorySuite))
 }
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type taskSeriesRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTaskSeriesRepository creates a new repository for the series of recurring tasks.
+func NewTaskSeriesRepository(db *mongo.Database, seriesCollectionString string) domain.TaskSeriesRepository {
+	return &taskSeriesRepository{
+		collection: db.Collection(seriesCollectionString),
+	}
+}
+
+// CreateSeries stores a series and returns its ID.
+func (sr *taskSeriesRepository) CreateSeries(c context.Context, series domain.TaskSeries) (string, domain.CustomError) {
+	series.ID = ""
+	result, err := sr.collection.InsertOne(c, series)
+	if err != nil {
+		return "", domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating task series"}
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), domain.CustomError{}
+}
+
+// GetSeriesByID retrieves a single series.
+func (sr *taskSeriesRepository) GetSeriesByID(c context.Context, seriesID string) (domain.TaskSeries, domain.CustomError) {
+	objectID, err := primitive.ObjectIDFromHex(seriesID)
+	if err != nil {
+		return domain.TaskSeries{}, seriesNotFound()
+	}
+
+	var series domain.TaskSeries
+	err = sr.collection.FindOne(c, bson.M{"_id": objectID}).Decode(&series)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.TaskSeries{}, seriesNotFound()
+		}
+		return domain.TaskSeries{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving task series"}
+	}
+	return series, domain.CustomError{}
+}
+
+// UpdateSeries stores the title and description that the next occurrences of a series are created with.
+func (sr *taskSeriesRepository) UpdateSeries(c context.Context, series domain.TaskSeries) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(series.ID)
+	if err != nil {
+		return seriesNotFound()
+	}
+
+	update := bson.M{"$set": bson.M{"title": series.Title, "description": series.Description}}
+	result, err := sr.collection.UpdateOne(c, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating task series"}
+	}
+	if result.MatchedCount == 0 {
+		return seriesNotFound()
+	}
+	return domain.CustomError{}
+}
+
+// AdvanceSeries moves the series on from the occurrence at fromIndex to the next one. It reports false
+// when the series is no longer at fromIndex, which means the next occurrence was already created.
+func (sr *taskSeriesRepository) AdvanceSeries(c context.Context, seriesID string, fromIndex int) (bool, domain.CustomError) {
+	objectID, err := primitive.ObjectIDFromHex(seriesID)
+	if err != nil {
+		return false, seriesNotFound()
+	}
+
+	filter := bson.M{"_id": objectID, "last_index": fromIndex}
+	result, err := sr.collection.UpdateOne(c, filter, bson.M{"$inc": bson.M{"last_index": 1}})
+	if err != nil {
+		return false, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating task series"}
+	}
+	return result.ModifiedCount == 1, domain.CustomError{}
+}
+
+func seriesNotFound() domain.CustomError {
+	return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task series not found"}
+}
@@ -0,0 +1,76 @@
+package repositories_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TaskSeriesRepositorySuite struct {
+	suite.Suite
+	db         *mongo.Database
+	collection *mongo.Collection
+	repo       domain.TaskSeriesRepository
+}
+
+func (suite *TaskSeriesRepositorySuite) SetupTest() {
+	// Clear the collection before each test
+	suite.collection.DeleteMany(context.TODO(), bson.D{})
+}
+
+func (suite *TaskSeriesRepositorySuite) SetupSuite() {
+	// Set up a test MongoDB instance
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	suite.Require().NoError(err)
+
+	suite.db = client.Database("task_management_test")
+	suite.collection = suite.db.Collection("task_series")
+
+	suite.repo = repositories.NewTaskSeriesRepository(suite.db, "task_series")
+}
+
+// Test UpdateSeries stores the new title and description
+func (suite *TaskSeriesRepositorySuite) TestUpdateSeries() {
+	start := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	seriesID, err := suite.repo.CreateSeries(context.TODO(), domain.TaskSeries{Rule: "FREQ=WEEKLY", Start: start, Title: "Standup notes", LastIndex: 1})
+	suite.Empty(err.ErrCode)
+
+	err = suite.repo.UpdateSeries(context.TODO(), domain.TaskSeries{ID: seriesID, Title: "Weekly report", Description: "For the team"})
+	suite.Empty(err.ErrCode)
+
+	series, err := suite.repo.GetSeriesByID(context.TODO(), seriesID)
+	suite.Empty(err.ErrCode)
+	suite.Equal("Weekly report", series.Title)
+	suite.Equal("FREQ=WEEKLY", series.Rule)
+	suite.True(start.Equal(series.Start))
+}
+
+// Test AdvanceSeries only moves on from the latest occurrence once
+func (suite *TaskSeriesRepositorySuite) TestAdvanceSeries() {
+	seriesID, err := suite.repo.CreateSeries(context.TODO(), domain.TaskSeries{Rule: "FREQ=DAILY", LastIndex: 1})
+	suite.Empty(err.ErrCode)
+
+	advanced, err := suite.repo.AdvanceSeries(context.TODO(), seriesID, 1)
+	suite.Empty(err.ErrCode)
+	suite.True(advanced)
+
+	advanced, err = suite.repo.AdvanceSeries(context.TODO(), seriesID, 1)
+	suite.Empty(err.ErrCode)
+	suite.False(advanced)
+
+	_, err = suite.repo.GetSeriesByID(context.TODO(), "not-an-id")
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+func TestTaskSeriesRepositorySuite(t *testing.T) {
+	suite.Run(t, new(TaskSeriesRepositorySuite))
+}
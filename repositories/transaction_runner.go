@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"task_managment_api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type transactionRunner struct {
+	client *mongo.Client
+}
+
+// NewTransactionRunner creates a runner for transactions over every collection of the database.
+// Transactions need MongoDB to run as a replica set or sharded cluster.
+func NewTransactionRunner(db *mongo.Database) domain.TransactionRunner {
+	return &transactionRunner{
+		client: db.Client(),
+	}
+}
+
+// abortError carries the error of a failed transaction body through the driver.
+type abortError struct {
+	err domain.CustomError
+}
+
+func (e abortError) Error() string {
+	return e.err.ErrMessage
+}
+
+// RunInTransaction runs fn in a transaction, retrying it on transient errors as the driver does. It answers
+// 501 without running fn when the server cannot run transactions.
+func (tr *transactionRunner) RunInTransaction(c context.Context, fn func(c context.Context) domain.CustomError) domain.CustomError {
+	if err := tr.checkTransactionSupport(c); err.ErrCode != 0 {
+		return err
+	}
+	session, err := tr.client.StartSession()
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while starting transaction"}
+	}
+	defer session.EndSession(c)
+
+	_, err = session.WithTransaction(c, func(sc mongo.SessionContext) (interface{}, error) {
+		if err := fn(sc); err.ErrCode != 0 {
+			return nil, abortError{err: err}
+		}
+		return nil, nil
+	})
+	if err == nil {
+		return domain.CustomError{}
+	}
+
+	var aborted abortError
+	if errors.As(err, &aborted) {
+		return aborted.err
+	}
+	return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while running transaction"}
+}
+
+// checkTransactionSupport asks the server whether it is a replica set member or a mongos router. A standalone
+// server only refuses a transaction at its first read or write, which the repositories report as their own error.
+func (tr *transactionRunner) checkTransactionSupport(c context.Context) domain.CustomError {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := tr.client.Database("admin").RunCommand(c, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while starting transaction"}
+	}
+	if hello.SetName == "" && hello.Msg != "isdbgrid" {
+		return domain.CustomError{ErrCode: http.StatusNotImplemented, ErrMessage: "Atomic batches need MongoDB running as a replica set"}
+	}
+	return domain.CustomError{}
+}
@@ -3,12 +3,14 @@ package repositories
 import (
 	"context"
 	"net/http"
+	"regexp"
 	"task_managment_api/domain"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type userRepository struct {
@@ -44,6 +46,24 @@ func (us *userRepository) GetUserByUsername(c context.Context, username string)
 	return user, domain.CustomError{}
 }
 
+// GetUserByID retrieves a user from the database based on the user ID.
+func (us *userRepository) GetUserByID(c context.Context, userID string) (domain.User, domain.CustomError) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.User{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid user ID"}
+	}
+
+	var user domain.User
+	err = us.collection.FindOne(c, bson.M{"_id": objectID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.User{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "User not found"}
+		}
+		return domain.User{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving user"}
+	}
+	return user, domain.CustomError{}
+}
+
 // UpdateUser updates an existing user in the database.
 func (us *userRepository) UpdateUser(c context.Context, user domain.User) domain.CustomError {
 	objectID, err := primitive.ObjectIDFromHex(user.ID)
@@ -69,3 +89,91 @@ func (us *userRepository) GetUserCount(c context.Context) (int64, domain.CustomE
 	}
 	return count, domain.CustomError{}
 }
+
+// CountUsersWithRole returns the number of users who have the role.
+func (us *userRepository) CountUsersWithRole(c context.Context, role string) (int64, domain.CustomError) {
+	count, err := us.collection.CountDocuments(c, bson.M{"role": role})
+	if err != nil {
+		return 0, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while counting users"}
+	}
+	return count, domain.CustomError{}
+}
+
+// CountEnabledUsersWithRole returns the number of users who have the role and are not disabled.
+func (us *userRepository) CountEnabledUsersWithRole(c context.Context, role string) (int64, domain.CustomError) {
+	count, err := us.collection.CountDocuments(c, bson.M{"role": role, "disabled": bson.M{"$ne": true}})
+	if err != nil {
+		return 0, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while counting users"}
+	}
+	return count, domain.CustomError{}
+}
+
+// GetUsers retrieves one page of the users matching the query, in the order they registered. The cursor
+// is the ID of the last user of the previous page.
+func (us *userRepository) GetUsers(c context.Context, query domain.UserQuery) (domain.UserPage, domain.CustomError) {
+	filter := bson.M{}
+	if query.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query.Search), Options: "i"}
+		filter["$or"] = bson.A{bson.M{"username": pattern}, bson.M{"email": pattern}}
+	}
+	if query.Role != "" {
+		filter["role"] = query.Role
+	}
+	if query.Disabled != nil {
+		if *query.Disabled {
+			filter["disabled"] = true
+		} else {
+			filter["disabled"] = bson.M{"$ne": true}
+		}
+	}
+
+	total, err := us.collection.CountDocuments(c, filter)
+	if err != nil {
+		return domain.UserPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while counting users"}
+	}
+
+	if query.Cursor != "" {
+		after, err := primitive.ObjectIDFromHex(query.Cursor)
+		if err != nil {
+			return domain.UserPage{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid cursor", Field: "cursor"}
+		}
+		filter["_id"] = bson.M{"$gt": after}
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(query.Limit + 1)
+	results, err := us.collection.Find(c, filter, findOptions)
+	if err != nil {
+		return domain.UserPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving users"}
+	}
+
+	users := []domain.User{}
+	if err := results.All(c, &users); err != nil {
+		return domain.UserPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving users"}
+	}
+
+	page := domain.UserPage{Users: []domain.UserSummary{}, Total: total}
+	for _, user := range users {
+		page.Users = append(page.Users, user.Summary())
+	}
+	if int64(len(page.Users)) > query.Limit {
+		page.Users = page.Users[:query.Limit]
+		page.NextCursor = page.Users[len(page.Users)-1].ID
+	}
+	return page, domain.CustomError{}
+}
+
+// DeleteUser removes a user.
+func (us *userRepository) DeleteUser(c context.Context, userID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "User not found"}
+	}
+	result, err := us.collection.DeleteOne(c, bson.M{"_id": objectID})
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while deleting user"}
+	}
+	if result.DeletedCount == 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "User not found"}
+	}
+	return domain.CustomError{}
+}
@@ -153,6 +153,55 @@ func (suite *UserRepositorySuite) TestGetUserByCount(){
 	suite.Equal(int64(1), count)
 }
 
+//Test GetUsers filters by search text and disabled accounts
+func (suite *UserRepositorySuite) TestGetUsers(){
+	users := []interface{}{
+		domain.User{Username: "alice", Email: "alice@example.com", Role: "user"},
+		domain.User{Username: "Alfred", Role: "admin", Disabled: true},
+		domain.User{Username: "bob", Role: "user"},
+	}
+	_, dbError := suite.collection.InsertMany(context.TODO(), users)
+	suite.NoError(dbError)
+
+	page, err := suite.repo.GetUsers(context.TODO(), domain.UserQuery{Search: "al", Limit: 1})
+	suite.Empty(err.ErrCode)
+	suite.Equal(int64(2), page.Total)
+	suite.Len(page.Users, 1)
+	suite.NotEmpty(page.NextCursor)
+
+	disabled := false
+	page, err = suite.repo.GetUsers(context.TODO(), domain.UserQuery{Search: "AL", Disabled: &disabled, Limit: 10})
+	suite.Empty(err.ErrCode)
+	suite.Len(page.Users, 1)
+	suite.Equal("alice", page.Users[0].Username)
+}
+
+//Test CountEnabledUsersWithRole leaves out disabled accounts
+func (suite *UserRepositorySuite) TestCountEnabledUsersWithRole(){
+	_, dbError := suite.collection.InsertMany(context.TODO(), []interface{}{
+		domain.User{Username: "root", Role: "admin"},
+		domain.User{Username: "old", Role: "admin", Disabled: true},
+	})
+	suite.NoError(dbError)
+
+	count, err := suite.repo.CountEnabledUsersWithRole(context.TODO(), "admin")
+	suite.Empty(err.ErrCode)
+	suite.Equal(int64(1), count)
+}
+
+//Test DeleteUser
+func (suite *UserRepositorySuite) TestDeleteUser(){
+	insertedResult, dbError := suite.collection.InsertOne(context.TODO(), domain.User{Username: "gone", Role: "user"})
+	suite.NoError(dbError)
+	userID := insertedResult.InsertedID.(primitive.ObjectID).Hex()
+
+	err := suite.repo.DeleteUser(context.TODO(), userID)
+	suite.Empty(err.ErrCode)
+
+	err = suite.repo.DeleteUser(context.TODO(), userID)
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
 func TestUserRepositorySuite(t *testing.T) {
 	suite.Run(t, new(UserRepositorySuite))
 }
\ No newline at end of file
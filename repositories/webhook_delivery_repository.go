@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type webhookDeliveryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewWebhookDeliveryRepository creates a new repository for the delivery log of webhooks.
+func NewWebhookDeliveryRepository(db *mongo.Database, deliveryCollectionString string) domain.WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		collection: db.Collection(deliveryCollectionString),
+	}
+}
+
+// CreateDelivery stores a delivery and returns its ID.
+func (dr *webhookDeliveryRepository) CreateDelivery(c context.Context, delivery domain.WebhookDelivery) (string, domain.CustomError) {
+	delivery.ID = ""
+	result, err := dr.collection.InsertOne(c, delivery)
+	if err != nil {
+		return "", domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating webhook delivery"}
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), domain.CustomError{}
+}
+
+// GetDeliveries retrieves one page of a webhook's deliveries, newest first.
+func (dr *webhookDeliveryRepository) GetDeliveries(c context.Context, webhookID string, cursor string, limit int64) (domain.WebhookDeliveryPage, domain.CustomError) {
+	filter := bson.M{"webhook_id": webhookID}
+	total, err := dr.collection.CountDocuments(c, filter)
+	if err != nil {
+		return domain.WebhookDeliveryPage{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while counting webhook deliveries"}
+	}
+
+	if cursor != "" {
+		before, err := primitive.ObjectIDFromHex(cursor)
+		if err != nil {
+			return domain.WebhookDeliveryPage{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid cursor"}
+		}
+		filter["_id"] = bson.M{"$lt": before}
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit + 1)
+	deliveries, customErr := dr.findDeliveries(c, filter, findOptions)
+	if customErr.ErrCode != 0 {
+		return domain.WebhookDeliveryPage{}, customErr
+	}
+
+	page := domain.WebhookDeliveryPage{Deliveries: deliveries, Total: total}
+	if int64(len(deliveries)) > limit {
+		page.Deliveries = deliveries[:limit]
+		page.NextCursor = page.Deliveries[len(page.Deliveries)-1].ID
+	}
+	return page, domain.CustomError{}
+}
+
+// GetDeliveryByID retrieves a single delivery.
+func (dr *webhookDeliveryRepository) GetDeliveryByID(c context.Context, deliveryID string) (domain.WebhookDelivery, domain.CustomError) {
+	objectID, err := primitive.ObjectIDFromHex(deliveryID)
+	if err != nil {
+		return domain.WebhookDelivery{}, deliveryNotFound()
+	}
+
+	var delivery domain.WebhookDelivery
+	err = dr.collection.FindOne(c, bson.M{"_id": objectID}).Decode(&delivery)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.WebhookDelivery{}, deliveryNotFound()
+		}
+		return domain.WebhookDelivery{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving webhook delivery"}
+	}
+	return delivery, domain.CustomError{}
+}
+
+// GetDueDeliveries retrieves the pending deliveries whose next attempt is due, longest waiting first.
+func (dr *webhookDeliveryRepository) GetDueDeliveries(c context.Context, now time.Time, limit int64) ([]domain.WebhookDelivery, domain.CustomError) {
+	filter := bson.M{"status": domain.DeliveryPending, "next_attempt_at": bson.M{"$lte": now}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).SetLimit(limit)
+	return dr.findDeliveries(c, filter, findOptions)
+}
+
+// UpdateDelivery stores the outcome of an attempt: the status, attempt count, response and when the next
+// attempt is due.
+func (dr *webhookDeliveryRepository) UpdateDelivery(c context.Context, delivery domain.WebhookDelivery) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(delivery.ID)
+	if err != nil {
+		return deliveryNotFound()
+	}
+
+	update := bson.M{"$set": bson.M{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"response_status": delivery.ResponseStatus,
+		"last_error":      delivery.LastError,
+		"last_attempt_at": delivery.LastAttemptAt,
+		"next_attempt_at": delivery.NextAttemptAt,
+	}}
+	result, err := dr.collection.UpdateOne(c, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating webhook delivery"}
+	}
+	if result.MatchedCount == 0 {
+		return deliveryNotFound()
+	}
+	return domain.CustomError{}
+}
+
+// DeleteDeliveries removes every delivery of a webhook.
+func (dr *webhookDeliveryRepository) DeleteDeliveries(c context.Context, webhookID string) domain.CustomError {
+	_, err := dr.collection.DeleteMany(c, bson.M{"webhook_id": webhookID})
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while deleting webhook deliveries"}
+	}
+	return domain.CustomError{}
+}
+
+func (dr *webhookDeliveryRepository) findDeliveries(c context.Context, filter bson.M, findOptions *options.FindOptions) ([]domain.WebhookDelivery, domain.CustomError) {
+	results, err := dr.collection.Find(c, filter, findOptions)
+	if err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving webhook deliveries"}
+	}
+
+	deliveries := []domain.WebhookDelivery{}
+	if err := results.All(c, &deliveries); err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving webhook deliveries"}
+	}
+	return deliveries, domain.CustomError{}
+}
+
+func deliveryNotFound() domain.CustomError {
+	return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Webhook delivery not found"}
+}
@@ -0,0 +1,96 @@
+package repositories_test
+
+import (
+	"context"
+	"task_managment_api/domain"
+	"task_managment_api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookDeliveryRepositorySuite struct {
+	suite.Suite
+	db         *mongo.Database
+	collection *mongo.Collection
+	repo       domain.WebhookDeliveryRepository
+}
+
+func (suite *WebhookDeliveryRepositorySuite) SetupTest() {
+	// Clear the collection before each test
+	suite.collection.DeleteMany(context.TODO(), bson.D{})
+}
+
+func (suite *WebhookDeliveryRepositorySuite) SetupSuite() {
+	// Set up a test MongoDB instance
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	suite.Require().NoError(err)
+
+	suite.db = client.Database("task_management_test")
+	suite.collection = suite.db.Collection("webhook_deliveries")
+
+	suite.repo = repositories.NewWebhookDeliveryRepository(suite.db, "webhook_deliveries")
+}
+
+// Test only pending deliveries whose next attempt has come are due
+func (suite *WebhookDeliveryRepositorySuite) TestGetDueDeliveries() {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	earlier := now.Add(-time.Minute)
+	later := now.Add(time.Minute)
+	dueID, err := suite.repo.CreateDelivery(context.TODO(), domain.WebhookDelivery{WebhookID: "hook-1", Status: domain.DeliveryPending, NextAttemptAt: &earlier})
+	suite.Empty(err.ErrCode)
+	_, err = suite.repo.CreateDelivery(context.TODO(), domain.WebhookDelivery{WebhookID: "hook-1", Status: domain.DeliveryPending, NextAttemptAt: &later})
+	suite.Empty(err.ErrCode)
+	_, err = suite.repo.CreateDelivery(context.TODO(), domain.WebhookDelivery{WebhookID: "hook-1", Status: domain.DeliverySucceeded})
+	suite.Empty(err.ErrCode)
+
+	due, err := suite.repo.GetDueDeliveries(context.TODO(), now, 10)
+	suite.Empty(err.ErrCode)
+	suite.Len(due, 1)
+	suite.Equal(dueID, due[0].ID)
+
+	due[0].Status = domain.DeliverySucceeded
+	due[0].Attempts = 1
+	due[0].NextAttemptAt = nil
+	suite.Empty(suite.repo.UpdateDelivery(context.TODO(), due[0]).ErrCode)
+
+	due, err = suite.repo.GetDueDeliveries(context.TODO(), now, 10)
+	suite.Empty(err.ErrCode)
+	suite.Empty(due)
+}
+
+// Test a webhook's deliveries are paged newest first
+func (suite *WebhookDeliveryRepositorySuite) TestGetDeliveries() {
+	ids := []string{}
+	for i := 0; i < 3; i++ {
+		id, err := suite.repo.CreateDelivery(context.TODO(), domain.WebhookDelivery{WebhookID: "hook-1", Status: domain.DeliveryPending})
+		suite.Empty(err.ErrCode)
+		ids = append(ids, id)
+	}
+	_, err := suite.repo.CreateDelivery(context.TODO(), domain.WebhookDelivery{WebhookID: "hook-2", Status: domain.DeliveryPending})
+	suite.Empty(err.ErrCode)
+
+	page, err := suite.repo.GetDeliveries(context.TODO(), "hook-1", "", 2)
+	suite.Empty(err.ErrCode)
+	suite.Equal(int64(3), page.Total)
+	suite.Equal([]string{ids[2], ids[1]}, []string{page.Deliveries[0].ID, page.Deliveries[1].ID})
+
+	page, err = suite.repo.GetDeliveries(context.TODO(), "hook-1", page.NextCursor, 2)
+	suite.Empty(err.ErrCode)
+	suite.Len(page.Deliveries, 1)
+	suite.Equal(ids[0], page.Deliveries[0].ID)
+	suite.Empty(page.NextCursor)
+
+	suite.Empty(suite.repo.DeleteDeliveries(context.TODO(), "hook-1").ErrCode)
+	page, _ = suite.repo.GetDeliveries(context.TODO(), "hook-1", "", 2)
+	suite.Equal(int64(0), page.Total)
+}
+
+func TestWebhookDeliveryRepositorySuite(t *testing.T) {
+	suite.Run(t, new(WebhookDeliveryRepositorySuite))
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type webhookRepository struct {
+	collection *mongo.Collection
+}
+
+// NewWebhookRepository creates a new repository for webhook subscriptions.
+func NewWebhookRepository(db *mongo.Database, webhookCollectionString string) domain.WebhookRepository {
+	return &webhookRepository{
+		collection: db.Collection(webhookCollectionString),
+	}
+}
+
+// CreateWebhook stores a webhook and returns its ID.
+func (wr *webhookRepository) CreateWebhook(c context.Context, webhook domain.Webhook) (string, domain.CustomError) {
+	webhook.ID = ""
+	result, err := wr.collection.InsertOne(c, webhook)
+	if err != nil {
+		return "", domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating webhook"}
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), domain.CustomError{}
+}
+
+// GetWebhooks retrieves every webhook, oldest first.
+func (wr *webhookRepository) GetWebhooks(c context.Context) ([]domain.Webhook, domain.CustomError) {
+	results, err := wr.collection.Find(c, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving webhooks"}
+	}
+
+	webhooks := []domain.Webhook{}
+	if err := results.All(c, &webhooks); err != nil {
+		return nil, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving webhooks"}
+	}
+	return webhooks, domain.CustomError{}
+}
+
+// GetWebhookByID retrieves a single webhook.
+func (wr *webhookRepository) GetWebhookByID(c context.Context, webhookID string) (domain.Webhook, domain.CustomError) {
+	objectID, err := primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return domain.Webhook{}, webhookNotFound()
+	}
+
+	var webhook domain.Webhook
+	err = wr.collection.FindOne(c, bson.M{"_id": objectID}).Decode(&webhook)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Webhook{}, webhookNotFound()
+		}
+		return domain.Webhook{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while retrieving webhook"}
+	}
+	return webhook, domain.CustomError{}
+}
+
+// UpdateWebhook stores a webhook's new URL, events and secret.
+func (wr *webhookRepository) UpdateWebhook(c context.Context, webhook domain.Webhook) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(webhook.ID)
+	if err != nil {
+		return webhookNotFound()
+	}
+
+	update := bson.M{"$set": bson.M{"url": webhook.URL, "events": webhook.Events, "secret": webhook.Secret}}
+	result, err := wr.collection.UpdateOne(c, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while updating webhook"}
+	}
+	if result.MatchedCount == 0 {
+		return webhookNotFound()
+	}
+	return domain.CustomError{}
+}
+
+// DeleteWebhook removes a webhook. Its deliveries are removed by the delivery repository.
+func (wr *webhookRepository) DeleteWebhook(c context.Context, webhookID string) domain.CustomError {
+	objectID, err := primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return webhookNotFound()
+	}
+
+	result, err := wr.collection.DeleteOne(c, bson.M{"_id": objectID})
+	if err != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while deleting webhook"}
+	}
+	if result.DeletedCount == 0 {
+		return webhookNotFound()
+	}
+	return domain.CustomError{}
+}
+
+func webhookNotFound() domain.CustomError {
+	return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Webhook not found"}
+}
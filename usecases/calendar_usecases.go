@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"task_managment_api/domain"
+	"time"
+)
+
+type calendarUsecase struct {
+	feedRepository domain.CalendarFeedRepository
+	userRepository domain.UserRepository
+	roleRepository domain.RoleRepository
+	// taskUsecase lists the feed's tasks with the visibility rules of its owner.
+	taskUsecase domain.TaskUsecase
+}
+
+func NewCalendarUsecase(feedRepository domain.CalendarFeedRepository, userRepository domain.UserRepository, roleRepository domain.RoleRepository, taskUsecase domain.TaskUsecase) domain.CalendarUsecase {
+	return &calendarUsecase{
+		feedRepository: feedRepository,
+		userRepository: userRepository,
+		roleRepository: roleRepository,
+		taskUsecase:    taskUsecase,
+	}
+}
+
+// CreateFeedToken creates a new feed token for the caller and revokes the one they had. The token is
+// only returned here; the feed keeps a hash of it.
+func (cu *calendarUsecase) CreateFeedToken(c context.Context, user domain.AuthUser) (domain.CalendarToken, domain.CustomError) {
+	token, randErr := newOpaqueToken(32)
+	if randErr != nil {
+		return domain.CalendarToken{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating calendar token"}
+	}
+
+	feed := domain.CalendarFeed{UserID: user.UserID, TokenHash: hashOpaqueToken(token), CreatedAt: time.Now().UTC()}
+	if err := cu.feedRepository.ReplaceFeed(c, feed); err.ErrCode != 0 {
+		return domain.CalendarToken{}, err
+	}
+	return domain.CalendarToken{Token: token, CreatedAt: feed.CreatedAt}, domain.CustomError{}
+}
+
+// GetFeedToken tells whether the caller has a feed token and when it was created.
+func (cu *calendarUsecase) GetFeedToken(c context.Context, user domain.AuthUser) (domain.CalendarFeed, domain.CustomError) {
+	return cu.feedRepository.GetFeedByUserID(c, user.UserID)
+}
+
+// RevokeFeedToken deletes the caller's feed, so its URL stops working.
+func (cu *calendarUsecase) RevokeFeedToken(c context.Context, user domain.AuthUser) domain.CustomError {
+	return cu.feedRepository.DeleteFeed(c, user.UserID)
+}
+
+// GetFeed returns the calendar of the feed the token belongs to: the tasks with a due date that its owner
+// can still see and is assigned to, or created without assigning anyone, from
+// CalendarFeedLookback ago onwards.
+func (cu *calendarUsecase) GetFeed(c context.Context, token string, kind string) (string, domain.CustomError) {
+	token = strings.TrimSuffix(token, ".ics")
+	if token == "" {
+		return "", calendarFeedNotFound()
+	}
+	feed, err := cu.feedRepository.GetFeedByTokenHash(c, hashOpaqueToken(token))
+	if err.ErrCode != 0 {
+		return "", err
+	}
+	owner, err := cu.userRepository.GetUserByID(c, feed.UserID)
+	if err.ErrCode == http.StatusNotFound || owner.Disabled {
+		return "", calendarFeedNotFound()
+	}
+	if err.ErrCode != 0 {
+		return "", err
+	}
+
+	// the feed sees what its owner's role allows now, as the auth middleware would for a request of theirs
+	permissions, err := rolePermissions(c, cu.roleRepository, owner.Role)
+	if err.ErrCode != 0 {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	from := now.Add(-domain.CalendarFeedLookback)
+	user := domain.AuthUser{UserID: owner.ID, Username: owner.Username, Role: owner.Role, Permissions: permissions}
+	query := domain.TaskQuery{DueFrom: &from, SortBy: "due_date", SortOrder: "asc"}
+	tasks := []domain.Task{}
+	err = cu.taskUsecase.ExportTasks(c, user, query, func(page []domain.Task) error {
+		for _, task := range page {
+			if containsID(domain.ReminderRecipients(task), owner.ID) {
+				tasks = append(tasks, task)
+			}
+		}
+		return nil
+	})
+	if err.ErrCode != 0 {
+		return "", err
+	}
+	return domain.BuildCalendar("Tasks of "+owner.Username, tasks, kind, now), domain.CustomError{}
+}
+
+func calendarFeedNotFound() domain.CustomError {
+	return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Calendar feed not found"}
+}
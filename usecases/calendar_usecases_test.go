@@ -0,0 +1,147 @@
+package usecases_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"task_managment_api/domain"
+	"task_managment_api/usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockCalendarFeedRepository struct {
+	mock.Mock
+}
+
+func (m *MockCalendarFeedRepository) ReplaceFeed(c context.Context, feed domain.CalendarFeed) domain.CustomError {
+	args := m.Called(c, feed)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockCalendarFeedRepository) GetFeedByUserID(c context.Context, userID string) (domain.CalendarFeed, domain.CustomError) {
+	args := m.Called(c, userID)
+	return args.Get(0).(domain.CalendarFeed), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCalendarFeedRepository) GetFeedByTokenHash(c context.Context, tokenHash string) (domain.CalendarFeed, domain.CustomError) {
+	args := m.Called(c, tokenHash)
+	return args.Get(0).(domain.CalendarFeed), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCalendarFeedRepository) DeleteFeed(c context.Context, userID string) domain.CustomError {
+	args := m.Called(c, userID)
+	return args.Get(0).(domain.CustomError)
+}
+
+type CalendarUsecaseSuite struct {
+	suite.Suite
+	mockFeedRepo *MockCalendarFeedRepository
+	mockUserRepo *MockUserRepository
+	mockRoleRepo *MockRoleRepository
+	mockTaskRepo *MockTaskRepository
+	usecase      domain.CalendarUsecase
+}
+
+func (suite *CalendarUsecaseSuite) SetupTest() {
+	suite.mockFeedRepo = new(MockCalendarFeedRepository)
+	suite.mockUserRepo = new(MockUserRepository)
+	suite.mockRoleRepo = new(MockRoleRepository)
+	suite.mockTaskRepo = new(MockTaskRepository)
+	// the feed reads tasks through the task usecase, which only needs the task repository for an admin
+	taskUsecase := usecases.NewTaskUsecase(suite.mockTaskRepo, suite.mockUserRepo, suite.mockRoleRepo, new(MockTaskHistoryRepository), new(MockLabelRepository), new(MockProjectRepository), new(MockTaskSeriesRepository), new(MockTaskEventPublisher), new(MockTransactionRunner), domain.DefaultStatusWorkflow, domain.SubtaskDeleteBlock)
+	suite.usecase = usecases.NewCalendarUsecase(suite.mockFeedRepo, suite.mockUserRepo, suite.mockRoleRepo, taskUsecase)
+}
+
+func calendarTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Test a new token is returned once and only its hash is stored
+func (suite *CalendarUsecaseSuite) TestCreateFeedToken() {
+	var stored domain.CalendarFeed
+	suite.mockFeedRepo.On("ReplaceFeed", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(domain.CalendarFeed)
+	}).Return(domain.CustomError{})
+
+	token, err := suite.usecase.CreateFeedToken(context.TODO(), domain.AuthUser{UserID: "user-1"})
+
+	suite.Empty(err.ErrMessage)
+	suite.Len(token.Token, 43)
+	suite.Equal("user-1", stored.UserID)
+	suite.Equal(calendarTokenHash(token.Token), stored.TokenHash)
+	suite.NotContains(stored.TokenHash, token.Token)
+}
+
+// Test the feed holds the owner's tasks with a due date and stable UIDs
+func (suite *CalendarUsecaseSuite) TestGetFeed() {
+	due := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+	suite.mockFeedRepo.On("GetFeedByTokenHash", mock.Anything, calendarTokenHash("secret")).Return(domain.CalendarFeed{UserID: "admin-1"}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "admin-1").Return(domain.User{ID: "admin-1", Username: "ada", Role: "admin"}, domain.CustomError{})
+	suite.mockTaskRepo.On("GetTasks", mock.Anything, mock.MatchedBy(func(query domain.TaskQuery) bool {
+		return query.DueFrom != nil && query.SortBy == "due_date"
+	})).Return(domain.TaskPage{Tasks: []domain.Task{
+		{ID: "t1", Title: "Assigned", DueDate: &due, AssigneeIDs: []string{"admin-1"}},
+		{ID: "t2", Title: "Created", DueDate: &due, CreatedBy: "admin-1"},
+		{ID: "t3", Title: "Someone else's", DueDate: &due, CreatedBy: "admin-1", AssigneeIDs: []string{"user-2"}},
+	}}, domain.CustomError{})
+
+	calendar, err := suite.usecase.GetFeed(context.TODO(), "secret.ics", domain.CalendarKindEvent)
+
+	suite.Empty(err.ErrMessage)
+	suite.Contains(calendar, "X-WR-CALNAME:Tasks of ada\r\n")
+	suite.Contains(calendar, "UID:t1@task-management-api\r\n")
+	suite.Contains(calendar, "UID:t2@task-management-api\r\n")
+	suite.NotContains(calendar, "t3@")
+	suite.Equal(2, strings.Count(calendar, "BEGIN:VEVENT"))
+}
+
+// Test the feed uses the permissions the owner's role has in the database
+func (suite *CalendarUsecaseSuite) TestGetFeed_RolePermissions() {
+	due := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+	suite.mockFeedRepo.On("GetFeedByTokenHash", mock.Anything, calendarTokenHash("secret")).Return(domain.CalendarFeed{UserID: "user-1"}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Username: "alice", Role: "auditor"}, domain.CustomError{})
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "auditor").Return(domain.Role{Name: "auditor", Permissions: []domain.Permission{domain.PermissionProjectManageAll}}, domain.CustomError{})
+	suite.mockTaskRepo.On("GetTasks", mock.Anything, mock.MatchedBy(func(query domain.TaskQuery) bool {
+		return query.VisibleTo == "" && query.VisibleProjectIDs == nil
+	})).Return(domain.TaskPage{Tasks: []domain.Task{
+		{ID: "t1", Title: "Assigned", DueDate: &due, ProjectID: "project-9", AssigneeIDs: []string{"user-1"}},
+	}}, domain.CustomError{})
+
+	calendar, err := suite.usecase.GetFeed(context.TODO(), "secret", domain.CalendarKindEvent)
+
+	suite.Empty(err.ErrMessage)
+	suite.Contains(calendar, "UID:t1@task-management-api\r\n")
+	suite.mockRoleRepo.AssertExpectations(suite.T())
+}
+
+// Test unknown and revoked tokens are not found
+func (suite *CalendarUsecaseSuite) TestGetFeed_UnknownToken() {
+	suite.mockFeedRepo.On("GetFeedByTokenHash", mock.Anything, mock.Anything).Return(domain.CalendarFeed{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Calendar feed not found"})
+
+	_, err := suite.usecase.GetFeed(context.TODO(), "revoked", domain.CalendarKindEvent)
+
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+	suite.mockTaskRepo.AssertNotCalled(suite.T(), "GetTasks", mock.Anything, mock.Anything)
+}
+
+// Test the feed of a deleted user is not found
+func (suite *CalendarUsecaseSuite) TestGetFeed_DeletedUser() {
+	suite.mockFeedRepo.On("GetFeedByTokenHash", mock.Anything, mock.Anything).Return(domain.CalendarFeed{UserID: "gone"}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "gone").Return(domain.User{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "User not found"})
+
+	_, err := suite.usecase.GetFeed(context.TODO(), "secret", domain.CalendarKindEvent)
+
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+	suite.Equal("Calendar feed not found", err.ErrMessage)
+}
+
+func TestCalendarUsecaseSuite(t *testing.T) {
+	suite.Run(t, new(CalendarUsecaseSuite))
+}
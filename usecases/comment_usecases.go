@@ -0,0 +1,257 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"task_managment_api/domain"
+	"time"
+	"unicode/utf8"
+)
+
+type commentUsecase struct {
+	commentRepository domain.CommentRepository
+	taskRepository    domain.TaskRepository
+	userRepository    domain.UserRepository
+	access            projectAccess
+	// editWindow is how long after posting the author can still change a comment.
+	editWindow time.Duration
+}
+
+func NewCommentUsecase(commentRepository domain.CommentRepository, taskRepository domain.TaskRepository, userRepository domain.UserRepository, projectRepository domain.ProjectRepository, editWindow time.Duration) domain.CommentUsecase {
+	if editWindow <= 0 {
+		editWindow = domain.DefaultCommentEditWindow
+	}
+	return &commentUsecase{
+		commentRepository: commentRepository,
+		taskRepository:    taskRepository,
+		userRepository:    userRepository,
+		access:            projectAccess{projectRepository: projectRepository},
+		editWindow:        editWindow,
+	}
+}
+
+// CreateComment posts a comment on a task the caller can change; project viewers can only read comments. A reply to a reply joins the thread of
+// the comment it answers.
+func (cu *commentUsecase) CreateComment(c context.Context, user domain.AuthUser, taskId string, input domain.CommentInput) (domain.Comment, domain.CustomError) {
+	body, err := validateCommentBody(input.Body)
+	if err.ErrCode != 0 {
+		return domain.Comment{}, err
+	}
+	task, err := cu.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return domain.Comment{}, err
+	}
+
+	comment := domain.Comment{
+		TaskID:         taskId,
+		AuthorID:       user.UserID,
+		AuthorUsername: user.Username,
+		Body:           body,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if input.ParentID != "" {
+		parent, err := cu.commentRepository.GetCommentByID(c, input.ParentID)
+		if err.ErrCode == http.StatusNotFound || (err.ErrCode == 0 && parent.TaskID != taskId) {
+			return domain.Comment{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "The comment being replied to was not found on this task", Field: "parent_id"}
+		}
+		if err.ErrCode != 0 {
+			return domain.Comment{}, err
+		}
+		comment.ParentID = parent.ID
+		if parent.ParentID != "" {
+			comment.ParentID = parent.ParentID
+		}
+	}
+
+	comment.Mentions, err = cu.resolveMentions(c, task, body)
+	if err.ErrCode != 0 {
+		return domain.Comment{}, err
+	}
+
+	comment.ID, err = cu.commentRepository.CreateComment(c, comment)
+	if err.ErrCode != 0 {
+		return domain.Comment{}, err
+	}
+	return comment, domain.CustomError{}
+}
+
+// GetComments returns a page of a task's threads, oldest first, each with all of its replies.
+func (cu *commentUsecase) GetComments(c context.Context, user domain.AuthUser, taskId string, cursor string, limit int64) (domain.CommentPage, domain.CustomError) {
+	limit, err := pageLimit(limit)
+	if err.ErrCode != 0 {
+		return domain.CommentPage{}, err
+	}
+	if _, err := cu.getVisibleTask(c, user, taskId); err.ErrCode != 0 {
+		return domain.CommentPage{}, err
+	}
+
+	page, err := cu.commentRepository.GetThreads(c, taskId, cursor, limit)
+	if err.ErrCode != 0 || len(page.Comments) == 0 {
+		return page, err
+	}
+
+	threadIDs := make([]string, len(page.Comments))
+	for i, comment := range page.Comments {
+		threadIDs[i] = comment.ID
+	}
+	replies, err := cu.commentRepository.GetReplies(c, threadIDs)
+	if err.ErrCode != 0 {
+		return domain.CommentPage{}, err
+	}
+	for i := range page.Comments {
+		page.Comments[i].Replies = []domain.Comment{}
+		for _, reply := range replies {
+			if reply.ParentID == page.Comments[i].ID {
+				page.Comments[i].Replies = append(page.Comments[i].Replies, reply)
+			}
+		}
+	}
+	return page, domain.CustomError{}
+}
+
+// UpdateComment changes a comment's body. Authors can edit their comments within the edit window;
+// admins can edit any comment at any time.
+func (cu *commentUsecase) UpdateComment(c context.Context, user domain.AuthUser, taskId string, commentId string, body string) (domain.Comment, domain.CustomError) {
+	body, err := validateCommentBody(body)
+	if err.ErrCode != 0 {
+		return domain.Comment{}, err
+	}
+	task, err := cu.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return domain.Comment{}, err
+	}
+	comment, err := cu.getTaskComment(c, taskId, commentId)
+	if err.ErrCode != 0 {
+		return domain.Comment{}, err
+	}
+
+	if !user.Can(domain.PermissionCommentModerate) {
+		if comment.AuthorID != user.UserID {
+			return domain.Comment{}, domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "Only the author or an admin can edit this comment"}
+		}
+		if time.Since(comment.CreatedAt) > cu.editWindow {
+			return domain.Comment{}, domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: fmt.Sprintf("Comments can only be edited within %s of posting", cu.editWindow)}
+		}
+	}
+
+	comment.Mentions, err = cu.resolveMentions(c, task, body)
+	if err.ErrCode != 0 {
+		return domain.Comment{}, err
+	}
+	editedAt := time.Now().UTC()
+	comment.Body = body
+	comment.EditedAt = &editedAt
+	comment.EditedBy = user.UserID
+
+	if err := cu.commentRepository.UpdateComment(c, comment); err.ErrCode != 0 {
+		return domain.Comment{}, err
+	}
+	return comment, domain.CustomError{}
+}
+
+// DeleteComment removes a comment and, for a top-level comment, its whole thread. Authors can delete
+// their own comments and admins any comment.
+func (cu *commentUsecase) DeleteComment(c context.Context, user domain.AuthUser, taskId string, commentId string) domain.CustomError {
+	if _, err := cu.getWritableTask(c, user, taskId); err.ErrCode != 0 {
+		return err
+	}
+	comment, err := cu.getTaskComment(c, taskId, commentId)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if !user.Can(domain.PermissionCommentModerate) && comment.AuthorID != user.UserID {
+		return domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "Only the author or an admin can delete this comment"}
+	}
+	return cu.commentRepository.DeleteComment(c, comment.ID)
+}
+
+// GetMentions returns a page of the comments that mention the caller, newest first.
+func (cu *commentUsecase) GetMentions(c context.Context, user domain.AuthUser, cursor string, limit int64) (domain.CommentPage, domain.CustomError) {
+	limit, err := pageLimit(limit)
+	if err.ErrCode != 0 {
+		return domain.CommentPage{}, err
+	}
+	return cu.commentRepository.GetMentions(c, user.UserID, cursor, limit)
+}
+
+// resolveMentions looks up the users mentioned in a comment. Unknown usernames and users who cannot
+// see the task are left out, so a mention never reveals a task to someone outside it.
+func (cu *commentUsecase) resolveMentions(c context.Context, task domain.Task, body string) ([]string, domain.CustomError) {
+	mentions := []string{}
+	for _, username := range domain.ParseMentions(body) {
+		mentioned, err := cu.userRepository.GetUserByUsername(c, username)
+		if err.ErrCode == http.StatusInternalServerError {
+			return nil, err
+		}
+		if err.ErrCode != 0 {
+			// the repository reports an unknown username as a client error
+			continue
+		}
+		role, err := cu.access.roleFor(c, domain.AuthUser{UserID: mentioned.ID, Username: mentioned.Username, Role: mentioned.Role}, task)
+		if err.ErrCode != 0 {
+			return nil, err
+		}
+		if role.AtLeast(domain.ProjectRoleViewer) {
+			mentions = append(mentions, mentioned.ID)
+		}
+	}
+	return mentions, domain.CustomError{}
+}
+
+func (cu *commentUsecase) getVisibleTask(c context.Context, user domain.AuthUser, taskId string) (domain.Task, domain.CustomError) {
+	task, err := cu.taskRepository.GetTaskByID(c, taskId)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	if err := cu.access.checkRead(c, user, task); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	return task, domain.CustomError{}
+}
+
+func (cu *commentUsecase) getWritableTask(c context.Context, user domain.AuthUser, taskId string) (domain.Task, domain.CustomError) {
+	task, err := cu.taskRepository.GetTaskByID(c, taskId)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	if err := cu.access.checkWrite(c, user, task); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	return task, domain.CustomError{}
+}
+
+// getTaskComment loads a comment and makes sure it belongs to the task in the URL.
+func (cu *commentUsecase) getTaskComment(c context.Context, taskId string, commentId string) (domain.Comment, domain.CustomError) {
+	comment, err := cu.commentRepository.GetCommentByID(c, commentId)
+	if err.ErrCode != 0 {
+		return domain.Comment{}, err
+	}
+	if comment.TaskID != taskId {
+		return domain.Comment{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Comment not found"}
+	}
+	return comment, domain.CustomError{}
+}
+
+func validateCommentBody(body string) (string, domain.CustomError) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "body is required", Field: "body"}
+	}
+	if utf8.RuneCountInString(body) > domain.MaxCommentLength {
+		return "", domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("body must be at most %d characters", domain.MaxCommentLength), Field: "body"}
+	}
+	return body, domain.CustomError{}
+}
+
+// pageLimit applies the default page size and rejects sizes above the maximum.
+func pageLimit(limit int64) (int64, domain.CustomError) {
+	if limit < 0 || limit > domain.MaxTaskPageSize {
+		return 0, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("limit must be between 1 and %d", domain.MaxTaskPageSize), Field: "limit"}
+	}
+	if limit == 0 {
+		limit = domain.DefaultTaskPageSize
+	}
+	return limit, domain.CustomError{}
+}
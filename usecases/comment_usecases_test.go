@@ -0,0 +1,234 @@
+package usecases_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommentRepository) CreateComment(c context.Context, comment domain.Comment) (string, domain.CustomError) {
+	args := m.Called(c, comment)
+	return args.String(0), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCommentRepository) GetCommentByID(c context.Context, commentID string) (domain.Comment, domain.CustomError) {
+	args := m.Called(c, commentID)
+	return args.Get(0).(domain.Comment), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCommentRepository) GetThreads(c context.Context, taskID string, cursor string, limit int64) (domain.CommentPage, domain.CustomError) {
+	args := m.Called(c, taskID, cursor, limit)
+	return args.Get(0).(domain.CommentPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCommentRepository) GetReplies(c context.Context, parentIDs []string) ([]domain.Comment, domain.CustomError) {
+	args := m.Called(c, parentIDs)
+	return args.Get(0).([]domain.Comment), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCommentRepository) GetMentions(c context.Context, userID string, cursor string, limit int64) (domain.CommentPage, domain.CustomError) {
+	args := m.Called(c, userID, cursor, limit)
+	return args.Get(0).(domain.CommentPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockCommentRepository) UpdateComment(c context.Context, comment domain.Comment) domain.CustomError {
+	args := m.Called(c, comment)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockCommentRepository) DeleteComment(c context.Context, commentID string) domain.CustomError {
+	args := m.Called(c, commentID)
+	return args.Get(0).(domain.CustomError)
+}
+
+type CommentUsecaseSuite struct {
+	suite.Suite
+	mockCommentRepo *MockCommentRepository
+	mockTaskRepo    *MockTaskRepository
+	mockUserRepo    *MockUserRepository
+	mockProjectRepo *MockProjectRepository
+	usecase         domain.CommentUsecase
+	admin           domain.AuthUser
+	user            domain.AuthUser
+	task            domain.Task
+}
+
+func (suite *CommentUsecaseSuite) SetupTest() {
+	suite.mockCommentRepo = new(MockCommentRepository)
+	suite.mockTaskRepo = new(MockTaskRepository)
+	suite.mockUserRepo = new(MockUserRepository)
+	suite.mockProjectRepo = new(MockProjectRepository)
+	suite.usecase = usecases.NewCommentUsecase(suite.mockCommentRepo, suite.mockTaskRepo, suite.mockUserRepo, suite.mockProjectRepo, 10*time.Minute)
+	suite.admin = domain.AuthUser{UserID: "admin-1", Username: "admin", Role: "admin"}
+	suite.user = domain.AuthUser{UserID: "user-1", Username: "alice", Role: "user"}
+	suite.task = domain.Task{ID: "task-1", CreatedBy: "user-1", AssigneeIDs: []string{"user-2"}}
+	suite.mockTaskRepo.On("GetTaskByID", mock.Anything, "task-1").Return(suite.task, domain.CustomError{})
+}
+
+// Test CreateComment resolves mentions of users who can see the task
+func (suite *CommentUsecaseSuite) TestCreateComment_Mentions() {
+	suite.mockUserRepo.On("GetUserByUsername", mock.Anything, "bob").Return(domain.User{ID: "user-2", Username: "bob", Role: "user"}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByUsername", mock.Anything, "carol").Return(domain.User{ID: "user-3", Username: "carol", Role: "user"}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByUsername", mock.Anything, "nobody").Return(domain.User{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "User not found"})
+	suite.mockCommentRepo.On("CreateComment", mock.Anything, mock.MatchedBy(func(comment domain.Comment) bool {
+		return comment.AuthorID == "user-1" && comment.AuthorUsername == "alice" && comment.Body == "@bob @carol @nobody have a look"
+	})).Return("comment-1", domain.CustomError{})
+
+	comment, err := suite.usecase.CreateComment(context.TODO(), suite.user, "task-1", domain.CommentInput{Body: "  @bob @carol @nobody have a look "})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal("comment-1", comment.ID)
+	// carol is neither the creator nor an assignee of the task
+	suite.Equal([]string{"user-2"}, comment.Mentions)
+	suite.mockCommentRepo.AssertExpectations(suite.T())
+}
+
+// Test a reply to a reply joins the thread of the top-level comment
+func (suite *CommentUsecaseSuite) TestCreateComment_ReplyToReply() {
+	suite.mockCommentRepo.On("GetCommentByID", mock.Anything, "reply-1").Return(domain.Comment{ID: "reply-1", TaskID: "task-1", ParentID: "comment-1"}, domain.CustomError{})
+	suite.mockCommentRepo.On("CreateComment", mock.Anything, mock.Anything).Return("reply-2", domain.CustomError{})
+
+	comment, err := suite.usecase.CreateComment(context.TODO(), suite.user, "task-1", domain.CommentInput{Body: "Agreed", ParentID: "reply-1"})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal("comment-1", comment.ParentID)
+}
+
+// Test replying to a comment on another task
+func (suite *CommentUsecaseSuite) TestCreateComment_ParentOnOtherTask() {
+	suite.mockCommentRepo.On("GetCommentByID", mock.Anything, "comment-9").Return(domain.Comment{ID: "comment-9", TaskID: "task-9"}, domain.CustomError{})
+
+	_, err := suite.usecase.CreateComment(context.TODO(), suite.user, "task-1", domain.CommentInput{Body: "Hi", ParentID: "comment-9"})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("parent_id", err.Field)
+	suite.mockCommentRepo.AssertNotCalled(suite.T(), "CreateComment", mock.Anything, mock.Anything)
+}
+
+// Test CreateComment on a task the caller cannot see
+func (suite *CommentUsecaseSuite) TestCreateComment_Forbidden() {
+	outsider := domain.AuthUser{UserID: "user-9", Role: "user"}
+
+	_, err := suite.usecase.CreateComment(context.TODO(), outsider, "task-1", domain.CommentInput{Body: "Hi"})
+
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+}
+
+// Test project viewers can read comments but not post them, and only project members are mentioned
+func (suite *CommentUsecaseSuite) TestProjectTaskComments() {
+	task := domain.Task{ID: "task-2", ProjectID: "project-1", CreatedBy: "user-1"}
+	project := domain.Project{ID: "project-1", Members: []domain.ProjectMember{
+		{UserID: "user-1", Role: domain.ProjectRoleMember},
+		{UserID: "user-2", Role: domain.ProjectRoleViewer},
+	}}
+	viewer := domain.AuthUser{UserID: "user-2", Username: "bob", Role: "user"}
+	suite.mockTaskRepo.On("GetTaskByID", mock.Anything, "task-2").Return(task, domain.CustomError{})
+	suite.mockProjectRepo.On("GetProjectByID", mock.Anything, "project-1").Return(project, domain.CustomError{})
+	suite.mockCommentRepo.On("GetThreads", mock.Anything, "task-2", "", int64(domain.DefaultTaskPageSize)).Return(domain.CommentPage{Comments: []domain.Comment{}}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByUsername", mock.Anything, "bob").Return(domain.User{ID: "user-2", Username: "bob", Role: "user"}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByUsername", mock.Anything, "carol").Return(domain.User{ID: "user-3", Username: "carol", Role: "user"}, domain.CustomError{})
+	suite.mockCommentRepo.On("CreateComment", mock.Anything, mock.Anything).Return("c1", domain.CustomError{})
+
+	_, err := suite.usecase.GetComments(context.TODO(), viewer, "task-2", "", 0)
+	suite.Empty(err.ErrMessage)
+
+	_, err = suite.usecase.CreateComment(context.TODO(), viewer, "task-2", domain.CommentInput{Body: "Hi"})
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+
+	comment, err := suite.usecase.CreateComment(context.TODO(), suite.user, "task-2", domain.CommentInput{Body: "@bob @carol look"})
+	suite.Empty(err.ErrMessage)
+	suite.Equal([]string{"user-2"}, comment.Mentions)
+}
+
+// Test GetComments attaches replies to their threads
+func (suite *CommentUsecaseSuite) TestGetComments() {
+	threads := domain.CommentPage{Comments: []domain.Comment{{ID: "c1"}, {ID: "c2"}}, Total: 2}
+	suite.mockCommentRepo.On("GetThreads", mock.Anything, "task-1", "", int64(domain.DefaultTaskPageSize)).Return(threads, domain.CustomError{})
+	suite.mockCommentRepo.On("GetReplies", mock.Anything, []string{"c1", "c2"}).Return([]domain.Comment{{ID: "r1", ParentID: "c2"}}, domain.CustomError{})
+
+	page, err := suite.usecase.GetComments(context.TODO(), suite.user, "task-1", "", 0)
+
+	suite.Empty(err.ErrMessage)
+	suite.Empty(page.Comments[0].Replies)
+	suite.Equal([]domain.Comment{{ID: "r1", ParentID: "c2"}}, page.Comments[1].Replies)
+}
+
+// Test authors cannot edit a comment once the edit window has passed
+func (suite *CommentUsecaseSuite) TestUpdateComment_WindowExpired() {
+	comment := domain.Comment{ID: "c1", TaskID: "task-1", AuthorID: "user-1", CreatedAt: time.Now().Add(-time.Hour)}
+	suite.mockCommentRepo.On("GetCommentByID", mock.Anything, "c1").Return(comment, domain.CustomError{})
+
+	_, err := suite.usecase.UpdateComment(context.TODO(), suite.user, "task-1", "c1", "Edited")
+
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+	suite.mockCommentRepo.AssertNotCalled(suite.T(), "UpdateComment", mock.Anything, mock.Anything)
+}
+
+// Test authors can edit within the edit window
+func (suite *CommentUsecaseSuite) TestUpdateComment_Author() {
+	comment := domain.Comment{ID: "c1", TaskID: "task-1", AuthorID: "user-1", CreatedAt: time.Now().Add(-time.Minute)}
+	suite.mockCommentRepo.On("GetCommentByID", mock.Anything, "c1").Return(comment, domain.CustomError{})
+	suite.mockCommentRepo.On("UpdateComment", mock.Anything, mock.Anything).Return(domain.CustomError{})
+
+	updated, err := suite.usecase.UpdateComment(context.TODO(), suite.user, "task-1", "c1", "Edited")
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal("Edited", updated.Body)
+	suite.NotNil(updated.EditedAt)
+	suite.Equal("user-1", updated.EditedBy)
+}
+
+// Test admins can moderate any comment after the edit window
+func (suite *CommentUsecaseSuite) TestUpdateComment_AdminModerates() {
+	comment := domain.Comment{ID: "c1", TaskID: "task-1", AuthorID: "user-2", CreatedAt: time.Now().Add(-24 * time.Hour)}
+	suite.mockCommentRepo.On("GetCommentByID", mock.Anything, "c1").Return(comment, domain.CustomError{})
+	suite.mockCommentRepo.On("UpdateComment", mock.Anything, mock.Anything).Return(domain.CustomError{})
+
+	updated, err := suite.usecase.UpdateComment(context.TODO(), suite.admin, "task-1", "c1", "[removed by moderator]")
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal("admin-1", updated.EditedBy)
+}
+
+// Test users cannot delete someone else's comment
+func (suite *CommentUsecaseSuite) TestDeleteComment_NotAuthor() {
+	suite.mockCommentRepo.On("GetCommentByID", mock.Anything, "c1").Return(domain.Comment{ID: "c1", TaskID: "task-1", AuthorID: "user-2"}, domain.CustomError{})
+
+	err := suite.usecase.DeleteComment(context.TODO(), suite.user, "task-1", "c1")
+
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+	suite.mockCommentRepo.AssertNotCalled(suite.T(), "DeleteComment", mock.Anything, mock.Anything)
+}
+
+// Test a comment is only found under its own task
+func (suite *CommentUsecaseSuite) TestDeleteComment_WrongTask() {
+	suite.mockCommentRepo.On("GetCommentByID", mock.Anything, "c1").Return(domain.Comment{ID: "c1", TaskID: "task-9", AuthorID: "admin-1"}, domain.CustomError{})
+
+	err := suite.usecase.DeleteComment(context.TODO(), suite.admin, "task-1", "c1")
+
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+// Test GetMentions looks up the caller's mentions
+func (suite *CommentUsecaseSuite) TestGetMentions() {
+	suite.mockCommentRepo.On("GetMentions", mock.Anything, "user-1", "", int64(5)).Return(domain.CommentPage{Comments: []domain.Comment{{ID: "c1"}}, Total: 1}, domain.CustomError{})
+
+	page, err := suite.usecase.GetMentions(context.TODO(), suite.user, "", 5)
+
+	suite.Empty(err.ErrMessage)
+	suite.Len(page.Comments, 1)
+}
+
+func TestCommentUsecaseSuite(t *testing.T) {
+	suite.Run(t, new(CommentUsecaseSuite))
+}
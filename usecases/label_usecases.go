@@ -0,0 +1,91 @@
+package usecases
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+)
+
+type labelUsecase struct {
+	labelRepository domain.LabelRepository
+	taskRepository  domain.TaskRepository
+}
+
+func NewLabelUsecase(labelRepository domain.LabelRepository, taskRepository domain.TaskRepository) domain.LabelUsecase {
+	return &labelUsecase{
+		labelRepository: labelRepository,
+		taskRepository:  taskRepository,
+	}
+}
+
+// CreateLabel stores a new label. Names are unique regardless of case.
+func (lu *labelUsecase) CreateLabel(c context.Context, input domain.LabelInput) (domain.Label, domain.CustomError) {
+	label, err := domain.NormalizeLabel(input)
+	if err.ErrCode != 0 {
+		return domain.Label{}, err
+	}
+	if err := lu.checkNameFree(c, label.Name, ""); err.ErrCode != 0 {
+		return domain.Label{}, err
+	}
+
+	label.ID, err = lu.labelRepository.CreateLabel(c, label)
+	if err.ErrCode != 0 {
+		return domain.Label{}, err
+	}
+	return label, domain.CustomError{}
+}
+
+func (lu *labelUsecase) GetLabels(c context.Context) ([]domain.Label, domain.CustomError) {
+	return lu.labelRepository.GetLabels(c)
+}
+
+func (lu *labelUsecase) GetLabelByID(c context.Context, labelId string) (domain.Label, domain.CustomError) {
+	return lu.labelRepository.GetLabelByID(c, labelId)
+}
+
+// UpdateLabel renames or recolors a label. Tasks refer to labels by ID, so they pick up the change.
+func (lu *labelUsecase) UpdateLabel(c context.Context, labelId string, input domain.LabelInput) (domain.Label, domain.CustomError) {
+	label, err := domain.NormalizeLabel(input)
+	if err.ErrCode != 0 {
+		return domain.Label{}, err
+	}
+	if _, err := lu.labelRepository.GetLabelByID(c, labelId); err.ErrCode != 0 {
+		return domain.Label{}, err
+	}
+	if err := lu.checkNameFree(c, label.Name, labelId); err.ErrCode != 0 {
+		return domain.Label{}, err
+	}
+
+	label.ID = labelId
+	if err := lu.labelRepository.UpdateLabel(c, label); err.ErrCode != 0 {
+		return domain.Label{}, err
+	}
+	return label, domain.CustomError{}
+}
+
+// DeleteLabel takes the label off every task and then deletes it, so no task is left pointing at a
+// label that no longer exists.
+func (lu *labelUsecase) DeleteLabel(c context.Context, labelId string) domain.CustomError {
+	if _, err := lu.labelRepository.GetLabelByID(c, labelId); err.ErrCode != 0 {
+		return err
+	}
+	if _, err := lu.taskRepository.RemoveLabelFromTasks(c, labelId); err.ErrCode != 0 {
+		return err
+	}
+	return lu.labelRepository.DeleteLabel(c, labelId)
+}
+
+// checkNameFree makes sure no other label already uses the name.
+func (lu *labelUsecase) checkNameFree(c context.Context, name string, labelId string) domain.CustomError {
+	existing, err := lu.labelRepository.GetLabelByName(c, name)
+	if err.ErrCode == http.StatusNotFound {
+		return domain.CustomError{}
+	}
+	if err.ErrCode != 0 {
+		return err
+	}
+	if existing.ID != labelId {
+		return domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "A label with this name already exists", Field: "name"}
+	}
+	return domain.CustomError{}
+}
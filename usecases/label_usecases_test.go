@@ -0,0 +1,133 @@
+package usecases_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockLabelRepository struct {
+	mock.Mock
+}
+
+func (m *MockLabelRepository) CreateLabel(c context.Context, label domain.Label) (string, domain.CustomError) {
+	args := m.Called(c, label)
+	return args.String(0), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockLabelRepository) GetLabels(c context.Context) ([]domain.Label, domain.CustomError) {
+	args := m.Called(c)
+	return args.Get(0).([]domain.Label), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockLabelRepository) GetLabelByID(c context.Context, labelID string) (domain.Label, domain.CustomError) {
+	args := m.Called(c, labelID)
+	return args.Get(0).(domain.Label), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockLabelRepository) GetLabelByName(c context.Context, name string) (domain.Label, domain.CustomError) {
+	args := m.Called(c, name)
+	return args.Get(0).(domain.Label), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockLabelRepository) GetLabelsByIDs(c context.Context, labelIDs []string) ([]domain.Label, domain.CustomError) {
+	args := m.Called(c, labelIDs)
+	return args.Get(0).([]domain.Label), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockLabelRepository) UpdateLabel(c context.Context, label domain.Label) domain.CustomError {
+	args := m.Called(c, label)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockLabelRepository) DeleteLabel(c context.Context, labelID string) domain.CustomError {
+	args := m.Called(c, labelID)
+	return args.Get(0).(domain.CustomError)
+}
+
+type LabelUsecaseSuite struct {
+	suite.Suite
+	mockLabelRepo *MockLabelRepository
+	mockTaskRepo  *MockTaskRepository
+	usecase       domain.LabelUsecase
+}
+
+func (suite *LabelUsecaseSuite) SetupTest() {
+	suite.mockLabelRepo = new(MockLabelRepository)
+	suite.mockTaskRepo = new(MockTaskRepository)
+	suite.usecase = usecases.NewLabelUsecase(suite.mockLabelRepo, suite.mockTaskRepo)
+}
+
+// Test CreateLabel normalizes the input
+func (suite *LabelUsecaseSuite) TestCreateLabel() {
+	notFound := domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Label not found"}
+	suite.mockLabelRepo.On("GetLabelByName", mock.Anything, "backend").Return(domain.Label{}, notFound)
+	suite.mockLabelRepo.On("CreateLabel", mock.Anything, domain.Label{Name: "backend", Color: "#1d76db"}).Return("label-1", domain.CustomError{})
+
+	label, err := suite.usecase.CreateLabel(context.TODO(), domain.LabelInput{Name: " backend ", Color: "#1D76DB"})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal(domain.Label{ID: "label-1", Name: "backend", Color: "#1d76db"}, label)
+}
+
+// Test CreateLabel rejects an invalid color
+func (suite *LabelUsecaseSuite) TestCreateLabel_InvalidColor() {
+	_, err := suite.usecase.CreateLabel(context.TODO(), domain.LabelInput{Name: "urgent", Color: "red"})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("color", err.Field)
+}
+
+// Test CreateLabel rejects a name that is already taken
+func (suite *LabelUsecaseSuite) TestCreateLabel_Duplicate() {
+	suite.mockLabelRepo.On("GetLabelByName", mock.Anything, "Urgent").Return(domain.Label{ID: "label-1", Name: "urgent"}, domain.CustomError{})
+
+	_, err := suite.usecase.CreateLabel(context.TODO(), domain.LabelInput{Name: "Urgent", Color: "#ff0000"})
+
+	suite.Equal(http.StatusConflict, err.ErrCode)
+	suite.mockLabelRepo.AssertNotCalled(suite.T(), "CreateLabel", mock.Anything, mock.Anything)
+}
+
+// Test UpdateLabel lets a label keep its own name
+func (suite *LabelUsecaseSuite) TestUpdateLabel_SameName() {
+	suite.mockLabelRepo.On("GetLabelByID", mock.Anything, "label-1").Return(domain.Label{ID: "label-1", Name: "urgent", Color: "#ff0000"}, domain.CustomError{})
+	suite.mockLabelRepo.On("GetLabelByName", mock.Anything, "Urgent").Return(domain.Label{ID: "label-1", Name: "urgent"}, domain.CustomError{})
+	suite.mockLabelRepo.On("UpdateLabel", mock.Anything, domain.Label{ID: "label-1", Name: "Urgent", Color: "#aa0000"}).Return(domain.CustomError{})
+
+	label, err := suite.usecase.UpdateLabel(context.TODO(), "label-1", domain.LabelInput{Name: "Urgent", Color: "#aa0000"})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal("Urgent", label.Name)
+}
+
+// Test DeleteLabel takes the label off every task before deleting it
+func (suite *LabelUsecaseSuite) TestDeleteLabel() {
+	suite.mockLabelRepo.On("GetLabelByID", mock.Anything, "label-1").Return(domain.Label{ID: "label-1"}, domain.CustomError{})
+	suite.mockTaskRepo.On("RemoveLabelFromTasks", mock.Anything, "label-1").Return(int64(3), domain.CustomError{})
+	suite.mockLabelRepo.On("DeleteLabel", mock.Anything, "label-1").Return(domain.CustomError{})
+
+	err := suite.usecase.DeleteLabel(context.TODO(), "label-1")
+
+	suite.Empty(err.ErrMessage)
+	suite.mockTaskRepo.AssertExpectations(suite.T())
+	suite.mockLabelRepo.AssertExpectations(suite.T())
+}
+
+// Test DeleteLabel of an unknown label leaves the tasks alone
+func (suite *LabelUsecaseSuite) TestDeleteLabel_NotFound() {
+	suite.mockLabelRepo.On("GetLabelByID", mock.Anything, "label-9").Return(domain.Label{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Label not found"})
+
+	err := suite.usecase.DeleteLabel(context.TODO(), "label-9")
+
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+	suite.mockTaskRepo.AssertNotCalled(suite.T(), "RemoveLabelFromTasks", mock.Anything, mock.Anything)
+}
+
+func TestLabelUsecaseSuite(t *testing.T) {
+	suite.Run(t, new(LabelUsecaseSuite))
+}
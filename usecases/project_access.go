@@ -0,0 +1,94 @@
+package usecases
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+)
+
+// projectAccess decides what a user may do with a task from their role in the task's project. It is
+// shared by the usecases that act on tasks.
+type projectAccess struct {
+	projectRepository domain.ProjectRepository
+}
+
+// roleFor returns the user's role for the task. Admins act as owners of every project. Tasks created
+// before projects existed have none; their creator and assignees act as members and nobody else has a role.
+func (pa projectAccess) roleFor(c context.Context, user domain.AuthUser, task domain.Task) (domain.ProjectRole, domain.CustomError) {
+	if user.Can(domain.PermissionProjectManageAll) {
+		return domain.ProjectRoleOwner, domain.CustomError{}
+	}
+	if task.ProjectID == "" {
+		if task.IsOwnedOrAssigned(user.UserID) {
+			return domain.ProjectRoleMember, domain.CustomError{}
+		}
+		return "", domain.CustomError{}
+	}
+
+	project, err := pa.projectRepository.GetProjectByID(c, task.ProjectID)
+	if err.ErrCode == http.StatusNotFound {
+		return "", domain.CustomError{}
+	}
+	if err.ErrCode != 0 {
+		return "", err
+	}
+	return project.RoleOf(user.UserID), domain.CustomError{}
+}
+
+// checkRead allows viewers and above.
+func (pa projectAccess) checkRead(c context.Context, user domain.AuthUser, task domain.Task) domain.CustomError {
+	role, err := pa.roleFor(c, user, task)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if !role.AtLeast(domain.ProjectRoleViewer) {
+		return domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "You do not have access to this task"}
+	}
+	return domain.CustomError{}
+}
+
+// checkWrite allows members and above.
+func (pa projectAccess) checkWrite(c context.Context, user domain.AuthUser, task domain.Task) domain.CustomError {
+	role, err := pa.roleFor(c, user, task)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if role == domain.ProjectRoleViewer {
+		return domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "Viewers cannot change the tasks of this project"}
+	}
+	if !role.AtLeast(domain.ProjectRoleMember) {
+		return domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "You do not have access to this task"}
+	}
+	return domain.CustomError{}
+}
+
+// readChecker returns a function reporting whether the user can see a task. Projects are loaded once
+// each, which keeps checking many tasks cheap; lookup errors count as not visible.
+func (pa projectAccess) readChecker(c context.Context, user domain.AuthUser) func(task domain.Task) bool {
+	roles := map[string]domain.ProjectRole{}
+	return func(task domain.Task) bool {
+		if user.Can(domain.PermissionProjectManageAll) || task.ProjectID == "" {
+			role, _ := pa.roleFor(c, user, task)
+			return role.AtLeast(domain.ProjectRoleViewer)
+		}
+		role, ok := roles[task.ProjectID]
+		if !ok {
+			role, _ = pa.roleFor(c, user, task)
+			roles[task.ProjectID] = role
+		}
+		return role.AtLeast(domain.ProjectRoleViewer)
+	}
+}
+
+// visibleProjectIDs lists the projects whose tasks the user can see.
+func (pa projectAccess) visibleProjectIDs(c context.Context, user domain.AuthUser) ([]string, domain.CustomError) {
+	projects, err := pa.projectRepository.GetProjects(c, user.UserID)
+	if err.ErrCode != 0 {
+		return nil, err
+	}
+	projectIDs := []string{}
+	for _, project := range projects {
+		projectIDs = append(projectIDs, project.ID)
+	}
+	return projectIDs, domain.CustomError{}
+}
@@ -0,0 +1,200 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"task_managment_api/domain"
+	"time"
+)
+
+type projectUsecase struct {
+	projectRepository domain.ProjectRepository
+	taskRepository    domain.TaskRepository
+	userRepository    domain.UserRepository
+	access            projectAccess
+}
+
+func NewProjectUsecase(projectRepository domain.ProjectRepository, taskRepository domain.TaskRepository, userRepository domain.UserRepository) domain.ProjectUsecase {
+	return &projectUsecase{
+		projectRepository: projectRepository,
+		taskRepository:    taskRepository,
+		userRepository:    userRepository,
+		access:            projectAccess{projectRepository: projectRepository},
+	}
+}
+
+// CreateProject stores a new project with the caller as its owner.
+func (pu *projectUsecase) CreateProject(c context.Context, user domain.AuthUser, input domain.ProjectInput) (domain.Project, domain.CustomError) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return domain.Project{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "name is required", Field: "name"}
+	}
+
+	project := domain.Project{
+		Name:        name,
+		Description: input.Description,
+		CreatedBy:   user.UserID,
+		CreatedAt:   time.Now().UTC(),
+		Members:     []domain.ProjectMember{{UserID: user.UserID, Role: domain.ProjectRoleOwner}},
+	}
+	id, err := pu.projectRepository.CreateProject(c, project)
+	if err.ErrCode != 0 {
+		return domain.Project{}, err
+	}
+	project.ID = id
+	return project, domain.CustomError{}
+}
+
+// GetProjects lists the projects the caller is a member of. Admins see every project.
+func (pu *projectUsecase) GetProjects(c context.Context, user domain.AuthUser) ([]domain.Project, domain.CustomError) {
+	if user.Can(domain.PermissionProjectManageAll) {
+		return pu.projectRepository.GetProjects(c, "")
+	}
+	return pu.projectRepository.GetProjects(c, user.UserID)
+}
+
+// GetProjectByID returns a project the caller is a member of.
+func (pu *projectUsecase) GetProjectByID(c context.Context, user domain.AuthUser, projectId string) (domain.Project, domain.CustomError) {
+	return pu.getProject(c, user, projectId, domain.ProjectRoleViewer)
+}
+
+// UpdateProject changes a project's name and description. Only owners and admins can update a project.
+func (pu *projectUsecase) UpdateProject(c context.Context, user domain.AuthUser, projectId string, input domain.ProjectInput) (domain.Project, domain.CustomError) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return domain.Project{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "name is required", Field: "name"}
+	}
+	project, err := pu.getProject(c, user, projectId, domain.ProjectRoleOwner)
+	if err.ErrCode != 0 {
+		return domain.Project{}, err
+	}
+
+	project.Name = name
+	project.Description = input.Description
+	if err := pu.projectRepository.UpdateProject(c, project); err.ErrCode != 0 {
+		return domain.Project{}, err
+	}
+	return project, domain.CustomError{}
+}
+
+// DeleteProject removes a project that has no tasks left, counting the tasks in the trash. Only owners
+// and admins can delete a project.
+func (pu *projectUsecase) DeleteProject(c context.Context, user domain.AuthUser, projectId string) domain.CustomError {
+	if _, err := pu.getProject(c, user, projectId, domain.ProjectRoleOwner); err.ErrCode != 0 {
+		return err
+	}
+	for _, deleted := range []bool{false, true} {
+		page, err := pu.taskRepository.GetTasks(c, domain.TaskQuery{ProjectID: projectId, Deleted: deleted, Limit: 1})
+		if err.ErrCode != 0 {
+			return err
+		}
+		if page.Total > 0 {
+			return domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "Project still has tasks, delete or purge them first"}
+		}
+	}
+	return pu.projectRepository.DeleteProject(c, projectId)
+}
+
+// SetMember adds a user to a project or changes their role. Only owners and admins manage members, and
+// the last owner cannot be demoted.
+func (pu *projectUsecase) SetMember(c context.Context, user domain.AuthUser, projectId string, userID string, role string) (domain.Project, domain.CustomError) {
+	projectRole, parseErr := domain.ParseProjectRole(role)
+	if parseErr != nil {
+		return domain.Project{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: parseErr.Error(), Field: "role"}
+	}
+	project, err := pu.getProject(c, user, projectId, domain.ProjectRoleOwner)
+	if err.ErrCode != 0 {
+		return domain.Project{}, err
+	}
+	_, err = pu.userRepository.GetUserByID(c, userID)
+	if err.ErrCode == http.StatusNotFound || err.ErrCode == http.StatusBadRequest {
+		return domain.Project{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: fmt.Sprintf("User %s not found", userID)}
+	}
+	if err.ErrCode != 0 {
+		return domain.Project{}, err
+	}
+	if project.RoleOf(userID) == domain.ProjectRoleOwner && projectRole != domain.ProjectRoleOwner && project.OwnerCount() == 1 {
+		return domain.Project{}, lastOwner()
+	}
+
+	member := domain.ProjectMember{UserID: userID, Role: projectRole}
+	if err := pu.projectRepository.SetMember(c, projectId, member); err.ErrCode != 0 {
+		return domain.Project{}, err
+	}
+
+	members := []domain.ProjectMember{}
+	for _, existing := range project.Members {
+		if existing.UserID != userID {
+			members = append(members, existing)
+		}
+	}
+	project.Members = append(members, member)
+	return project, domain.CustomError{}
+}
+
+// RemoveMember takes a user out of a project. Owners and admins can remove anyone and members can leave
+// on their own, but the last owner cannot leave.
+func (pu *projectUsecase) RemoveMember(c context.Context, user domain.AuthUser, projectId string, userID string) domain.CustomError {
+	required := domain.ProjectRoleOwner
+	if userID == user.UserID {
+		required = domain.ProjectRoleViewer
+	}
+	project, err := pu.getProject(c, user, projectId, required)
+	if err.ErrCode != 0 {
+		return err
+	}
+	role := project.RoleOf(userID)
+	if role == "" {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Member not found"}
+	}
+	if role == domain.ProjectRoleOwner && project.OwnerCount() == 1 {
+		return lastOwner()
+	}
+	return pu.projectRepository.RemoveMember(c, projectId, userID)
+}
+
+// GetProjectRole returns the caller's role in a project. Admins act as owners of every project.
+func (pu *projectUsecase) GetProjectRole(c context.Context, user domain.AuthUser, projectId string) (domain.ProjectRole, domain.CustomError) {
+	project, err := pu.projectRepository.GetProjectByID(c, projectId)
+	if err.ErrCode != 0 {
+		return "", err
+	}
+	if user.Can(domain.PermissionProjectManageAll) {
+		return domain.ProjectRoleOwner, domain.CustomError{}
+	}
+	return project.RoleOf(user.UserID), domain.CustomError{}
+}
+
+// GetTaskProjectRole returns the caller's role for a task, from the project the task belongs to.
+func (pu *projectUsecase) GetTaskProjectRole(c context.Context, user domain.AuthUser, taskId string) (domain.ProjectRole, domain.CustomError) {
+	task, err := pu.taskRepository.GetTaskByID(c, taskId)
+	if err.ErrCode != 0 {
+		return "", err
+	}
+	return pu.access.roleFor(c, user, task)
+}
+
+// getProject loads a project and makes sure the caller has at least the required role in it.
+func (pu *projectUsecase) getProject(c context.Context, user domain.AuthUser, projectId string, required domain.ProjectRole) (domain.Project, domain.CustomError) {
+	project, err := pu.projectRepository.GetProjectByID(c, projectId)
+	if err.ErrCode != 0 {
+		return domain.Project{}, err
+	}
+	if user.Can(domain.PermissionProjectManageAll) {
+		return project, domain.CustomError{}
+	}
+	role := project.RoleOf(user.UserID)
+	if role == "" {
+		return domain.Project{}, domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "You are not a member of this project"}
+	}
+	if !role.AtLeast(required) {
+		return domain.Project{}, domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: fmt.Sprintf("Only project %ss can do this", required)}
+	}
+	return project, domain.CustomError{}
+}
+
+func lastOwner() domain.CustomError {
+	return domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "A project needs at least one owner, add another owner first"}
+}
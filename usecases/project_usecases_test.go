@@ -0,0 +1,198 @@
+package usecases_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockProjectRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectRepository) CreateProject(c context.Context, project domain.Project) (string, domain.CustomError) {
+	args := m.Called(c, project)
+	return args.String(0), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockProjectRepository) GetProjects(c context.Context, memberID string) ([]domain.Project, domain.CustomError) {
+	args := m.Called(c, memberID)
+	return args.Get(0).([]domain.Project), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockProjectRepository) GetProjectByID(c context.Context, projectID string) (domain.Project, domain.CustomError) {
+	args := m.Called(c, projectID)
+	return args.Get(0).(domain.Project), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockProjectRepository) UpdateProject(c context.Context, project domain.Project) domain.CustomError {
+	args := m.Called(c, project)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockProjectRepository) DeleteProject(c context.Context, projectID string) domain.CustomError {
+	args := m.Called(c, projectID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockProjectRepository) SetMember(c context.Context, projectID string, member domain.ProjectMember) domain.CustomError {
+	args := m.Called(c, projectID, member)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockProjectRepository) RemoveMember(c context.Context, projectID string, userID string) domain.CustomError {
+	args := m.Called(c, projectID, userID)
+	return args.Get(0).(domain.CustomError)
+}
+
+type ProjectUsecaseSuite struct {
+	suite.Suite
+	mockProjectRepo *MockProjectRepository
+	mockTaskRepo    *MockTaskRepository
+	mockUserRepo    *MockUserRepository
+	usecase         domain.ProjectUsecase
+	owner           domain.AuthUser
+	viewer          domain.AuthUser
+	project         domain.Project
+}
+
+func (suite *ProjectUsecaseSuite) SetupTest() {
+	suite.mockProjectRepo = new(MockProjectRepository)
+	suite.mockTaskRepo = new(MockTaskRepository)
+	suite.mockUserRepo = new(MockUserRepository)
+	suite.usecase = usecases.NewProjectUsecase(suite.mockProjectRepo, suite.mockTaskRepo, suite.mockUserRepo)
+	suite.owner = domain.AuthUser{UserID: "user-1", Username: "owner", Role: "user"}
+	suite.viewer = domain.AuthUser{UserID: "user-2", Username: "viewer", Role: "user"}
+	suite.project = domain.Project{
+		ID:   "project-1",
+		Name: "Website",
+		Members: []domain.ProjectMember{
+			{UserID: "user-1", Role: domain.ProjectRoleOwner},
+			{UserID: "user-2", Role: domain.ProjectRoleViewer},
+		},
+	}
+}
+
+// Test CreateProject makes the caller the owner
+func (suite *ProjectUsecaseSuite) TestCreateProject() {
+	suite.mockProjectRepo.On("CreateProject", mock.Anything, mock.MatchedBy(func(project domain.Project) bool {
+		return project.Name == "Website" && project.CreatedBy == "user-1" &&
+			len(project.Members) == 1 && project.Members[0] == domain.ProjectMember{UserID: "user-1", Role: domain.ProjectRoleOwner}
+	})).Return("project-1", domain.CustomError{})
+
+	project, err := suite.usecase.CreateProject(context.TODO(), suite.owner, domain.ProjectInput{Name: " Website "})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal("project-1", project.ID)
+	suite.mockProjectRepo.AssertExpectations(suite.T())
+}
+
+// Test GetProjects lists only the caller's projects, and every project for admins
+func (suite *ProjectUsecaseSuite) TestGetProjects() {
+	suite.mockProjectRepo.On("GetProjects", mock.Anything, "user-1").Return([]domain.Project{suite.project}, domain.CustomError{})
+	suite.mockProjectRepo.On("GetProjects", mock.Anything, "").Return([]domain.Project{}, domain.CustomError{})
+
+	projects, err := suite.usecase.GetProjects(context.TODO(), suite.owner)
+	suite.Empty(err.ErrMessage)
+	suite.Len(projects, 1)
+
+	_, err = suite.usecase.GetProjects(context.TODO(), domain.AuthUser{UserID: "admin-1", Role: "admin"})
+	suite.Empty(err.ErrMessage)
+	suite.mockProjectRepo.AssertExpectations(suite.T())
+}
+
+// Test only owners can update a project and outsiders are told they are not members
+func (suite *ProjectUsecaseSuite) TestUpdateProject_Forbidden() {
+	suite.mockProjectRepo.On("GetProjectByID", mock.Anything, "project-1").Return(suite.project, domain.CustomError{})
+
+	_, err := suite.usecase.UpdateProject(context.TODO(), suite.viewer, "project-1", domain.ProjectInput{Name: "Renamed"})
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+
+	_, err = suite.usecase.UpdateProject(context.TODO(), domain.AuthUser{UserID: "user-9", Role: "user"}, "project-1", domain.ProjectInput{Name: "Renamed"})
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+	suite.Equal("You are not a member of this project", err.ErrMessage)
+	suite.mockProjectRepo.AssertNotCalled(suite.T(), "UpdateProject", mock.Anything, mock.Anything)
+}
+
+// Test a project that still has tasks cannot be deleted
+func (suite *ProjectUsecaseSuite) TestDeleteProject_HasTasks() {
+	suite.mockProjectRepo.On("GetProjectByID", mock.Anything, "project-1").Return(suite.project, domain.CustomError{})
+	suite.mockTaskRepo.On("GetTasks", mock.Anything, domain.TaskQuery{ProjectID: "project-1", Limit: 1}).Return(domain.TaskPage{}, domain.CustomError{})
+	suite.mockTaskRepo.On("GetTasks", mock.Anything, domain.TaskQuery{ProjectID: "project-1", Deleted: true, Limit: 1}).Return(domain.TaskPage{Total: 1}, domain.CustomError{})
+
+	err := suite.usecase.DeleteProject(context.TODO(), suite.owner, "project-1")
+
+	suite.Equal(http.StatusConflict, err.ErrCode)
+	suite.mockProjectRepo.AssertNotCalled(suite.T(), "DeleteProject", mock.Anything, mock.Anything)
+}
+
+// Test SetMember adds a user with a role
+func (suite *ProjectUsecaseSuite) TestSetMember() {
+	suite.mockProjectRepo.On("GetProjectByID", mock.Anything, "project-1").Return(suite.project, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-3").Return(domain.User{ID: "user-3"}, domain.CustomError{})
+	suite.mockProjectRepo.On("SetMember", mock.Anything, "project-1", domain.ProjectMember{UserID: "user-3", Role: domain.ProjectRoleMember}).Return(domain.CustomError{})
+
+	project, err := suite.usecase.SetMember(context.TODO(), suite.owner, "project-1", "user-3", "Member")
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal(domain.ProjectRoleMember, project.RoleOf("user-3"))
+	suite.mockProjectRepo.AssertExpectations(suite.T())
+}
+
+// Test SetMember rejects an unknown role
+func (suite *ProjectUsecaseSuite) TestSetMember_InvalidRole() {
+	_, err := suite.usecase.SetMember(context.TODO(), suite.owner, "project-1", "user-3", "boss")
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("role", err.Field)
+}
+
+// Test the last owner can neither be demoted nor removed
+func (suite *ProjectUsecaseSuite) TestLastOwner() {
+	suite.mockProjectRepo.On("GetProjectByID", mock.Anything, "project-1").Return(suite.project, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1"}, domain.CustomError{})
+
+	_, err := suite.usecase.SetMember(context.TODO(), suite.owner, "project-1", "user-1", "member")
+	suite.Equal(http.StatusConflict, err.ErrCode)
+
+	err = suite.usecase.RemoveMember(context.TODO(), suite.owner, "project-1", "user-1")
+	suite.Equal(http.StatusConflict, err.ErrCode)
+	suite.mockProjectRepo.AssertNotCalled(suite.T(), "SetMember", mock.Anything, mock.Anything, mock.Anything)
+	suite.mockProjectRepo.AssertNotCalled(suite.T(), "RemoveMember", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test members can leave a project but not remove others
+func (suite *ProjectUsecaseSuite) TestRemoveMember_Self() {
+	suite.mockProjectRepo.On("GetProjectByID", mock.Anything, "project-1").Return(suite.project, domain.CustomError{})
+	suite.mockProjectRepo.On("RemoveMember", mock.Anything, "project-1", "user-2").Return(domain.CustomError{})
+
+	err := suite.usecase.RemoveMember(context.TODO(), suite.viewer, "project-1", "user-1")
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+
+	err = suite.usecase.RemoveMember(context.TODO(), suite.viewer, "project-1", "user-2")
+	suite.Empty(err.ErrMessage)
+	suite.mockProjectRepo.AssertExpectations(suite.T())
+}
+
+// Test GetTaskProjectRole uses the role in the task's project
+func (suite *ProjectUsecaseSuite) TestGetTaskProjectRole() {
+	suite.mockTaskRepo.On("GetTaskByID", mock.Anything, "task-1").Return(domain.Task{ID: "task-1", ProjectID: "project-1"}, domain.CustomError{})
+	suite.mockProjectRepo.On("GetProjectByID", mock.Anything, "project-1").Return(suite.project, domain.CustomError{})
+
+	role, err := suite.usecase.GetTaskProjectRole(context.TODO(), suite.viewer, "task-1")
+	suite.Empty(err.ErrMessage)
+	suite.Equal(domain.ProjectRoleViewer, role)
+
+	role, err = suite.usecase.GetTaskProjectRole(context.TODO(), domain.AuthUser{UserID: "user-9", Role: "user"}, "task-1")
+	suite.Empty(err.ErrMessage)
+	suite.Empty(role)
+}
+
+func TestProjectUsecaseSuite(t *testing.T) {
+	suite.Run(t, new(ProjectUsecaseSuite))
+}
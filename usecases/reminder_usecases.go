@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/infrastructure"
+	"time"
+)
+
+type reminderUsecase struct {
+	taskRepository     domain.TaskRepository
+	userRepository     domain.UserRepository
+	reminderRepository domain.ReminderRepository
+	notifier           infrastructure.Notifier
+}
+
+func NewReminderUsecase(taskRepository domain.TaskRepository, userRepository domain.UserRepository, reminderRepository domain.ReminderRepository, notifier infrastructure.Notifier) domain.ReminderUsecase {
+	return &reminderUsecase{
+		taskRepository:     taskRepository,
+		userRepository:     userRepository,
+		reminderRepository: reminderRepository,
+		notifier:           notifier,
+	}
+}
+
+// SendDueReminders reminds the assignees of the open tasks due within the window, or their creator when
+// nobody is assigned, and returns how many reminders were sent. Every reminder is recorded before it is
+// sent so it goes out once per task, user and due date, and forgotten again when sending fails so the next
+// run retries it. A failed reminder does not hold up the others; the last failure is returned.
+func (ru *reminderUsecase) SendDueReminders(c context.Context, window time.Duration) (int, domain.CustomError) {
+	if window <= 0 {
+		return 0, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "window must be a positive duration"}
+	}
+	now := time.Now().UTC()
+	until := now.Add(window)
+	query := domain.TaskQuery{OpenOnly: true, DueFrom: &now, DueTo: &until, SortBy: "due_date", Limit: domain.MaxTaskPageSize}
+
+	users := map[string]domain.User{}
+	sent := 0
+	var failure domain.CustomError
+	for {
+		page, err := ru.taskRepository.GetTasks(c, query)
+		if err.ErrCode != 0 {
+			return sent, err
+		}
+		for _, task := range page.Tasks {
+			for _, userID := range domain.ReminderRecipients(task) {
+				ok, err := ru.remind(c, task, userID, users, now)
+				if err.ErrCode != 0 {
+					failure = err
+				}
+				if ok {
+					sent++
+				}
+			}
+		}
+		if page.NextCursor == "" {
+			return sent, failure
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// remind sends one reminder unless it was sent before. users caches the recipients looked up so far.
+func (ru *reminderUsecase) remind(c context.Context, task domain.Task, userID string, users map[string]domain.User, now time.Time) (bool, domain.CustomError) {
+	user, found := users[userID]
+	if !found {
+		var err domain.CustomError
+		user, err = ru.userRepository.GetUserByID(c, userID)
+		if err.ErrCode == http.StatusNotFound || err.ErrCode == http.StatusBadRequest {
+			return false, domain.CustomError{}
+		}
+		if err.ErrCode != 0 {
+			return false, err
+		}
+		users[userID] = user
+	}
+
+	reminder := domain.Reminder{TaskID: task.ID, UserID: userID, DueDate: *task.DueDate, SentAt: now}
+	recorded, err := ru.reminderRepository.RecordReminder(c, reminder)
+	if err.ErrCode != 0 || !recorded {
+		return false, err
+	}
+	if err := ru.notifier.Notify(c, domain.ReminderNotice{Task: task, Recipient: user}); err.ErrCode != 0 {
+		if deleteErr := ru.reminderRepository.DeleteReminder(c, reminder); deleteErr.ErrCode != 0 {
+			return false, deleteErr
+		}
+		return false, err
+	}
+	return true, domain.CustomError{}
+}
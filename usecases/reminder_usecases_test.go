@@ -0,0 +1,139 @@
+package usecases_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockReminderRepository struct {
+	mock.Mock
+}
+
+func (m *MockReminderRepository) RecordReminder(c context.Context, reminder domain.Reminder) (bool, domain.CustomError) {
+	args := m.Called(c, reminder)
+	return args.Bool(0), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockReminderRepository) DeleteReminder(c context.Context, reminder domain.Reminder) domain.CustomError {
+	args := m.Called(c, reminder)
+	return args.Get(0).(domain.CustomError)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(c context.Context, notice domain.ReminderNotice) domain.CustomError {
+	args := m.Called(c, notice)
+	return args.Get(0).(domain.CustomError)
+}
+
+type ReminderUsecaseSuite struct {
+	suite.Suite
+	mockTaskRepo     *MockTaskRepository
+	mockUserRepo     *MockUserRepository
+	mockReminderRepo *MockReminderRepository
+	mockNotifier     *MockNotifier
+	usecase          domain.ReminderUsecase
+	dueDate          time.Time
+}
+
+func (suite *ReminderUsecaseSuite) SetupTest() {
+	suite.mockTaskRepo = new(MockTaskRepository)
+	suite.mockUserRepo = new(MockUserRepository)
+	suite.mockReminderRepo = new(MockReminderRepository)
+	suite.mockNotifier = new(MockNotifier)
+	suite.usecase = usecases.NewReminderUsecase(suite.mockTaskRepo, suite.mockUserRepo, suite.mockReminderRepo, suite.mockNotifier)
+	suite.dueDate = time.Now().UTC().Add(2 * time.Hour)
+}
+
+func (suite *ReminderUsecaseSuite) expectDueTasks(tasks ...domain.Task) {
+	suite.mockTaskRepo.On("GetTasks", mock.Anything, mock.MatchedBy(func(query domain.TaskQuery) bool {
+		return query.OpenOnly && query.DueFrom != nil && query.DueTo != nil && query.DueTo.Sub(*query.DueFrom) == 24*time.Hour
+	})).Return(domain.TaskPage{Tasks: tasks}, domain.CustomError{})
+}
+
+// Test the assignees of a task coming due are reminded once each
+func (suite *ReminderUsecaseSuite) TestSendDueReminders_Assignees() {
+	task := domain.Task{ID: "task-1", Title: "Ship it", DueDate: &suite.dueDate, CreatedBy: "creator", AssigneeIDs: []string{"alice", "bob"}}
+	suite.expectDueTasks(task)
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "alice").Return(domain.User{ID: "alice", Username: "alice"}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "bob").Return(domain.User{ID: "bob", Username: "bob"}, domain.CustomError{})
+	suite.mockReminderRepo.On("RecordReminder", mock.Anything, mock.MatchedBy(func(reminder domain.Reminder) bool {
+		return reminder.UserID == "alice" && reminder.TaskID == "task-1" && reminder.DueDate.Equal(suite.dueDate)
+	})).Return(true, domain.CustomError{})
+	// bob was reminded before the restart
+	suite.mockReminderRepo.On("RecordReminder", mock.Anything, mock.MatchedBy(func(reminder domain.Reminder) bool {
+		return reminder.UserID == "bob"
+	})).Return(false, domain.CustomError{})
+	suite.mockNotifier.On("Notify", mock.Anything, domain.ReminderNotice{Task: task, Recipient: domain.User{ID: "alice", Username: "alice"}}).Return(domain.CustomError{})
+
+	sent, err := suite.usecase.SendDueReminders(context.TODO(), 24*time.Hour)
+
+	suite.Empty(err.ErrCode)
+	suite.Equal(1, sent)
+	suite.mockNotifier.AssertNumberOfCalls(suite.T(), "Notify", 1)
+}
+
+// Test the creator is reminded of a task nobody is assigned to
+func (suite *ReminderUsecaseSuite) TestSendDueReminders_Creator() {
+	task := domain.Task{ID: "task-1", Title: "Ship it", DueDate: &suite.dueDate, CreatedBy: "creator"}
+	suite.expectDueTasks(task)
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "creator").Return(domain.User{ID: "creator", Username: "carol"}, domain.CustomError{})
+	suite.mockReminderRepo.On("RecordReminder", mock.Anything, mock.Anything).Return(true, domain.CustomError{})
+	suite.mockNotifier.On("Notify", mock.Anything, mock.Anything).Return(domain.CustomError{})
+
+	sent, err := suite.usecase.SendDueReminders(context.TODO(), 24*time.Hour)
+
+	suite.Empty(err.ErrCode)
+	suite.Equal(1, sent)
+}
+
+// Test a reminder that could not be sent is forgotten so the next run retries it
+func (suite *ReminderUsecaseSuite) TestSendDueReminders_NotifyFails() {
+	task := domain.Task{ID: "task-1", Title: "Ship it", DueDate: &suite.dueDate, AssigneeIDs: []string{"alice"}}
+	suite.expectDueTasks(task)
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "alice").Return(domain.User{ID: "alice", Username: "alice"}, domain.CustomError{})
+	suite.mockReminderRepo.On("RecordReminder", mock.Anything, mock.Anything).Return(true, domain.CustomError{})
+	suite.mockNotifier.On("Notify", mock.Anything, mock.Anything).Return(domain.CustomError{ErrCode: http.StatusBadGateway, ErrMessage: "Error while sending reminder email"})
+	suite.mockReminderRepo.On("DeleteReminder", mock.Anything, mock.MatchedBy(func(reminder domain.Reminder) bool {
+		return reminder.UserID == "alice" && reminder.TaskID == "task-1"
+	})).Return(domain.CustomError{})
+
+	sent, err := suite.usecase.SendDueReminders(context.TODO(), 24*time.Hour)
+
+	suite.Equal(http.StatusBadGateway, err.ErrCode)
+	suite.Equal(0, sent)
+	suite.mockReminderRepo.AssertExpectations(suite.T())
+}
+
+// Test an unknown recipient is skipped
+func (suite *ReminderUsecaseSuite) TestSendDueReminders_UnknownUser() {
+	task := domain.Task{ID: "task-1", Title: "Ship it", DueDate: &suite.dueDate, AssigneeIDs: []string{"gone"}}
+	suite.expectDueTasks(task)
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "gone").Return(domain.User{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "User not found"})
+
+	sent, err := suite.usecase.SendDueReminders(context.TODO(), 24*time.Hour)
+
+	suite.Empty(err.ErrCode)
+	suite.Equal(0, sent)
+	suite.mockReminderRepo.AssertNotCalled(suite.T(), "RecordReminder", mock.Anything, mock.Anything)
+}
+
+// Test the window must be positive
+func (suite *ReminderUsecaseSuite) TestSendDueReminders_InvalidWindow() {
+	_, err := suite.usecase.SendDueReminders(context.TODO(), 0)
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+}
+
+func TestReminderUsecaseSuite(t *testing.T) {
+	suite.Run(t, new(ReminderUsecaseSuite))
+}
@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"task_managment_api/domain"
+	"time"
+)
+
+type roleUsecase struct {
+	roleRepository domain.RoleRepository
+	userRepository domain.UserRepository
+}
+
+func NewRoleUsecase(roleRepository domain.RoleRepository, userRepository domain.UserRepository) domain.RoleUsecase {
+	return &roleUsecase{
+		roleRepository: roleRepository,
+		userRepository: userRepository,
+	}
+}
+
+func (ru *roleUsecase) GetPermissions() []domain.PermissionInfo {
+	return domain.Permissions
+}
+
+func (ru *roleUsecase) GetRoles(c context.Context) ([]domain.Role, domain.CustomError) {
+	roles, err := ru.roleRepository.GetRoles(c)
+	if err.ErrCode != 0 {
+		return nil, err
+	}
+	for i := range roles {
+		roles[i] = withAdminPermissions(roles[i])
+	}
+	return roles, domain.CustomError{}
+}
+
+func (ru *roleUsecase) GetRoleByName(c context.Context, name string) (domain.Role, domain.CustomError) {
+	role, err := ru.roleRepository.GetRoleByName(c, name)
+	if err.ErrCode != 0 {
+		return domain.Role{}, err
+	}
+	return withAdminPermissions(role), domain.CustomError{}
+}
+
+// CreateRole stores a new custom role.
+func (ru *roleUsecase) CreateRole(c context.Context, input domain.RoleInput) (domain.Role, domain.CustomError) {
+	role, err := domain.NormalizeRole(input)
+	if err.ErrCode != 0 {
+		return domain.Role{}, err
+	}
+	role.CreatedAt = time.Now().UTC()
+	role.UpdatedAt = role.CreatedAt
+	if err := ru.roleRepository.CreateRole(c, role); err.ErrCode != 0 {
+		return domain.Role{}, err
+	}
+	return role, domain.CustomError{}
+}
+
+// UpdateRole replaces the description and permissions of a role. The admin role cannot be changed, so
+// that there is always a role that can manage the others.
+func (ru *roleUsecase) UpdateRole(c context.Context, name string, input domain.RoleInput) (domain.Role, domain.CustomError) {
+	input.Name = name
+	role, err := domain.NormalizeRole(input)
+	if err.ErrCode != 0 {
+		return domain.Role{}, err
+	}
+	if role.Name == domain.RoleAdmin {
+		return domain.Role{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "The admin role cannot be changed"}
+	}
+	existing, err := ru.roleRepository.GetRoleByName(c, role.Name)
+	if err.ErrCode != 0 {
+		return domain.Role{}, err
+	}
+
+	existing.Description = role.Description
+	existing.Permissions = role.Permissions
+	existing.UpdatedAt = time.Now().UTC()
+	if err := ru.roleRepository.UpdateRole(c, existing); err.ErrCode != 0 {
+		return domain.Role{}, err
+	}
+	return existing, domain.CustomError{}
+}
+
+// DeleteRole deletes a custom role that no user has.
+func (ru *roleUsecase) DeleteRole(c context.Context, name string) domain.CustomError {
+	role, err := ru.roleRepository.GetRoleByName(c, name)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if role.BuiltIn {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Built-in roles cannot be deleted"}
+	}
+	count, err := ru.userRepository.CountUsersWithRole(c, role.Name)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if count > 0 {
+		return domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: fmt.Sprintf("The role is given to %d users", count)}
+	}
+	return ru.roleRepository.DeleteRole(c, role.Name)
+}
+
+func (ru *roleUsecase) GetRolePermissions(c context.Context, name string) ([]domain.Permission, domain.CustomError) {
+	return rolePermissions(c, ru.roleRepository, name)
+}
+
+// rolePermissions returns the permissions a role currently grants. Admins always have every permission, and
+// a role that no longer exists grants none.
+func rolePermissions(c context.Context, roleRepository domain.RoleRepository, name string) ([]domain.Permission, domain.CustomError) {
+	if name == domain.RoleAdmin {
+		return domain.AllPermissions(), domain.CustomError{}
+	}
+	role, err := roleRepository.GetRoleByName(c, name)
+	if err.ErrCode == http.StatusNotFound {
+		return []domain.Permission{}, domain.CustomError{}
+	}
+	if err.ErrCode != 0 {
+		return nil, err
+	}
+	if role.Permissions == nil {
+		return []domain.Permission{}, domain.CustomError{}
+	}
+	return role.Permissions, domain.CustomError{}
+}
+
+// EnsureBuiltInRoles creates the built-in roles that do not exist yet.
+func (ru *roleUsecase) EnsureBuiltInRoles(c context.Context) domain.CustomError {
+	now := time.Now().UTC()
+	for _, role := range domain.BuiltInRoles {
+		role.CreatedAt = now
+		role.UpdatedAt = now
+		if err := ru.roleRepository.EnsureRole(c, role); err.ErrCode != 0 {
+			return err
+		}
+	}
+	return domain.CustomError{}
+}
+
+// withAdminPermissions shows the admin role with every permission, which is what it has even if it was
+// stored before some of them existed.
+func withAdminPermissions(role domain.Role) domain.Role {
+	if role.Name == domain.RoleAdmin {
+		role.Permissions = domain.AllPermissions()
+	}
+	return role
+}
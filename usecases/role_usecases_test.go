@@ -0,0 +1,156 @@
+package usecases_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) CreateRole(c context.Context, role domain.Role) domain.CustomError {
+	args := m.Called(c, role)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockRoleRepository) EnsureRole(c context.Context, role domain.Role) domain.CustomError {
+	args := m.Called(c, role)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockRoleRepository) GetRoles(c context.Context) ([]domain.Role, domain.CustomError) {
+	args := m.Called(c)
+	return args.Get(0).([]domain.Role), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockRoleRepository) GetRoleByName(c context.Context, name string) (domain.Role, domain.CustomError) {
+	args := m.Called(c, name)
+	return args.Get(0).(domain.Role), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockRoleRepository) UpdateRole(c context.Context, role domain.Role) domain.CustomError {
+	args := m.Called(c, role)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockRoleRepository) DeleteRole(c context.Context, name string) domain.CustomError {
+	args := m.Called(c, name)
+	return args.Get(0).(domain.CustomError)
+}
+
+type RoleUsecaseSuite struct {
+	suite.Suite
+	mockRoleRepo *MockRoleRepository
+	mockUserRepo *MockUserRepository
+	usecase      domain.RoleUsecase
+}
+
+func (suite *RoleUsecaseSuite) SetupTest() {
+	suite.mockRoleRepo = new(MockRoleRepository)
+	suite.mockUserRepo = new(MockUserRepository)
+	suite.usecase = usecases.NewRoleUsecase(suite.mockRoleRepo, suite.mockUserRepo)
+}
+
+func (suite *RoleUsecaseSuite) TearDownTest() {
+	suite.mockRoleRepo.AssertExpectations(suite.T())
+	suite.mockUserRepo.AssertExpectations(suite.T())
+}
+
+// Test creating a role normalizes its name and permissions
+func (suite *RoleUsecaseSuite) TestCreateRole() {
+	suite.mockRoleRepo.On("CreateRole", mock.Anything, mock.MatchedBy(func(role domain.Role) bool {
+		return role.Name == "viewer" && len(role.Permissions) == 1 && !role.BuiltIn && !role.CreatedAt.IsZero()
+	})).Return(domain.CustomError{})
+
+	role, err := suite.usecase.CreateRole(context.TODO(), domain.RoleInput{Name: " Viewer ", Permissions: []string{"task:update", "task:update"}})
+
+	suite.Empty(err.ErrCode)
+	suite.Equal("viewer", role.Name)
+	suite.Equal([]domain.Permission{domain.PermissionTaskUpdate}, role.Permissions)
+}
+
+// Test unknown permissions and bad names are rejected
+func (suite *RoleUsecaseSuite) TestCreateRoleInvalid() {
+	_, err := suite.usecase.CreateRole(context.TODO(), domain.RoleInput{Name: "viewer", Permissions: []string{"task:fly"}})
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("permissions", err.Field)
+
+	_, err = suite.usecase.CreateRole(context.TODO(), domain.RoleInput{Name: "no spaces", Permissions: []string{}})
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("name", err.Field)
+}
+
+// Test the admin role cannot be changed
+func (suite *RoleUsecaseSuite) TestUpdateAdminRole() {
+	_, err := suite.usecase.UpdateRole(context.TODO(), "admin", domain.RoleInput{Permissions: []string{}})
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+}
+
+// Test updating a role replaces its permissions
+func (suite *RoleUsecaseSuite) TestUpdateRole() {
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "user").Return(domain.Role{Name: "user", BuiltIn: true}, domain.CustomError{})
+	suite.mockRoleRepo.On("UpdateRole", mock.Anything, mock.MatchedBy(func(role domain.Role) bool {
+		return role.BuiltIn && len(role.Permissions) == 1 && role.Permissions[0] == domain.PermissionTaskCreate
+	})).Return(domain.CustomError{})
+
+	role, err := suite.usecase.UpdateRole(context.TODO(), "user", domain.RoleInput{Permissions: []string{"task:create"}})
+
+	suite.Empty(err.ErrCode)
+	suite.Equal("user", role.Name)
+}
+
+// Test built-in roles and roles that are in use cannot be deleted
+func (suite *RoleUsecaseSuite) TestDeleteRoleRefused() {
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "user").Return(domain.Role{Name: "user", BuiltIn: true}, domain.CustomError{})
+	suite.Equal(http.StatusBadRequest, suite.usecase.DeleteRole(context.TODO(), "user").ErrCode)
+
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "manager").Return(domain.Role{Name: "manager"}, domain.CustomError{})
+	suite.mockUserRepo.On("CountUsersWithRole", mock.Anything, "manager").Return(int64(2), domain.CustomError{})
+	suite.Equal(http.StatusConflict, suite.usecase.DeleteRole(context.TODO(), "manager").ErrCode)
+	suite.mockRoleRepo.AssertNotCalled(suite.T(), "DeleteRole", mock.Anything, mock.Anything)
+}
+
+// Test deleting an unused custom role
+func (suite *RoleUsecaseSuite) TestDeleteRole() {
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "manager").Return(domain.Role{Name: "manager"}, domain.CustomError{})
+	suite.mockUserRepo.On("CountUsersWithRole", mock.Anything, "manager").Return(int64(0), domain.CustomError{})
+	suite.mockRoleRepo.On("DeleteRole", mock.Anything, "manager").Return(domain.CustomError{})
+
+	suite.Empty(suite.usecase.DeleteRole(context.TODO(), "manager").ErrCode)
+}
+
+// Test the permissions of admin, stored and unknown roles
+func (suite *RoleUsecaseSuite) TestGetRolePermissions() {
+	permissions, err := suite.usecase.GetRolePermissions(context.TODO(), "admin")
+	suite.Empty(err.ErrCode)
+	suite.Equal(domain.AllPermissions(), permissions)
+
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "viewer").Return(domain.Role{Name: "viewer", Permissions: []domain.Permission{domain.PermissionTaskUpdate}}, domain.CustomError{})
+	permissions, err = suite.usecase.GetRolePermissions(context.TODO(), "viewer")
+	suite.Empty(err.ErrCode)
+	suite.Equal([]domain.Permission{domain.PermissionTaskUpdate}, permissions)
+
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "gone").Return(domain.Role{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Role not found"})
+	permissions, err = suite.usecase.GetRolePermissions(context.TODO(), "gone")
+	suite.Empty(err.ErrCode)
+	suite.NotNil(permissions)
+	suite.Empty(permissions)
+}
+
+// Test the built-in roles are created at startup
+func (suite *RoleUsecaseSuite) TestEnsureBuiltInRoles() {
+	suite.mockRoleRepo.On("EnsureRole", mock.Anything, mock.MatchedBy(func(role domain.Role) bool { return role.BuiltIn })).Return(domain.CustomError{}).Times(len(domain.BuiltInRoles))
+
+	suite.Empty(suite.usecase.EnsureBuiltInRoles(context.TODO()).ErrCode)
+}
+
+func TestRoleUsecaseSuite(t *testing.T) {
+	suite.Run(t, new(RoleUsecaseSuite))
+}
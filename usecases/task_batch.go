@@ -0,0 +1,151 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"task_managment_api/domain"
+)
+
+// BatchTasks runs a list of create, update and delete operations and reports the outcome of each. Every
+// operation goes through the checks of the matching single request, including the permission its route requires.
+// A batch that is not atomic runs every operation on its own and some may fail while others succeed. An
+// atomic batch runs in a transaction that stops at the first failure and rolls back the operations
+// before it, including their history entries and webhook deliveries.
+func (uc *taskUsecase) BatchTasks(c context.Context, user domain.AuthUser, request domain.BatchRequest) (domain.BatchResponse, domain.CustomError) {
+	operations := request.Operations
+	if len(operations) == 0 {
+		return domain.BatchResponse{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "operations is required", Field: "operations"}
+	}
+	if len(operations) > domain.MaxBatchSize {
+		return domain.BatchResponse{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("a batch holds at most %d operations", domain.MaxBatchSize), Field: "operations"}
+	}
+
+	response := domain.BatchResponse{Atomic: request.Atomic, Results: make([]domain.BatchResult, 0, len(operations))}
+	if !request.Atomic {
+		for i, operation := range operations {
+			response.Results = append(response.Results, uc.runBatchOperation(c, user, i, operation))
+		}
+		return countBatchResults(response), domain.CustomError{}
+	}
+
+	failed := -1
+	err := uc.transactions.RunInTransaction(c, func(c context.Context) domain.CustomError {
+		// a retried transaction starts over
+		failed = -1
+		response.Results = response.Results[:0]
+		for i, operation := range operations {
+			result := uc.runBatchOperation(c, user, i, operation)
+			response.Results = append(response.Results, result)
+			if !batchSucceeded(result) {
+				failed = i
+				return domain.CustomError{ErrCode: result.Status, ErrMessage: result.Error, Field: result.Field}
+			}
+		}
+		return domain.CustomError{}
+	})
+	if failed < 0 {
+		if err.ErrCode != 0 {
+			return domain.BatchResponse{}, err
+		}
+		return countBatchResults(response), domain.CustomError{}
+	}
+
+	response.RolledBack = true
+	for i := 0; i < failed; i++ {
+		result := &response.Results[i]
+		if result.Op == domain.BatchCreate {
+			result.ID = ""
+		}
+		result.Version = 0
+		result.Status = http.StatusFailedDependency
+		result.Error = fmt.Sprintf("Rolled back because operation %d failed", failed)
+	}
+	for i := failed + 1; i < len(operations); i++ {
+		response.Results = append(response.Results, domain.BatchResult{
+			Index:  i,
+			Op:     operations[i].Op,
+			ID:     operations[i].ID,
+			Status: http.StatusFailedDependency,
+			Error:  fmt.Sprintf("Not run because operation %d failed", failed),
+		})
+	}
+	return countBatchResults(response), domain.CustomError{}
+}
+
+// runBatchOperation runs one operation and turns its outcome into a result.
+func (uc *taskUsecase) runBatchOperation(c context.Context, user domain.AuthUser, index int, operation domain.BatchOperation) domain.BatchResult {
+	result, err := uc.batchOperation(c, user, operation)
+	if err.ErrCode != 0 {
+		return domain.BatchResult{Index: index, Op: operation.Op, ID: operation.ID, Status: err.ErrCode, Error: err.ErrMessage, Field: err.Field}
+	}
+	result.Index = index
+	result.Op = operation.Op
+	return result
+}
+
+func (uc *taskUsecase) batchOperation(c context.Context, user domain.AuthUser, operation domain.BatchOperation) (domain.BatchResult, domain.CustomError) {
+	switch operation.Op {
+	case domain.BatchCreate:
+		if !user.Can(domain.PermissionTaskCreate) {
+			return domain.BatchResult{}, domain.PermissionRequired(domain.PermissionTaskCreate)
+		}
+		if operation.Task == nil {
+			return domain.BatchResult{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "task is required", Field: "task"}
+		}
+		task, err := uc.CreateTask(c, user, *operation.Task)
+		if err.ErrCode != 0 {
+			return domain.BatchResult{}, err
+		}
+		return domain.BatchResult{ID: task.ID, Status: http.StatusCreated, Version: task.Version}, domain.CustomError{}
+
+	case domain.BatchUpdate:
+		if operation.ID == "" {
+			return domain.BatchResult{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "id is required", Field: "id"}
+		}
+		if !user.Can(domain.PermissionTaskUpdate) {
+			return domain.BatchResult{}, domain.PermissionRequired(domain.PermissionTaskUpdate)
+		}
+		if operation.Task == nil {
+			return domain.BatchResult{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "task is required", Field: "task"}
+		}
+		scope, scopeErr := domain.ParseRecurrenceScope(operation.Scope)
+		if scopeErr != nil {
+			return domain.BatchResult{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: scopeErr.Error(), Field: "scope"}
+		}
+		task, err := uc.UpdateTaskByID(c, user, operation.ID, *operation.Task, operation.Version, scope)
+		if err.ErrCode != 0 {
+			return domain.BatchResult{}, err
+		}
+		return domain.BatchResult{ID: operation.ID, Status: http.StatusOK, Version: task.Version}, domain.CustomError{}
+
+	case domain.BatchDelete:
+		if operation.ID == "" {
+			return domain.BatchResult{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "id is required", Field: "id"}
+		}
+		if !user.Can(domain.PermissionTaskDelete) {
+			return domain.BatchResult{}, domain.PermissionRequired(domain.PermissionTaskDelete)
+		}
+		if err := uc.DeleteTaskByID(c, user, operation.ID); err.ErrCode != 0 {
+			return domain.BatchResult{}, err
+		}
+		return domain.BatchResult{ID: operation.ID, Status: http.StatusOK}, domain.CustomError{}
+	}
+	return domain.BatchResult{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("unknown op %q, expected create, update or delete", operation.Op), Field: "op"}
+}
+
+func batchSucceeded(result domain.BatchResult) bool {
+	return result.Status < http.StatusBadRequest
+}
+
+func countBatchResults(response domain.BatchResponse) domain.BatchResponse {
+	response.Succeeded, response.Failed = 0, 0
+	for _, result := range response.Results {
+		if batchSucceeded(result) {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	return response
+}
@@ -0,0 +1,131 @@
+package usecases_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/usecases"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransactionRunner runs the transaction body directly; there is nothing to roll back in the mocks.
+// An error given to Return is answered instead, as when the server cannot run transactions.
+type MockTransactionRunner struct {
+	mock.Mock
+}
+
+func (m *MockTransactionRunner) RunInTransaction(c context.Context, fn func(c context.Context) domain.CustomError) domain.CustomError {
+	args := m.Called(c)
+	if len(args) > 0 {
+		if err := args.Get(0).(domain.CustomError); err.ErrCode != 0 {
+			return err
+		}
+	}
+	return fn(c)
+}
+
+func batchTask(title string) *domain.TaskInput {
+	return &domain.TaskInput{Title: title, ProjectID: "project-1"}
+}
+
+// Test BatchTasks reports every operation of a batch that is not atomic on its own
+func (suite *TaskUsecaseSuite) TestBatchTasks() {
+	suite.mockRepo.On("CreateTask", mock.Anything, mock.MatchedBy(func(task domain.Task) bool { return task.Title == "Task 1" })).Return("1", domain.CustomError{})
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "missing").Return(domain.Task{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"})
+
+	response, err := suite.usecase.BatchTasks(context.TODO(), suite.user, domain.BatchRequest{Operations: []domain.BatchOperation{
+		{Op: domain.BatchCreate, Task: batchTask("Task 1")},
+		{Op: domain.BatchUpdate, ID: "missing", Task: batchTask("Task 2")},
+		{Op: domain.BatchDelete, ID: "1"},
+		{Op: "archive", ID: "1"},
+	}})
+
+	suite.Empty(err.ErrMessage)
+	suite.False(response.RolledBack)
+	suite.Equal(1, response.Succeeded)
+	suite.Equal(3, response.Failed)
+	suite.Equal(domain.BatchResult{Index: 0, Op: domain.BatchCreate, ID: "1", Status: http.StatusCreated, Version: 1}, response.Results[0])
+	suite.Equal(http.StatusNotFound, response.Results[1].Status)
+	suite.Equal("Task not found", response.Results[1].Error)
+	suite.Equal(http.StatusForbidden, response.Results[2].Status)
+	suite.Equal(http.StatusBadRequest, response.Results[3].Status)
+	suite.Equal("op", response.Results[3].Field)
+	suite.mockTransactions.AssertNotCalled(suite.T(), "RunInTransaction", mock.Anything)
+	suite.mockRepo.AssertNotCalled(suite.T(), "DeleteTaskByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test an atomic batch stops at the first failure and reports the other operations as rolled back or not run
+func (suite *TaskUsecaseSuite) TestBatchTasks_AtomicRollsBack() {
+	suite.mockRepo.On("CreateTask", mock.Anything, mock.Anything).Return("1", domain.CustomError{})
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "missing").Return(domain.Task{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"})
+
+	response, err := suite.usecase.BatchTasks(context.TODO(), suite.admin, domain.BatchRequest{Atomic: true, Operations: []domain.BatchOperation{
+		{Op: domain.BatchCreate, Task: batchTask("Task 1")},
+		{Op: domain.BatchUpdate, ID: "missing", Task: batchTask("Task 2")},
+		{Op: domain.BatchDelete, ID: "3"},
+	}})
+
+	suite.Empty(err.ErrMessage)
+	suite.True(response.RolledBack)
+	suite.Equal(0, response.Succeeded)
+	suite.Equal(3, response.Failed)
+	suite.Equal(domain.BatchResult{Index: 0, Op: domain.BatchCreate, Status: http.StatusFailedDependency, Error: "Rolled back because operation 1 failed"}, response.Results[0])
+	suite.Equal(http.StatusNotFound, response.Results[1].Status)
+	suite.Equal(domain.BatchResult{Index: 2, Op: domain.BatchDelete, ID: "3", Status: http.StatusFailedDependency, Error: "Not run because operation 1 failed"}, response.Results[2])
+	suite.mockTransactions.AssertCalled(suite.T(), "RunInTransaction", mock.Anything)
+	suite.mockRepo.AssertNotCalled(suite.T(), "DeleteTaskByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test an atomic batch whose operations all succeed
+func (suite *TaskUsecaseSuite) TestBatchTasks_Atomic() {
+	suite.mockRepo.On("CreateTask", mock.Anything, mock.Anything).Return("1", domain.CustomError{})
+
+	response, err := suite.usecase.BatchTasks(context.TODO(), suite.user, domain.BatchRequest{Atomic: true, Operations: []domain.BatchOperation{
+		{Op: domain.BatchCreate, Task: batchTask("Task 1")},
+		{Op: domain.BatchCreate, Task: batchTask("Task 2")},
+	}})
+
+	suite.Empty(err.ErrMessage)
+	suite.False(response.RolledBack)
+	suite.Equal(2, response.Succeeded)
+	suite.mockTransactions.AssertNumberOfCalls(suite.T(), "RunInTransaction", 1)
+}
+
+// Test a batch delete by a user who is not a member of the task's project
+func (suite *TaskUsecaseSuite) TestBatchTasks_DeleteNotProjectMember() {
+	outsider := domain.AuthUser{UserID: "user-2", Role: "cleaner", Permissions: []domain.Permission{domain.PermissionTaskDelete}}
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", Title: "Task 1", ProjectID: "project-1"}, domain.CustomError{})
+
+	response, err := suite.usecase.BatchTasks(context.TODO(), outsider, domain.BatchRequest{Operations: []domain.BatchOperation{
+		{Op: domain.BatchDelete, ID: "1"},
+	}})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal(http.StatusForbidden, response.Results[0].Status)
+	suite.mockRepo.AssertNotCalled(suite.T(), "DeleteTaskByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test BatchTasks refuses empty and oversized batches
+func (suite *TaskUsecaseSuite) TestBatchTasks_Size() {
+	_, err := suite.usecase.BatchTasks(context.TODO(), suite.user, domain.BatchRequest{})
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("operations", err.Field)
+
+	_, err = suite.usecase.BatchTasks(context.TODO(), suite.user, domain.BatchRequest{Operations: make([]domain.BatchOperation, domain.MaxBatchSize+1)})
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+}
+
+// Test an atomic batch is refused as a whole when the server cannot run transactions
+func (suite *TaskUsecaseSuite) TestBatchTasks_AtomicUnsupported() {
+	suite.mockTransactions = new(MockTransactionRunner)
+	suite.mockTransactions.On("RunInTransaction", mock.Anything).Return(domain.CustomError{ErrCode: http.StatusNotImplemented, ErrMessage: "Atomic batches need MongoDB running as a replica set"})
+	suite.usecase = usecases.NewTaskUsecase(suite.mockRepo, suite.mockUserRepo, suite.mockRoleRepo, suite.mockHistoryRepo, suite.mockLabelRepo, suite.mockProjectRepo, suite.mockSeriesRepo, suite.mockPublisher, suite.mockTransactions, domain.DefaultStatusWorkflow, domain.SubtaskDeleteBlock)
+
+	_, err := suite.usecase.BatchTasks(context.TODO(), suite.user, domain.BatchRequest{Atomic: true, Operations: []domain.BatchOperation{
+		{Op: domain.BatchCreate, Task: batchTask("Task 1")},
+	}})
+
+	suite.Equal(http.StatusNotImplemented, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateTask", mock.Anything, mock.Anything)
+}
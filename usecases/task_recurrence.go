@@ -0,0 +1,286 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"task_managment_api/domain"
+	"time"
+)
+
+// recurrenceEdit is what an edit does to the series of a recurring task.
+type recurrenceEdit struct {
+	// recurrence is the recurrence of the edited task once the edit is applied.
+	recurrence *domain.TaskRecurrence
+	// newSeries is created before the task is updated when the task starts a series of its own.
+	newSeries *domain.TaskSeries
+	// updatedSeries carries the new title and description of the series the task stays in.
+	updatedSeries *domain.TaskSeries
+	// later lists the open occurrences after the edited one, which follow the edit when it applies to
+	// all future occurrences.
+	later []domain.Task
+}
+
+// parseRecurrence validates an RRULE and returns it in its canonical form. Recurring tasks need a due
+// date, which is when their first occurrence is due.
+func parseRecurrence(value string, dueDate *time.Time) (string, domain.CustomError) {
+	rule, err := domain.ParseRRule(value)
+	if err != nil {
+		return "", domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: err.Error(), Field: "recurrence"}
+	}
+	if dueDate == nil {
+		return "", domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "a recurring task needs a due_date", Field: "due_date"}
+	}
+	return rule.String(), domain.CustomError{}
+}
+
+// planRecurrence works out what an edit does to the task's series. A task that starts repeating becomes
+// the first occurrence of a new series. Edits to a single occurrence cannot change the rule. Edits to all
+// future occurrences change the title and description of the series, or, when the rule or the due date
+// changes, split the series so that the edited occurrence starts a new one.
+func (uc *taskUsecase) planRecurrence(c context.Context, existingTask domain.Task, updatedTask domain.Task, recurrence *string, scope domain.RecurrenceScope) (recurrenceEdit, domain.CustomError) {
+	edit := recurrenceEdit{recurrence: existingTask.Recurrence}
+	currentRule := ""
+	if existingTask.Recurrence != nil {
+		currentRule = existingTask.Recurrence.Rule
+	}
+	newRule := currentRule
+	if recurrence != nil {
+		newRule = ""
+		if strings.TrimSpace(*recurrence) != "" {
+			rule, err := parseRecurrence(*recurrence, updatedTask.DueDate)
+			if err.ErrCode != 0 {
+				return recurrenceEdit{}, err
+			}
+			newRule = rule
+		}
+	}
+
+	if existingTask.Recurrence == nil {
+		if newRule != "" {
+			edit.newSeries = newSeries(existingTask, updatedTask, newRule)
+			edit.recurrence = &domain.TaskRecurrence{Rule: newRule, Index: 1}
+		}
+		return edit, domain.CustomError{}
+	}
+	if scope != domain.ScopeFutureOccurrences {
+		if newRule != currentRule {
+			return recurrenceEdit{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "the recurrence can only be changed for all future occurrences, use scope=future", Field: "recurrence"}
+		}
+		return edit, domain.CustomError{}
+	}
+
+	later, err := uc.taskRepository.GetSeriesTasks(c, existingTask.Recurrence.SeriesID, existingTask.Recurrence.Index)
+	if err.ErrCode != 0 {
+		return recurrenceEdit{}, err
+	}
+	for _, task := range later {
+		if task.Status != domain.StatusDone {
+			edit.later = append(edit.later, task)
+		}
+	}
+	if newRule == "" {
+		edit.recurrence = nil
+		return edit, domain.CustomError{}
+	}
+
+	series, err := uc.seriesRepository.GetSeriesByID(c, existingTask.Recurrence.SeriesID)
+	if err.ErrCode != 0 && err.ErrCode != http.StatusNotFound {
+		return recurrenceEdit{}, err
+	}
+	if err.ErrCode == 0 && newRule == currentRule && sameDueDate(existingTask.DueDate, updatedTask.DueDate) {
+		series.Title = updatedTask.Title
+		series.Description = updatedTask.Description
+		edit.updatedSeries = &series
+		return edit, domain.CustomError{}
+	}
+
+	if updatedTask.DueDate == nil {
+		return recurrenceEdit{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "a recurring task needs a due_date", Field: "due_date"}
+	}
+	if newRule == currentRule {
+		// the occurrences before this one already used up part of the count
+		if rule, parseErr := domain.ParseRRule(currentRule); parseErr == nil && rule.Count > 0 {
+			rule.Count -= existingTask.Recurrence.Index - 1
+			newRule = rule.String()
+		}
+	}
+	edit.newSeries = newSeries(existingTask, updatedTask, newRule)
+	for _, task := range edit.later {
+		if index := laterIndex(existingTask, task); index > edit.newSeries.LastIndex {
+			edit.newSeries.LastIndex = index
+		}
+	}
+	edit.recurrence = &domain.TaskRecurrence{Rule: newRule, Index: 1}
+	return edit, domain.CustomError{}
+}
+
+func newSeries(existingTask domain.Task, updatedTask domain.Task, rule string) *domain.TaskSeries {
+	return &domain.TaskSeries{
+		ProjectID:   existingTask.ProjectID,
+		Rule:        rule,
+		Start:       *updatedTask.DueDate,
+		Title:       updatedTask.Title,
+		Description: updatedTask.Description,
+		CreatedBy:   existingTask.CreatedBy,
+		LastIndex:   1,
+	}
+}
+
+// laterIndex is the index a later occurrence gets when the edited occurrence starts a new series.
+func laterIndex(editedTask domain.Task, laterTask domain.Task) int {
+	return laterTask.Recurrence.Index - editedTask.Recurrence.Index + 1
+}
+
+func sameDueDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// createPlannedSeries stores the new series of an edit, if there is one, and links the edited task to it.
+func (uc *taskUsecase) createPlannedSeries(c context.Context, edit *recurrenceEdit) domain.CustomError {
+	if edit.newSeries == nil {
+		return domain.CustomError{}
+	}
+	seriesID, err := uc.seriesRepository.CreateSeries(c, *edit.newSeries)
+	if err.ErrCode != 0 {
+		return err
+	}
+	edit.newSeries.ID = seriesID
+	edit.recurrence.SeriesID = seriesID
+	return domain.CustomError{}
+}
+
+// finishRecurrenceEdit stores the new template of the series and carries an edit to all future occurrences
+// over to the open occurrences after the edited one: they take its title, description and series, and keep
+// their own due dates.
+func (uc *taskUsecase) finishRecurrenceEdit(c context.Context, user domain.AuthUser, editedTask domain.Task, before domain.Task, edit recurrenceEdit) domain.CustomError {
+	if edit.updatedSeries != nil {
+		if err := uc.seriesRepository.UpdateSeries(c, *edit.updatedSeries); err.ErrCode != 0 {
+			return err
+		}
+	}
+	for _, task := range edit.later {
+		after := task
+		after.Title = editedTask.Title
+		after.Description = editedTask.Description
+		switch {
+		case edit.recurrence == nil:
+			after.Recurrence = nil
+		case edit.newSeries != nil:
+			after.Recurrence = &domain.TaskRecurrence{SeriesID: edit.newSeries.ID, Rule: edit.newSeries.Rule, Index: laterIndex(before, task)}
+		}
+		replacement := domain.Task{
+			ID:          task.ID,
+			Title:       after.Title,
+			Description: after.Description,
+			DueDate:     task.DueDate,
+			ParentID:    task.ParentID,
+			Recurrence:  after.Recurrence,
+			Version:     task.Version,
+		}
+		if err := uc.taskRepository.UpdateTaskByID(c, replacement, nil); err.ErrCode != 0 {
+			return err
+		}
+		after.Version++
+		if err := uc.recordChanges(c, user, task, after); err.ErrCode != 0 {
+			return err
+		}
+	}
+	return domain.CustomError{}
+}
+
+// scheduleNextOccurrence creates the next occurrence of a recurring task that was just marked done. It
+// is due on the series' next date and takes the series' title and description, and the assignees, labels,
+// parent and unchecked checklist of the finished occurrence. Nothing is created when the series has ended
+// or the next occurrence already exists.
+func (uc *taskUsecase) scheduleNextOccurrence(c context.Context, user domain.AuthUser, task domain.Task) domain.CustomError {
+	if task.Recurrence == nil || task.Status != domain.StatusDone {
+		return domain.CustomError{}
+	}
+	series, err := uc.seriesRepository.GetSeriesByID(c, task.Recurrence.SeriesID)
+	if err.ErrCode == http.StatusNotFound {
+		return domain.CustomError{}
+	}
+	if err.ErrCode != 0 {
+		return err
+	}
+	if series.LastIndex != task.Recurrence.Index {
+		return domain.CustomError{}
+	}
+	rule, parseErr := domain.ParseRRule(series.Rule)
+	if parseErr != nil {
+		return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Stored recurrence rule is invalid"}
+	}
+	dueDate, ok := rule.Occurrence(series.Start, task.Recurrence.Index+1)
+	if !ok {
+		return domain.CustomError{}
+	}
+	// only one of several concurrent completions gets to create the occurrence
+	advanced, err := uc.seriesRepository.AdvanceSeries(c, series.ID, task.Recurrence.Index)
+	if err.ErrCode != 0 || !advanced {
+		return err
+	}
+
+	checklist := make([]domain.ChecklistItem, 0, len(task.Checklist))
+	for _, item := range task.Checklist {
+		item.Done = false
+		checklist = append(checklist, item)
+	}
+	next := domain.Task{
+		ProjectID:   task.ProjectID,
+		Title:       series.Title,
+		Description: series.Description,
+		DueDate:     &dueDate,
+		Status:      domain.StatusTodo,
+		CreatedBy:   series.CreatedBy,
+		AssigneeIDs: task.AssigneeIDs,
+		ParentID:    task.ParentID,
+		Checklist:   checklist,
+		LabelIDs:    task.LabelIDs,
+		Recurrence:  &domain.TaskRecurrence{SeriesID: series.ID, Rule: series.Rule, Index: task.Recurrence.Index + 1},
+		Version:     1,
+	}
+	next.ID, err = uc.taskRepository.CreateTask(c, next)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if err := uc.recordHistory(c, user, next.ID, domain.HistoryActionCreated, domain.DiffTasks(domain.Task{}, next), nil); err.ErrCode != 0 {
+		return err
+	}
+	uc.publishEvent(c, user, domain.EventTaskCreated, next, nil)
+	return domain.CustomError{}
+}
+
+// GetOccurrences lists the due dates of the occurrences that will follow a recurring task.
+func (uc *taskUsecase) GetOccurrences(c context.Context, user domain.AuthUser, taskId string, count int) (domain.OccurrencePreview, domain.CustomError) {
+	if count < 0 || count > domain.MaxOccurrencePreview {
+		return domain.OccurrencePreview{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("count must be between 1 and %d", domain.MaxOccurrencePreview), Field: "count"}
+	}
+	if count == 0 {
+		count = domain.DefaultOccurrencePreview
+	}
+	task, err := uc.getVisibleTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return domain.OccurrencePreview{}, err
+	}
+	if task.Recurrence == nil {
+		return domain.OccurrencePreview{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Task does not repeat"}
+	}
+	series, err := uc.seriesRepository.GetSeriesByID(c, task.Recurrence.SeriesID)
+	if err.ErrCode != 0 {
+		return domain.OccurrencePreview{}, err
+	}
+	rule, parseErr := domain.ParseRRule(series.Rule)
+	if parseErr != nil {
+		return domain.OccurrencePreview{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Stored recurrence rule is invalid"}
+	}
+	return domain.OccurrencePreview{
+		TaskID:      task.ID,
+		Rule:        series.Rule,
+		Occurrences: rule.OccurrencesAfter(series.Start, task.Recurrence.Index, count),
+	}, domain.CustomError{}
+}
@@ -0,0 +1,218 @@
+package usecases_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"task_managment_api/domain"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTaskSeriesRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaskSeriesRepository) CreateSeries(c context.Context, series domain.TaskSeries) (string, domain.CustomError) {
+	args := m.Called(c, series)
+	return args.String(0), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskSeriesRepository) GetSeriesByID(c context.Context, seriesID string) (domain.TaskSeries, domain.CustomError) {
+	args := m.Called(c, seriesID)
+	return args.Get(0).(domain.TaskSeries), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskSeriesRepository) UpdateSeries(c context.Context, series domain.TaskSeries) domain.CustomError {
+	args := m.Called(c, series)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskSeriesRepository) AdvanceSeries(c context.Context, seriesID string, fromIndex int) (bool, domain.CustomError) {
+	args := m.Called(c, seriesID, fromIndex)
+	return args.Bool(0), args.Get(1).(domain.CustomError)
+}
+
+func weeklySeries(lastIndex int) domain.TaskSeries {
+	return domain.TaskSeries{
+		ID:          "series-1",
+		ProjectID:   "project-1",
+		Rule:        "FREQ=WEEKLY;BYDAY=MO",
+		Start:       time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		Title:       "Standup notes",
+		Description: "Write up the standup",
+		CreatedBy:   "user-1",
+		LastIndex:   lastIndex,
+	}
+}
+
+func weeklyOccurrence(index int, status domain.TaskStatus) domain.Task {
+	dueDate := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC).AddDate(0, 0, 7*(index-1))
+	return domain.Task{
+		ID:          "occurrence-" + strconv.Itoa(index),
+		ProjectID:   "project-1",
+		Title:       "Standup notes",
+		Description: "Write up the standup",
+		DueDate:     &dueDate,
+		Status:      status,
+		CreatedBy:   "user-1",
+		Recurrence:  &domain.TaskRecurrence{SeriesID: "series-1", Rule: "FREQ=WEEKLY;BYDAY=MO", Index: index},
+		Version:     1,
+	}
+}
+
+// Test CreateTask with a recurrence rule starts a new series
+func (suite *TaskUsecaseSuite) TestCreateTask_Recurring() {
+	rule := "freq=weekly;byday=mo"
+	input := domain.TaskInput{Title: "Standup notes", DueDate: "2026-10-19T09:00:00Z", ProjectID: "project-1", Recurrence: &rule}
+	series := weeklySeries(1)
+	series.ID = ""
+	series.Description = ""
+
+	suite.mockSeriesRepo.On("CreateSeries", mock.Anything, series).Return("series-1", domain.CustomError{})
+	suite.mockRepo.On("CreateTask", mock.Anything, mock.MatchedBy(func(task domain.Task) bool {
+		return task.Recurrence != nil && *task.Recurrence == domain.TaskRecurrence{SeriesID: "series-1", Rule: "FREQ=WEEKLY;BYDAY=MO", Index: 1}
+	})).Return("1", domain.CustomError{})
+
+	task, err := suite.usecase.CreateTask(context.TODO(), suite.user, input)
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal("series-1", task.Recurrence.SeriesID)
+	suite.mockSeriesRepo.AssertExpectations(suite.T())
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+// Test CreateTask refuses a recurring task without a due date or with an invalid rule
+func (suite *TaskUsecaseSuite) TestCreateTask_RecurringInvalid() {
+	rule := "FREQ=WEEKLY"
+	_, err := suite.usecase.CreateTask(context.TODO(), suite.user, domain.TaskInput{Title: "Standup notes", ProjectID: "project-1", Recurrence: &rule})
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("due_date", err.Field)
+
+	rule = "FREQ=HOURLY"
+	_, err = suite.usecase.CreateTask(context.TODO(), suite.user, domain.TaskInput{Title: "Standup notes", DueDate: "2026-10-19", ProjectID: "project-1", Recurrence: &rule})
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("recurrence", err.Field)
+	suite.mockSeriesRepo.AssertNotCalled(suite.T(), "CreateSeries", mock.Anything, mock.Anything)
+}
+
+// Test marking an occurrence done creates the next one from the series
+func (suite *TaskUsecaseSuite) TestTransitionTask_CreatesNextOccurrence() {
+	task := weeklyOccurrence(1, domain.StatusReview)
+	task.Title = "Standup notes (moved)"
+	task.AssigneeIDs = []string{"user-1"}
+	task.LabelIDs = []string{"label-1"}
+	task.Checklist = []domain.ChecklistItem{{ID: "item-1", Text: "Send notes", Done: true}}
+	nextDue := time.Date(2026, 10, 26, 9, 0, 0, 0, time.UTC)
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, task.ID).Return(task, domain.CustomError{})
+	suite.mockRepo.On("TransitionTaskStatus", mock.Anything, task.ID, mock.Anything).Return(domain.CustomError{})
+	suite.mockSeriesRepo.On("GetSeriesByID", mock.Anything, "series-1").Return(weeklySeries(1), domain.CustomError{})
+	suite.mockSeriesRepo.On("AdvanceSeries", mock.Anything, "series-1", 1).Return(true, domain.CustomError{})
+	suite.mockRepo.On("CreateTask", mock.Anything, mock.MatchedBy(func(next domain.Task) bool {
+		return next.Title == "Standup notes" && next.Status == domain.StatusTodo && next.DueDate.Equal(nextDue) &&
+			next.Recurrence.Index == 2 && next.AssigneeIDs[0] == "user-1" && next.LabelIDs[0] == "label-1" &&
+			len(next.Checklist) == 1 && !next.Checklist[0].Done
+	})).Return("occurrence-2", domain.CustomError{})
+
+	err := suite.usecase.TransitionTask(context.TODO(), suite.user, task.ID, "done")
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockSeriesRepo.AssertExpectations(suite.T())
+}
+
+// Test no occurrence is created once the series has ended or the next occurrence exists
+func (suite *TaskUsecaseSuite) TestTransitionTask_NoNextOccurrence() {
+	ended := weeklySeries(1)
+	ended.Rule = "FREQ=WEEKLY;BYDAY=MO;COUNT=1"
+	for _, series := range []domain.TaskSeries{ended, weeklySeries(2)} {
+		suite.SetupTest()
+		task := weeklyOccurrence(1, domain.StatusReview)
+		suite.mockRepo.On("GetTaskByID", mock.Anything, task.ID).Return(task, domain.CustomError{})
+		suite.mockRepo.On("TransitionTaskStatus", mock.Anything, task.ID, mock.Anything).Return(domain.CustomError{})
+		suite.mockSeriesRepo.On("GetSeriesByID", mock.Anything, "series-1").Return(series, domain.CustomError{})
+
+		err := suite.usecase.TransitionTask(context.TODO(), suite.user, task.ID, "done")
+
+		suite.Empty(err.ErrMessage)
+		suite.mockSeriesRepo.AssertNotCalled(suite.T(), "AdvanceSeries", mock.Anything, mock.Anything, mock.Anything)
+		suite.mockRepo.AssertNotCalled(suite.T(), "CreateTask", mock.Anything, mock.Anything)
+	}
+}
+
+// Test the rule of a single occurrence cannot be changed
+func (suite *TaskUsecaseSuite) TestPatchTaskByID_RecurrenceThisOccurrence() {
+	task := weeklyOccurrence(1, domain.StatusTodo)
+	suite.mockRepo.On("GetTaskByID", mock.Anything, task.ID).Return(task, domain.CustomError{})
+
+	_, err := suite.usecase.PatchTaskByID(context.TODO(), suite.user, task.ID, domain.TaskPatch{"recurrence": json.RawMessage(`"FREQ=DAILY"`)}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("recurrence", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateTaskByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test editing all future occurrences updates the series and the open occurrences after this one
+func (suite *TaskUsecaseSuite) TestPatchTaskByID_FutureOccurrences() {
+	task := weeklyOccurrence(1, domain.StatusDone)
+	later := weeklyOccurrence(2, domain.StatusTodo)
+	updatedSeries := weeklySeries(2)
+	updatedSeries.Title = "Weekly report"
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, task.ID).Return(task, domain.CustomError{})
+	suite.mockRepo.On("GetSeriesTasks", mock.Anything, "series-1", 1).Return([]domain.Task{later}, domain.CustomError{})
+	suite.mockSeriesRepo.On("GetSeriesByID", mock.Anything, "series-1").Return(weeklySeries(2), domain.CustomError{})
+	suite.mockRepo.On("UpdateTaskByID", mock.Anything, mock.MatchedBy(func(replacement domain.Task) bool {
+		return replacement.ID == task.ID && replacement.Title == "Weekly report" && replacement.Recurrence == task.Recurrence
+	}), (*domain.StatusTransition)(nil)).Return(domain.CustomError{})
+	suite.mockSeriesRepo.On("UpdateSeries", mock.Anything, updatedSeries).Return(domain.CustomError{})
+	suite.mockRepo.On("UpdateTaskByID", mock.Anything, mock.MatchedBy(func(replacement domain.Task) bool {
+		return replacement.ID == later.ID && replacement.Title == "Weekly report" && replacement.DueDate.Equal(*later.DueDate)
+	}), (*domain.StatusTransition)(nil)).Return(domain.CustomError{})
+
+	_, err := suite.usecase.PatchTaskByID(context.TODO(), suite.user, task.ID, domain.TaskPatch{"title": json.RawMessage(`"Weekly report"`)}, nil, domain.ScopeFutureOccurrences)
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockSeriesRepo.AssertExpectations(suite.T())
+}
+
+// Test changing the rule for all future occurrences splits the series at the edited occurrence
+func (suite *TaskUsecaseSuite) TestPatchTaskByID_FutureRuleChange() {
+	task := weeklyOccurrence(3, domain.StatusTodo)
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, task.ID).Return(task, domain.CustomError{})
+	suite.mockRepo.On("GetSeriesTasks", mock.Anything, "series-1", 3).Return([]domain.Task{}, domain.CustomError{})
+	suite.mockSeriesRepo.On("GetSeriesByID", mock.Anything, "series-1").Return(weeklySeries(3), domain.CustomError{})
+	suite.mockSeriesRepo.On("CreateSeries", mock.Anything, mock.MatchedBy(func(series domain.TaskSeries) bool {
+		return series.Rule == "FREQ=WEEKLY;BYDAY=MO,TH" && series.Start.Equal(*task.DueDate) && series.LastIndex == 1
+	})).Return("series-2", domain.CustomError{})
+	suite.mockRepo.On("UpdateTaskByID", mock.Anything, mock.MatchedBy(func(replacement domain.Task) bool {
+		return *replacement.Recurrence == domain.TaskRecurrence{SeriesID: "series-2", Rule: "FREQ=WEEKLY;BYDAY=MO,TH", Index: 1}
+	}), (*domain.StatusTransition)(nil)).Return(domain.CustomError{})
+
+	updated, err := suite.usecase.PatchTaskByID(context.TODO(), suite.user, task.ID, domain.TaskPatch{"recurrence": json.RawMessage(`"FREQ=WEEKLY;BYDAY=MO,TH"`)}, nil, domain.ScopeFutureOccurrences)
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal("series-2", updated.Recurrence.SeriesID)
+	suite.mockSeriesRepo.AssertExpectations(suite.T())
+	suite.mockSeriesRepo.AssertNotCalled(suite.T(), "UpdateSeries", mock.Anything, mock.Anything)
+}
+
+// Test GetOccurrences lists the due dates after the task's occurrence
+func (suite *TaskUsecaseSuite) TestGetOccurrences() {
+	task := weeklyOccurrence(2, domain.StatusTodo)
+	suite.mockRepo.On("GetTaskByID", mock.Anything, task.ID).Return(task, domain.CustomError{})
+	suite.mockSeriesRepo.On("GetSeriesByID", mock.Anything, "series-1").Return(weeklySeries(2), domain.CustomError{})
+
+	preview, err := suite.usecase.GetOccurrences(context.TODO(), suite.user, task.ID, 2)
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal([]time.Time{time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC), time.Date(2026, 11, 9, 9, 0, 0, 0, time.UTC)}, preview.Occurrences)
+
+	_, err = suite.usecase.GetOccurrences(context.TODO(), suite.user, task.ID, domain.MaxOccurrencePreview+1)
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+}
@@ -0,0 +1,71 @@
+package usecases
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+)
+
+// ExportTasks hands every task matching the query that the caller can see to write, one page at a time,
+// so an export is streamed instead of held in memory. The limit and cursor of the query are ignored.
+func (uc *taskUsecase) ExportTasks(c context.Context, user domain.AuthUser, query domain.TaskQuery, write func(tasks []domain.Task) error) domain.CustomError {
+	query.Limit = domain.MaxTaskPageSize
+	query.Cursor = ""
+	for {
+		page, err := uc.GetTasks(c, user, query)
+		if err.ErrCode != 0 {
+			return err
+		}
+		if err := write(page.Tasks); err != nil {
+			return domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while writing export"}
+		}
+		if page.NextCursor == "" {
+			return domain.CustomError{}
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// ImportTasks validates every row with the checks of CreateTask and reports the rows that fail by line.
+// Tasks are only created when every row is valid and this is not a dry run, so a file with errors never
+// leaves half an import behind.
+func (uc *taskUsecase) ImportTasks(c context.Context, user domain.AuthUser, rows []domain.ImportRow, dryRun bool) (domain.ImportReport, domain.CustomError) {
+	report := domain.ImportReport{DryRun: dryRun, Rows: len(rows), Errors: []domain.ImportError{}, Created: []domain.ImportedTask{}}
+	type preparedTask struct {
+		line int
+		task domain.Task
+		rule string
+	}
+	prepared := make([]preparedTask, 0, len(rows))
+	for _, row := range rows {
+		err := row.Err
+		if err.ErrCode == 0 {
+			var task domain.Task
+			var rule string
+			if task, rule, err = uc.prepareNewTask(c, user, row.Input); err.ErrCode == 0 {
+				prepared = append(prepared, preparedTask{line: row.Line, task: task, rule: rule})
+				continue
+			}
+		}
+		if err.ErrCode >= http.StatusInternalServerError {
+			return domain.ImportReport{}, err
+		}
+		report.Errors = append(report.Errors, domain.ImportError{Line: row.Line, Field: err.Field, Message: err.ErrMessage})
+	}
+	report.Valid = len(prepared)
+	if dryRun || len(report.Errors) > 0 {
+		return report, domain.CustomError{}
+	}
+
+	for _, item := range prepared {
+		task, err := uc.storeNewTask(c, user, item.task, item.rule)
+		if err.ErrCode != 0 {
+			// tell the caller which tasks made it in before the failure
+			err.Details = map[string]interface{}{"imported": report.Imported, "created": report.Created}
+			return domain.ImportReport{}, err
+		}
+		report.Created = append(report.Created, domain.ImportedTask{Line: item.line, ID: task.ID})
+		report.Imported++
+	}
+	return report, domain.CustomError{}
+}
@@ -0,0 +1,97 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"task_managment_api/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Test ExportTasks hands every page to the writer and ignores the requested limit
+func (suite *TaskUsecaseSuite) TestExportTasks() {
+	suite.mockRepo.On("GetTasks", mock.Anything, mock.MatchedBy(func(query domain.TaskQuery) bool {
+		return query.Cursor == "" && query.Limit == domain.MaxTaskPageSize && query.Status == "todo"
+	})).Return(domain.TaskPage{Tasks: []domain.Task{{ID: "1"}, {ID: "2"}}, NextCursor: "next"}, domain.CustomError{})
+	suite.mockRepo.On("GetTasks", mock.Anything, mock.MatchedBy(func(query domain.TaskQuery) bool {
+		return query.Cursor == "next"
+	})).Return(domain.TaskPage{Tasks: []domain.Task{{ID: "3"}}}, domain.CustomError{})
+
+	exported := []string{}
+	err := suite.usecase.ExportTasks(context.TODO(), suite.admin, domain.TaskQuery{Status: "todo", Limit: 5, Cursor: "old"}, func(tasks []domain.Task) error {
+		for _, task := range tasks {
+			exported = append(exported, task.ID)
+		}
+		return nil
+	})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal([]string{"1", "2", "3"}, exported)
+}
+
+// Test ExportTasks stops when the writer fails
+func (suite *TaskUsecaseSuite) TestExportTasks_WriteError() {
+	suite.mockRepo.On("GetTasks", mock.Anything, mock.Anything).Return(domain.TaskPage{Tasks: []domain.Task{{ID: "1"}}, NextCursor: "next"}, domain.CustomError{}).Once()
+
+	err := suite.usecase.ExportTasks(context.TODO(), suite.admin, domain.TaskQuery{}, func(tasks []domain.Task) error {
+		return errors.New("connection reset")
+	})
+
+	suite.Equal(http.StatusInternalServerError, err.ErrCode)
+	suite.mockRepo.AssertNumberOfCalls(suite.T(), "GetTasks", 1)
+}
+
+func importRows() []domain.ImportRow {
+	return []domain.ImportRow{
+		{Line: 2, Input: domain.TaskInput{Title: "Task 1", ProjectID: "project-1"}},
+		{Line: 3, Input: domain.TaskInput{ProjectID: "project-1"}},
+		{Line: 4, Input: domain.TaskInput{Title: "Task 3", ProjectID: "project-1", DueDate: "someday"}},
+		{Line: 5, Err: domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "expected 2 columns, found 3"}},
+	}
+}
+
+// Test ImportTasks reports every invalid row by line and creates nothing
+func (suite *TaskUsecaseSuite) TestImportTasks_InvalidRows() {
+	report, err := suite.usecase.ImportTasks(context.TODO(), suite.user, importRows(), false)
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal(4, report.Rows)
+	suite.Equal(1, report.Valid)
+	suite.Equal(0, report.Imported)
+	suite.Equal([]domain.ImportError{
+		{Line: 3, Field: "title", Message: "title is required"},
+		{Line: 4, Field: "due_date", Message: report.Errors[1].Message},
+		{Line: 5, Message: "expected 2 columns, found 3"},
+	}, report.Errors)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateTask", mock.Anything, mock.Anything)
+}
+
+// Test a dry run validates the rows without creating them
+func (suite *TaskUsecaseSuite) TestImportTasks_DryRun() {
+	report, err := suite.usecase.ImportTasks(context.TODO(), suite.user, importRows()[:1], true)
+
+	suite.Empty(err.ErrMessage)
+	suite.True(report.DryRun)
+	suite.Equal(1, report.Valid)
+	suite.Empty(report.Errors)
+	suite.Empty(report.Created)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateTask", mock.Anything, mock.Anything)
+}
+
+// Test ImportTasks creates the tasks of a valid file
+func (suite *TaskUsecaseSuite) TestImportTasks() {
+	suite.mockRepo.On("CreateTask", mock.Anything, mock.MatchedBy(func(task domain.Task) bool { return task.Title == "Task 1" })).Return("1", domain.CustomError{})
+	suite.mockRepo.On("CreateTask", mock.Anything, mock.MatchedBy(func(task domain.Task) bool { return task.Title == "Task 2" })).Return("2", domain.CustomError{})
+	rows := []domain.ImportRow{
+		{Line: 2, Input: domain.TaskInput{Title: "Task 1", ProjectID: "project-1"}},
+		{Line: 3, Input: domain.TaskInput{Title: "Task 2", ProjectID: "project-1", Status: "in_progress"}},
+	}
+
+	report, err := suite.usecase.ImportTasks(context.TODO(), suite.user, rows, false)
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal(2, report.Imported)
+	suite.Equal([]domain.ImportedTask{{Line: 2, ID: "1"}, {Line: 3, ID: "2"}}, report.Created)
+	suite.mockPublisher.AssertNumberOfCalls(suite.T(), "Publish", 2)
+}
@@ -3,42 +3,1270 @@ package usecases
 import (
 	"context"
 	//"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"task_managment_api/domain"
+	"time"
 )
 
 type taskUsecase struct {
-	taskRepository domain.TaskRepository
+	taskRepository    domain.TaskRepository
+	userRepository    domain.UserRepository
+	roleRepository    domain.RoleRepository
+	historyRepository domain.TaskHistoryRepository
+	labelRepository   domain.LabelRepository
+	seriesRepository  domain.TaskSeriesRepository
+	access            projectAccess
+	workflow          domain.StatusWorkflow
+	// events receives the task lifecycle events that webhooks subscribe to.
+	events domain.TaskEventPublisher
+	// transactions runs atomic batches.
+	transactions domain.TransactionRunner
+	// subtaskDeletePolicy decides whether deleting a task with subtasks is refused or cascades.
+	subtaskDeletePolicy domain.SubtaskDeletePolicy
 }
 
-func NewTaskUsecase(taskRepository domain.TaskRepository, ) domain.TaskUsecase {
+func NewTaskUsecase(taskRepository domain.TaskRepository, userRepository domain.UserRepository, roleRepository domain.RoleRepository, historyRepository domain.TaskHistoryRepository, labelRepository domain.LabelRepository, projectRepository domain.ProjectRepository, seriesRepository domain.TaskSeriesRepository, events domain.TaskEventPublisher, transactions domain.TransactionRunner, workflow domain.StatusWorkflow, subtaskDeletePolicy domain.SubtaskDeletePolicy) domain.TaskUsecase {
 	return &taskUsecase{
-		taskRepository: taskRepository,
+		taskRepository:      taskRepository,
+		userRepository:      userRepository,
+		roleRepository:      roleRepository,
+		historyRepository:   historyRepository,
+		labelRepository:     labelRepository,
+		seriesRepository:    seriesRepository,
+		events:              events,
+		transactions:        transactions,
+		access:              projectAccess{projectRepository: projectRepository},
+		workflow:            workflow,
+		subtaskDeletePolicy: subtaskDeletePolicy,
 	}
 }
 
 
-func (uc *taskUsecase) GetTasks(c context.Context) ([]domain.Task, domain.CustomError) {
-	return uc.taskRepository.GetTasks(c)
+// GetTasks returns a page of tasks matching the query. Regular users only see the tasks of the projects they
+// are a member of, and the tasks without a project that they own or are assigned to.
+func (uc *taskUsecase) GetTasks(c context.Context, user domain.AuthUser, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	query.Search = ""
+	if err := uc.scopeQuery(c, user, &query); err.ErrCode != 0 {
+		return domain.TaskPage{}, err
+	}
+	return uc.taskRepository.GetTasks(c, query)
+}
+
+// SearchTasks returns a page of the tasks whose title or description match the search text, most relevant
+// first, with the matching words highlighted. The filters and visibility rules of GetTasks apply.
+func (uc *taskUsecase) SearchTasks(c context.Context, user domain.AuthUser, query domain.TaskQuery) (domain.TaskSearchPage, domain.CustomError) {
+	query.Search = strings.TrimSpace(query.Search)
+	if query.Search == "" {
+		return domain.TaskSearchPage{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "q is required", Field: "q"}
+	}
+	if len([]rune(query.Search)) > domain.MaxSearchLength {
+		return domain.TaskSearchPage{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("q must be at most %d characters", domain.MaxSearchLength), Field: "q"}
+	}
+	// results are always ordered by relevance
+	query.SortBy = ""
+	query.SortOrder = ""
+	if err := uc.scopeQuery(c, user, &query); err.ErrCode != 0 {
+		return domain.TaskSearchPage{}, err
+	}
+
+	page, err := uc.taskRepository.SearchTasks(c, query)
+	if err.ErrCode != 0 {
+		return domain.TaskSearchPage{}, err
+	}
+	terms := domain.SearchTerms(query.Search)
+	for i := range page.Results {
+		page.Results[i].Highlights = domain.HighlightTask(page.Results[i].Task, terms)
+	}
+	return page, domain.CustomError{}
+}
+
+// scopeQuery validates the query filters and restricts the query to the tasks the caller can see.
+func (uc *taskUsecase) scopeQuery(c context.Context, user domain.AuthUser, query *domain.TaskQuery) domain.CustomError {
+	if query.SortBy != "" {
+		if _, ok := domain.TaskSortFields[query.SortBy]; !ok {
+			return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "sort must be one of created, title, due_date, status"}
+		}
+	}
+	if query.SortOrder != "" && query.SortOrder != "asc" && query.SortOrder != "desc" {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "order must be asc or desc"}
+	}
+	if query.Limit < 0 || query.Limit > domain.MaxTaskPageSize {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("limit must be between 1 and %d", domain.MaxTaskPageSize)}
+	}
+	if query.Limit == 0 {
+		query.Limit = domain.DefaultTaskPageSize
+	}
+	if query.Status != "" {
+		status, parseErr := uc.workflow.ParseStatus(query.Status)
+		if parseErr != nil {
+			return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: parseErr.Error(), Field: "status"}
+		}
+		query.Status = string(status)
+	}
+	if query.LabelMatch != "" && query.LabelMatch != domain.LabelMatchAny && query.LabelMatch != domain.LabelMatchAll {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "label_match must be any or all", Field: "label_match"}
+	}
+
+	query.VisibleTo = ""
+	query.VisibleProjectIDs = nil
+	if !user.Can(domain.PermissionProjectManageAll) {
+		projectIDs, err := uc.access.visibleProjectIDs(c, user)
+		if err.ErrCode != 0 {
+			return err
+		}
+		query.VisibleTo = user.UserID
+		query.VisibleProjectIDs = projectIDs
+	}
+	return domain.CustomError{}
+}
+
+// GetTaskByID returns a task along with its progress, computed from its direct subtasks and checklist items.
+func (uc *taskUsecase) GetTaskByID(c context.Context, user domain.AuthUser, taskId string) (domain.Task, domain.CustomError) {
+	task, err := uc.getVisibleTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	subtasks, err := uc.taskRepository.GetSubtasks(c, []string{taskId})
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	progress := domain.TaskProgress(task, subtasks)
+	task.Progress = &progress
+	return task, domain.CustomError{}
+}
+
+// getVisibleTask loads a task the caller is allowed to see: admins see every task and regular users the
+// tasks of their projects, or the tasks without a project that they own or are assigned to.
+func (uc *taskUsecase) getVisibleTask(c context.Context, user domain.AuthUser, taskId string) (domain.Task, domain.CustomError) {
+	task, err := uc.taskRepository.GetTaskByID(c, taskId)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	if err := uc.access.checkRead(c, user, task); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	return task, domain.CustomError{}
+}
+
+// getWritableTask loads a task the caller is allowed to change. Project viewers can see tasks but not change them.
+func (uc *taskUsecase) getWritableTask(c context.Context, user domain.AuthUser, taskId string) (domain.Task, domain.CustomError) {
+	task, err := uc.taskRepository.GetTaskByID(c, taskId)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	if err := uc.access.checkWrite(c, user, task); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	return task, domain.CustomError{}
+}
+
+
+// GetOverdueTasks returns the open tasks whose due date has already passed, earliest first.
+func (uc *taskUsecase) GetOverdueTasks(c context.Context, user domain.AuthUser, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	now := time.Now().UTC()
+	query.DueFrom = nil
+	query.DueTo = &now
+	query.OpenOnly = true
+	if query.SortBy == "" {
+		query.SortBy = "due_date"
+	}
+	return uc.GetTasks(c, user, query)
+}
+
+// GetUpcomingTasks returns the open tasks that fall due within the given window, earliest first.
+func (uc *taskUsecase) GetUpcomingTasks(c context.Context, user domain.AuthUser, within time.Duration, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	if within <= 0 {
+		return domain.TaskPage{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "within must be a positive duration", Field: "within"}
+	}
+	now := time.Now().UTC()
+	until := now.Add(within)
+	query.DueFrom = &now
+	query.DueTo = &until
+	query.OpenOnly = true
+	if query.SortBy == "" {
+		query.SortBy = "due_date"
+	}
+	return uc.GetTasks(c, user, query)
+}
+
+// CreateTask stores a new task owned by the caller in one of their projects. Tasks start as todo unless
+// another known status is given. A task with a recurrence rule is the first occurrence of a new series.
+func (uc *taskUsecase) CreateTask(c context.Context, user domain.AuthUser, input domain.TaskInput) (domain.Task, domain.CustomError) {
+	task, rule, err := uc.prepareNewTask(c, user, input)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	return uc.storeNewTask(c, user, task, rule)
+}
+
+// prepareNewTask runs every check of CreateTask without writing anything, and returns the task to store
+// and its recurrence rule, if any.
+func (uc *taskUsecase) prepareNewTask(c context.Context, user domain.AuthUser, input domain.TaskInput) (domain.Task, string, domain.CustomError) {
+	if input.Title == "" {
+		return domain.Task{}, "", domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "title is required", Field: "title"}
+	}
+	if input.ProjectID == "" {
+		return domain.Task{}, "", domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "project_id is required", Field: "project_id"}
+	}
+	task, err := uc.taskFromInput(input)
+	if err.ErrCode != 0 {
+		return domain.Task{}, "", err
+	}
+	task.ProjectID = input.ProjectID
+	if task.Status == "" {
+		task.Status = domain.StatusTodo
+	}
+	rule := ""
+	if input.Recurrence != nil && strings.TrimSpace(*input.Recurrence) != "" {
+		if rule, err = parseRecurrence(*input.Recurrence, task.DueDate); err.ErrCode != 0 {
+			return domain.Task{}, "", err
+		}
+	}
+	if err := uc.checkProjectForNewTask(c, user, task.ProjectID); err.ErrCode != 0 {
+		return domain.Task{}, "", err
+	}
+	if err := uc.checkAssignees(c, task.ProjectID, task.AssigneeIDs, "assignee_ids"); err.ErrCode != 0 {
+		return domain.Task{}, "", err
+	}
+	if task.ParentID != "" {
+		if err := uc.checkParent(c, user, "", task.ProjectID, task.ParentID); err.ErrCode != 0 {
+			return domain.Task{}, "", err
+		}
+	}
+	task.CreatedBy = user.UserID
+	task.Version = 1
+	return task, rule, domain.CustomError{}
+}
+
+// storeNewTask stores a task checked by prepareNewTask, starting its series when it recurs.
+func (uc *taskUsecase) storeNewTask(c context.Context, user domain.AuthUser, task domain.Task, rule string) (domain.Task, domain.CustomError) {
+	if rule != "" {
+		series := domain.TaskSeries{ProjectID: task.ProjectID, Rule: rule, Start: *task.DueDate, Title: task.Title, Description: task.Description, CreatedBy: user.UserID, LastIndex: 1}
+		seriesID, err := uc.seriesRepository.CreateSeries(c, series)
+		if err.ErrCode != 0 {
+			return domain.Task{}, err
+		}
+		task.Recurrence = &domain.TaskRecurrence{SeriesID: seriesID, Rule: rule, Index: 1}
+	}
+
+	taskID, err := uc.taskRepository.CreateTask(c, task)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	task.ID = taskID
+	if err := uc.recordHistory(c, user, task.ID, domain.HistoryActionCreated, domain.DiffTasks(domain.Task{}, task), nil); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	uc.publishEvent(c, user, domain.EventTaskCreated, task, nil)
+	return task, domain.CustomError{}
+}
+
+// UpdateTaskByID replaces a task's title, description, due date and parent with the input (PUT semantics), so
+// fields that are left out are cleared. The status and recurrence only change when they are sent and the project never
+// changes. Admins may update any task and regular users the tasks they can change in their projects. When expectedVersion is set (from
+// If-Match) the update fails with 412 unless the task is still at that version. The returned task
+// carries the new version. For a recurring task, scope says whether the edit applies to this occurrence
+// only or to all future occurrences too.
+func (uc *taskUsecase) UpdateTaskByID(c context.Context, user domain.AuthUser, taskId string, input domain.TaskInput, expectedVersion *int64, scope domain.RecurrenceScope) (domain.Task, domain.CustomError) {
+	// a replacement keeps the assignees, so refuse new ones rather than ignore them
+	if len(input.AssigneeIDs) > 0 {
+		return domain.Task{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "assignee_ids are changed through /tasks/:id/assignees", Field: "assignee_ids"}
+	}
+	updatedTask, err := uc.replacementFromInput(input)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	existingTask, err := uc.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	if input.ProjectID != "" && input.ProjectID != existingTask.ProjectID {
+		return domain.Task{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "project_id cannot be changed", Field: "project_id"}
+	}
+	return uc.replaceTask(c, user, existingTask, updatedTask, input.Recurrence, scope, expectedVersion)
+}
+
+// PatchTaskByID applies a JSON Merge Patch to a task and then validates the result with the same rules as UpdateTaskByID.
+func (uc *taskUsecase) PatchTaskByID(c context.Context, user domain.AuthUser, taskId string, patch domain.TaskPatch, expectedVersion *int64, scope domain.RecurrenceScope) (domain.Task, domain.CustomError) {
+	existingTask, err := uc.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	input, err := patch.Apply(domain.TaskInputFromTask(existingTask))
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	updatedTask, err := uc.replacementFromInput(input)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	return uc.replaceTask(c, user, existingTask, updatedTask, input.Recurrence, scope, expectedVersion)
+}
+
+// replacementFromInput validates a full replacement of a task.
+func (uc *taskUsecase) replacementFromInput(input domain.TaskInput) (domain.Task, domain.CustomError) {
+	if input.Title == "" {
+		return domain.Task{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "title is required", Field: "title"}
+	}
+	return uc.taskFromInput(input)
+}
+
+func (uc *taskUsecase) replaceTask(c context.Context, user domain.AuthUser, existingTask domain.Task, updatedTask domain.Task, recurrence *string, scope domain.RecurrenceScope, expectedVersion *int64) (domain.Task, domain.CustomError) {
+	if expectedVersion != nil && *expectedVersion != existingTask.Version {
+		return domain.Task{}, preconditionFailed(existingTask.Version)
+	}
+
+	newStatus := updatedTask.Status
+	statusChanged := newStatus != "" && newStatus != existingTask.Status
+	if statusChanged {
+		if err := uc.checkTransition(existingTask.Status, newStatus); err.ErrCode != 0 {
+			return domain.Task{}, err
+		}
+		if err := uc.checkBlockers(c, existingTask, newStatus); err.ErrCode != 0 {
+			return domain.Task{}, err
+		}
+	}
+	if updatedTask.ParentID != "" && updatedTask.ParentID != existingTask.ParentID {
+		if err := uc.checkParent(c, user, existingTask.ID, existingTask.ProjectID, updatedTask.ParentID); err.ErrCode != 0 {
+			return domain.Task{}, err
+		}
+	}
+	edit, err := uc.planRecurrence(c, existingTask, updatedTask, recurrence, scope)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	if err := uc.createPlannedSeries(c, &edit); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+
+	// the repository only applies the update while the task is still at the version that was checked
+	replacement := domain.Task{
+		ID:          existingTask.ID,
+		Title:       updatedTask.Title,
+		Description: updatedTask.Description,
+		DueDate:     updatedTask.DueDate,
+		ParentID:    updatedTask.ParentID,
+		Recurrence:  edit.recurrence,
+		Version:     existingTask.Version,
+	}
+	// a status change is stored in the same update, so that the request applies completely or not at all
+	var transition *domain.StatusTransition
+	if statusChanged {
+		change := newTransition(user, existingTask.Status, newStatus)
+		transition = &change
+	}
+	if err := uc.taskRepository.UpdateTaskByID(c, replacement, transition); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	after := existingTask
+	after.Title = replacement.Title
+	after.Description = replacement.Description
+	after.DueDate = replacement.DueDate
+	after.ParentID = replacement.ParentID
+	after.Recurrence = replacement.Recurrence
+	after.Version++
+	if statusChanged {
+		after.Status = newStatus
+	}
+
+	if err := uc.recordChanges(c, user, existingTask, after); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	if err := uc.finishRecurrenceEdit(c, user, after, existingTask, edit); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	if statusChanged {
+		if err := uc.scheduleNextOccurrence(c, user, after); err.ErrCode != 0 {
+			return domain.Task{}, err
+		}
+	}
+	return after, domain.CustomError{}
+}
+
+func preconditionFailed(currentVersion int64) domain.CustomError {
+	return domain.CustomError{
+		ErrCode:    http.StatusPreconditionFailed,
+		ErrMessage: "Task was modified by someone else, reload the task and try again",
+		Details:    map[string]interface{}{"current_version": currentVersion},
+	}
+}
+
+func (uc *taskUsecase) TransitionTask(c context.Context, user domain.AuthUser, taskId string, status string) domain.CustomError {
+	newStatus, parseErr := uc.workflow.ParseStatus(status)
+	if parseErr != nil {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: parseErr.Error(), Field: "status"}
+	}
+	task, err := uc.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if err := uc.checkTransition(task.Status, newStatus); err.ErrCode != 0 {
+		return err
+	}
+	if err := uc.checkBlockers(c, task, newStatus); err.ErrCode != 0 {
+		return err
+	}
+	if err := uc.taskRepository.TransitionTaskStatus(c, taskId, newTransition(user, task.Status, newStatus)); err.ErrCode != 0 {
+		return err
+	}
+
+	after := task
+	after.Status = newStatus
+	if err := uc.recordChanges(c, user, task, after); err.ErrCode != 0 {
+		return err
+	}
+	return uc.scheduleNextOccurrence(c, user, after)
+}
+
+func (uc *taskUsecase) checkTransition(from, to domain.TaskStatus) domain.CustomError {
+	if uc.workflow.CanTransition(from, to) {
+		return domain.CustomError{}
+	}
+	allowed := []string{}
+	for _, status := range uc.workflow.Allowed(from) {
+		allowed = append(allowed, string(status))
+	}
+	return domain.CustomError{
+		ErrCode:    http.StatusConflict,
+		ErrMessage: fmt.Sprintf("Cannot move task from %s to %s", from, to),
+		Field:      "status",
+		Details:    map[string]interface{}{"allowed_statuses": allowed},
+	}
+}
+
+// checkBlockers refuses to start or finish a task while any task blocking it is not done yet.
+// Blockers that were deleted no longer count.
+func (uc *taskUsecase) checkBlockers(c context.Context, task domain.Task, to domain.TaskStatus) domain.CustomError {
+	if !domain.IsBlockedStatus(to) || len(task.BlockedBy) == 0 {
+		return domain.CustomError{}
+	}
+	blockers, err := uc.taskRepository.GetTasksByIDs(c, task.BlockedBy)
+	if err.ErrCode != 0 {
+		return err
+	}
+	open := []string{}
+	for _, blocker := range blockers {
+		if blocker.Status != domain.StatusDone {
+			open = append(open, blocker.ID)
+		}
+	}
+	if len(open) > 0 {
+		return domain.CustomError{
+			ErrCode:    http.StatusConflict,
+			ErrMessage: fmt.Sprintf("Task cannot move to %s while it is blocked by open tasks", to),
+			Field:      "status",
+			Details:    map[string]interface{}{"open_blockers": open},
+		}
+	}
+	return domain.CustomError{}
+}
+
+func newTransition(user domain.AuthUser, from, to domain.TaskStatus) domain.StatusTransition {
+	return domain.StatusTransition{From: from, To: to, By: user.UserID, At: time.Now().UTC()}
+}
+
+// taskFromInput validates the client fields and converts them to a task.
+func (uc *taskUsecase) taskFromInput(input domain.TaskInput) (domain.Task, domain.CustomError) {
+	dueDate, parseErr := domain.ParseDueDate(input.DueDate)
+	if parseErr != nil {
+		return domain.Task{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: parseErr.Error(), Field: "due_date"}
+	}
+	var status domain.TaskStatus
+	if input.Status != "" {
+		status, parseErr = uc.workflow.ParseStatus(input.Status)
+		if parseErr != nil {
+			return domain.Task{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: parseErr.Error(), Field: "status"}
+		}
+	}
+	return domain.Task{
+		Title:       input.Title,
+		Description: input.Description,
+		DueDate:     dueDate,
+		Status:      status,
+		AssigneeIDs: input.AssigneeIDs,
+		ParentID:    input.ParentID,
+	}, domain.CustomError{}
+}
+
+// DeleteTaskByID moves a task to the trash. A task with subtasks is either refused or deleted together
+// with all of its subtasks, depending on the configured policy.
+func (uc *taskUsecase) DeleteTaskByID(c context.Context, user domain.AuthUser, taskId string) domain.CustomError {
+	task, err := uc.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+	subtasks, err := uc.descendants(c, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+
+	deletedAt := time.Now().UTC()
+	if len(subtasks) > 0 {
+		if uc.subtaskDeletePolicy != domain.SubtaskDeleteCascade {
+			return domain.CustomError{
+				ErrCode:    http.StatusConflict,
+				ErrMessage: "Task has subtasks, delete or move them first",
+				Details:    map[string]interface{}{"subtask_count": len(subtasks)},
+			}
+		}
+		// deepest first, so a failure part way never leaves a subtask whose parent is already gone
+		for i := len(subtasks) - 1; i >= 0; i-- {
+			if err := uc.trashTask(c, user, subtasks[i], deletedAt); err.ErrCode != 0 {
+				return err
+			}
+		}
+	}
+	return uc.trashTask(c, user, task, deletedAt)
+}
+
+func (uc *taskUsecase) trashTask(c context.Context, user domain.AuthUser, task domain.Task, deletedAt time.Time) domain.CustomError {
+	if err := uc.taskRepository.DeleteTaskByID(c, task.ID, user.UserID, deletedAt); err.ErrCode != 0 {
+		return err
+	}
+	if err := uc.recordHistory(c, user, task.ID, domain.HistoryActionDeleted, domain.DiffTasks(task, domain.Task{}), &task); err.ErrCode != 0 {
+		return err
+	}
+	uc.publishEvent(c, user, domain.EventTaskDeleted, task, nil)
+	return domain.CustomError{}
+}
+
+// ReleaseUserTasks either hands a user's tasks and assignments over to another user, or moves the tasks
+// they created to the trash together with their subtasks, whatever the subtask delete policy, and takes
+// them off the tasks of others. Trashing records history and sends webhooks like DeleteTaskByID does.
+func (uc *taskUsecase) ReleaseUserTasks(c context.Context, user domain.AuthUser, userID string, deletion domain.UserDeletion) (domain.UserDeletionReport, domain.CustomError) {
+	report := domain.UserDeletionReport{Tasks: deletion.Tasks, TransferTo: deletion.TransferTo}
+	var err domain.CustomError
+	if deletion.Tasks == domain.UserTasksTransfer {
+		if report.Transferred, err = uc.taskRepository.TransferTasks(c, userID, deletion.TransferTo); err.ErrCode != 0 {
+			return domain.UserDeletionReport{}, err
+		}
+		if report.Unassigned, err = uc.taskRepository.ReplaceAssignee(c, userID, deletion.TransferTo); err.ErrCode != 0 {
+			return domain.UserDeletionReport{}, err
+		}
+		return report, domain.CustomError{}
+	}
+
+	created, err := uc.taskRepository.GetTasksCreatedBy(c, userID)
+	if err.ErrCode != 0 {
+		return domain.UserDeletionReport{}, err
+	}
+	deletedAt := time.Now().UTC()
+	trashed := map[string]bool{}
+	for _, task := range created {
+		if trashed[task.ID] {
+			continue
+		}
+		subtasks, err := uc.descendants(c, task.ID)
+		if err.ErrCode != 0 {
+			return domain.UserDeletionReport{}, err
+		}
+		// deepest first and the task itself last, as in DeleteTaskByID
+		doomed := append([]domain.Task{task}, subtasks...)
+		for i := len(doomed) - 1; i >= 0; i-- {
+			if trashed[doomed[i].ID] {
+				continue
+			}
+			if err := uc.trashTask(c, user, doomed[i], deletedAt); err.ErrCode != 0 {
+				return domain.UserDeletionReport{}, err
+			}
+			trashed[doomed[i].ID] = true
+			report.Deleted++
+		}
+	}
+	if report.Unassigned, err = uc.taskRepository.RemoveAssigneeFromTasks(c, userID); err.ErrCode != 0 {
+		return domain.UserDeletionReport{}, err
+	}
+	return report, domain.CustomError{}
+}
+
+// GetTrash lists the tasks in the trash, with the visibility rules of GetTasks: regular users see the
+// deleted tasks of the projects they are a member of, and the deleted tasks without a project that they
+// own or were assigned to.
+func (uc *taskUsecase) GetTrash(c context.Context, user domain.AuthUser, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	query.Deleted = true
+	return uc.GetTasks(c, user, query)
+}
+
+// RestoreTaskByID takes a task out of the trash. Only admins and the task's creator may restore it, and the
+// creator must still be able to change the tasks of its project.
+func (uc *taskUsecase) RestoreTaskByID(c context.Context, user domain.AuthUser, taskId string) (domain.Task, domain.CustomError) {
+	task, err := uc.taskRepository.GetDeletedTaskByID(c, taskId)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	if !user.Can(domain.PermissionTaskDelete) && task.CreatedBy != user.UserID {
+		return domain.Task{}, domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "Only the task creator or an admin can restore a task"}
+	}
+	if err := uc.access.checkWrite(c, user, task); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	if err := uc.taskRepository.RestoreTaskByID(c, taskId); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+
+	task.DeletedAt = nil
+	task.DeletedBy = ""
+	task.Version++
+	if err := uc.recordHistory(c, user, taskId, domain.HistoryActionRestored, domain.DiffTasks(domain.Task{}, task), nil); err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	uc.publishEvent(c, user, domain.EventTaskRestored, task, nil)
+	return task, domain.CustomError{}
+}
+
+// PurgeTaskByID permanently removes a task from the trash. The route is admin only.
+func (uc *taskUsecase) PurgeTaskByID(c context.Context, user domain.AuthUser, taskId string) domain.CustomError {
+	task, err := uc.taskRepository.GetDeletedTaskByID(c, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if err := uc.taskRepository.PurgeTaskByID(c, taskId); err.ErrCode != 0 {
+		return err
+	}
+	return uc.recordHistory(c, user, taskId, domain.HistoryActionPurged, []domain.FieldChange{}, &task)
+}
+
+// PurgeExpiredTasks permanently removes the tasks that have been in the trash for longer than the retention period.
+func (uc *taskUsecase) PurgeExpiredTasks(c context.Context, retention time.Duration) (int64, domain.CustomError) {
+	if retention <= 0 {
+		return 0, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "retention must be a positive duration"}
+	}
+	return uc.taskRepository.PurgeDeletedBefore(c, time.Now().UTC().Add(-retention))
+}
+
+// AssignUsers adds existing users to a task. Only admins, project owners and the task creator can change
+// assignees, and the users must be able to change the tasks of the project.
+func (uc *taskUsecase) AssignUsers(c context.Context, user domain.AuthUser, taskId string, userIDs []string) domain.CustomError {
+	if len(userIDs) == 0 {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "user_ids is required", Field: "user_ids"}
+	}
+	task, err := uc.getTaskForAssigneeChange(c, user, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if err := uc.checkAssignees(c, task.ProjectID, userIDs, "user_ids"); err.ErrCode != 0 {
+		return err
+	}
+	if err := uc.taskRepository.AddAssignees(c, taskId, userIDs); err.ErrCode != 0 {
+		return err
+	}
+
+	after := task
+	after.AssigneeIDs = append([]string{}, task.AssigneeIDs...)
+	for _, id := range userIDs {
+		if !containsID(after.AssigneeIDs, id) {
+			after.AssigneeIDs = append(after.AssigneeIDs, id)
+		}
+	}
+	return uc.recordChanges(c, user, task, after)
+}
+
+func (uc *taskUsecase) UnassignUser(c context.Context, user domain.AuthUser, taskId string, userID string) domain.CustomError {
+	task, err := uc.getTaskForAssigneeChange(c, user, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if err := uc.taskRepository.RemoveAssignee(c, taskId, userID); err.ErrCode != 0 {
+		return err
+	}
+
+	after := task
+	after.AssigneeIDs = []string{}
+	for _, id := range task.AssigneeIDs {
+		if id != userID {
+			after.AssigneeIDs = append(after.AssigneeIDs, id)
+		}
+	}
+	return uc.recordChanges(c, user, task, after)
+}
+
+// GetTaskHistory returns a page of a task's history, newest first. Deleted tasks are checked against
+// the snapshot taken when they were deleted, so their history stays readable.
+func (uc *taskUsecase) GetTaskHistory(c context.Context, user domain.AuthUser, taskId string, cursor string, limit int64) (domain.TaskHistoryPage, domain.CustomError) {
+	if limit < 0 || limit > domain.MaxTaskPageSize {
+		return domain.TaskHistoryPage{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("limit must be between 1 and %d", domain.MaxTaskPageSize), Field: "limit"}
+	}
+	if limit == 0 {
+		limit = domain.DefaultTaskPageSize
+	}
+
+	task, err := uc.taskRepository.GetTaskByID(c, taskId)
+	if err.ErrCode == http.StatusNotFound {
+		deletion, historyErr := uc.historyRepository.GetDeletionEntry(c, taskId)
+		if historyErr.ErrCode != 0 {
+			return domain.TaskHistoryPage{}, historyErr
+		}
+		if deletion.Snapshot != nil {
+			task = *deletion.Snapshot
+		}
+	} else if err.ErrCode != 0 {
+		return domain.TaskHistoryPage{}, err
+	}
+	if err := uc.access.checkRead(c, user, task); err.ErrCode != 0 {
+		return domain.TaskHistoryPage{}, err
+	}
+
+	return uc.historyRepository.GetEntries(c, taskId, cursor, limit)
+}
+
+// GetSubtasks returns a page of the direct subtasks of a task the caller can see.
+func (uc *taskUsecase) GetSubtasks(c context.Context, user domain.AuthUser, taskId string, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	if _, err := uc.getVisibleTask(c, user, taskId); err.ErrCode != 0 {
+		return domain.TaskPage{}, err
+	}
+	query.ParentID = taskId
+	query.Deleted = false
+	return uc.GetTasks(c, user, query)
+}
+
+// AddChecklistItem appends an unchecked item to a task's checklist.
+func (uc *taskUsecase) AddChecklistItem(c context.Context, user domain.AuthUser, taskId string, text string) (domain.ChecklistItem, domain.CustomError) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return domain.ChecklistItem{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "text is required", Field: "text"}
+	}
+	task, err := uc.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return domain.ChecklistItem{}, err
+	}
+	item, err := uc.taskRepository.AddChecklistItem(c, taskId, domain.ChecklistItem{Text: text})
+	if err.ErrCode != 0 {
+		return domain.ChecklistItem{}, err
+	}
+
+	after := task
+	after.Checklist = append(append([]domain.ChecklistItem{}, task.Checklist...), item)
+	if err := uc.recordChanges(c, user, task, after); err.ErrCode != 0 {
+		return domain.ChecklistItem{}, err
+	}
+	return item, domain.CustomError{}
+}
+
+// UpdateChecklistItem renames or checks off a checklist item.
+func (uc *taskUsecase) UpdateChecklistItem(c context.Context, user domain.AuthUser, taskId string, itemID string, update domain.ChecklistItemUpdate) (domain.ChecklistItem, domain.CustomError) {
+	task, err := uc.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return domain.ChecklistItem{}, err
+	}
+	index := findChecklistItem(task.Checklist, itemID)
+	if index < 0 {
+		return domain.ChecklistItem{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Checklist item not found"}
+	}
+
+	item := task.Checklist[index]
+	if update.Text != nil {
+		item.Text = strings.TrimSpace(*update.Text)
+		if item.Text == "" {
+			return domain.ChecklistItem{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "text cannot be empty", Field: "text"}
+		}
+	}
+	if update.Done != nil {
+		item.Done = *update.Done
+	}
+	if err := uc.taskRepository.UpdateChecklistItem(c, taskId, item); err.ErrCode != 0 {
+		return domain.ChecklistItem{}, err
+	}
+
+	after := task
+	after.Checklist = append([]domain.ChecklistItem{}, task.Checklist...)
+	after.Checklist[index] = item
+	if err := uc.recordChanges(c, user, task, after); err.ErrCode != 0 {
+		return domain.ChecklistItem{}, err
+	}
+	return item, domain.CustomError{}
+}
+
+// RemoveChecklistItem deletes an item from a task's checklist.
+func (uc *taskUsecase) RemoveChecklistItem(c context.Context, user domain.AuthUser, taskId string, itemID string) domain.CustomError {
+	task, err := uc.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+	index := findChecklistItem(task.Checklist, itemID)
+	if index < 0 {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Checklist item not found"}
+	}
+	if err := uc.taskRepository.RemoveChecklistItem(c, taskId, itemID); err.ErrCode != 0 {
+		return err
+	}
+
+	after := task
+	after.Checklist = append(append([]domain.ChecklistItem{}, task.Checklist[:index]...), task.Checklist[index+1:]...)
+	return uc.recordChanges(c, user, task, after)
+}
+
+func findChecklistItem(items []domain.ChecklistItem, itemID string) int {
+	for i, item := range items {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetDependencies returns every task the given task transitively waits on and every task transitively waiting on it.
+func (uc *taskUsecase) GetDependencies(c context.Context, user domain.AuthUser, taskId string) (domain.TaskDependencyGraph, domain.CustomError) {
+	task, err := uc.getVisibleTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return domain.TaskDependencyGraph{}, err
+	}
+	graph := domain.TaskDependencyGraph{TaskID: taskId, Upstream: []domain.DependencyNode{}, Downstream: []domain.DependencyNode{}, Edges: []domain.DependencyEdge{}}
+	canRead := uc.access.readChecker(c, user)
+
+	// upstream: follow blocked_by from the task
+	seen := map[string]bool{taskId: true}
+	level := []domain.Task{task}
+	for depth := 1; len(level) > 0; depth++ {
+		ids := []string{}
+		for _, t := range level {
+			ids = append(ids, t.BlockedBy...)
+		}
+		blockers, err := uc.taskRepository.GetTasksByIDs(c, ids)
+		if err.ErrCode != 0 {
+			return domain.TaskDependencyGraph{}, err
+		}
+		active := map[string]bool{}
+		for _, blocker := range blockers {
+			active[blocker.ID] = true
+		}
+		for _, t := range level {
+			for _, blockerID := range t.BlockedBy {
+				if active[blockerID] {
+					graph.Edges = append(graph.Edges, domain.DependencyEdge{BlockerID: blockerID, BlockedID: t.ID})
+				}
+			}
+		}
+		level = nil
+		for _, blocker := range blockers {
+			if !seen[blocker.ID] {
+				seen[blocker.ID] = true
+				graph.Upstream = append(graph.Upstream, dependencyNode(canRead(blocker), blocker, depth))
+				level = append(level, blocker)
+			}
+		}
+	}
+
+	// downstream: find the tasks that list the current level in their blocked_by
+	seen = map[string]bool{taskId: true}
+	levelIDs := []string{taskId}
+	for depth := 1; len(levelIDs) > 0; depth++ {
+		dependents, err := uc.taskRepository.GetDependents(c, levelIDs)
+		if err.ErrCode != 0 {
+			return domain.TaskDependencyGraph{}, err
+		}
+		inLevel := map[string]bool{}
+		for _, id := range levelIDs {
+			inLevel[id] = true
+		}
+		levelIDs = nil
+		for _, dependent := range dependents {
+			for _, blockerID := range dependent.BlockedBy {
+				if inLevel[blockerID] {
+					graph.Edges = append(graph.Edges, domain.DependencyEdge{BlockerID: blockerID, BlockedID: dependent.ID})
+				}
+			}
+			if !seen[dependent.ID] {
+				seen[dependent.ID] = true
+				graph.Downstream = append(graph.Downstream, dependencyNode(canRead(dependent), dependent, depth))
+				levelIDs = append(levelIDs, dependent.ID)
+			}
+		}
+	}
+	return graph, domain.CustomError{}
+}
+
+func dependencyNode(visible bool, task domain.Task, depth int) domain.DependencyNode {
+	node := domain.DependencyNode{ID: task.ID, Status: task.Status, Depth: depth}
+	if visible {
+		node.Title = task.Title
+	} else {
+		node.Hidden = true
+	}
+	return node
+}
+
+// AddDependency marks a task as blocked by another task. Links that would make a task wait on itself,
+// directly or through other tasks, are refused.
+func (uc *taskUsecase) AddDependency(c context.Context, user domain.AuthUser, taskId string, blockerID string) domain.CustomError {
+	if blockerID == taskId {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "a task cannot block itself", Field: "blocker_id"}
+	}
+	task, err := uc.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if containsID(task.BlockedBy, blockerID) {
+		return domain.CustomError{}
+	}
+	blocker, err := uc.getVisibleTask(c, user, blockerID)
+	if err.ErrCode == http.StatusNotFound {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "blocking task not found", Field: "blocker_id"}
+	}
+	if err.ErrCode != 0 {
+		return err
+	}
+
+	cycle, err := uc.waitsOn(c, blocker, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if cycle {
+		return domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "The dependency would create a cycle", Field: "blocker_id"}
+	}
+
+	if err := uc.taskRepository.AddDependency(c, taskId, blockerID); err.ErrCode != 0 {
+		return err
+	}
+	after := task
+	after.BlockedBy = append(append([]string{}, task.BlockedBy...), blockerID)
+	return uc.recordChanges(c, user, task, after)
 }
 
-func (uc *taskUsecase) GetTaskByID(c context.Context, taskId string) (domain.Task, domain.CustomError) {
-	return uc.taskRepository.GetTaskByID(c, taskId)
+// waitsOn reports whether a task is blocked, directly or through other tasks, by the task with the given ID.
+func (uc *taskUsecase) waitsOn(c context.Context, task domain.Task, targetID string) (bool, domain.CustomError) {
+	seen := map[string]bool{task.ID: true}
+	level := task.BlockedBy
+	for len(level) > 0 {
+		next := []string{}
+		for _, id := range level {
+			if id == targetID {
+				return true, domain.CustomError{}
+			}
+			if !seen[id] {
+				seen[id] = true
+				next = append(next, id)
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		blockers, err := uc.taskRepository.GetTasksByIDs(c, next)
+		if err.ErrCode != 0 {
+			return false, err
+		}
+		level = nil
+		for _, blocker := range blockers {
+			level = append(level, blocker.BlockedBy...)
+		}
+	}
+	return false, domain.CustomError{}
 }
 
+// RemoveDependency unblocks a task from another task.
+func (uc *taskUsecase) RemoveDependency(c context.Context, user domain.AuthUser, taskId string, blockerID string) domain.CustomError {
+	task, err := uc.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if !containsID(task.BlockedBy, blockerID) {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Dependency not found"}
+	}
+	if err := uc.taskRepository.RemoveDependency(c, taskId, blockerID); err.ErrCode != 0 {
+		return err
+	}
 
-func (uc *taskUsecase) CreateTask(c context.Context, task domain.Task) domain.CustomError {
-	if task.Title == "" {
-		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "title is required"}
+	after := task
+	after.BlockedBy = []string{}
+	for _, id := range task.BlockedBy {
+		if id != blockerID {
+			after.BlockedBy = append(after.BlockedBy, id)
+		}
 	}
-	return uc.taskRepository.CreateTask(c, task)
+	return uc.recordChanges(c, user, task, after)
 }
 
-func (uc *taskUsecase) UpdateTaskByID(c context.Context, taskId string, updatedTask domain.Task) domain.CustomError {
-	updatedTask.ID = taskId
-	return uc.taskRepository.UpdateTaskByID(c, updatedTask)
+// AddLabels puts existing labels on a task. Labels the task already carries are left as they are.
+func (uc *taskUsecase) AddLabels(c context.Context, user domain.AuthUser, taskId string, labelIDs []string) domain.CustomError {
+	if len(labelIDs) == 0 {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "label_ids is required", Field: "label_ids"}
+	}
+	task, err := uc.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+
+	distinct := []string{}
+	for _, id := range labelIDs {
+		if !containsID(distinct, id) {
+			distinct = append(distinct, id)
+		}
+	}
+	labels, err := uc.labelRepository.GetLabelsByIDs(c, distinct)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if len(labels) != len(distinct) {
+		found := []string{}
+		for _, label := range labels {
+			found = append(found, label.ID)
+		}
+		for _, id := range distinct {
+			if !containsID(found, id) {
+				return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("Label %s not found", id), Field: "label_ids"}
+			}
+		}
+	}
+	if err := uc.taskRepository.AddLabels(c, taskId, distinct); err.ErrCode != 0 {
+		return err
+	}
+
+	after := task
+	after.LabelIDs = append([]string{}, task.LabelIDs...)
+	for _, id := range distinct {
+		if !containsID(after.LabelIDs, id) {
+			after.LabelIDs = append(after.LabelIDs, id)
+		}
+	}
+	return uc.recordChanges(c, user, task, after)
 }
 
-func (uc taskUsecase) DeleteTaskByID(c context.Context, taskId string) domain.CustomError {
-	return uc.taskRepository.DeleteTaskByID(c, taskId)
-}
\ No newline at end of file
+// RemoveLabel takes a label off a task.
+func (uc *taskUsecase) RemoveLabel(c context.Context, user domain.AuthUser, taskId string, labelID string) domain.CustomError {
+	task, err := uc.getWritableTask(c, user, taskId)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if !containsID(task.LabelIDs, labelID) {
+		return domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Label not found on this task"}
+	}
+	if err := uc.taskRepository.RemoveLabel(c, taskId, labelID); err.ErrCode != 0 {
+		return err
+	}
+
+	after := task
+	after.LabelIDs = []string{}
+	for _, id := range task.LabelIDs {
+		if id != labelID {
+			after.LabelIDs = append(after.LabelIDs, id)
+		}
+	}
+	return uc.recordChanges(c, user, task, after)
+}
+
+// checkParent makes sure a task can be placed under parentID: the parent must exist, be visible to the
+// caller and belong to the same project, and the move must neither create a cycle nor nest tasks deeper
+// than domain.MaxTaskDepth. taskId is empty for a task that is still being created.
+func (uc *taskUsecase) checkParent(c context.Context, user domain.AuthUser, taskId string, projectID string, parentID string) domain.CustomError {
+	if parentID == taskId {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "a task cannot be its own parent", Field: "parent_id"}
+	}
+	parent, err := uc.taskRepository.GetTaskByID(c, parentID)
+	if err.ErrCode == http.StatusNotFound {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "parent task not found", Field: "parent_id"}
+	}
+	if err.ErrCode != 0 {
+		return err
+	}
+	role, err := uc.access.roleFor(c, user, parent)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if !role.AtLeast(domain.ProjectRoleViewer) {
+		return domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "You do not have access to the parent task", Field: "parent_id"}
+	}
+	if parent.ProjectID != projectID {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "parent task belongs to another project", Field: "parent_id"}
+	}
+
+	// walk up from the new parent; meeting the task itself on the way means the move would create a cycle
+	depth := 1
+	ancestor := parent
+	for ancestor.ParentID != "" && depth < domain.MaxTaskDepth {
+		if taskId != "" && ancestor.ParentID == taskId {
+			return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "parent_id would make the task a subtask of itself", Field: "parent_id"}
+		}
+		next, err := uc.taskRepository.GetTaskByID(c, ancestor.ParentID)
+		if err.ErrCode == http.StatusNotFound {
+			// the ancestor is in the trash, so the chain stops here
+			break
+		}
+		if err.ErrCode != 0 {
+			return err
+		}
+		ancestor = next
+		depth++
+	}
+	if ancestor.ParentID != "" && depth >= domain.MaxTaskDepth {
+		// the walk stopped at the limit with ancestors left, so the parent is already too deep
+		depth++
+	}
+
+	height := 1
+	if taskId != "" {
+		height, err = uc.subtreeHeight(c, taskId)
+		if err.ErrCode != 0 {
+			return err
+		}
+	}
+	if depth+height > domain.MaxTaskDepth {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("tasks can be nested at most %d levels deep", domain.MaxTaskDepth), Field: "parent_id"}
+	}
+	return domain.CustomError{}
+}
+
+// subtreeHeight counts the levels of the hierarchy below and including a task, stopping once it is
+// deeper than any allowed hierarchy.
+func (uc *taskUsecase) subtreeHeight(c context.Context, taskId string) (int, domain.CustomError) {
+	height := 1
+	level := []string{taskId}
+	for height <= domain.MaxTaskDepth {
+		children, err := uc.taskRepository.GetSubtasks(c, level)
+		if err.ErrCode != 0 {
+			return 0, err
+		}
+		if len(children) == 0 {
+			break
+		}
+		level = taskIDs(children)
+		height++
+	}
+	return height, domain.CustomError{}
+}
+
+// descendants lists every active task below the given one, level by level from the top.
+func (uc *taskUsecase) descendants(c context.Context, taskId string) ([]domain.Task, domain.CustomError) {
+	all := []domain.Task{}
+	seen := map[string]bool{taskId: true}
+	level := []string{taskId}
+	for len(level) > 0 {
+		children, err := uc.taskRepository.GetSubtasks(c, level)
+		if err.ErrCode != 0 {
+			return nil, err
+		}
+		level = nil
+		for _, child := range children {
+			if seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			all = append(all, child)
+			level = append(level, child.ID)
+		}
+	}
+	return all, domain.CustomError{}
+}
+
+func taskIDs(tasks []domain.Task) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+// recordChanges writes an update entry for the fields that differ, if any.
+func (uc *taskUsecase) recordChanges(c context.Context, user domain.AuthUser, before, after domain.Task) domain.CustomError {
+	changes := domain.DiffTasks(before, after)
+	if len(changes) == 0 {
+		return domain.CustomError{}
+	}
+	if err := uc.recordHistory(c, user, before.ID, domain.HistoryActionUpdated, changes, nil); err.ErrCode != 0 {
+		return err
+	}
+	uc.publishEvent(c, user, domain.EventTaskUpdated, after, changes)
+	if before.Status != after.Status {
+		uc.publishEvent(c, user, domain.EventTaskStatusChanged, after, changes)
+	}
+	return domain.CustomError{}
+}
+
+// publishEvent raises a task lifecycle event for the webhooks subscribed to it.
+func (uc *taskUsecase) publishEvent(c context.Context, user domain.AuthUser, eventType string, task domain.Task, changes []domain.FieldChange) {
+	uc.events.Publish(c, domain.TaskEvent{
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		ActorID:    user.UserID,
+		Task:       task,
+		Changes:    changes,
+	})
+}
+
+func (uc *taskUsecase) recordHistory(c context.Context, user domain.AuthUser, taskId string, action string, changes []domain.FieldChange, snapshot *domain.Task) domain.CustomError {
+	return uc.historyRepository.AddEntry(c, domain.TaskHistoryEntry{
+		TaskID:        taskId,
+		Action:        action,
+		ActorID:       user.UserID,
+		ActorUsername: user.Username,
+		At:            time.Now().UTC(),
+		Changes:       changes,
+		Snapshot:      snapshot,
+	})
+}
+
+func (uc *taskUsecase) getTaskForAssigneeChange(c context.Context, user domain.AuthUser, taskId string) (domain.Task, domain.CustomError) {
+	task, err := uc.taskRepository.GetTaskByID(c, taskId)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	role, err := uc.access.roleFor(c, user, task)
+	if err.ErrCode != 0 {
+		return domain.Task{}, err
+	}
+	isCreator := task.CreatedBy == user.UserID && role.AtLeast(domain.ProjectRoleMember)
+	if role != domain.ProjectRoleOwner && !isCreator {
+		return domain.Task{}, domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "Only the task creator, a project owner or an admin can change assignees"}
+	}
+	return task, domain.CustomError{}
+}
+
+// checkProjectForNewTask makes sure the project exists and the caller may add tasks to it.
+func (uc *taskUsecase) checkProjectForNewTask(c context.Context, user domain.AuthUser, projectID string) domain.CustomError {
+	project, err := uc.access.projectRepository.GetProjectByID(c, projectID)
+	if err.ErrCode == http.StatusNotFound {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "project not found", Field: "project_id"}
+	}
+	if err.ErrCode != 0 {
+		return err
+	}
+	if !user.Can(domain.PermissionProjectManageAll) && !project.RoleOf(user.UserID).AtLeast(domain.ProjectRoleMember) {
+		return domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "Only project members can add tasks to this project", Field: "project_id"}
+	}
+	return domain.CustomError{}
+}
+
+// checkAssignees makes sure every user exists and, for a task in a project, can change the project's
+// tasks. Users whose role has the project:manage_all permission can be assigned to any task.
+func (uc *taskUsecase) checkAssignees(c context.Context, projectID string, userIDs []string, field string) domain.CustomError {
+	var project *domain.Project
+	for _, id := range userIDs {
+		assignee, err := uc.userRepository.GetUserByID(c, id)
+		if err.ErrCode == http.StatusNotFound || err.ErrCode == http.StatusBadRequest {
+			return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("User %s not found", id), Field: field}
+		}
+		if err.ErrCode != 0 {
+			return err
+		}
+		if projectID == "" {
+			continue
+		}
+		permissions, err := rolePermissions(c, uc.roleRepository, assignee.Role)
+		if err.ErrCode != 0 {
+			return err
+		}
+		if domain.HasPermission(permissions, domain.PermissionProjectManageAll) {
+			continue
+		}
+		if project == nil {
+			found, err := uc.access.projectRepository.GetProjectByID(c, projectID)
+			if err.ErrCode != 0 {
+				return err
+			}
+			project = &found
+		}
+		if !project.RoleOf(id).AtLeast(domain.ProjectRoleMember) {
+			return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("User %s is not a member of the project", id), Field: field}
+		}
+	}
+	return domain.CustomError{}
+}
+
+func containsID(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
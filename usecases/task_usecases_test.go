@@ -2,6 +2,8 @@ package usecases_test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"task_managment_api/domain"
 	"task_managment_api/usecases"
@@ -16,9 +18,14 @@ type MockTaskRepository struct {
 	mock.Mock
 }
 
-func (m *MockTaskRepository) GetTasks(c context.Context) ([]domain.Task, domain.CustomError) {
-	args := m.Called(c)
-	return args.Get(0).([]domain.Task), args.Get(1).(domain.CustomError)
+func (m *MockTaskRepository) GetTasks(c context.Context, query domain.TaskQuery) (domain.TaskPage, domain.CustomError) {
+	args := m.Called(c, query)
+	return args.Get(0).(domain.TaskPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) SearchTasks(c context.Context, query domain.TaskQuery) (domain.TaskSearchPage, domain.CustomError) {
+	args := m.Called(c, query)
+	return args.Get(0).(domain.TaskSearchPage), args.Get(1).(domain.CustomError)
 }
 
 func (m *MockTaskRepository) GetTaskByID(c context.Context, taskId string) (domain.Task, domain.CustomError) {
@@ -26,107 +33,1202 @@ func (m *MockTaskRepository) GetTaskByID(c context.Context, taskId string) (doma
 	return args.Get(0).(domain.Task), args.Get(1).(domain.CustomError)
 }
 
-func (m *MockTaskRepository) CreateTask(c context.Context, task domain.Task) domain.CustomError {
+func (m *MockTaskRepository) CreateTask(c context.Context, task domain.Task) (string, domain.CustomError) {
 	args := m.Called(c, task)
+	return args.String(0), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) UpdateTaskByID(c context.Context, updatedTask domain.Task, transition *domain.StatusTransition) domain.CustomError {
+	args := m.Called(c, updatedTask, transition)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) TransitionTaskStatus(c context.Context, taskId string, transition domain.StatusTransition) domain.CustomError {
+	args := m.Called(c, taskId, transition)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) DeleteTaskByID(c context.Context, taskId string, deletedBy string, deletedAt time.Time) domain.CustomError {
+	args := m.Called(c, taskId, deletedBy, deletedAt)
 	return args.Get(0).(domain.CustomError)
 }
 
-func (m *MockTaskRepository) UpdateTaskByID(c context.Context, updatedTask domain.Task) domain.CustomError {
-	args := m.Called(c, updatedTask)
+func (m *MockTaskRepository) GetDeletedTaskByID(c context.Context, taskId string) (domain.Task, domain.CustomError) {
+	args := m.Called(c, taskId)
+	return args.Get(0).(domain.Task), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) RestoreTaskByID(c context.Context, taskId string) domain.CustomError {
+	args := m.Called(c, taskId)
 	return args.Get(0).(domain.CustomError)
 }
 
-func (m *MockTaskRepository) DeleteTaskByID(c context.Context, taskId string) domain.CustomError {
+func (m *MockTaskRepository) PurgeTaskByID(c context.Context, taskId string) domain.CustomError {
 	args := m.Called(c, taskId)
 	return args.Get(0).(domain.CustomError)
 }
 
+func (m *MockTaskRepository) PurgeDeletedBefore(c context.Context, cutoff time.Time) (int64, domain.CustomError) {
+	args := m.Called(c, cutoff)
+	return args.Get(0).(int64), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) GetSubtasks(c context.Context, parentIDs []string) ([]domain.Task, domain.CustomError) {
+	args := m.Called(c, parentIDs)
+	return args.Get(0).([]domain.Task), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) AddChecklistItem(c context.Context, taskId string, item domain.ChecklistItem) (domain.ChecklistItem, domain.CustomError) {
+	args := m.Called(c, taskId, item)
+	return args.Get(0).(domain.ChecklistItem), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) UpdateChecklistItem(c context.Context, taskId string, item domain.ChecklistItem) domain.CustomError {
+	args := m.Called(c, taskId, item)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) RemoveChecklistItem(c context.Context, taskId string, itemID string) domain.CustomError {
+	args := m.Called(c, taskId, itemID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) GetTasksByIDs(c context.Context, taskIDs []string) ([]domain.Task, domain.CustomError) {
+	args := m.Called(c, taskIDs)
+	return args.Get(0).([]domain.Task), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) GetDependents(c context.Context, taskIDs []string) ([]domain.Task, domain.CustomError) {
+	args := m.Called(c, taskIDs)
+	return args.Get(0).([]domain.Task), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) AddDependency(c context.Context, taskId string, blockerID string) domain.CustomError {
+	args := m.Called(c, taskId, blockerID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) RemoveDependency(c context.Context, taskId string, blockerID string) domain.CustomError {
+	args := m.Called(c, taskId, blockerID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) AddLabels(c context.Context, taskId string, labelIDs []string) domain.CustomError {
+	args := m.Called(c, taskId, labelIDs)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) RemoveLabel(c context.Context, taskId string, labelID string) domain.CustomError {
+	args := m.Called(c, taskId, labelID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) RemoveLabelFromTasks(c context.Context, labelID string) (int64, domain.CustomError) {
+	args := m.Called(c, labelID)
+	return args.Get(0).(int64), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) GetTasksCreatedBy(c context.Context, userID string) ([]domain.Task, domain.CustomError) {
+	args := m.Called(c, userID)
+	return args.Get(0).([]domain.Task), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) TransferTasks(c context.Context, fromUserID string, toUserID string) (int64, domain.CustomError) {
+	args := m.Called(c, fromUserID, toUserID)
+	return args.Get(0).(int64), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) ReplaceAssignee(c context.Context, fromUserID string, toUserID string) (int64, domain.CustomError) {
+	args := m.Called(c, fromUserID, toUserID)
+	return args.Get(0).(int64), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) RemoveAssigneeFromTasks(c context.Context, userID string) (int64, domain.CustomError) {
+	args := m.Called(c, userID)
+	return args.Get(0).(int64), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) AddAssignees(c context.Context, taskId string, userIDs []string) domain.CustomError {
+	args := m.Called(c, taskId, userIDs)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) RemoveAssignee(c context.Context, taskId string, userID string) domain.CustomError {
+	args := m.Called(c, taskId, userID)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskRepository) GetSeriesTasks(c context.Context, seriesID string, afterIndex int) ([]domain.Task, domain.CustomError) {
+	args := m.Called(c, seriesID, afterIndex)
+	return args.Get(0).([]domain.Task), args.Get(1).(domain.CustomError)
+}
+
+type MockTaskHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockTaskHistoryRepository) AddEntry(c context.Context, entry domain.TaskHistoryEntry) domain.CustomError {
+	args := m.Called(c, entry)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockTaskHistoryRepository) GetEntries(c context.Context, taskId string, cursor string, limit int64) (domain.TaskHistoryPage, domain.CustomError) {
+	args := m.Called(c, taskId, cursor, limit)
+	return args.Get(0).(domain.TaskHistoryPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockTaskHistoryRepository) GetDeletionEntry(c context.Context, taskId string) (domain.TaskHistoryEntry, domain.CustomError) {
+	args := m.Called(c, taskId)
+	return args.Get(0).(domain.TaskHistoryEntry), args.Get(1).(domain.CustomError)
+}
+
 type TaskUsecaseSuite struct {
 	suite.Suite
-	mockRepo  *MockTaskRepository
-	usecase   domain.TaskUsecase
+	mockRepo        *MockTaskRepository
+	mockUserRepo    *MockUserRepository
+	mockRoleRepo    *MockRoleRepository
+	mockHistoryRepo *MockTaskHistoryRepository
+	mockLabelRepo   *MockLabelRepository
+	mockProjectRepo *MockProjectRepository
+	mockSeriesRepo  *MockTaskSeriesRepository
+	mockPublisher   *MockTaskEventPublisher
+	mockTransactions *MockTransactionRunner
+	usecase         domain.TaskUsecase
+	admin        domain.AuthUser
+	user         domain.AuthUser
+	project      domain.Project
 }
 
 func (suite *TaskUsecaseSuite) SetupTest() {
 	suite.mockRepo = new(MockTaskRepository)
-	suite.usecase = usecases.NewTaskUsecase(suite.mockRepo)
+	suite.mockUserRepo = new(MockUserRepository)
+	suite.mockRoleRepo = new(MockRoleRepository)
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "user").Return(domain.Role{Name: "user", Permissions: domain.BuiltInPermissions("user")}, domain.CustomError{}).Maybe()
+	suite.mockHistoryRepo = new(MockTaskHistoryRepository)
+	suite.mockLabelRepo = new(MockLabelRepository)
+	suite.mockProjectRepo = new(MockProjectRepository)
+	suite.mockSeriesRepo = new(MockTaskSeriesRepository)
+	suite.mockPublisher = new(MockTaskEventPublisher)
+	suite.mockTransactions = new(MockTransactionRunner)
+	suite.mockHistoryRepo.On("AddEntry", mock.Anything, mock.Anything).Return(domain.CustomError{}).Maybe()
+	suite.mockPublisher.On("Publish", mock.Anything, mock.Anything).Return().Maybe()
+	suite.mockTransactions.On("RunInTransaction", mock.Anything).Return().Maybe()
+	suite.usecase = usecases.NewTaskUsecase(suite.mockRepo, suite.mockUserRepo, suite.mockRoleRepo, suite.mockHistoryRepo, suite.mockLabelRepo, suite.mockProjectRepo, suite.mockSeriesRepo, suite.mockPublisher, suite.mockTransactions, domain.DefaultStatusWorkflow, domain.SubtaskDeleteBlock)
+	suite.admin = domain.AuthUser{UserID: "admin-1", Username: "admin", Role: "admin"}
+	suite.user = domain.AuthUser{UserID: "user-1", Username: "user", Role: "user"}
+	suite.project = domain.Project{ID: "project-1", Members: []domain.ProjectMember{
+		{UserID: "user-1", Role: domain.ProjectRoleMember},
+		{UserID: "user-3", Role: domain.ProjectRoleViewer},
+	}}
+	suite.mockProjectRepo.On("GetProjectByID", mock.Anything, "project-1").Return(suite.project, domain.CustomError{}).Maybe()
+	suite.mockProjectRepo.On("GetProjects", mock.Anything, "user-1").Return([]domain.Project{suite.project}, domain.CustomError{}).Maybe()
 }
 
 // Test GetTasks
 func (suite *TaskUsecaseSuite) TestGetTasks() {
+	dueDate := time.Now()
+	mockTasks := []domain.Task{
+		{ID: "1", Title: "Task 1", Description: "First task", DueDate: &dueDate, Status: domain.StatusTodo},
+	}
+
+	expectedQuery := domain.TaskQuery{Status: "in_progress", Limit: domain.DefaultTaskPageSize}
+	suite.mockRepo.On("GetTasks", mock.Anything, expectedQuery).Return(domain.TaskPage{Tasks: mockTasks, Total: 1}, domain.CustomError{})
+
+	page, err := suite.usecase.GetTasks(context.TODO(), suite.admin, domain.TaskQuery{Status: "In Progress"})
+
+	suite.Empty(err.ErrCode)
+	suite.Equal(1, len(page.Tasks))
+	suite.Equal("Task 1", page.Tasks[0].Title)
+	suite.Equal(int64(1), page.Total)
+
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+// Test GetTasks for a regular user only returns the tasks of their projects, and owned or assigned tasks without a project
+func (suite *TaskUsecaseSuite) TestGetTasks_RegularUser() {
 	mockTasks := []domain.Task{
-		{ID: "1", Title: "Task 1", Description: "First task", DueDate: time.Now().Format(time.RFC3339), Status: "Pending"},
+		{ID: "1", Title: "Task 1", CreatedBy: suite.user.UserID},
 	}
 
-	suite.mockRepo.On("GetTasks", mock.Anything).Return(mockTasks, domain.CustomError{})
+	expectedQuery := domain.TaskQuery{Limit: 5, VisibleTo: suite.user.UserID, VisibleProjectIDs: []string{"project-1"}}
+	suite.mockRepo.On("GetTasks", mock.Anything, expectedQuery).Return(domain.TaskPage{Tasks: mockTasks, Total: 1}, domain.CustomError{})
 
-	tasks, err := suite.usecase.GetTasks(context.TODO())
+	page, err := suite.usecase.GetTasks(context.TODO(), suite.user, domain.TaskQuery{Limit: 5, VisibleTo: "someone-else"})
 
 	suite.Empty(err.ErrCode)
-	suite.Equal(1, len(tasks))
-	suite.Equal("Task 1", tasks[0].Title)
+	suite.Equal(1, len(page.Tasks))
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+// Test SearchTasks applies the caller's visibility and highlights the matches
+func (suite *TaskUsecaseSuite) TestSearchTasks() {
+	expectedQuery := domain.TaskQuery{Search: "deploy", Limit: domain.DefaultTaskPageSize, VisibleTo: suite.user.UserID, VisibleProjectIDs: []string{"project-1"}}
+	results := domain.TaskSearchPage{Results: []domain.TaskSearchResult{{Task: domain.Task{ID: "1", Title: "Deploy the API", Description: "Deploying needs a <review>"}, Score: 2}}, Total: 1}
+	suite.mockRepo.On("SearchTasks", mock.Anything, expectedQuery).Return(results, domain.CustomError{})
 
+	page, err := suite.usecase.SearchTasks(context.TODO(), suite.user, domain.TaskQuery{Search: " deploy ", SortBy: "title"})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal("<mark>Deploy</mark> the API", page.Results[0].Highlights.Title)
+	suite.Equal("<mark>Deploying</mark> needs a &lt;review&gt;", page.Results[0].Highlights.Description)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+// Test SearchTasks without search text
+func (suite *TaskUsecaseSuite) TestSearchTasks_MissingQuery() {
+	_, err := suite.usecase.SearchTasks(context.TODO(), suite.user, domain.TaskQuery{Search: "   "})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("q", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "SearchTasks", mock.Anything, mock.Anything)
+}
+
+// Test GetTasks with an unsupported sort field
+func (suite *TaskUsecaseSuite) TestGetTasks_InvalidSort() {
+	_, err := suite.usecase.GetTasks(context.TODO(), suite.admin, domain.TaskQuery{SortBy: "password"})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetTasks", mock.Anything, mock.Anything)
+}
+
+// Test GetTasks with a page size above the maximum
+func (suite *TaskUsecaseSuite) TestGetTasks_LimitTooLarge() {
+	_, err := suite.usecase.GetTasks(context.TODO(), suite.admin, domain.TaskQuery{Limit: domain.MaxTaskPageSize + 1})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+}
+
 // Test GetTaskByID
 func (suite *TaskUsecaseSuite) TestGetTaskByID() {
-	mockTask := domain.Task{ID: "1", Title: "Task 1", Description: "First task", DueDate: time.Now().Format(time.RFC3339), Status: "Pending"}
+	dueDate := time.Now()
+	mockTask := domain.Task{ID: "1", Title: "Task 1", Description: "First task", DueDate: &dueDate, Status: domain.StatusTodo}
+
+	mockTask.Checklist = []domain.ChecklistItem{{ID: "a", Text: "Step", Done: true}}
+	subtasks := []domain.Task{{ID: "2", Status: domain.StatusDone}, {ID: "3", Status: domain.StatusTodo}, {ID: "4", Status: domain.StatusReview}}
 
 	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(mockTask, domain.CustomError{})
+	suite.mockRepo.On("GetSubtasks", mock.Anything, []string{"1"}).Return(subtasks, domain.CustomError{})
 
-	task, err := suite.usecase.GetTaskByID(context.TODO(), "1")
+	task, err := suite.usecase.GetTaskByID(context.TODO(), suite.admin, "1")
 
 	suite.Empty(err.ErrMessage)
 	suite.Equal("Task 1", task.Title)
+	suite.Equal(50, *task.Progress)
 
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+// Test GetTaskByID for a task the user neither owns nor is assigned to
+func (suite *TaskUsecaseSuite) TestGetTaskByID_Forbidden() {
+	mockTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: "someone-else"}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(mockTask, domain.CustomError{})
+
+	_, err := suite.usecase.GetTaskByID(context.TODO(), suite.user, "1")
+
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+}
+
 // Test CreateTask
 func (suite *TaskUsecaseSuite) TestCreateTask() {
-	mockTask := domain.Task{ID: "1", Title: "Task 1", Description: "First task", DueDate: time.Now().Format(time.RFC3339), Status: "Pending"}
+	input := domain.TaskInput{Title: "Task 1", Description: "First task", DueDate: "2024-12-31T18:30:00+02:00", Status: "todo", ProjectID: "project-1"}
+	dueDate := time.Date(2024, 12, 31, 16, 30, 0, 0, time.UTC)
+	storedTask := domain.Task{ProjectID: "project-1", Title: "Task 1", Description: "First task", DueDate: &dueDate, Status: domain.StatusTodo, CreatedBy: suite.user.UserID, Version: 1}
 
-	suite.mockRepo.On("CreateTask", mock.Anything, mockTask).Return(domain.CustomError{})
+	suite.mockRepo.On("CreateTask", mock.Anything, storedTask).Return("1", domain.CustomError{})
 
-	err := suite.usecase.CreateTask(context.TODO(), mockTask)
+	task, err := suite.usecase.CreateTask(context.TODO(), suite.user, input)
 
 	suite.Empty(err.ErrMessage)
+	suite.Equal("1", task.ID)
+	suite.Equal(int64(1), task.Version)
 	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockHistoryRepo.AssertCalled(suite.T(), "AddEntry", mock.Anything, mock.MatchedBy(func(entry domain.TaskHistoryEntry) bool {
+		return entry.TaskID == "1" && entry.Action == domain.HistoryActionCreated && entry.ActorID == suite.user.UserID && len(entry.Changes) == 4
+	}))
+	suite.mockPublisher.AssertCalled(suite.T(), "Publish", mock.Anything, mock.MatchedBy(func(event domain.TaskEvent) bool {
+		return event.Type == domain.EventTaskCreated && event.Task.ID == "1" && event.ActorID == suite.user.UserID
+	}))
+}
+
+// Test CreateTask with a date-only due date
+func (suite *TaskUsecaseSuite) TestCreateTask_DateOnlyDueDate() {
+	input := domain.TaskInput{Title: "Task 1", DueDate: "2024-12-31", ProjectID: "project-1"}
+	dueDate := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	storedTask := domain.Task{ProjectID: "project-1", Title: "Task 1", DueDate: &dueDate, Status: domain.StatusTodo, CreatedBy: suite.user.UserID, Version: 1}
+
+	suite.mockRepo.On("CreateTask", mock.Anything, storedTask).Return("1", domain.CustomError{})
+
+	_, err := suite.usecase.CreateTask(context.TODO(), suite.user, input)
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+// Test CreateTask with an invalid due date
+func (suite *TaskUsecaseSuite) TestCreateTask_InvalidDueDate() {
+	input := domain.TaskInput{Title: "Task 1", DueDate: "next friday", ProjectID: "project-1"}
+
+	_, err := suite.usecase.CreateTask(context.TODO(), suite.user, input)
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("due_date", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateTask", mock.Anything, mock.Anything)
 }
 
 // Test CreateTask with Missing Title
 func (suite *TaskUsecaseSuite) TestCreateTask_MissingTitle() {
-	mockTask := domain.Task{ID: "1", Description: "First task", DueDate: time.Now().Format(time.RFC3339), Status: "Pending"}
+	input := domain.TaskInput{Description: "First task", DueDate: time.Now().Format(time.RFC3339), Status: "todo"}
 
-	err := suite.usecase.CreateTask(context.TODO(), mockTask)
+	_, err := suite.usecase.CreateTask(context.TODO(), suite.user, input)
 
 	suite.Equal(http.StatusBadRequest, err.ErrCode)
 	suite.Equal("title is required", err.ErrMessage)
 }
 
+// Test CreateTask without a project
+func (suite *TaskUsecaseSuite) TestCreateTask_MissingProject() {
+	_, err := suite.usecase.CreateTask(context.TODO(), suite.user, domain.TaskInput{Title: "Task 1"})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("project_id", err.Field)
+}
+
+// Test CreateTask refuses project viewers and unknown projects
+func (suite *TaskUsecaseSuite) TestCreateTask_ProjectAccess() {
+	suite.mockProjectRepo.On("GetProjectByID", mock.Anything, "project-2").Return(domain.Project{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Project not found"})
+	viewer := domain.AuthUser{UserID: "user-3", Role: "user"}
+
+	_, err := suite.usecase.CreateTask(context.TODO(), viewer, domain.TaskInput{Title: "Task 1", ProjectID: "project-1"})
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+
+	_, err = suite.usecase.CreateTask(context.TODO(), suite.user, domain.TaskInput{Title: "Task 1", ProjectID: "project-2"})
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("project_id", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateTask", mock.Anything, mock.Anything)
+}
+
+// Test CreateTask only assigns users who can change the project's tasks
+func (suite *TaskUsecaseSuite) TestCreateTask_AssigneeNotMember() {
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-3").Return(domain.User{ID: "user-3", Role: "user"}, domain.CustomError{})
+
+	_, err := suite.usecase.CreateTask(context.TODO(), suite.user, domain.TaskInput{Title: "Task 1", ProjectID: "project-1", AssigneeIDs: []string{"user-3"}})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("assignee_ids", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateTask", mock.Anything, mock.Anything)
+}
+
+// Test CreateTask assigns users whose role manages every project to any project's tasks
+func (suite *TaskUsecaseSuite) TestCreateTask_AssigneeManagesAllProjects() {
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-4").Return(domain.User{ID: "user-4", Role: "auditor"}, domain.CustomError{})
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "auditor").Return(domain.Role{Name: "auditor", Permissions: []domain.Permission{domain.PermissionProjectManageAll}}, domain.CustomError{})
+	suite.mockRepo.On("CreateTask", mock.Anything, mock.Anything).Return("1", domain.CustomError{})
+
+	task, err := suite.usecase.CreateTask(context.TODO(), suite.user, domain.TaskInput{Title: "Task 1", ProjectID: "project-1", AssigneeIDs: []string{"user-4"}})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal([]string{"user-4"}, task.AssigneeIDs)
+}
+
+// Test project viewers can read a task but not change it
+func (suite *TaskUsecaseSuite) TestProjectViewer() {
+	viewer := domain.AuthUser{UserID: "user-3", Role: "user"}
+	task := domain.Task{ID: "1", ProjectID: "project-1", Title: "Task 1", CreatedBy: suite.user.UserID, Version: 1}
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(task, domain.CustomError{})
+	suite.mockRepo.On("GetSubtasks", mock.Anything, []string{"1"}).Return([]domain.Task{}, domain.CustomError{})
+
+	_, err := suite.usecase.GetTaskByID(context.TODO(), viewer, "1")
+	suite.Empty(err.ErrMessage)
+
+	_, err = suite.usecase.UpdateTaskByID(context.TODO(), viewer, "1", domain.TaskInput{Title: "Renamed"}, nil, domain.ScopeThisOccurrence)
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+
+	err = suite.usecase.TransitionTask(context.TODO(), viewer, "1", "in_progress")
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+
+	_, err = suite.usecase.GetTaskByID(context.TODO(), domain.AuthUser{UserID: "user-9", Role: "user"}, "1")
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateTaskByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test UpdateTaskByID does not move a task to another project
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_ProjectChange() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", ProjectID: "project-1", Title: "Task 1", CreatedBy: suite.user.UserID}, domain.CustomError{})
+
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Title: "Task 1", ProjectID: "project-2"}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("project_id", err.Field)
+}
+
 // Test UpdateTaskByID
 func (suite *TaskUsecaseSuite) TestUpdateTaskByID() {
-	mockTask := domain.Task{ID: "1", Title: "Updated Task", Description: "Updated Description", Status: "Completed"}
+	input := domain.TaskInput{Title: "Updated Task", Description: "Updated Description"}
+	mockTask := domain.Task{ID: "1", Title: "Updated Task", Description: "Updated Description", Version: 3}
+	existingTask := domain.Task{ID: "1", Title: "Task 1", Status: domain.StatusTodo, AssigneeIDs: []string{suite.user.UserID}, Version: 3}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockRepo.On("UpdateTaskByID", mock.Anything, mockTask, (*domain.StatusTransition)(nil)).Return(domain.CustomError{})
+
+	task, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", input, nil, domain.ScopeThisOccurrence)
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal(int64(4), task.Version)
+	suite.Equal("Updated Task", task.Title)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockRepo.AssertNotCalled(suite.T(), "TransitionTaskStatus", mock.Anything, mock.Anything, mock.Anything)
+}
 
-	suite.mockRepo.On("UpdateTaskByID", mock.Anything, mockTask).Return(domain.CustomError{})
+// Test UpdateTaskByID clears the fields that are left out
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_Replaces() {
+	dueDate := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	existingTask := domain.Task{ID: "1", Title: "Task 1", Description: "Old", DueDate: &dueDate, Status: domain.StatusTodo, CreatedBy: suite.user.UserID, Version: 1}
 
-	err := suite.usecase.UpdateTaskByID(context.TODO(), "1", mockTask)
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockRepo.On("UpdateTaskByID", mock.Anything, domain.Task{ID: "1", Title: "Task 1", Version: 1}, (*domain.StatusTransition)(nil)).Return(domain.CustomError{})
+
+	task, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Title: "Task 1"}, nil, domain.ScopeThisOccurrence)
 
 	suite.Empty(err.ErrMessage)
+	suite.Empty(task.Description)
+	suite.Nil(task.DueDate)
+	suite.Equal(domain.StatusTodo, task.Status)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+// Test UpdateTaskByID without a title
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_MissingTitle() {
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Description: "No title"}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("title", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetTaskByID", mock.Anything, mock.Anything)
+}
+
+// Test PatchTaskByID only touches the patched fields and clears the ones set to null
+func (suite *TaskUsecaseSuite) TestPatchTaskByID() {
+	dueDate := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	existingTask := domain.Task{ID: "1", Title: "Task 1", Description: "Keep me", DueDate: &dueDate, Status: domain.StatusTodo, CreatedBy: suite.user.UserID, Version: 2}
+	patch := domain.TaskPatch{"due_date": json.RawMessage(`null`), "status": json.RawMessage(`"in_progress"`)}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockRepo.On("UpdateTaskByID", mock.Anything, domain.Task{ID: "1", Title: "Task 1", Description: "Keep me", Version: 2}, mock.MatchedBy(func(transition *domain.StatusTransition) bool {
+		return transition != nil && transition.To == domain.StatusInProgress
+	})).Return(domain.CustomError{})
+
+	task, err := suite.usecase.PatchTaskByID(context.TODO(), suite.user, "1", patch, nil, domain.ScopeThisOccurrence)
+
+	suite.Empty(err.ErrMessage)
+	suite.Nil(task.DueDate)
+	suite.Equal(domain.StatusInProgress, task.Status)
+	// the fields and the status are written in one update, which moves the version once
+	suite.Equal(int64(3), task.Version)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockHistoryRepo.AssertCalled(suite.T(), "AddEntry", mock.Anything, mock.MatchedBy(func(entry domain.TaskHistoryEntry) bool {
+		return len(entry.Changes) == 2 && entry.Changes[0] == domain.FieldChange{Field: "due_date", Before: dueDate, After: nil}
+	}))
+}
+
+// Test PatchTaskByID rejects clearing the title
+func (suite *TaskUsecaseSuite) TestPatchTaskByID_ClearTitle() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: suite.user.UserID}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+
+	_, err := suite.usecase.PatchTaskByID(context.TODO(), suite.user, "1", domain.TaskPatch{"title": json.RawMessage(`null`)}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("title", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateTaskByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test UpdateTaskByID with a matching If-Match version
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_MatchingVersion() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: suite.user.UserID, Version: 2}
+	expected := int64(2)
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockRepo.On("UpdateTaskByID", mock.Anything, domain.Task{ID: "1", Title: "Renamed", Version: 2}, (*domain.StatusTransition)(nil)).Return(domain.CustomError{})
+
+	task, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Title: "Renamed"}, &expected, domain.ScopeThisOccurrence)
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal(int64(3), task.Version)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+// Test UpdateTaskByID with a stale If-Match version
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_StaleVersion() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: suite.user.UserID, Version: 5}
+	expected := int64(4)
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Title: "Renamed"}, &expected, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusPreconditionFailed, err.ErrCode)
+	suite.Equal(int64(5), err.Details["current_version"])
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateTaskByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test UpdateTaskByID when another write lands between the read and the conditional update
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_ConcurrentWrite() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: suite.user.UserID, Version: 5}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockRepo.On("UpdateTaskByID", mock.Anything, domain.Task{ID: "1", Title: "Renamed", Version: 5}, (*domain.StatusTransition)(nil)).Return(domain.CustomError{ErrCode: http.StatusPreconditionFailed, ErrMessage: "Task was modified by someone else, reload the task and try again"})
+
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Title: "Renamed"}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusPreconditionFailed, err.ErrCode)
+	suite.mockHistoryRepo.AssertNotCalled(suite.T(), "AddEntry", mock.Anything, mock.Anything)
+}
+
+// Test UpdateTaskByID with a status change records the transition
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_StatusChange() {
+	input := domain.TaskInput{Title: "Updated Task", Status: "in_progress"}
+	existingTask := domain.Task{ID: "1", Title: "Task 1", Status: domain.StatusTodo, CreatedBy: suite.user.UserID}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockRepo.On("UpdateTaskByID", mock.Anything, domain.Task{ID: "1", Title: "Updated Task"}, mock.MatchedBy(func(transition *domain.StatusTransition) bool {
+		return transition != nil && transition.From == domain.StatusTodo && transition.To == domain.StatusInProgress && transition.By == suite.user.UserID
+	})).Return(domain.CustomError{})
+
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", input, nil, domain.ScopeThisOccurrence)
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockHistoryRepo.AssertCalled(suite.T(), "AddEntry", mock.Anything, domain.TaskHistoryEntry{
+		TaskID:        "1",
+		Action:        domain.HistoryActionUpdated,
+		ActorID:       suite.user.UserID,
+		ActorUsername: suite.user.Username,
+		At:            suite.mockHistoryRepo.Calls[0].Arguments[1].(domain.TaskHistoryEntry).At,
+		Changes: []domain.FieldChange{
+			{Field: "title", Before: "Task 1", After: "Updated Task"},
+			{Field: "status", Before: "todo", After: "in_progress"},
+		},
+	})
+}
+
+// Test a status change is not applied on its own when the update loses a race
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_StatusChangeConflict() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", Status: domain.StatusTodo, CreatedBy: suite.user.UserID, Version: 2}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockRepo.On("UpdateTaskByID", mock.Anything, mock.Anything, mock.Anything).Return(domain.CustomError{ErrCode: http.StatusPreconditionFailed, ErrMessage: "Task was modified by someone else, reload the task and try again"})
+
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Title: "Renamed", Status: "in_progress"}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusPreconditionFailed, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "TransitionTaskStatus", mock.Anything, mock.Anything, mock.Anything)
+	suite.mockHistoryRepo.AssertNotCalled(suite.T(), "AddEntry", mock.Anything, mock.Anything)
+}
+
+// Test UpdateTaskByID with a status change the workflow does not allow
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_IllegalTransition() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", Status: domain.StatusTodo, CreatedBy: suite.user.UserID}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Title: "Task 1", Status: "done"}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusConflict, err.ErrCode)
+	suite.Equal([]string{"in_progress"}, err.Details["allowed_statuses"])
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateTaskByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test UpdateTaskByID with an unknown status
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_UnknownStatus() {
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Title: "Task 1", Status: "completed"}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("status", err.Field)
+}
+
+// Test TransitionTask
+func (suite *TaskUsecaseSuite) TestTransitionTask() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", Status: domain.StatusReview, AssigneeIDs: []string{suite.user.UserID}}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockRepo.On("TransitionTaskStatus", mock.Anything, "1", mock.MatchedBy(func(transition domain.StatusTransition) bool {
+		return transition.From == domain.StatusReview && transition.To == domain.StatusDone && transition.By == suite.user.UserID && !transition.At.IsZero()
+	})).Return(domain.CustomError{})
+
+	err := suite.usecase.TransitionTask(context.TODO(), suite.user, "1", "Done")
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+	for _, eventType := range []string{domain.EventTaskUpdated, domain.EventTaskStatusChanged} {
+		eventType := eventType
+		suite.mockPublisher.AssertCalled(suite.T(), "Publish", mock.Anything, mock.MatchedBy(func(event domain.TaskEvent) bool {
+			return event.Type == eventType && event.Task.Status == domain.StatusDone && len(event.Changes) == 1
+		}))
+	}
+}
+
+// Test TransitionTask brings a task whose status is not part of the workflow back into it
+func (suite *TaskUsecaseSuite) TestTransitionTask_UnknownStatus() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", Status: "Pending", CreatedBy: suite.user.UserID}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockRepo.On("TransitionTaskStatus", mock.Anything, "1", mock.MatchedBy(func(transition domain.StatusTransition) bool {
+		return transition.From == "Pending" && transition.To == domain.StatusTodo
+	})).Return(domain.CustomError{})
+
+	err := suite.usecase.TransitionTask(context.TODO(), suite.user, "1", "todo")
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+// Test TransitionTask with a transition the workflow does not allow
+func (suite *TaskUsecaseSuite) TestTransitionTask_Illegal() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", Status: domain.StatusInProgress, CreatedBy: suite.user.UserID}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+
+	err := suite.usecase.TransitionTask(context.TODO(), suite.user, "1", "done")
+
+	suite.Equal(http.StatusConflict, err.ErrCode)
+	suite.Equal([]string{"review", "todo"}, err.Details["allowed_statuses"])
+}
+
+// Test UpdateTaskByID refuses assignees, which have their own endpoints
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_AssigneeIDs() {
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Title: "Task 1", AssigneeIDs: []string{"user-2"}}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("assignee_ids", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateTaskByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test UpdateTaskByID with an invalid due date
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_InvalidDueDate() {
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Title: "Task 1", DueDate: "31/12/2024"}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("due_date", err.Field)
+}
+
+// Test UpdateTaskByID by a user who neither owns nor is assigned to the task
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_Forbidden() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: "someone-else"}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.user, "1", domain.TaskInput{Title: "Hijacked"}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateTaskByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test GetOverdueTasks only asks for open tasks due before now
+func (suite *TaskUsecaseSuite) TestGetOverdueTasks() {
+	before := time.Now()
+	suite.mockRepo.On("GetTasks", mock.Anything, mock.MatchedBy(func(query domain.TaskQuery) bool {
+		return query.OpenOnly && query.DueFrom == nil && query.DueTo != nil && !query.DueTo.Before(before) && query.SortBy == "due_date"
+	})).Return(domain.TaskPage{}, domain.CustomError{})
+
+	_, err := suite.usecase.GetOverdueTasks(context.TODO(), suite.admin, domain.TaskQuery{})
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+// Test GetUpcomingTasks asks for open tasks due within the window
+func (suite *TaskUsecaseSuite) TestGetUpcomingTasks() {
+	suite.mockRepo.On("GetTasks", mock.Anything, mock.MatchedBy(func(query domain.TaskQuery) bool {
+		return query.OpenOnly && query.DueFrom != nil && query.DueTo != nil && query.DueTo.Sub(*query.DueFrom) == 48*time.Hour
+	})).Return(domain.TaskPage{}, domain.CustomError{})
+
+	_, err := suite.usecase.GetUpcomingTasks(context.TODO(), suite.admin, 48*time.Hour, domain.TaskQuery{})
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+// Test GetUpcomingTasks with a non-positive window
+func (suite *TaskUsecaseSuite) TestGetUpcomingTasks_InvalidWindow() {
+	_, err := suite.usecase.GetUpcomingTasks(context.TODO(), suite.admin, -time.Hour, domain.TaskQuery{})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+}
+
 // Test DeleteTaskByID
 func (suite *TaskUsecaseSuite) TestDeleteTaskByID() {
-	suite.mockRepo.On("DeleteTaskByID", mock.Anything, "1").Return(domain.CustomError{})
+	existingTask := domain.Task{ID: "1", Title: "Task 1", Status: domain.StatusTodo, CreatedBy: suite.user.UserID}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockRepo.On("GetSubtasks", mock.Anything, []string{"1"}).Return([]domain.Task{}, domain.CustomError{})
+	suite.mockRepo.On("DeleteTaskByID", mock.Anything, "1", suite.admin.UserID, mock.AnythingOfType("time.Time")).Return(domain.CustomError{})
+
+	err := suite.usecase.DeleteTaskByID(context.TODO(), suite.admin, "1")
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockHistoryRepo.AssertCalled(suite.T(), "AddEntry", mock.Anything, mock.MatchedBy(func(entry domain.TaskHistoryEntry) bool {
+		return entry.Action == domain.HistoryActionDeleted && entry.ActorID == suite.admin.UserID && entry.Snapshot != nil && entry.Snapshot.Title == "Task 1"
+	}))
+}
+
+// Test DeleteTaskByID by a user whose role can delete tasks but who is not a member of the task's project
+func (suite *TaskUsecaseSuite) TestDeleteTaskByID_NotProjectMember() {
+	outsider := domain.AuthUser{UserID: "user-2", Role: "cleaner", Permissions: []domain.Permission{domain.PermissionTaskDelete}}
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", Title: "Task 1", ProjectID: "project-1"}, domain.CustomError{})
+
+	err := suite.usecase.DeleteTaskByID(context.TODO(), outsider, "1")
+
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "DeleteTaskByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test DeleteTaskByID refuses a task with subtasks under the block policy
+func (suite *TaskUsecaseSuite) TestDeleteTaskByID_HasSubtasks() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", Title: "Parent"}, domain.CustomError{})
+	suite.mockRepo.On("GetSubtasks", mock.Anything, []string{"1"}).Return([]domain.Task{{ID: "2", ParentID: "1"}}, domain.CustomError{})
+	suite.mockRepo.On("GetSubtasks", mock.Anything, []string{"2"}).Return([]domain.Task{}, domain.CustomError{})
+
+	err := suite.usecase.DeleteTaskByID(context.TODO(), suite.admin, "1")
+
+	suite.Equal(http.StatusConflict, err.ErrCode)
+	suite.Equal(1, err.Details["subtask_count"])
+	suite.mockRepo.AssertNotCalled(suite.T(), "DeleteTaskByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test DeleteTaskByID deletes every subtask under the cascade policy
+func (suite *TaskUsecaseSuite) TestDeleteTaskByID_Cascade() {
+	suite.usecase = usecases.NewTaskUsecase(suite.mockRepo, suite.mockUserRepo, suite.mockRoleRepo, suite.mockHistoryRepo, suite.mockLabelRepo, suite.mockProjectRepo, suite.mockSeriesRepo, suite.mockPublisher, suite.mockTransactions, domain.DefaultStatusWorkflow, domain.SubtaskDeleteCascade)
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", Title: "Parent"}, domain.CustomError{})
+	suite.mockRepo.On("GetSubtasks", mock.Anything, []string{"1"}).Return([]domain.Task{{ID: "2", ParentID: "1"}}, domain.CustomError{})
+	suite.mockRepo.On("GetSubtasks", mock.Anything, []string{"2"}).Return([]domain.Task{{ID: "3", ParentID: "2"}}, domain.CustomError{})
+	suite.mockRepo.On("GetSubtasks", mock.Anything, []string{"3"}).Return([]domain.Task{}, domain.CustomError{})
+	for _, id := range []string{"1", "2", "3"} {
+		suite.mockRepo.On("DeleteTaskByID", mock.Anything, id, suite.admin.UserID, mock.AnythingOfType("time.Time")).Return(domain.CustomError{}).Once()
+	}
+
+	err := suite.usecase.DeleteTaskByID(context.TODO(), suite.admin, "1")
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockHistoryRepo.AssertNumberOfCalls(suite.T(), "AddEntry", 3)
+}
+
+// Test ReleaseUserTasks hands the tasks and assignments of a user to another user
+func (suite *TaskUsecaseSuite) TestReleaseUserTasks_Transfer() {
+	suite.mockRepo.On("TransferTasks", mock.Anything, "gone", "heir").Return(int64(3), domain.CustomError{})
+	suite.mockRepo.On("ReplaceAssignee", mock.Anything, "gone", "heir").Return(int64(2), domain.CustomError{})
+
+	report, err := suite.usecase.ReleaseUserTasks(context.TODO(), suite.admin, "gone", domain.UserDeletion{Tasks: domain.UserTasksTransfer, TransferTo: "heir"})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal(domain.UserDeletionReport{Tasks: domain.UserTasksTransfer, TransferTo: "heir", Transferred: 3, Unassigned: 2}, report)
+	suite.mockRepo.AssertNotCalled(suite.T(), "DeleteTaskByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test ReleaseUserTasks trashes the tasks a user created with their subtasks, once each
+func (suite *TaskUsecaseSuite) TestReleaseUserTasks_Delete() {
+	// task 2 is both created by the user and a subtask of task 1
+	suite.mockRepo.On("GetTasksCreatedBy", mock.Anything, "gone").Return([]domain.Task{{ID: "1"}, {ID: "2", ParentID: "1"}}, domain.CustomError{})
+	suite.mockRepo.On("GetSubtasks", mock.Anything, []string{"1"}).Return([]domain.Task{{ID: "2", ParentID: "1"}, {ID: "3", ParentID: "1"}}, domain.CustomError{})
+	suite.mockRepo.On("GetSubtasks", mock.Anything, []string{"2", "3"}).Return([]domain.Task{}, domain.CustomError{})
+	for _, id := range []string{"1", "2", "3"} {
+		suite.mockRepo.On("DeleteTaskByID", mock.Anything, id, suite.admin.UserID, mock.AnythingOfType("time.Time")).Return(domain.CustomError{}).Once()
+	}
+	suite.mockRepo.On("RemoveAssigneeFromTasks", mock.Anything, "gone").Return(int64(4), domain.CustomError{})
+
+	report, err := suite.usecase.ReleaseUserTasks(context.TODO(), suite.admin, "gone", domain.UserDeletion{Tasks: domain.UserTasksDelete})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal(int64(3), report.Deleted)
+	suite.Equal(int64(4), report.Unassigned)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockHistoryRepo.AssertNumberOfCalls(suite.T(), "AddEntry", 3)
+}
+
+// Test CreateTask as a subtask
+func (suite *TaskUsecaseSuite) TestCreateTask_Subtask() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "p").Return(domain.Task{ID: "p", ProjectID: "project-1", CreatedBy: suite.user.UserID}, domain.CustomError{})
+	suite.mockRepo.On("CreateTask", mock.Anything, mock.MatchedBy(func(task domain.Task) bool {
+		return task.ParentID == "p"
+	})).Return("1", domain.CustomError{})
+
+	task, err := suite.usecase.CreateTask(context.TODO(), suite.user, domain.TaskInput{Title: "Child", ParentID: "p", ProjectID: "project-1"})
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal("p", task.ParentID)
+}
+
+// Test CreateTask under a parent in another project
+func (suite *TaskUsecaseSuite) TestCreateTask_ParentInOtherProject() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "p").Return(domain.Task{ID: "p", CreatedBy: suite.user.UserID}, domain.CustomError{})
+
+	_, err := suite.usecase.CreateTask(context.TODO(), suite.user, domain.TaskInput{Title: "Child", ParentID: "p", ProjectID: "project-1"})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("parent task belongs to another project", err.ErrMessage)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateTask", mock.Anything, mock.Anything)
+}
+
+// Test CreateTask under a parent that does not exist
+func (suite *TaskUsecaseSuite) TestCreateTask_ParentNotFound() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "p").Return(domain.Task{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"})
+
+	_, err := suite.usecase.CreateTask(context.TODO(), suite.user, domain.TaskInput{Title: "Child", ParentID: "p", ProjectID: "project-1"})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("parent_id", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateTask", mock.Anything, mock.Anything)
+}
+
+// Test CreateTask under a parent that is already at the deepest level
+func (suite *TaskUsecaseSuite) TestCreateTask_TooDeep() {
+	// t5 is nested under t4, t3, t2 and t1
+	for level := 1; level <= domain.MaxTaskDepth; level++ {
+		task := domain.Task{ID: fmt.Sprintf("t%d", level), ProjectID: "project-1"}
+		if level > 1 {
+			task.ParentID = fmt.Sprintf("t%d", level-1)
+		}
+		suite.mockRepo.On("GetTaskByID", mock.Anything, task.ID).Return(task, domain.CustomError{})
+	}
+
+	_, err := suite.usecase.CreateTask(context.TODO(), suite.admin, domain.TaskInput{Title: "Too deep", ParentID: fmt.Sprintf("t%d", domain.MaxTaskDepth), ProjectID: "project-1"})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("parent_id", err.Field)
+}
+
+// Test UpdateTaskByID refuses to move a task under one of its own subtasks
+func (suite *TaskUsecaseSuite) TestUpdateTaskByID_ParentCycle() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", Title: "Top"}, domain.CustomError{})
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "2").Return(domain.Task{ID: "2", ParentID: "1"}, domain.CustomError{})
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "3").Return(domain.Task{ID: "3", ParentID: "2"}, domain.CustomError{})
+
+	_, err := suite.usecase.UpdateTaskByID(context.TODO(), suite.admin, "1", domain.TaskInput{Title: "Top", ParentID: "3"}, nil, domain.ScopeThisOccurrence)
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("parent_id", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateTaskByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test GetSubtasks lists the children of a visible task
+func (suite *TaskUsecaseSuite) TestGetSubtasks() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", CreatedBy: suite.user.UserID}, domain.CustomError{})
+	suite.mockRepo.On("GetTasks", mock.Anything, mock.MatchedBy(func(query domain.TaskQuery) bool {
+		return query.ParentID == "1" && !query.Deleted && query.VisibleTo == suite.user.UserID
+	})).Return(domain.TaskPage{Tasks: []domain.Task{{ID: "2", ParentID: "1"}}, Total: 1}, domain.CustomError{})
+
+	page, err := suite.usecase.GetSubtasks(context.TODO(), suite.user, "1", domain.TaskQuery{})
+
+	suite.Empty(err.ErrMessage)
+	suite.Len(page.Tasks, 1)
+}
+
+// Test AddChecklistItem
+func (suite *TaskUsecaseSuite) TestAddChecklistItem() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", CreatedBy: suite.user.UserID}, domain.CustomError{})
+	suite.mockRepo.On("AddChecklistItem", mock.Anything, "1", domain.ChecklistItem{Text: "Write tests"}).Return(domain.ChecklistItem{ID: "a", Text: "Write tests"}, domain.CustomError{})
+
+	item, err := suite.usecase.AddChecklistItem(context.TODO(), suite.user, "1", "  Write tests ")
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal("a", item.ID)
+	suite.mockHistoryRepo.AssertCalled(suite.T(), "AddEntry", mock.Anything, mock.MatchedBy(func(entry domain.TaskHistoryEntry) bool {
+		return len(entry.Changes) == 1 && entry.Changes[0].Field == "checklist"
+	}))
+}
+
+// Test UpdateChecklistItem checks an item off
+func (suite *TaskUsecaseSuite) TestUpdateChecklistItem() {
+	task := domain.Task{ID: "1", CreatedBy: suite.user.UserID, Checklist: []domain.ChecklistItem{{ID: "a", Text: "Write tests"}}}
+	done := true
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(task, domain.CustomError{})
+	suite.mockRepo.On("UpdateChecklistItem", mock.Anything, "1", domain.ChecklistItem{ID: "a", Text: "Write tests", Done: true}).Return(domain.CustomError{})
+
+	item, err := suite.usecase.UpdateChecklistItem(context.TODO(), suite.user, "1", "a", domain.ChecklistItemUpdate{Done: &done})
+
+	suite.Empty(err.ErrMessage)
+	suite.True(item.Done)
+	suite.False(task.Checklist[0].Done)
+}
+
+// Test RemoveChecklistItem with an unknown item
+func (suite *TaskUsecaseSuite) TestRemoveChecklistItem_NotFound() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", CreatedBy: suite.user.UserID}, domain.CustomError{})
+
+	err := suite.usecase.RemoveChecklistItem(context.TODO(), suite.user, "1", "missing")
+
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "RemoveChecklistItem", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test TransitionTask refuses to start a task while a blocker is open
+func (suite *TaskUsecaseSuite) TestTransitionTask_Blocked() {
+	task := domain.Task{ID: "1", Status: domain.StatusTodo, CreatedBy: suite.user.UserID, BlockedBy: []string{"2", "3"}}
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(task, domain.CustomError{})
+	suite.mockRepo.On("GetTasksByIDs", mock.Anything, []string{"2", "3"}).Return([]domain.Task{{ID: "2", Status: domain.StatusDone}, {ID: "3", Status: domain.StatusReview}}, domain.CustomError{})
+
+	err := suite.usecase.TransitionTask(context.TODO(), suite.user, "1", "in_progress")
+
+	suite.Equal(http.StatusConflict, err.ErrCode)
+	suite.Equal([]string{"3"}, err.Details["open_blockers"])
+	suite.mockRepo.AssertNotCalled(suite.T(), "TransitionTaskStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test TransitionTask once every blocker is done
+func (suite *TaskUsecaseSuite) TestTransitionTask_BlockersDone() {
+	task := domain.Task{ID: "1", Status: domain.StatusTodo, CreatedBy: suite.user.UserID, BlockedBy: []string{"2"}}
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(task, domain.CustomError{})
+	suite.mockRepo.On("GetTasksByIDs", mock.Anything, []string{"2"}).Return([]domain.Task{{ID: "2", Status: domain.StatusDone}}, domain.CustomError{})
+	suite.mockRepo.On("TransitionTaskStatus", mock.Anything, "1", mock.Anything).Return(domain.CustomError{})
+
+	err := suite.usecase.TransitionTask(context.TODO(), suite.user, "1", "in_progress")
+
+	suite.Empty(err.ErrMessage)
+}
+
+// Test AddDependency
+func (suite *TaskUsecaseSuite) TestAddDependency() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", CreatedBy: suite.user.UserID}, domain.CustomError{})
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "2").Return(domain.Task{ID: "2", CreatedBy: suite.user.UserID, BlockedBy: []string{"3"}}, domain.CustomError{})
+	suite.mockRepo.On("GetTasksByIDs", mock.Anything, []string{"3"}).Return([]domain.Task{{ID: "3"}}, domain.CustomError{})
+	suite.mockRepo.On("AddDependency", mock.Anything, "1", "2").Return(domain.CustomError{})
+
+	err := suite.usecase.AddDependency(context.TODO(), suite.user, "1", "2")
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+// Test AddDependency refuses a link that closes a cycle
+func (suite *TaskUsecaseSuite) TestAddDependency_Cycle() {
+	// 3 is blocked by 2, which is blocked by 1; making 1 wait on 3 closes the loop
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1"}, domain.CustomError{})
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "3").Return(domain.Task{ID: "3", BlockedBy: []string{"2"}}, domain.CustomError{})
+	suite.mockRepo.On("GetTasksByIDs", mock.Anything, []string{"2"}).Return([]domain.Task{{ID: "2", BlockedBy: []string{"1"}}}, domain.CustomError{})
+
+	err := suite.usecase.AddDependency(context.TODO(), suite.admin, "1", "3")
+
+	suite.Equal(http.StatusConflict, err.ErrCode)
+	suite.Equal("blocker_id", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "AddDependency", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test GetDependencies walks both directions of the graph
+func (suite *TaskUsecaseSuite) TestGetDependencies() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "2").Return(domain.Task{ID: "2", BlockedBy: []string{"1"}}, domain.CustomError{})
+	suite.mockRepo.On("GetTasksByIDs", mock.Anything, []string{"1"}).Return([]domain.Task{{ID: "1", Title: "Upstream", CreatedBy: "someone-else"}}, domain.CustomError{})
+	suite.mockRepo.On("GetTasksByIDs", mock.Anything, []string{}).Return([]domain.Task{}, domain.CustomError{})
+	suite.mockRepo.On("GetDependents", mock.Anything, []string{"2"}).Return([]domain.Task{{ID: "3", Title: "Downstream", BlockedBy: []string{"2"}}}, domain.CustomError{})
+	suite.mockRepo.On("GetDependents", mock.Anything, []string{"3"}).Return([]domain.Task{}, domain.CustomError{})
+
+	graph, err := suite.usecase.GetDependencies(context.TODO(), suite.admin, "2")
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal([]domain.DependencyNode{{ID: "1", Title: "Upstream", Depth: 1}}, graph.Upstream)
+	suite.Equal([]domain.DependencyNode{{ID: "3", Title: "Downstream", Depth: 1}}, graph.Downstream)
+	suite.ElementsMatch([]domain.DependencyEdge{{BlockerID: "1", BlockedID: "2"}, {BlockerID: "2", BlockedID: "3"}}, graph.Edges)
+}
+
+// Test GetTasks rejects an unknown label match mode
+func (suite *TaskUsecaseSuite) TestGetTasks_InvalidLabelMatch() {
+	_, err := suite.usecase.GetTasks(context.TODO(), suite.admin, domain.TaskQuery{LabelIDs: []string{"label-1"}, LabelMatch: "some"})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("label_match", err.Field)
+}
+
+// Test AddLabels puts known labels on a task and records the change
+func (suite *TaskUsecaseSuite) TestAddLabels() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", CreatedBy: suite.user.UserID, LabelIDs: []string{"label-1"}}, domain.CustomError{})
+	suite.mockLabelRepo.On("GetLabelsByIDs", mock.Anything, []string{"label-1", "label-2"}).Return([]domain.Label{{ID: "label-1"}, {ID: "label-2"}}, domain.CustomError{})
+	suite.mockRepo.On("AddLabels", mock.Anything, "1", []string{"label-1", "label-2"}).Return(domain.CustomError{})
+
+	err := suite.usecase.AddLabels(context.TODO(), suite.user, "1", []string{"label-1", "label-2", "label-1"})
+
+	suite.Empty(err.ErrMessage)
+	suite.mockHistoryRepo.AssertCalled(suite.T(), "AddEntry", mock.Anything, mock.MatchedBy(func(entry domain.TaskHistoryEntry) bool {
+		return len(entry.Changes) == 1 && entry.Changes[0].Field == "label_ids"
+	}))
+}
+
+// Test AddLabels rejects a label that does not exist
+func (suite *TaskUsecaseSuite) TestAddLabels_UnknownLabel() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", CreatedBy: suite.user.UserID}, domain.CustomError{})
+	suite.mockLabelRepo.On("GetLabelsByIDs", mock.Anything, []string{"label-1", "label-9"}).Return([]domain.Label{{ID: "label-1"}}, domain.CustomError{})
+
+	err := suite.usecase.AddLabels(context.TODO(), suite.user, "1", []string{"label-1", "label-9"})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("Label label-9 not found", err.ErrMessage)
+	suite.mockRepo.AssertNotCalled(suite.T(), "AddLabels", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test RemoveLabel of a label the task does not carry
+func (suite *TaskUsecaseSuite) TestRemoveLabel_NotOnTask() {
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{ID: "1", CreatedBy: suite.user.UserID}, domain.CustomError{})
 
-	err := suite.usecase.DeleteTaskByID(context.TODO(), "1")
+	err := suite.usecase.RemoveLabel(context.TODO(), suite.user, "1", "label-1")
+
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+// Test GetTrash asks for deleted tasks visible to the caller
+func (suite *TaskUsecaseSuite) TestGetTrash() {
+	suite.mockRepo.On("GetTasks", mock.Anything, mock.MatchedBy(func(query domain.TaskQuery) bool {
+		return query.Deleted && query.VisibleTo == suite.user.UserID && query.Limit == domain.DefaultTaskPageSize
+	})).Return(domain.TaskPage{}, domain.CustomError{})
+
+	_, err := suite.usecase.GetTrash(context.TODO(), suite.user, domain.TaskQuery{})
 
 	suite.Empty(err.ErrMessage)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+// Test RestoreTaskByID by the task's creator
+func (suite *TaskUsecaseSuite) TestRestoreTaskByID() {
+	deletedAt := time.Now()
+	deletedTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: suite.user.UserID, Version: 2, DeletedAt: &deletedAt, DeletedBy: suite.admin.UserID}
+
+	suite.mockRepo.On("GetDeletedTaskByID", mock.Anything, "1").Return(deletedTask, domain.CustomError{})
+	suite.mockRepo.On("RestoreTaskByID", mock.Anything, "1").Return(domain.CustomError{})
+
+	task, err := suite.usecase.RestoreTaskByID(context.TODO(), suite.user, "1")
+
+	suite.Empty(err.ErrMessage)
+	suite.Nil(task.DeletedAt)
+	suite.Empty(task.DeletedBy)
+	suite.Equal(int64(3), task.Version)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockHistoryRepo.AssertCalled(suite.T(), "AddEntry", mock.Anything, mock.MatchedBy(func(entry domain.TaskHistoryEntry) bool {
+		return entry.Action == domain.HistoryActionRestored && entry.TaskID == "1"
+	}))
+}
+
+// Test RestoreTaskByID by a user who did not create the task
+func (suite *TaskUsecaseSuite) TestRestoreTaskByID_Forbidden() {
+	deletedAt := time.Now()
+	deletedTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: "someone-else", AssigneeIDs: []string{suite.user.UserID}, DeletedAt: &deletedAt}
+
+	suite.mockRepo.On("GetDeletedTaskByID", mock.Anything, "1").Return(deletedTask, domain.CustomError{})
+
+	_, err := suite.usecase.RestoreTaskByID(context.TODO(), suite.user, "1")
+
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "RestoreTaskByID", mock.Anything, mock.Anything)
+}
+
+// Test PurgeTaskByID keeps a snapshot in the history
+func (suite *TaskUsecaseSuite) TestPurgeTaskByID() {
+	deletedAt := time.Now()
+	deletedTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: suite.user.UserID, DeletedAt: &deletedAt}
+
+	suite.mockRepo.On("GetDeletedTaskByID", mock.Anything, "1").Return(deletedTask, domain.CustomError{})
+	suite.mockRepo.On("PurgeTaskByID", mock.Anything, "1").Return(domain.CustomError{})
+
+	err := suite.usecase.PurgeTaskByID(context.TODO(), suite.admin, "1")
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockHistoryRepo.AssertCalled(suite.T(), "AddEntry", mock.Anything, mock.MatchedBy(func(entry domain.TaskHistoryEntry) bool {
+		return entry.Action == domain.HistoryActionPurged && entry.Snapshot != nil && entry.Snapshot.Title == "Task 1"
+	}))
+}
+
+// Test PurgeTaskByID for a task that is not in the trash
+func (suite *TaskUsecaseSuite) TestPurgeTaskByID_NotInTrash() {
+	suite.mockRepo.On("GetDeletedTaskByID", mock.Anything, "1").Return(domain.Task{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found in trash"})
+
+	err := suite.usecase.PurgeTaskByID(context.TODO(), suite.admin, "1")
+
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "PurgeTaskByID", mock.Anything, mock.Anything)
+}
+
+// Test PurgeExpiredTasks purges what was deleted before the retention cutoff
+func (suite *TaskUsecaseSuite) TestPurgeExpiredTasks() {
+	before := time.Now().Add(-24 * time.Hour)
+	suite.mockRepo.On("PurgeDeletedBefore", mock.Anything, mock.MatchedBy(func(cutoff time.Time) bool {
+		return !cutoff.Before(before) && cutoff.Before(time.Now().Add(-23*time.Hour))
+	})).Return(int64(2), domain.CustomError{})
+
+	purged, err := suite.usecase.PurgeExpiredTasks(context.TODO(), 24*time.Hour)
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal(int64(2), purged)
+}
+
+// Test GetTaskHistory for an existing task
+func (suite *TaskUsecaseSuite) TestGetTaskHistory() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: suite.user.UserID}
+	mockPage := domain.TaskHistoryPage{Entries: []domain.TaskHistoryEntry{{TaskID: "1", Action: domain.HistoryActionCreated}}, Total: 1}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockHistoryRepo.On("GetEntries", mock.Anything, "1", "", int64(domain.DefaultTaskPageSize)).Return(mockPage, domain.CustomError{})
+
+	page, err := suite.usecase.GetTaskHistory(context.TODO(), suite.user, "1", "", 0)
+
+	suite.Empty(err.ErrMessage)
+	suite.Equal(1, len(page.Entries))
+}
+
+// Test GetTaskHistory for a deleted task uses the deletion snapshot for access checks
+func (suite *TaskUsecaseSuite) TestGetTaskHistory_DeletedTask() {
+	snapshot := domain.Task{ID: "1", Title: "Task 1", CreatedBy: "someone-else"}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(domain.Task{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Task not found"})
+	suite.mockHistoryRepo.On("GetDeletionEntry", mock.Anything, "1").Return(domain.TaskHistoryEntry{TaskID: "1", Snapshot: &snapshot}, domain.CustomError{})
+	suite.mockHistoryRepo.On("GetEntries", mock.Anything, "1", "", int64(10)).Return(domain.TaskHistoryPage{}, domain.CustomError{})
+
+	_, err := suite.usecase.GetTaskHistory(context.TODO(), suite.user, "1", "", 10)
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+
+	_, err = suite.usecase.GetTaskHistory(context.TODO(), suite.admin, "1", "", 10)
+	suite.Empty(err.ErrMessage)
+	suite.mockHistoryRepo.AssertExpectations(suite.T())
+}
+
+// Test AssignUsers
+func (suite *TaskUsecaseSuite) TestAssignUsers() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: suite.user.UserID}
+	userIDs := []string{"user-2"}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-2").Return(domain.User{ID: "user-2"}, domain.CustomError{})
+	suite.mockRepo.On("AddAssignees", mock.Anything, "1", userIDs).Return(domain.CustomError{})
+
+	err := suite.usecase.AssignUsers(context.TODO(), suite.user, "1", userIDs)
+
+	suite.Empty(err.ErrMessage)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockUserRepo.AssertExpectations(suite.T())
+}
+
+// Test AssignUsers with a user that does not exist
+func (suite *TaskUsecaseSuite) TestAssignUsers_UnknownUser() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: suite.user.UserID}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "ghost").Return(domain.User{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "User not found"})
+
+	err := suite.usecase.AssignUsers(context.TODO(), suite.user, "1", []string{"ghost"})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "AddAssignees", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test UnassignUser by an assignee who is not the creator
+func (suite *TaskUsecaseSuite) TestUnassignUser_Forbidden() {
+	existingTask := domain.Task{ID: "1", Title: "Task 1", CreatedBy: "someone-else", AssigneeIDs: []string{suite.user.UserID}}
+
+	suite.mockRepo.On("GetTaskByID", mock.Anything, "1").Return(existingTask, domain.CustomError{})
+
+	err := suite.usecase.UnassignUser(context.TODO(), suite.user, "1", "someone-else")
+
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+}
+
 func TestTaskUsecaseSuite(t *testing.T) {
 	suite.Run(t, new(TaskUsecaseSuite))
 }
@@ -0,0 +1,205 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"task_managment_api/domain"
+	"time"
+)
+
+type userAdminUsecase struct {
+	userRepository         domain.UserRepository
+	roleRepository         domain.RoleRepository
+	projectRepository      domain.ProjectRepository
+	calendarFeedRepository domain.CalendarFeedRepository
+	taskUsecase            domain.TaskUsecase
+}
+
+func NewUserAdminUsecase(userRepository domain.UserRepository, roleRepository domain.RoleRepository, projectRepository domain.ProjectRepository, calendarFeedRepository domain.CalendarFeedRepository, taskUsecase domain.TaskUsecase) domain.UserAdminUsecase {
+	return &userAdminUsecase{
+		userRepository:         userRepository,
+		roleRepository:         roleRepository,
+		projectRepository:      projectRepository,
+		calendarFeedRepository: calendarFeedRepository,
+		taskUsecase:            taskUsecase,
+	}
+}
+
+// GetUsers returns a page of the users matching the query, in the order they registered.
+func (ua *userAdminUsecase) GetUsers(c context.Context, query domain.UserQuery) (domain.UserPage, domain.CustomError) {
+	limit, err := pageLimit(query.Limit)
+	if err.ErrCode != 0 {
+		return domain.UserPage{}, err
+	}
+	query.Limit = limit
+	query.Search = strings.TrimSpace(query.Search)
+	if len([]rune(query.Search)) > domain.MaxSearchLength {
+		return domain.UserPage{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("q must be at most %d characters", domain.MaxSearchLength), Field: "q"}
+	}
+	return ua.userRepository.GetUsers(c, query)
+}
+
+func (ua *userAdminUsecase) GetUserByID(c context.Context, userID string) (domain.UserSummary, domain.CustomError) {
+	user, err := ua.getUser(c, userID)
+	if err.ErrCode != 0 {
+		return domain.UserSummary{}, err
+	}
+	return user.Summary(), domain.CustomError{}
+}
+
+// ChangeRole gives a user any role that exists, so it can demote as well as promote.
+func (ua *userAdminUsecase) ChangeRole(c context.Context, userID string, role string) (domain.UserSummary, domain.CustomError) {
+	if err := checkRoleExists(c, ua.roleRepository, role); err.ErrCode != 0 {
+		return domain.UserSummary{}, err
+	}
+	user, err := ua.getUser(c, userID)
+	if err.ErrCode != 0 {
+		return domain.UserSummary{}, err
+	}
+	if user.Role == role {
+		return user.Summary(), domain.CustomError{}
+	}
+	if err := assignRole(c, ua.userRepository, &user, role); err.ErrCode != 0 {
+		return domain.UserSummary{}, err
+	}
+	if err := ua.userRepository.UpdateUser(c, user); err.ErrCode != 0 {
+		return domain.UserSummary{}, err
+	}
+	return user.Summary(), domain.CustomError{}
+}
+
+// DisableUser stops a user from logging in and revokes their access tokens. Their sessions are revoked
+// when they next try to refresh them. Disabling an account that is already disabled changes nothing.
+func (ua *userAdminUsecase) DisableUser(c context.Context, user domain.AuthUser, userID string) (domain.UserSummary, domain.CustomError) {
+	if userID == user.UserID {
+		return domain.UserSummary{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "You cannot disable your own account"}
+	}
+	target, err := ua.getUser(c, userID)
+	if err.ErrCode != 0 {
+		return domain.UserSummary{}, err
+	}
+	if target.Disabled {
+		return target.Summary(), domain.CustomError{}
+	}
+	if err := keepAnAdmin(c, ua.userRepository, target, "Cannot disable the last admin"); err.ErrCode != 0 {
+		return domain.UserSummary{}, err
+	}
+
+	now := time.Now().UTC()
+	target.Disabled = true
+	target.DisabledAt = &now
+	invalidateTokens(&target)
+	if err := ua.userRepository.UpdateUser(c, target); err.ErrCode != 0 {
+		return domain.UserSummary{}, err
+	}
+	return target.Summary(), domain.CustomError{}
+}
+
+// EnableUser lets a disabled user log in again.
+func (ua *userAdminUsecase) EnableUser(c context.Context, userID string) (domain.UserSummary, domain.CustomError) {
+	target, err := ua.getUser(c, userID)
+	if err.ErrCode != 0 {
+		return domain.UserSummary{}, err
+	}
+	if !target.Disabled {
+		return target.Summary(), domain.CustomError{}
+	}
+
+	target.Disabled = false
+	target.DisabledAt = nil
+	if err := ua.userRepository.UpdateUser(c, target); err.ErrCode != 0 {
+		return domain.UserSummary{}, err
+	}
+	return target.Summary(), domain.CustomError{}
+}
+
+// DeleteUser deletes a user after dealing with their tasks as the deletion asks. With a transfer the other
+// user also takes over the deleted user's project roles where those are higher than their own; otherwise
+// the user just leaves their projects, and projects left without an owner are managed by admins. The
+// comments the user wrote are kept.
+func (ua *userAdminUsecase) DeleteUser(c context.Context, user domain.AuthUser, userID string, deletion domain.UserDeletion) (domain.UserDeletionReport, domain.CustomError) {
+	if userID == user.UserID {
+		return domain.UserDeletionReport{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "You cannot delete your own account"}
+	}
+	target, err := ua.getUser(c, userID)
+	if err.ErrCode != 0 {
+		return domain.UserDeletionReport{}, err
+	}
+	if deletion.Tasks == domain.UserTasksTransfer {
+		if err := ua.checkTransferTarget(c, userID, deletion.TransferTo); err.ErrCode != 0 {
+			return domain.UserDeletionReport{}, err
+		}
+	} else {
+		deletion.TransferTo = ""
+	}
+	if err := keepAnAdmin(c, ua.userRepository, target, "Cannot delete the last admin"); err.ErrCode != 0 {
+		return domain.UserDeletionReport{}, err
+	}
+
+	report, err := ua.taskUsecase.ReleaseUserTasks(c, user, userID, deletion)
+	if err.ErrCode != 0 {
+		return domain.UserDeletionReport{}, err
+	}
+	if err := ua.leaveProjects(c, userID, deletion.TransferTo); err.ErrCode != 0 {
+		return domain.UserDeletionReport{}, err
+	}
+	if err := ua.calendarFeedRepository.DeleteFeed(c, userID); err.ErrCode != 0 && err.ErrCode != http.StatusNotFound {
+		return domain.UserDeletionReport{}, err
+	}
+	if err := ua.userRepository.DeleteUser(c, userID); err.ErrCode != 0 {
+		return domain.UserDeletionReport{}, err
+	}
+	return report, domain.CustomError{}
+}
+
+// checkTransferTarget makes sure the tasks of a deleted user go to another user who can use them.
+func (ua *userAdminUsecase) checkTransferTarget(c context.Context, userID string, transferTo string) domain.CustomError {
+	if transferTo == "" {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "to is required when tasks is transfer", Field: "to"}
+	}
+	if transferTo == userID {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "to must be another user", Field: "to"}
+	}
+	recipient, err := ua.getUser(c, transferTo)
+	if err.ErrCode == http.StatusNotFound {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("User %s not found", transferTo), Field: "to"}
+	}
+	if err.ErrCode != 0 {
+		return err
+	}
+	if recipient.Disabled {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "to must not be a disabled user", Field: "to"}
+	}
+	return domain.CustomError{}
+}
+
+// leaveProjects takes a user out of their projects, first handing their role to transferTo if it is set.
+func (ua *userAdminUsecase) leaveProjects(c context.Context, userID string, transferTo string) domain.CustomError {
+	projects, err := ua.projectRepository.GetProjects(c, userID)
+	if err.ErrCode != 0 {
+		return err
+	}
+	for _, project := range projects {
+		role := project.RoleOf(userID)
+		if transferTo != "" && !project.RoleOf(transferTo).AtLeast(role) {
+			if err := ua.projectRepository.SetMember(c, project.ID, domain.ProjectMember{UserID: transferTo, Role: role}); err.ErrCode != 0 {
+				return err
+			}
+		}
+		if err := ua.projectRepository.RemoveMember(c, project.ID, userID); err.ErrCode != 0 && err.ErrCode != http.StatusNotFound {
+			return err
+		}
+	}
+	return domain.CustomError{}
+}
+
+// getUser loads a user, answering 404 for IDs that cannot exist.
+func (ua *userAdminUsecase) getUser(c context.Context, userID string) (domain.User, domain.CustomError) {
+	user, err := ua.userRepository.GetUserByID(c, userID)
+	if err.ErrCode == http.StatusBadRequest {
+		return domain.User{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "User not found"}
+	}
+	return user, err
+}
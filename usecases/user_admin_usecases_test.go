@@ -0,0 +1,200 @@
+package usecases_test
+
+import (
+	"context"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserAdminUsecaseSuite struct {
+	suite.Suite
+	mockUserRepo    *MockUserRepository
+	mockRoleRepo    *MockRoleRepository
+	mockProjectRepo *MockProjectRepository
+	mockFeedRepo    *MockCalendarFeedRepository
+	mockTaskRepo    *MockTaskRepository
+	usecase         domain.UserAdminUsecase
+	admin           domain.AuthUser
+}
+
+func (suite *UserAdminUsecaseSuite) SetupTest() {
+	suite.mockUserRepo = new(MockUserRepository)
+	suite.mockRoleRepo = new(MockRoleRepository)
+	suite.mockProjectRepo = new(MockProjectRepository)
+	suite.mockFeedRepo = new(MockCalendarFeedRepository)
+	suite.mockTaskRepo = new(MockTaskRepository)
+	// releasing a user's tasks goes through the task usecase, which records history and publishes events
+	historyRepo := new(MockTaskHistoryRepository)
+	historyRepo.On("AddEntry", mock.Anything, mock.Anything).Return(domain.CustomError{}).Maybe()
+	publisher := new(MockTaskEventPublisher)
+	publisher.On("Publish", mock.Anything, mock.Anything).Return().Maybe()
+	taskUsecase := usecases.NewTaskUsecase(suite.mockTaskRepo, suite.mockUserRepo, suite.mockRoleRepo, historyRepo, new(MockLabelRepository), new(MockProjectRepository), new(MockTaskSeriesRepository), publisher, new(MockTransactionRunner), domain.DefaultStatusWorkflow, domain.SubtaskDeleteBlock)
+	suite.usecase = usecases.NewUserAdminUsecase(suite.mockUserRepo, suite.mockRoleRepo, suite.mockProjectRepo, suite.mockFeedRepo, taskUsecase)
+	suite.admin = domain.AuthUser{UserID: "admin-1", Username: "admin", Role: "admin"}
+}
+
+func (suite *UserAdminUsecaseSuite) TearDownTest() {
+	suite.mockUserRepo.AssertExpectations(suite.T())
+	suite.mockRoleRepo.AssertExpectations(suite.T())
+	suite.mockProjectRepo.AssertExpectations(suite.T())
+	suite.mockFeedRepo.AssertExpectations(suite.T())
+	suite.mockTaskRepo.AssertExpectations(suite.T())
+}
+
+// Test listing users applies the default page size and trims the search
+func (suite *UserAdminUsecaseSuite) TestGetUsers() {
+	page := domain.UserPage{Users: []domain.UserSummary{{ID: "user-1", Username: "alice"}}, Total: 1}
+	suite.mockUserRepo.On("GetUsers", mock.Anything, mock.MatchedBy(func(query domain.UserQuery) bool {
+		return query.Search == "ali" && query.Limit == 20
+	})).Return(page, domain.CustomError{})
+
+	result, err := suite.usecase.GetUsers(context.TODO(), domain.UserQuery{Search: " ali "})
+
+	suite.Empty(err.ErrCode)
+	suite.Equal(page, result)
+}
+
+// Test a malformed user ID answers 404
+func (suite *UserAdminUsecaseSuite) TestGetUserByIDInvalid() {
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "nope").Return(domain.User{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "Invalid user ID"})
+
+	_, err := suite.usecase.GetUserByID(context.TODO(), "nope")
+
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+// Test changing the role of a user revokes their tokens
+func (suite *UserAdminUsecaseSuite) TestChangeRole() {
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "manager").Return(domain.Role{Name: "manager"}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Role: "user"}, domain.CustomError{})
+	suite.mockUserRepo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(user domain.User) bool {
+		return user.Role == "manager" && user.TokensValidAfter != nil
+	})).Return(domain.CustomError{})
+
+	user, err := suite.usecase.ChangeRole(context.TODO(), "user-1", "manager")
+
+	suite.Empty(err.ErrCode)
+	suite.Equal("manager", user.Role)
+}
+
+// Test the last enabled admin cannot be demoted
+func (suite *UserAdminUsecaseSuite) TestChangeRoleLastAdmin() {
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "user").Return(domain.Role{Name: "user"}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "admin-2").Return(domain.User{ID: "admin-2", Role: "admin"}, domain.CustomError{})
+	suite.mockUserRepo.On("CountEnabledUsersWithRole", mock.Anything, "admin").Return(int64(1), domain.CustomError{})
+
+	_, err := suite.usecase.ChangeRole(context.TODO(), "admin-2", "user")
+
+	suite.Equal(http.StatusConflict, err.ErrCode)
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "UpdateUser", mock.Anything, mock.Anything)
+}
+
+// Test disabling a user records when and revokes their tokens
+func (suite *UserAdminUsecaseSuite) TestDisableUser() {
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Role: "user"}, domain.CustomError{})
+	suite.mockUserRepo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(user domain.User) bool {
+		return user.Disabled && user.DisabledAt != nil && user.TokensValidAfter != nil
+	})).Return(domain.CustomError{})
+
+	user, err := suite.usecase.DisableUser(context.TODO(), suite.admin, "user-1")
+
+	suite.Empty(err.ErrCode)
+	suite.True(user.Disabled)
+}
+
+// Test admins cannot disable themselves
+func (suite *UserAdminUsecaseSuite) TestDisableUserSelf() {
+	_, err := suite.usecase.DisableUser(context.TODO(), suite.admin, suite.admin.UserID)
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+}
+
+// Test enabling a user clears when they were disabled
+func (suite *UserAdminUsecaseSuite) TestEnableUser() {
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Disabled: true}, domain.CustomError{})
+	suite.mockUserRepo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(user domain.User) bool {
+		return !user.Disabled && user.DisabledAt == nil
+	})).Return(domain.CustomError{})
+
+	user, err := suite.usecase.EnableUser(context.TODO(), "user-1")
+
+	suite.Empty(err.ErrCode)
+	suite.False(user.Disabled)
+}
+
+// Test deleting a user hands their tasks and project roles to another user
+func (suite *UserAdminUsecaseSuite) TestDeleteUserTransfer() {
+	project := domain.Project{ID: "project-1", Members: []domain.ProjectMember{
+		{UserID: "user-1", Role: domain.ProjectRoleOwner},
+		{UserID: "user-2", Role: domain.ProjectRoleMember},
+	}}
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Role: "user"}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-2").Return(domain.User{ID: "user-2", Role: "user"}, domain.CustomError{})
+	suite.mockTaskRepo.On("TransferTasks", mock.Anything, "user-1", "user-2").Return(int64(2), domain.CustomError{})
+	suite.mockTaskRepo.On("ReplaceAssignee", mock.Anything, "user-1", "user-2").Return(int64(1), domain.CustomError{})
+	suite.mockProjectRepo.On("GetProjects", mock.Anything, "user-1").Return([]domain.Project{project}, domain.CustomError{})
+	suite.mockProjectRepo.On("SetMember", mock.Anything, "project-1", domain.ProjectMember{UserID: "user-2", Role: domain.ProjectRoleOwner}).Return(domain.CustomError{})
+	suite.mockProjectRepo.On("RemoveMember", mock.Anything, "project-1", "user-1").Return(domain.CustomError{})
+	suite.mockFeedRepo.On("DeleteFeed", mock.Anything, "user-1").Return(domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Calendar feed not found"})
+	suite.mockUserRepo.On("DeleteUser", mock.Anything, "user-1").Return(domain.CustomError{})
+
+	report, err := suite.usecase.DeleteUser(context.TODO(), suite.admin, "user-1", domain.UserDeletion{Tasks: domain.UserTasksTransfer, TransferTo: "user-2"})
+
+	suite.Empty(err.ErrCode)
+	suite.Equal(int64(2), report.Transferred)
+	suite.Equal(int64(1), report.Unassigned)
+}
+
+// Test deleting a user can trash the tasks they created instead
+func (suite *UserAdminUsecaseSuite) TestDeleteUserDeleteTasks() {
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Role: "user"}, domain.CustomError{})
+	suite.mockTaskRepo.On("GetTasksCreatedBy", mock.Anything, "user-1").Return([]domain.Task{{ID: "task-1"}}, domain.CustomError{})
+	suite.mockTaskRepo.On("GetSubtasks", mock.Anything, []string{"task-1"}).Return([]domain.Task{}, domain.CustomError{})
+	suite.mockTaskRepo.On("DeleteTaskByID", mock.Anything, "task-1", suite.admin.UserID, mock.AnythingOfType("time.Time")).Return(domain.CustomError{})
+	suite.mockTaskRepo.On("RemoveAssigneeFromTasks", mock.Anything, "user-1").Return(int64(0), domain.CustomError{})
+	suite.mockProjectRepo.On("GetProjects", mock.Anything, "user-1").Return([]domain.Project{{ID: "project-1", Members: []domain.ProjectMember{{UserID: "user-1", Role: domain.ProjectRoleMember}}}}, domain.CustomError{})
+	suite.mockProjectRepo.On("RemoveMember", mock.Anything, "project-1", "user-1").Return(domain.CustomError{})
+	suite.mockFeedRepo.On("DeleteFeed", mock.Anything, "user-1").Return(domain.CustomError{})
+	suite.mockUserRepo.On("DeleteUser", mock.Anything, "user-1").Return(domain.CustomError{})
+
+	report, err := suite.usecase.DeleteUser(context.TODO(), suite.admin, "user-1", domain.UserDeletion{Tasks: domain.UserTasksDelete, TransferTo: "ignored"})
+
+	suite.Empty(err.ErrCode)
+	suite.Equal(int64(1), report.Deleted)
+	suite.Empty(report.TransferTo)
+	suite.mockProjectRepo.AssertNotCalled(suite.T(), "SetMember", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test a transfer needs another enabled user to receive the tasks
+func (suite *UserAdminUsecaseSuite) TestDeleteUserInvalidTransfer() {
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1"}, domain.CustomError{})
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "user-2").Return(domain.User{ID: "user-2", Disabled: true}, domain.CustomError{})
+
+	for _, to := range []string{"", "user-1", "user-2"} {
+		_, err := suite.usecase.DeleteUser(context.TODO(), suite.admin, "user-1", domain.UserDeletion{Tasks: domain.UserTasksTransfer, TransferTo: to})
+		suite.Equal(http.StatusBadRequest, err.ErrCode, to)
+		suite.Equal("to", err.Field, to)
+	}
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "DeleteUser", mock.Anything, mock.Anything)
+}
+
+// Test admins cannot delete themselves or the last admin
+func (suite *UserAdminUsecaseSuite) TestDeleteUserRefused() {
+	_, err := suite.usecase.DeleteUser(context.TODO(), suite.admin, suite.admin.UserID, domain.UserDeletion{Tasks: domain.UserTasksDelete})
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+
+	suite.mockUserRepo.On("GetUserByID", mock.Anything, "admin-2").Return(domain.User{ID: "admin-2", Role: "admin"}, domain.CustomError{})
+	suite.mockUserRepo.On("CountEnabledUsersWithRole", mock.Anything, "admin").Return(int64(1), domain.CustomError{})
+	_, err = suite.usecase.DeleteUser(context.TODO(), suite.admin, "admin-2", domain.UserDeletion{Tasks: domain.UserTasksDelete})
+	suite.Equal(http.StatusConflict, err.ErrCode)
+	suite.mockTaskRepo.AssertNotCalled(suite.T(), "GetTasksCreatedBy", mock.Anything, mock.Anything)
+}
+
+func TestUserAdminUsecaseSuite(t *testing.T) {
+	suite.Run(t, new(UserAdminUsecaseSuite))
+}
@@ -2,8 +2,14 @@ package usecases
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"net/mail"
+	"time"
 
 	"task_managment_api/domain"
 	"task_managment_api/infrastructure"
@@ -13,15 +19,29 @@ type userUsecase struct {
 	userRepository domain.UserRepository
 	passwordService infrastructure.PasswordService
 	jwtService infrastructure.JWTService
+	refreshTokenRepository domain.RefreshTokenRepository
+	revokedTokenRepository domain.RevokedTokenRepository
+	roleRepository domain.RoleRepository
+	refreshTokenTTL time.Duration
 }
 
-func NewUserUsecase(userRepository domain.UserRepository, jwtService infrastructure.JWTService, passwordService infrastructure.PasswordService) domain.UserUsecase {
-	return &userUsecase{userRepository: userRepository, jwtService: jwtService, passwordService: passwordService}
+func NewUserUsecase(userRepository domain.UserRepository, jwtService infrastructure.JWTService, passwordService infrastructure.PasswordService, refreshTokenRepository domain.RefreshTokenRepository, revokedTokenRepository domain.RevokedTokenRepository, roleRepository domain.RoleRepository, refreshTokenTTL time.Duration) domain.UserUsecase {
+	if refreshTokenTTL <= 0 {
+		refreshTokenTTL = domain.DefaultRefreshTokenTTL
+	}
+	return &userUsecase{userRepository: userRepository, jwtService: jwtService, passwordService: passwordService, refreshTokenRepository: refreshTokenRepository, revokedTokenRepository: revokedTokenRepository, roleRepository: roleRepository, refreshTokenTTL: refreshTokenTTL}
 }
 
 
 func (uc *userUsecase)RegisterUser(c context.Context, user domain.User) domain.CustomError{
-	
+	if user.Email != "" {
+		address, err := mail.ParseAddress(user.Email)
+		if err != nil || address.Name != "" {
+			return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "email is not a valid address", Field: "email"}
+		}
+		user.Email = address.Address
+	}
+
 	_ ,err := uc.userRepository.GetUserByUsername(c, user.Username)
 
 	if err.ErrCode == 0{
@@ -40,9 +60,9 @@ func (uc *userUsecase)RegisterUser(c context.Context, user domain.User) domain.C
 	}
 
 	if count == 0 {
-		user.Role = "admin"
+		user.Role = domain.RoleAdmin
 	} else {
-		user.Role = "user"
+		user.Role = domain.RoleUser
 	}	
 	hashed,err :=  uc.passwordService.HashPassword(user.Password)
 	if err.ErrCode != 0 {
@@ -55,34 +75,257 @@ func (uc *userUsecase)RegisterUser(c context.Context, user domain.User) domain.C
 }
 
 
-func (uc *userUsecase)AuthenticateUser(c context.Context, username, password string) (string, domain.CustomError){
+// AuthenticateUser checks the credentials and starts a new session with its first refresh token.
+func (uc *userUsecase)AuthenticateUser(c context.Context, username, password string) (domain.TokenPair, domain.CustomError){
 	
 	user, err := uc.userRepository.GetUserByUsername(c, username)
 
 	if err.ErrCode != 0 {
 		if err.ErrCode ==  500{
-			return "", domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while checking user"}
+			return domain.TokenPair{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while checking user"}
 
 		}
-		return "", domain.CustomError{ErrCode: http.StatusUnauthorized, ErrMessage: "Invalid username or password"}
+		return domain.TokenPair{}, domain.CustomError{ErrCode: http.StatusUnauthorized, ErrMessage: "Invalid username or password"}
 	}
 
 	err = uc.passwordService.VerifyPassword(user, password)
 
 	if err.ErrCode != 0 { 
+		return domain.TokenPair{}, err
+	}
+	if user.Disabled {
+		return domain.TokenPair{}, accountDisabled()
+	}
+
+	familyID, randErr := newOpaqueToken(16)
+	if randErr != nil {
+		return domain.TokenPair{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating session"}
+	}
+	return uc.issueTokens(c, user, familyID)
+}
+
+// RefreshSession exchanges a refresh token for a new access token and a new refresh token of the same
+// session. A refresh token works once: presenting one that was already exchanged means it leaked, so the
+// whole session is revoked, which also locks out whoever holds its newest token.
+func (uc *userUsecase) RefreshSession(c context.Context, refreshToken string) (domain.TokenPair, domain.CustomError) {
+	stored, err := uc.refreshTokenRepository.GetRefreshTokenByHash(c, hashOpaqueToken(refreshToken))
+	if err.ErrCode == http.StatusNotFound {
+		return domain.TokenPair{}, invalidRefreshToken()
+	}
+	if err.ErrCode != 0 {
+		return domain.TokenPair{}, err
+	}
+	if stored.RevokedAt != nil {
+		return domain.TokenPair{}, invalidRefreshToken()
+	}
+	now := time.Now().UTC()
+	if stored.RotatedAt != nil {
+		return domain.TokenPair{}, uc.revokeReusedSession(c, stored.FamilyID, now)
+	}
+	if !now.Before(stored.ExpiresAt) {
+		return domain.TokenPair{}, domain.CustomError{ErrCode: http.StatusUnauthorized, ErrMessage: "Refresh token expired"}
+	}
+
+	user, err := uc.userRepository.GetUserByID(c, stored.UserID)
+	if err.ErrCode == http.StatusNotFound {
+		return domain.TokenPair{}, invalidRefreshToken()
+	}
+	if err.ErrCode != 0 {
+		return domain.TokenPair{}, err
+	}
+	if user.Disabled {
+		if err := uc.refreshTokenRepository.RevokeRefreshTokenFamily(c, stored.FamilyID, now); err.ErrCode != 0 {
+			return domain.TokenPair{}, err
+		}
+		return domain.TokenPair{}, accountDisabled()
+	}
+
+	// a concurrent refresh with the same token may have won the race; that is reuse too
+	err = uc.refreshTokenRepository.RotateRefreshToken(c, stored.ID, now)
+	if err.ErrCode == http.StatusConflict {
+		return domain.TokenPair{}, uc.revokeReusedSession(c, stored.FamilyID, now)
+	}
+	if err.ErrCode != 0 {
+		return domain.TokenPair{}, err
+	}
+	return uc.issueTokens(c, user, stored.FamilyID)
+}
+
+// Logout revokes the refresh tokens of the caller's session and the access token the request was made with.
+func (uc *userUsecase) Logout(c context.Context, user domain.AuthUser) domain.CustomError {
+	if user.SessionID == "" {
+		return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "The token does not belong to a session"}
+	}
+	now := time.Now().UTC()
+	if user.TokenID != "" {
+		// the token cannot outlive the longest lifetime an access token has
+		revoked := domain.RevokedToken{ID: user.TokenID, UserID: user.UserID, RevokedAt: now, ExpiresAt: now.Add(uc.jwtService.AccessTokenTTL())}
+		if err := uc.revokedTokenRepository.RevokeToken(c, revoked); err.ErrCode != 0 {
+			return err
+		}
+	}
+	return uc.refreshTokenRepository.RevokeRefreshTokenFamily(c, user.SessionID, now)
+}
+
+// CheckTokenRevocation refuses an access token that was revoked on its own, that was issued before the
+// user's tokens were last invalidated, or whose user no longer exists or is disabled.
+func (uc *userUsecase) CheckTokenRevocation(c context.Context, userID string, tokenID string, issuedAt time.Time) domain.CustomError {
+	if tokenID != "" {
+		revoked, err := uc.revokedTokenRepository.IsTokenRevoked(c, tokenID)
+		if err.ErrCode != 0 {
+			return err
+		}
+		if revoked {
+			return tokenRevoked()
+		}
+	}
+
+	user, err := uc.userRepository.GetUserByID(c, userID)
+	if err.ErrCode == http.StatusNotFound || err.ErrCode == http.StatusBadRequest {
+		return domain.CustomError{ErrCode: http.StatusUnauthorized, ErrMessage: "Invalid token"}
+	}
+	if err.ErrCode != 0 {
+		return err
+	}
+	// iat has a precision of seconds, so a token from the second of the change is revoked too
+	if user.Disabled || user.TokensValidAfter != nil && issuedAt.Unix() <= user.TokensValidAfter.Unix() {
+		return tokenRevoked()
+	}
+	return domain.CustomError{}
+}
+
+// issueTokens stores a new refresh token of the session and signs an access token that names it.
+func (uc *userUsecase) issueTokens(c context.Context, user domain.User, familyID string) (domain.TokenPair, domain.CustomError) {
+	refreshToken, randErr := newOpaqueToken(32)
+	if randErr != nil {
+		return domain.TokenPair{}, domain.CustomError{ErrCode: http.StatusInternalServerError, ErrMessage: "Error while creating refresh token"}
+	}
+	now := time.Now().UTC()
+	stored := domain.RefreshToken{
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		TokenHash: hashOpaqueToken(refreshToken),
+		CreatedAt: now,
+		ExpiresAt: now.Add(uc.refreshTokenTTL),
+	}
+	if err := uc.refreshTokenRepository.CreateRefreshToken(c, stored); err.ErrCode != 0 {
+		return domain.TokenPair{}, err
+	}
+
+	accessToken, err := uc.jwtService.GenerateUserToken(user, familyID)
+	if err.ErrCode != 0 {
+		return domain.TokenPair{}, err
+	}
+	return domain.TokenPair{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(uc.jwtService.AccessTokenTTL() / time.Second),
+	}, domain.CustomError{}
+}
+
+func (uc *userUsecase) revokeReusedSession(c context.Context, familyID string, now time.Time) domain.CustomError {
+	if err := uc.refreshTokenRepository.RevokeRefreshTokenFamily(c, familyID, now); err.ErrCode != 0 {
+		return err
+	}
+	return domain.CustomError{ErrCode: http.StatusUnauthorized, ErrMessage: "Refresh token was already used; the session has been revoked"}
+}
+
+func invalidRefreshToken() domain.CustomError {
+	return domain.CustomError{ErrCode: http.StatusUnauthorized, ErrMessage: "Invalid refresh token"}
+}
+
+// newOpaqueToken returns size random bytes encoded for use in URLs and headers.
+func newOpaqueToken(size int) (string, error) {
+	secret := make([]byte, size)
+	if _, err := rand.Read(secret); err != nil {
 		return "", err
 	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
 
-	return uc.jwtService.GenerateUserToken(user)
+// hashOpaqueToken hashes a token for storage. The tokens are random, so a fast hash is enough.
+func hashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 
-func (uc *userUsecase)PromoteUser(c context.Context, username string) domain.CustomError{
+// PromoteUser gives the user a role, admin unless another is named. The role must exist.
+func (uc *userUsecase)PromoteUser(c context.Context, username string, role string) domain.CustomError{
+	if role == "" {
+		role = domain.RoleAdmin
+	}
+	if err := checkRoleExists(c, uc.roleRepository, role); err.ErrCode != 0 {
+		return err
+	}
 	user, err := uc.userRepository.GetUserByUsername(c, username)
 	if err.ErrCode != 0 {
 		return err
 	}
-	user.Role = "admin"
+	if err := assignRole(c, uc.userRepository, &user, role); err.ErrCode != 0 {
+		return err
+	}
 	return uc.userRepository.UpdateUser(c, user)
 }
 
+// checkRoleExists answers 400 for a role that users cannot be given because it does not exist.
+func checkRoleExists(c context.Context, roleRepository domain.RoleRepository, role string) domain.CustomError {
+	if _, err := roleRepository.GetRoleByName(c, role); err.ErrCode != 0 {
+		if err.ErrCode == http.StatusNotFound {
+			return domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: fmt.Sprintf("Role %s not found", role), Field: "role"}
+		}
+		return err
+	}
+	return domain.CustomError{}
+}
+
+// assignRole gives the user a role, unless that takes away the last admin, and revokes their access
+// tokens so that the change applies to the next request.
+func assignRole(c context.Context, userRepository domain.UserRepository, user *domain.User, role string) domain.CustomError {
+	if role != domain.RoleAdmin {
+		if err := keepAnAdmin(c, userRepository, *user, "Cannot demote the last admin"); err.ErrCode != 0 {
+			return err
+		}
+	}
+	user.Role = role
+	invalidateTokens(user)
+	return domain.CustomError{}
+}
+
+// keepAnAdmin refuses to demote, disable or delete the last admin whose account is enabled, which would
+// leave nobody to manage the others.
+func keepAnAdmin(c context.Context, userRepository domain.UserRepository, user domain.User, message string) domain.CustomError {
+	if user.Role != domain.RoleAdmin || user.Disabled {
+		return domain.CustomError{}
+	}
+	admins, err := userRepository.CountEnabledUsersWithRole(c, domain.RoleAdmin)
+	if err.ErrCode != 0 {
+		return err
+	}
+	if admins <= 1 {
+		return domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: message}
+	}
+	return domain.CustomError{}
+}
+
+// GetJWKS returns the public keys that other services can verify our access tokens with.
+func (uc *userUsecase) GetJWKS() domain.JWKSet {
+	return uc.jwtService.JWKS()
+}
+
+// invalidateTokens revokes every access token of the user issued so far, so that a change to their role or
+// account applies to the next request. Their refresh tokens keep working and get tokens that reflect it.
+func invalidateTokens(user *domain.User) {
+	now := time.Now().UTC()
+	user.TokensValidAfter = &now
+}
+
+func accountDisabled() domain.CustomError {
+	return domain.CustomError{ErrCode: http.StatusForbidden, ErrMessage: "Account is disabled"}
+}
+
+func tokenRevoked() domain.CustomError {
+	return domain.CustomError{ErrCode: http.StatusUnauthorized, ErrMessage: "Token has been revoked"}
+}
+
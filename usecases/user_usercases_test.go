@@ -2,9 +2,13 @@ package usecases_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
 	"task_managment_api/domain"
 	"task_managment_api/usecases"
 	"testing"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/stretchr/testify/mock"
@@ -20,11 +24,36 @@ func (m *MockUserRepository) GetUserByUsername(c context.Context, username strin
 	return args.Get(0).(domain.User), args.Get(1).(domain.CustomError)
 }
 
+func (m *MockUserRepository) GetUserByID(c context.Context, userID string) (domain.User, domain.CustomError) {
+	args := m.Called(c, userID)
+	return args.Get(0).(domain.User), args.Get(1).(domain.CustomError)
+}
+
 func (m *MockUserRepository) GetUserCount(c context.Context) (int64, domain.CustomError) {
 	args := m.Called(c)
 	return args.Get(0).(int64), args.Get(1).(domain.CustomError)
 }
 
+func (m *MockUserRepository) CountUsersWithRole(c context.Context, role string) (int64, domain.CustomError) {
+	args := m.Called(c, role)
+	return args.Get(0).(int64), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockUserRepository) CountEnabledUsersWithRole(c context.Context, role string) (int64, domain.CustomError) {
+	args := m.Called(c, role)
+	return args.Get(0).(int64), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockUserRepository) GetUsers(c context.Context, query domain.UserQuery) (domain.UserPage, domain.CustomError) {
+	args := m.Called(c, query)
+	return args.Get(0).(domain.UserPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockUserRepository) DeleteUser(c context.Context, userID string) domain.CustomError {
+	args := m.Called(c, userID)
+	return args.Get(0).(domain.CustomError)
+}
+
 func (m *MockUserRepository) CreateUser(c context.Context, user domain.User) domain.CustomError {
 	args := m.Called(c, user)
 	return args.Get(0).(domain.CustomError)
@@ -53,22 +82,71 @@ type MockJWTService struct {
 	mock.Mock
 }	
 
-func (m *MockJWTService) GenerateUserToken(user domain.User) (string, domain.CustomError) {
-	args := m.Called(user)
+func (m *MockJWTService) GenerateUserToken(user domain.User, sessionID string) (string, domain.CustomError) {
+	args := m.Called(user, sessionID)
 	return args.Get(0).(string), args.Get(1).(domain.CustomError)
 }
 
+func (m *MockJWTService) AccessTokenTTL() time.Duration {
+	return m.Called().Get(0).(time.Duration)
+}
+
+func (m *MockJWTService) JWKS() domain.JWKSet {
+	return m.Called().Get(0).(domain.JWKSet)
+}
+
 func (m *MockJWTService) ValidateToken(token string) (jwt.MapClaims, domain.CustomError) {
 	args := m.Called(token)
 	return args.Get(0).(jwt.MapClaims), args.Get(1).(domain.CustomError)
 }
 
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) CreateRefreshToken(c context.Context, token domain.RefreshToken) domain.CustomError {
+	args := m.Called(c, token)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockRefreshTokenRepository) GetRefreshTokenByHash(c context.Context, tokenHash string) (domain.RefreshToken, domain.CustomError) {
+	args := m.Called(c, tokenHash)
+	return args.Get(0).(domain.RefreshToken), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockRefreshTokenRepository) RotateRefreshToken(c context.Context, tokenID string, at time.Time) domain.CustomError {
+	args := m.Called(c, tokenID, at)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockRefreshTokenRepository) RevokeRefreshTokenFamily(c context.Context, familyID string, at time.Time) domain.CustomError {
+	args := m.Called(c, familyID, at)
+	return args.Get(0).(domain.CustomError)
+}
+
+type MockRevokedTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRevokedTokenRepository) RevokeToken(c context.Context, token domain.RevokedToken) domain.CustomError {
+	args := m.Called(c, token)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockRevokedTokenRepository) IsTokenRevoked(c context.Context, tokenID string) (bool, domain.CustomError) {
+	args := m.Called(c, tokenID)
+	return args.Bool(0), args.Get(1).(domain.CustomError)
+}
+
 // Test Suite for UserUsecase
 type UserUsecaseSuite struct {
 	suite.Suite
 	mockRepo        *MockUserRepository
 	mockPasswordSvc *MockPasswordService
 	mockJwtService *MockJWTService
+	mockRefreshRepo *MockRefreshTokenRepository
+	mockRevokedRepo *MockRevokedTokenRepository
+	mockRoleRepo    *MockRoleRepository
 	usecase         domain.UserUsecase
 }
 
@@ -76,12 +154,23 @@ func (suite *UserUsecaseSuite) SetupTest() {
 	suite.mockRepo = new(MockUserRepository)
 	suite.mockPasswordSvc = new(MockPasswordService)
 	suite.mockJwtService = new(MockJWTService)
-	suite.usecase = usecases.NewUserUsecase(suite.mockRepo, suite.mockJwtService,suite.mockPasswordSvc )
+	suite.mockRefreshRepo = new(MockRefreshTokenRepository)
+	suite.mockRevokedRepo = new(MockRevokedTokenRepository)
+	suite.mockRoleRepo = new(MockRoleRepository)
+	suite.mockJwtService.On("AccessTokenTTL").Return(15 * time.Minute).Maybe()
+	suite.usecase = usecases.NewUserUsecase(suite.mockRepo, suite.mockJwtService,suite.mockPasswordSvc, suite.mockRefreshRepo, suite.mockRevokedRepo, suite.mockRoleRepo, time.Hour)
 }
 
 func (suite *UserUsecaseSuite) TearDownTest() {
 	suite.mockRepo.AssertExpectations(suite.T())
 	suite.mockPasswordSvc.AssertExpectations(suite.T())
+	suite.mockRefreshRepo.AssertExpectations(suite.T())
+	suite.mockRevokedRepo.AssertExpectations(suite.T())
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 // Test RegisterUser
@@ -102,6 +191,17 @@ func (suite *UserUsecaseSuite) TestRegisterUser() {
 	suite.mockRepo.AssertCalled(suite.T(), "CreateUser", mock.Anything, mock.AnythingOfType("domain.User"))
 }
 
+// Test RegisterUser with an email that is not an address
+func (suite *UserUsecaseSuite) TestRegisterUser_InvalidEmail() {
+	user := domain.User{Username: "testuser", Password: "password", Email: "not an address"}
+
+	err := suite.usecase.RegisterUser(context.TODO(), user)
+
+	suite.Equal(400, err.ErrCode)
+	suite.Equal("email", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateUser", mock.Anything, mock.Anything)
+}
+
 // Test RegisterUser when User already exists
 func (suite *UserUsecaseSuite) TestRegisterUser_UserAlreadyExists() {
 	user := domain.User{Username: "testuser", Password: "password"}
@@ -140,12 +240,171 @@ func (suite *UserUsecaseSuite) TestAuthenticateUser() {
 
 	suite.mockRepo.On("GetUserByUsername", mock.Anything, user.Username).Return(user, domain.CustomError{})
 	suite.mockPasswordSvc.On("VerifyPassword", user, "password").Return(domain.CustomError{})
-	suite.mockJwtService.On("GenerateUserToken", user).Return("token", domain.CustomError{})
+	var stored domain.RefreshToken
+	suite.mockRefreshRepo.On("CreateRefreshToken", mock.Anything, mock.AnythingOfType("domain.RefreshToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(domain.RefreshToken)
+	}).Return(domain.CustomError{})
+	suite.mockJwtService.On("GenerateUserToken", user, mock.AnythingOfType("string")).Return("token", domain.CustomError{})
 
-	token, err := suite.usecase.AuthenticateUser(context.TODO(), user.Username, "password")
+	tokens, err := suite.usecase.AuthenticateUser(context.TODO(), user.Username, "password")
 
 	suite.Empty(err.ErrMessage)
-	suite.NotEmpty(token)
+	suite.Equal("token", tokens.Token)
+	suite.Equal("Bearer", tokens.TokenType)
+	suite.Equal(int64(900), tokens.ExpiresIn)
+	suite.NotEmpty(tokens.RefreshToken)
+	// only the hash of the refresh token is stored, and the access token names its session
+	suite.Equal(hashRefreshToken(tokens.RefreshToken), stored.TokenHash)
+	suite.NotEmpty(stored.FamilyID)
+	suite.WithinDuration(time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+	suite.mockJwtService.AssertCalled(suite.T(), "GenerateUserToken", user, stored.FamilyID)
+}
+
+// Test a disabled user cannot log in even with the right password
+func (suite *UserUsecaseSuite) TestAuthenticateUser_Disabled() {
+	user := domain.User{Username: "testuser", Password: "hashedpassword", Disabled: true}
+	suite.mockRepo.On("GetUserByUsername", mock.Anything, user.Username).Return(user, domain.CustomError{})
+	suite.mockPasswordSvc.On("VerifyPassword", user, "password").Return(domain.CustomError{})
+
+	_, err := suite.usecase.AuthenticateUser(context.TODO(), user.Username, "password")
+
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+	suite.mockRefreshRepo.AssertNotCalled(suite.T(), "CreateRefreshToken", mock.Anything, mock.Anything)
+}
+
+// Test RefreshSession revokes the session of a disabled user
+func (suite *UserUsecaseSuite) TestRefreshSession_Disabled() {
+	old := domain.RefreshToken{ID: "token-1", FamilyID: "family-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}
+	suite.mockRefreshRepo.On("GetRefreshTokenByHash", mock.Anything, hashRefreshToken("old-refresh")).Return(old, domain.CustomError{})
+	suite.mockRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", Disabled: true}, domain.CustomError{})
+	suite.mockRefreshRepo.On("RevokeRefreshTokenFamily", mock.Anything, "family-1", mock.Anything).Return(domain.CustomError{})
+
+	_, err := suite.usecase.RefreshSession(context.TODO(), "old-refresh")
+
+	suite.Equal(http.StatusForbidden, err.ErrCode)
+	suite.mockRefreshRepo.AssertNotCalled(suite.T(), "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test RefreshSession rotates the refresh token within the same session
+func (suite *UserUsecaseSuite) TestRefreshSession() {
+	user := domain.User{ID: "user-1", Username: "testuser", Role: "user"}
+	old := domain.RefreshToken{ID: "token-1", FamilyID: "family-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}
+	suite.mockRefreshRepo.On("GetRefreshTokenByHash", mock.Anything, hashRefreshToken("old-refresh")).Return(old, domain.CustomError{})
+	suite.mockRepo.On("GetUserByID", mock.Anything, "user-1").Return(user, domain.CustomError{})
+	suite.mockRefreshRepo.On("RotateRefreshToken", mock.Anything, "token-1", mock.Anything).Return(domain.CustomError{})
+	suite.mockRefreshRepo.On("CreateRefreshToken", mock.Anything, mock.MatchedBy(func(token domain.RefreshToken) bool {
+		return token.FamilyID == "family-1" && token.UserID == "user-1"
+	})).Return(domain.CustomError{})
+	suite.mockJwtService.On("GenerateUserToken", user, "family-1").Return("new-token", domain.CustomError{})
+
+	tokens, err := suite.usecase.RefreshSession(context.TODO(), "old-refresh")
+
+	suite.Empty(err.ErrCode)
+	suite.Equal("new-token", tokens.Token)
+	suite.NotEqual("old-refresh", tokens.RefreshToken)
+	suite.mockRefreshRepo.AssertNotCalled(suite.T(), "RevokeRefreshTokenFamily", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test RefreshSession revokes the whole session when a rotated token is used again
+func (suite *UserUsecaseSuite) TestRefreshSession_Reused() {
+	rotatedAt := time.Now().Add(-time.Minute)
+	old := domain.RefreshToken{ID: "token-1", FamilyID: "family-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour), RotatedAt: &rotatedAt}
+	suite.mockRefreshRepo.On("GetRefreshTokenByHash", mock.Anything, hashRefreshToken("old-refresh")).Return(old, domain.CustomError{})
+	suite.mockRefreshRepo.On("RevokeRefreshTokenFamily", mock.Anything, "family-1", mock.Anything).Return(domain.CustomError{})
+
+	_, err := suite.usecase.RefreshSession(context.TODO(), "old-refresh")
+
+	suite.Equal(http.StatusUnauthorized, err.ErrCode)
+	suite.mockRefreshRepo.AssertNotCalled(suite.T(), "CreateRefreshToken", mock.Anything, mock.Anything)
+}
+
+// Test RefreshSession treats losing a concurrent rotation as reuse
+func (suite *UserUsecaseSuite) TestRefreshSession_ConcurrentRotation() {
+	old := domain.RefreshToken{ID: "token-1", FamilyID: "family-1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}
+	suite.mockRefreshRepo.On("GetRefreshTokenByHash", mock.Anything, hashRefreshToken("old-refresh")).Return(old, domain.CustomError{})
+	suite.mockRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1"}, domain.CustomError{})
+	suite.mockRefreshRepo.On("RotateRefreshToken", mock.Anything, "token-1", mock.Anything).Return(domain.CustomError{ErrCode: http.StatusConflict, ErrMessage: "Refresh token already used"})
+	suite.mockRefreshRepo.On("RevokeRefreshTokenFamily", mock.Anything, "family-1", mock.Anything).Return(domain.CustomError{})
+
+	_, err := suite.usecase.RefreshSession(context.TODO(), "old-refresh")
+
+	suite.Equal(http.StatusUnauthorized, err.ErrCode)
+}
+
+// Test RefreshSession refuses unknown, revoked and expired tokens
+func (suite *UserUsecaseSuite) TestRefreshSession_Invalid() {
+	revokedAt := time.Now().Add(-time.Minute)
+	suite.mockRefreshRepo.On("GetRefreshTokenByHash", mock.Anything, hashRefreshToken("unknown")).Return(domain.RefreshToken{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Refresh token not found"})
+	suite.mockRefreshRepo.On("GetRefreshTokenByHash", mock.Anything, hashRefreshToken("revoked")).Return(domain.RefreshToken{ID: "token-1", RevokedAt: &revokedAt, ExpiresAt: time.Now().Add(time.Hour)}, domain.CustomError{})
+	suite.mockRefreshRepo.On("GetRefreshTokenByHash", mock.Anything, hashRefreshToken("expired")).Return(domain.RefreshToken{ID: "token-2", ExpiresAt: time.Now().Add(-time.Minute)}, domain.CustomError{})
+
+	for _, token := range []string{"unknown", "revoked", "expired"} {
+		_, err := suite.usecase.RefreshSession(context.TODO(), token)
+		suite.Equal(http.StatusUnauthorized, err.ErrCode, token)
+	}
+	suite.mockRefreshRepo.AssertNotCalled(suite.T(), "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test Logout revokes the caller's session and access token
+func (suite *UserUsecaseSuite) TestLogout() {
+	suite.mockRefreshRepo.On("RevokeRefreshTokenFamily", mock.Anything, "family-1", mock.Anything).Return(domain.CustomError{})
+	suite.mockRevokedRepo.On("RevokeToken", mock.Anything, mock.MatchedBy(func(token domain.RevokedToken) bool {
+		return token.ID == "token-1" && token.UserID == "user-1" && token.ExpiresAt.After(time.Now().Add(14*time.Minute))
+	})).Return(domain.CustomError{})
+
+	err := suite.usecase.Logout(context.TODO(), domain.AuthUser{UserID: "user-1", SessionID: "family-1", TokenID: "token-1"})
+
+	suite.Empty(err.ErrCode)
+}
+
+// Test CheckTokenRevocation accepts a token issued after the user's last change
+func (suite *UserUsecaseSuite) TestCheckTokenRevocation() {
+	validAfter := time.Now().Add(-time.Hour)
+	suite.mockRevokedRepo.On("IsTokenRevoked", mock.Anything, "token-1").Return(false, domain.CustomError{})
+	suite.mockRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", TokensValidAfter: &validAfter}, domain.CustomError{})
+
+	err := suite.usecase.CheckTokenRevocation(context.TODO(), "user-1", "token-1", time.Now())
+
+	suite.Empty(err.ErrCode)
+}
+
+// Test CheckTokenRevocation refuses a token revoked on its own
+func (suite *UserUsecaseSuite) TestCheckTokenRevocation_Revoked() {
+	suite.mockRevokedRepo.On("IsTokenRevoked", mock.Anything, "token-1").Return(true, domain.CustomError{})
+
+	err := suite.usecase.CheckTokenRevocation(context.TODO(), "user-1", "token-1", time.Now())
+
+	suite.Equal(http.StatusUnauthorized, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetUserByID", mock.Anything, mock.Anything)
+}
+
+// Test CheckTokenRevocation refuses a token issued before the user's role changed
+func (suite *UserUsecaseSuite) TestCheckTokenRevocation_IssuedBeforeChange() {
+	validAfter := time.Now()
+	suite.mockRevokedRepo.On("IsTokenRevoked", mock.Anything, "token-1").Return(false, domain.CustomError{})
+	suite.mockRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{ID: "user-1", TokensValidAfter: &validAfter}, domain.CustomError{})
+
+	err := suite.usecase.CheckTokenRevocation(context.TODO(), "user-1", "token-1", validAfter.Add(-time.Minute))
+
+	suite.Equal(http.StatusUnauthorized, err.ErrCode)
+	suite.Equal("Token has been revoked", err.ErrMessage)
+}
+
+// Test CheckTokenRevocation refuses the token of a user that no longer exists
+func (suite *UserUsecaseSuite) TestCheckTokenRevocation_UserGone() {
+	suite.mockRevokedRepo.On("IsTokenRevoked", mock.Anything, "token-1").Return(false, domain.CustomError{})
+	suite.mockRepo.On("GetUserByID", mock.Anything, "user-1").Return(domain.User{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "User not found"})
+
+	err := suite.usecase.CheckTokenRevocation(context.TODO(), "user-1", "token-1", time.Now())
+
+	suite.Equal(http.StatusUnauthorized, err.ErrCode)
+}
+
+// Test Logout with a token issued before sessions existed
+func (suite *UserUsecaseSuite) TestLogout_NoSession() {
+	err := suite.usecase.Logout(context.TODO(), domain.AuthUser{UserID: "user-1"})
+
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
 }
 
 // Test AuthenticateUser with Invalid Credentials
@@ -153,9 +412,9 @@ func (suite *UserUsecaseSuite) TestAuthenticateUser_InvalidCredentials() {
 	user := domain.User{Username: "testuser"}
 	suite.mockRepo.On("GetUserByUsername", mock.Anything, user.Username).Return(domain.User{}, domain.CustomError{ErrCode: 404, ErrMessage: "User not found"})
 
-	token, err := suite.usecase.AuthenticateUser(context.TODO(), user.Username, "password")
+	tokens, err := suite.usecase.AuthenticateUser(context.TODO(), user.Username, "password")
 
-	suite.Equal("", token)
+	suite.Equal("", tokens.Token)
 	suite.Equal(401, err.ErrCode)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
@@ -164,15 +423,58 @@ func (suite *UserUsecaseSuite) TestAuthenticateUser_InvalidCredentials() {
 func (suite *UserUsecaseSuite) TestPromoteUser() {
 	user := domain.User{Username: "testuser", Role: "user"}
 
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "admin").Return(domain.Role{Name: "admin"}, domain.CustomError{})
 	suite.mockRepo.On("GetUserByUsername", mock.Anything, user.Username).Return(user, domain.CustomError{})
-	suite.mockRepo.On("UpdateUser", mock.Anything, mock.AnythingOfType("domain.User")).Return(domain.CustomError{})
+	suite.mockRepo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(updated domain.User) bool {
+		// the tokens that still carry the old role stop working
+		return updated.Role == "admin" && updated.TokensValidAfter != nil
+	})).Return(domain.CustomError{})
 
-	err := suite.usecase.PromoteUser(context.TODO(), user.Username)
+	err := suite.usecase.PromoteUser(context.TODO(), user.Username, "")
 
 	suite.Empty(err.ErrMessage)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+// Test PromoteUser to a custom role
+func (suite *UserUsecaseSuite) TestPromoteUserToCustomRole() {
+	user := domain.User{Username: "testuser", Role: "user"}
+
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "manager").Return(domain.Role{Name: "manager"}, domain.CustomError{})
+	suite.mockRepo.On("GetUserByUsername", mock.Anything, user.Username).Return(user, domain.CustomError{})
+	suite.mockRepo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(updated domain.User) bool {
+		return updated.Role == "manager"
+	})).Return(domain.CustomError{})
+
+	err := suite.usecase.PromoteUser(context.TODO(), user.Username, "manager")
+
+	suite.Empty(err.ErrMessage)
+}
+
+// Test PromoteUser to a role that does not exist
+func (suite *UserUsecaseSuite) TestPromoteUserUnknownRole() {
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "missing").Return(domain.Role{}, domain.CustomError{ErrCode: 404, ErrMessage: "Role not found"})
+
+	err := suite.usecase.PromoteUser(context.TODO(), "testuser", "missing")
+
+	suite.Equal(400, err.ErrCode)
+	suite.Equal("role", err.Field)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateUser", mock.Anything, mock.Anything)
+}
+
+// Test PromoteUser cannot demote the last enabled admin
+func (suite *UserUsecaseSuite) TestPromoteUserLastAdmin() {
+	user := domain.User{Username: "root", Role: "admin"}
+	suite.mockRoleRepo.On("GetRoleByName", mock.Anything, "user").Return(domain.Role{Name: "user"}, domain.CustomError{})
+	suite.mockRepo.On("GetUserByUsername", mock.Anything, user.Username).Return(user, domain.CustomError{})
+	suite.mockRepo.On("CountEnabledUsersWithRole", mock.Anything, "admin").Return(int64(1), domain.CustomError{})
+
+	err := suite.usecase.PromoteUser(context.TODO(), user.Username, "user")
+
+	suite.Equal(http.StatusConflict, err.ErrCode)
+	suite.mockRepo.AssertNotCalled(suite.T(), "UpdateUser", mock.Anything, mock.Anything)
+}
+
 // Run the test suite
 func TestUserUsecaseSuite(t *testing.T) {
 	suite.Run(t, new(UserUsecaseSuite))
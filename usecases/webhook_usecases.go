@@ -0,0 +1,260 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/infrastructure"
+	"time"
+)
+
+type webhookUsecase struct {
+	webhookRepository  domain.WebhookRepository
+	deliveryRepository domain.WebhookDeliveryRepository
+	sender             infrastructure.WebhookSender
+	// maxAttempts is how often a delivery is tried before it is marked failed.
+	maxAttempts int
+}
+
+func NewWebhookUsecase(webhookRepository domain.WebhookRepository, deliveryRepository domain.WebhookDeliveryRepository, sender infrastructure.WebhookSender, maxAttempts int) domain.WebhookUsecase {
+	if maxAttempts <= 0 {
+		maxAttempts = domain.DefaultWebhookMaxAttempts
+	}
+	return &webhookUsecase{
+		webhookRepository:  webhookRepository,
+		deliveryRepository: deliveryRepository,
+		sender:             sender,
+		maxAttempts:        maxAttempts,
+	}
+}
+
+// CreateWebhook subscribes a URL to task events. The route is admin only.
+func (wu *webhookUsecase) CreateWebhook(c context.Context, user domain.AuthUser, input domain.WebhookInput) (domain.Webhook, domain.CustomError) {
+	webhook, err := domain.NormalizeWebhook(input)
+	if err.ErrCode != 0 {
+		return domain.Webhook{}, err
+	}
+	if webhook.Secret == "" {
+		return domain.Webhook{}, domain.CustomError{ErrCode: http.StatusBadRequest, ErrMessage: "secret is required", Field: "secret"}
+	}
+	webhook.CreatedBy = user.UserID
+	webhook.CreatedAt = time.Now().UTC()
+
+	webhook.ID, err = wu.webhookRepository.CreateWebhook(c, webhook)
+	if err.ErrCode != 0 {
+		return domain.Webhook{}, err
+	}
+	return webhook, domain.CustomError{}
+}
+
+func (wu *webhookUsecase) GetWebhooks(c context.Context) ([]domain.Webhook, domain.CustomError) {
+	return wu.webhookRepository.GetWebhooks(c)
+}
+
+func (wu *webhookUsecase) GetWebhookByID(c context.Context, webhookId string) (domain.Webhook, domain.CustomError) {
+	return wu.webhookRepository.GetWebhookByID(c, webhookId)
+}
+
+// UpdateWebhook replaces a webhook's URL and events, and its secret when a new one is given.
+func (wu *webhookUsecase) UpdateWebhook(c context.Context, webhookId string, input domain.WebhookInput) (domain.Webhook, domain.CustomError) {
+	update, err := domain.NormalizeWebhook(input)
+	if err.ErrCode != 0 {
+		return domain.Webhook{}, err
+	}
+	webhook, err := wu.webhookRepository.GetWebhookByID(c, webhookId)
+	if err.ErrCode != 0 {
+		return domain.Webhook{}, err
+	}
+
+	webhook.URL = update.URL
+	webhook.Events = update.Events
+	if update.Secret != "" {
+		webhook.Secret = update.Secret
+	}
+	if err := wu.webhookRepository.UpdateWebhook(c, webhook); err.ErrCode != 0 {
+		return domain.Webhook{}, err
+	}
+	return webhook, domain.CustomError{}
+}
+
+// DeleteWebhook removes a webhook together with its delivery log, which stops its pending deliveries.
+func (wu *webhookUsecase) DeleteWebhook(c context.Context, webhookId string) domain.CustomError {
+	if err := wu.webhookRepository.DeleteWebhook(c, webhookId); err.ErrCode != 0 {
+		return err
+	}
+	return wu.deliveryRepository.DeleteDeliveries(c, webhookId)
+}
+
+// GetDeliveries returns one page of a webhook's delivery log, newest first.
+func (wu *webhookUsecase) GetDeliveries(c context.Context, webhookId string, cursor string, limit int64) (domain.WebhookDeliveryPage, domain.CustomError) {
+	limit, err := pageLimit(limit)
+	if err.ErrCode != 0 {
+		return domain.WebhookDeliveryPage{}, err
+	}
+	if _, err := wu.webhookRepository.GetWebhookByID(c, webhookId); err.ErrCode != 0 {
+		return domain.WebhookDeliveryPage{}, err
+	}
+	return wu.deliveryRepository.GetDeliveries(c, webhookId, cursor, limit)
+}
+
+// GetDeliveryByID returns one delivery of a webhook.
+func (wu *webhookUsecase) GetDeliveryByID(c context.Context, webhookId string, deliveryId string) (domain.WebhookDelivery, domain.CustomError) {
+	delivery, err := wu.deliveryRepository.GetDeliveryByID(c, deliveryId)
+	if err.ErrCode != 0 {
+		return domain.WebhookDelivery{}, err
+	}
+	if delivery.WebhookID != webhookId {
+		return domain.WebhookDelivery{}, domain.CustomError{ErrCode: http.StatusNotFound, ErrMessage: "Webhook delivery not found"}
+	}
+	return delivery, domain.CustomError{}
+}
+
+// Redeliver sends the payload of an earlier delivery again as a new delivery, signed with the webhook's
+// current secret. The first attempt is made right away; when it fails the new delivery is retried like
+// any other.
+func (wu *webhookUsecase) Redeliver(c context.Context, webhookId string, deliveryId string) (domain.WebhookDelivery, domain.CustomError) {
+	webhook, err := wu.webhookRepository.GetWebhookByID(c, webhookId)
+	if err.ErrCode != 0 {
+		return domain.WebhookDelivery{}, err
+	}
+	original, err := wu.GetDeliveryByID(c, webhookId, deliveryId)
+	if err.ErrCode != 0 {
+		return domain.WebhookDelivery{}, err
+	}
+
+	now := time.Now().UTC()
+	delivery := domain.WebhookDelivery{
+		WebhookID:     webhookId,
+		EventID:       original.EventID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        domain.DeliveryPending,
+		CreatedAt:     now,
+		NextAttemptAt: &now,
+		RedeliveryOf:  original.ID,
+	}
+	delivery.ID, err = wu.deliveryRepository.CreateDelivery(c, delivery)
+	if err.ErrCode != 0 {
+		return domain.WebhookDelivery{}, err
+	}
+	return wu.attempt(c, webhook, delivery)
+}
+
+// Publish queues a delivery of the event for every webhook subscribed to it; the dispatcher sends them.
+// Failures are logged rather than returned, so they never undo the change that raised the event.
+func (wu *webhookUsecase) Publish(c context.Context, event domain.TaskEvent) {
+	webhooks, err := wu.webhookRepository.GetWebhooks(c)
+	if err.ErrCode != 0 {
+		log.Printf("webhook event %s for task %s not published: %s", event.Type, event.Task.ID, err.ErrMessage)
+		return
+	}
+	if event.ID == "" {
+		event.ID = newEventID()
+	}
+	payload, marshalErr := json.Marshal(event)
+	if marshalErr != nil {
+		log.Printf("webhook event %s for task %s not published: %s", event.Type, event.Task.ID, marshalErr)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event.Type) {
+			continue
+		}
+		delivery := domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			Event:         event.Type,
+			Payload:       string(payload),
+			Status:        domain.DeliveryPending,
+			CreatedAt:     now,
+			NextAttemptAt: &now,
+		}
+		if _, err := wu.deliveryRepository.CreateDelivery(c, delivery); err.ErrCode != 0 {
+			log.Printf("webhook event %s for task %s not queued for webhook %s: %s", event.Type, event.Task.ID, webhook.ID, err.ErrMessage)
+		}
+	}
+}
+
+// DispatchDueDeliveries sends the pending deliveries that are due and returns how many succeeded. Failed
+// attempts are recorded on the delivery and retried with exponential backoff; only errors of the
+// delivery log itself are returned.
+func (wu *webhookUsecase) DispatchDueDeliveries(c context.Context) (int, domain.CustomError) {
+	deliveries, err := wu.deliveryRepository.GetDueDeliveries(c, time.Now().UTC(), domain.WebhookDispatchBatch)
+	if err.ErrCode != 0 {
+		return 0, err
+	}
+
+	webhooks := map[string]domain.Webhook{}
+	delivered := 0
+	for _, delivery := range deliveries {
+		webhook, found := webhooks[delivery.WebhookID]
+		if !found {
+			webhook, err = wu.webhookRepository.GetWebhookByID(c, delivery.WebhookID)
+			if err.ErrCode == http.StatusNotFound {
+				delivery.Status = domain.DeliveryFailed
+				delivery.LastError = "Webhook was deleted"
+				delivery.NextAttemptAt = nil
+				if err := wu.deliveryRepository.UpdateDelivery(c, delivery); err.ErrCode != 0 {
+					return delivered, err
+				}
+				continue
+			}
+			if err.ErrCode != 0 {
+				return delivered, err
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		delivery, err = wu.attempt(c, webhook, delivery)
+		if err.ErrCode != 0 {
+			return delivered, err
+		}
+		if delivery.Status == domain.DeliverySucceeded {
+			delivered++
+		}
+	}
+	return delivered, domain.CustomError{}
+}
+
+// attempt sends a delivery once and records the outcome. A failed attempt schedules the next one after
+// the backoff, until the delivery runs out of attempts.
+func (wu *webhookUsecase) attempt(c context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (domain.WebhookDelivery, domain.CustomError) {
+	status, sendErr := wu.sender.Send(c, webhook, delivery)
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	delivery.LastAttemptAt = &now
+	delivery.NextAttemptAt = nil
+	switch {
+	case sendErr.ErrCode == 0:
+		delivery.Status = domain.DeliverySucceeded
+		delivery.LastError = ""
+	case delivery.Attempts >= wu.maxAttempts:
+		delivery.Status = domain.DeliveryFailed
+		delivery.LastError = sendErr.ErrMessage
+	default:
+		delivery.Status = domain.DeliveryPending
+		delivery.LastError = sendErr.ErrMessage
+		next := now.Add(domain.WebhookBackoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	}
+
+	if err := wu.deliveryRepository.UpdateDelivery(c, delivery); err.ErrCode != 0 {
+		return domain.WebhookDelivery{}, err
+	}
+	return delivery, domain.CustomError{}
+}
+
+// newEventID returns a random ID that receivers can use to spot an event delivered twice.
+func newEventID() string {
+	id := make([]byte, 12)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,244 @@
+package usecases_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"task_managment_api/domain"
+	"task_managment_api/usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockTaskEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockTaskEventPublisher) Publish(c context.Context, event domain.TaskEvent) {
+	m.Called(c, event)
+}
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateWebhook(c context.Context, webhook domain.Webhook) (string, domain.CustomError) {
+	args := m.Called(c, webhook)
+	return args.String(0), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookRepository) GetWebhooks(c context.Context) ([]domain.Webhook, domain.CustomError) {
+	args := m.Called(c)
+	return args.Get(0).([]domain.Webhook), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookRepository) GetWebhookByID(c context.Context, webhookID string) (domain.Webhook, domain.CustomError) {
+	args := m.Called(c, webhookID)
+	return args.Get(0).(domain.Webhook), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookRepository) UpdateWebhook(c context.Context, webhook domain.Webhook) domain.CustomError {
+	args := m.Called(c, webhook)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockWebhookRepository) DeleteWebhook(c context.Context, webhookID string) domain.CustomError {
+	args := m.Called(c, webhookID)
+	return args.Get(0).(domain.CustomError)
+}
+
+type MockWebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookDeliveryRepository) CreateDelivery(c context.Context, delivery domain.WebhookDelivery) (string, domain.CustomError) {
+	args := m.Called(c, delivery)
+	return args.String(0), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookDeliveryRepository) GetDeliveries(c context.Context, webhookID string, cursor string, limit int64) (domain.WebhookDeliveryPage, domain.CustomError) {
+	args := m.Called(c, webhookID, cursor, limit)
+	return args.Get(0).(domain.WebhookDeliveryPage), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookDeliveryRepository) GetDeliveryByID(c context.Context, deliveryID string) (domain.WebhookDelivery, domain.CustomError) {
+	args := m.Called(c, deliveryID)
+	return args.Get(0).(domain.WebhookDelivery), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookDeliveryRepository) GetDueDeliveries(c context.Context, now time.Time, limit int64) ([]domain.WebhookDelivery, domain.CustomError) {
+	args := m.Called(c, now, limit)
+	return args.Get(0).([]domain.WebhookDelivery), args.Get(1).(domain.CustomError)
+}
+
+func (m *MockWebhookDeliveryRepository) UpdateDelivery(c context.Context, delivery domain.WebhookDelivery) domain.CustomError {
+	args := m.Called(c, delivery)
+	return args.Get(0).(domain.CustomError)
+}
+
+func (m *MockWebhookDeliveryRepository) DeleteDeliveries(c context.Context, webhookID string) domain.CustomError {
+	args := m.Called(c, webhookID)
+	return args.Get(0).(domain.CustomError)
+}
+
+type MockWebhookSender struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSender) Send(c context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, domain.CustomError) {
+	args := m.Called(c, webhook, delivery)
+	return args.Int(0), args.Get(1).(domain.CustomError)
+}
+
+type WebhookUsecaseSuite struct {
+	suite.Suite
+	mockWebhookRepo  *MockWebhookRepository
+	mockDeliveryRepo *MockWebhookDeliveryRepository
+	mockSender       *MockWebhookSender
+	usecase          domain.WebhookUsecase
+	admin            domain.AuthUser
+	webhook          domain.Webhook
+}
+
+func (suite *WebhookUsecaseSuite) SetupTest() {
+	suite.mockWebhookRepo = new(MockWebhookRepository)
+	suite.mockDeliveryRepo = new(MockWebhookDeliveryRepository)
+	suite.mockSender = new(MockWebhookSender)
+	suite.usecase = usecases.NewWebhookUsecase(suite.mockWebhookRepo, suite.mockDeliveryRepo, suite.mockSender, 3)
+	suite.admin = domain.AuthUser{UserID: "admin-1", Username: "admin", Role: "admin"}
+	suite.webhook = domain.Webhook{ID: "hook-1", URL: "https://example.com/hooks", Events: []string{domain.EventTaskCreated}, Secret: "0123456789abcdef"}
+}
+
+// Test CreateWebhook stores the normalized subscription
+func (suite *WebhookUsecaseSuite) TestCreateWebhook() {
+	input := domain.WebhookInput{URL: "https://example.com/hooks", Events: []string{"Task.Created", "task.created"}, Secret: "0123456789abcdef"}
+	suite.mockWebhookRepo.On("CreateWebhook", mock.Anything, mock.MatchedBy(func(webhook domain.Webhook) bool {
+		return webhook.URL == "https://example.com/hooks" && len(webhook.Events) == 1 && webhook.CreatedBy == "admin-1"
+	})).Return("hook-1", domain.CustomError{})
+
+	webhook, err := suite.usecase.CreateWebhook(context.TODO(), suite.admin, input)
+
+	suite.Empty(err.ErrCode)
+	suite.Equal("hook-1", webhook.ID)
+	suite.Equal([]string{domain.EventTaskCreated}, webhook.Events)
+}
+
+// Test CreateWebhook needs a secret and known events
+func (suite *WebhookUsecaseSuite) TestCreateWebhook_Invalid() {
+	_, err := suite.usecase.CreateWebhook(context.TODO(), suite.admin, domain.WebhookInput{URL: "https://example.com/hooks"})
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("secret", err.Field)
+
+	_, err = suite.usecase.CreateWebhook(context.TODO(), suite.admin, domain.WebhookInput{URL: "https://example.com/hooks", Events: []string{"task.renamed"}, Secret: "0123456789abcdef"})
+	suite.Equal(http.StatusBadRequest, err.ErrCode)
+	suite.Equal("events", err.Field)
+
+	suite.mockWebhookRepo.AssertNotCalled(suite.T(), "CreateWebhook", mock.Anything, mock.Anything)
+}
+
+// Test UpdateWebhook keeps the secret when no new one is given
+func (suite *WebhookUsecaseSuite) TestUpdateWebhook_KeepsSecret() {
+	suite.mockWebhookRepo.On("GetWebhookByID", mock.Anything, "hook-1").Return(suite.webhook, domain.CustomError{})
+	suite.mockWebhookRepo.On("UpdateWebhook", mock.Anything, mock.MatchedBy(func(webhook domain.Webhook) bool {
+		return webhook.Secret == suite.webhook.Secret && webhook.URL == "https://example.com/other"
+	})).Return(domain.CustomError{})
+
+	webhook, err := suite.usecase.UpdateWebhook(context.TODO(), "hook-1", domain.WebhookInput{URL: "https://example.com/other"})
+
+	suite.Empty(err.ErrCode)
+	suite.Equal([]string{domain.WebhookEventAll}, webhook.Events)
+}
+
+// Test Publish queues a delivery for the subscribed webhooks only
+func (suite *WebhookUsecaseSuite) TestPublish() {
+	other := domain.Webhook{ID: "hook-2", URL: "https://example.com/other", Events: []string{domain.EventTaskDeleted}}
+	suite.mockWebhookRepo.On("GetWebhooks", mock.Anything).Return([]domain.Webhook{suite.webhook, other}, domain.CustomError{})
+	suite.mockDeliveryRepo.On("CreateDelivery", mock.Anything, mock.MatchedBy(func(delivery domain.WebhookDelivery) bool {
+		var event domain.TaskEvent
+		if err := json.Unmarshal([]byte(delivery.Payload), &event); err != nil {
+			return false
+		}
+		return delivery.WebhookID == "hook-1" && delivery.Status == domain.DeliveryPending && delivery.NextAttemptAt != nil &&
+			event.ID != "" && event.ID == delivery.EventID && event.Type == domain.EventTaskCreated && event.Task.ID == "task-1"
+	})).Return("delivery-1", domain.CustomError{})
+
+	suite.usecase.Publish(context.TODO(), domain.TaskEvent{Type: domain.EventTaskCreated, Task: domain.Task{ID: "task-1"}})
+
+	suite.mockDeliveryRepo.AssertNumberOfCalls(suite.T(), "CreateDelivery", 1)
+}
+
+// Test a successful attempt marks the delivery as succeeded
+func (suite *WebhookUsecaseSuite) TestDispatchDueDeliveries_Success() {
+	delivery := domain.WebhookDelivery{ID: "delivery-1", WebhookID: "hook-1", Event: domain.EventTaskCreated, Payload: "{}", Status: domain.DeliveryPending}
+	suite.mockDeliveryRepo.On("GetDueDeliveries", mock.Anything, mock.Anything, int64(domain.WebhookDispatchBatch)).Return([]domain.WebhookDelivery{delivery}, domain.CustomError{})
+	suite.mockWebhookRepo.On("GetWebhookByID", mock.Anything, "hook-1").Return(suite.webhook, domain.CustomError{})
+	suite.mockSender.On("Send", mock.Anything, suite.webhook, delivery).Return(http.StatusOK, domain.CustomError{})
+	suite.mockDeliveryRepo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(updated domain.WebhookDelivery) bool {
+		return updated.Status == domain.DeliverySucceeded && updated.Attempts == 1 && updated.ResponseStatus == http.StatusOK && updated.NextAttemptAt == nil
+	})).Return(domain.CustomError{})
+
+	delivered, err := suite.usecase.DispatchDueDeliveries(context.TODO())
+
+	suite.Empty(err.ErrCode)
+	suite.Equal(1, delivered)
+}
+
+// Test a failed attempt is retried after the backoff until the attempts run out
+func (suite *WebhookUsecaseSuite) TestDispatchDueDeliveries_Retry() {
+	retrying := domain.WebhookDelivery{ID: "delivery-1", WebhookID: "hook-1", Status: domain.DeliveryPending, Attempts: 1}
+	lastTry := domain.WebhookDelivery{ID: "delivery-2", WebhookID: "hook-1", Status: domain.DeliveryPending, Attempts: 2}
+	suite.mockDeliveryRepo.On("GetDueDeliveries", mock.Anything, mock.Anything, mock.Anything).Return([]domain.WebhookDelivery{retrying, lastTry}, domain.CustomError{})
+	suite.mockWebhookRepo.On("GetWebhookByID", mock.Anything, "hook-1").Return(suite.webhook, domain.CustomError{}).Once()
+	suite.mockSender.On("Send", mock.Anything, mock.Anything, mock.Anything).Return(http.StatusInternalServerError, domain.CustomError{ErrCode: http.StatusBadGateway, ErrMessage: "Webhook answered with status 500"})
+	suite.mockDeliveryRepo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(updated domain.WebhookDelivery) bool {
+		return updated.ID == "delivery-1" && updated.Status == domain.DeliveryPending && updated.Attempts == 2 &&
+			updated.NextAttemptAt != nil && updated.NextAttemptAt.Sub(*updated.LastAttemptAt) == 2*domain.WebhookBaseBackoff
+	})).Return(domain.CustomError{})
+	suite.mockDeliveryRepo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(updated domain.WebhookDelivery) bool {
+		return updated.ID == "delivery-2" && updated.Status == domain.DeliveryFailed && updated.Attempts == 3 &&
+			updated.NextAttemptAt == nil && updated.LastError == "Webhook answered with status 500"
+	})).Return(domain.CustomError{})
+
+	delivered, err := suite.usecase.DispatchDueDeliveries(context.TODO())
+
+	suite.Empty(err.ErrCode)
+	suite.Equal(0, delivered)
+	suite.mockDeliveryRepo.AssertNumberOfCalls(suite.T(), "UpdateDelivery", 2)
+}
+
+// Test Redeliver sends the same payload again as a new delivery
+func (suite *WebhookUsecaseSuite) TestRedeliver() {
+	original := domain.WebhookDelivery{ID: "delivery-1", WebhookID: "hook-1", EventID: "event-1", Event: domain.EventTaskCreated, Payload: `{"id":"event-1"}`, Status: domain.DeliveryFailed, Attempts: 3}
+	suite.mockWebhookRepo.On("GetWebhookByID", mock.Anything, "hook-1").Return(suite.webhook, domain.CustomError{})
+	suite.mockDeliveryRepo.On("GetDeliveryByID", mock.Anything, "delivery-1").Return(original, domain.CustomError{})
+	suite.mockDeliveryRepo.On("CreateDelivery", mock.Anything, mock.MatchedBy(func(delivery domain.WebhookDelivery) bool {
+		return delivery.Payload == original.Payload && delivery.EventID == "event-1" && delivery.RedeliveryOf == "delivery-1" && delivery.Attempts == 0
+	})).Return("delivery-2", domain.CustomError{})
+	suite.mockSender.On("Send", mock.Anything, suite.webhook, mock.MatchedBy(func(delivery domain.WebhookDelivery) bool {
+		return delivery.ID == "delivery-2"
+	})).Return(http.StatusNoContent, domain.CustomError{})
+	suite.mockDeliveryRepo.On("UpdateDelivery", mock.Anything, mock.Anything).Return(domain.CustomError{})
+
+	delivery, err := suite.usecase.Redeliver(context.TODO(), "hook-1", "delivery-1")
+
+	suite.Empty(err.ErrCode)
+	suite.Equal("delivery-2", delivery.ID)
+	suite.Equal(domain.DeliverySucceeded, delivery.Status)
+	suite.Equal(1, delivery.Attempts)
+}
+
+// Test a delivery of another webhook is not found
+func (suite *WebhookUsecaseSuite) TestGetDeliveryByID_OtherWebhook() {
+	suite.mockDeliveryRepo.On("GetDeliveryByID", mock.Anything, "delivery-1").Return(domain.WebhookDelivery{ID: "delivery-1", WebhookID: "hook-2"}, domain.CustomError{})
+
+	_, err := suite.usecase.GetDeliveryByID(context.TODO(), "hook-1", "delivery-1")
+
+	suite.Equal(http.StatusNotFound, err.ErrCode)
+}
+
+func TestWebhookUsecaseSuite(t *testing.T) {
+	suite.Run(t, new(WebhookUsecaseSuite))
+}